@@ -1,67 +1,279 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"go.opentelemetry.io/otel"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/klog/v2/textlogger"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/audit"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/boskos"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned"
 	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/controller"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/restapi"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler/plugins"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/tracing"
 )
 
 func main() {
-	logger := textlogger.NewLogger(textlogger.NewConfig())
+	schedulerConfigPath := flag.String("scheduler-config", "", "path to the scheduler profile configuration. the default profiles are used if not set.")
+	cleanupJobTemplatePath := flag.String("cleanup-job-template", "", "path to a Job template run when a lease holding resources is deleted. the resources are released once the Job finishes.")
+	networkQuarantine := flag.Duration("network-quarantine", 0, "how long networks released by a lease are not assigned to other leases, unless a cleanup check passes before.")
+	networkFailureThreshold := flag.Int("network-failure-threshold", controller.DEFAULT_NETWORK_FAILURE_THRESHOLD, "number of leases reporting a network as broken within the failure window which disables the network. 0 never disables networks.")
+	networkFailureWindow := flag.Duration("network-failure-window", controller.DEFAULT_NETWORK_FAILURE_WINDOW, "how long reports of a broken network count towards disabling it.")
+	poolOutcomeWindow := flag.Duration("pool-outcome-window", controller.DEFAULT_POOL_OUTCOME_WINDOW, "how long the outcomes reported by leases count towards the health of a pool.")
+	poolInfraFailureThreshold := flag.Int("pool-infra-failure-threshold", 0, "percentage of leases reporting an infrastructure failure within the outcome window which taints a pool. 0 never taints pools.")
+	poolInfraFailureMinLeases := flag.Int("pool-infra-failure-min-leases", controller.DEFAULT_POOL_INFRA_FAILURE_MIN_LEASES, "number of outcomes a pool needs within the outcome window before it is tainted.")
+	usageRetention := flag.Duration("usage-retention", controller.DEFAULT_USAGE_RETENTION, "how long the usage records of released leases are kept. 0 keeps them forever.")
+	forecastWindow := flag.Duration("forecast-window", controller.DEFAULT_FORECAST_WINDOW, "how far back the leases used to forecast lease wait times and pool capacity go.")
+	apiBindAddress := flag.String("api-bind-address", "", "address the lease API for clients without a kubeconfig listens on, such as :8443. the API is disabled if not set.")
+	apiTokensPath := flag.String("api-tokens", "", "path to the file mapping the bearer tokens of the lease API to namespaces.")
+	apiCertFile := flag.String("api-tls-cert-file", "", "path to the TLS certificate of the lease API. the API is served over plain HTTP if not set.")
+	apiKeyFile := flag.String("api-tls-key-file", "", "path to the TLS key of the lease API.")
+	apiMaxWait := flag.Duration("api-max-wait", restapi.DEFAULT_MAX_WAIT, "how long a request to the lease API may wait for a lease to be fulfilled.")
+	boskosBindAddress := flag.String("boskos-bind-address", "", "address the boskos protocol listens on, such as :8081. boskos is not served if not set.")
+	boskosNamespace := flag.String("boskos-namespace", "vsphere-infra-helpers", "namespace of the leases acquired through boskos.")
+	boskosResourceType := flag.String("boskos-resource-type", boskos.DEFAULT_RESOURCE_TYPE, "boskos resource type served.")
+	boskosExpiry := flag.Duration("boskos-expiry", boskos.DEFAULT_EXPIRY, "how long a lease acquired through boskos is kept without a heartbeat from its owner.")
+	auditLogPath := flag.String("audit-log", "", "path of the file the allocations and releases of pools and networks are appended to as JSON lines, or - for stdout. nothing is recorded if not set.")
+	auditWebhookURL := flag.String("audit-webhook-url", "", "URL each allocation and release of a pool or network is posted to as JSON.")
+	logFormat := flag.String("log-format", "text", "format of the logs, text or json.")
+	verbosity := flag.Int("v", 0, "verbosity of the logs. the scheduling decisions are logged at 2, their details at 4.")
+	otlpEndpoint := flag.String("otlp-endpoint", "", "OTLP/HTTP endpoint the traces of the reconciling and scheduling of leases are exported to, such as http://otel-collector:4318. traces are not recorded unless this or --trace-file is set.")
+	traceFile := flag.String("trace-file", "", "path of the file the traces are appended to as OTLP/JSON lines, or - for stdout.")
+	flag.Parse()
+
+	logger, err := newLogger(*logFormat, *verbosity)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
 	ctrl.SetLogger(logger)
+	setupLog := logger.WithName("setup")
 
-	mgr, err := manager.New(config.GetConfigOrDie(), manager.Options{})
+	tracerProvider, err := tracing.NewProvider(*otlpEndpoint, *traceFile)
 	if err != nil {
-		log.Printf("could not create manager: %v", err)
+		setupLog.Error(err, "could not set up tracing")
 		os.Exit(1)
 	}
+	if tracerProvider != nil {
+		tracingLog := logger.WithName("tracing")
+		otel.SetLogger(tracingLog)
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			tracingLog.Error(err, "unable to export traces")
+		}))
+		otel.SetTracerProvider(tracerProvider)
+	}
+
+	usageReport := &controller.UsageReportHandler{}
+	forecastHandler := &controller.ForecastHandler{}
+	mgr, err := manager.New(config.GetConfigOrDie(), manager.Options{
+		Metrics: metricsserver.Options{
+			ExtraHandlers: map[string]http.Handler{
+				controller.USAGE_REPORT_PATH: usageReport,
+				controller.FORECAST_PATH:     forecastHandler,
+			},
+		},
+	})
+	if err != nil {
+		setupLog.Error(err, "could not create manager")
+		os.Exit(1)
+	}
+	usageReport.Reader = mgr.GetAPIReader()
 
 	err = v1.AddToScheme(mgr.GetScheme())
 	if err != nil {
-		log.Printf("could not add types to scheme: %v", err)
+		setupLog.Error(err, "could not add types to scheme")
 		os.Exit(1)
 	}
 
 	controller.InitMetrics()
 
-	if err := (&controller.PoolReconciler{}).
-		SetupWithManager(mgr); err != nil {
-		log.Printf("unable to create controller: %v", err)
+	var schedulerConfig *scheduler.Config
+	if *schedulerConfigPath != "" {
+		schedulerConfig, err = scheduler.LoadConfig(*schedulerConfigPath)
+		if err != nil {
+			setupLog.Error(err, "could not load scheduler config")
+			os.Exit(1)
+		}
+	}
+	leaseScheduler, err := plugins.NewScheduler(schedulerConfig)
+	if err != nil {
+		setupLog.Error(err, "could not create scheduler")
 		os.Exit(1)
 	}
 
-	if err := (&controller.LeaseReconciler{
-		// This will be set for now via constant, but might be good in future to make configurable via startup parameter.
-		AllowMultiToUseSingle: controller.ALLOW_MULTI_TO_USE_SINGLE,
+	var cleanupJobTemplate *batchv1.JobTemplateSpec
+	if *cleanupJobTemplatePath != "" {
+		cleanupJobTemplate, err = controller.LoadCleanupJobTemplate(*cleanupJobTemplatePath)
+		if err != nil {
+			setupLog.Error(err, "could not load cleanup job template")
+			os.Exit(1)
+		}
+	}
+
+	if err := (&controller.PoolReconciler{
+		OutcomeWindow:         *poolOutcomeWindow,
+		InfraFailureThreshold: *poolInfraFailureThreshold,
+		InfraFailureMinLeases: *poolInfraFailureMinLeases,
 	}).
 		SetupWithManager(mgr); err != nil {
-		log.Printf("unable to create controller: %v", err)
+		setupLog.Error(err, "unable to create controller")
 		os.Exit(1)
 	}
 
+	var auditSinks []audit.Sink
+	if *auditLogPath != "" {
+		auditLog, err := audit.OpenFile(*auditLogPath)
+		if err != nil {
+			setupLog.Error(err, "could not open audit log")
+			os.Exit(1)
+		}
+		auditSinks = append(auditSinks, auditLog)
+	}
+	if *auditWebhookURL != "" {
+		auditWebhook := audit.NewWebhook(*auditWebhookURL)
+		if err := mgr.Add(auditWebhook); err != nil {
+			setupLog.Error(err, "unable to add the audit webhook")
+			os.Exit(1)
+		}
+		auditSinks = append(auditSinks, auditWebhook)
+	}
+	var auditLogger *audit.Logger
+	if len(auditSinks) > 0 {
+		auditLogger = audit.NewLogger(auditSinks...)
+	}
+
+	leaseReconciler := &controller.LeaseReconciler{
+		// This will be set for now via constant, but might be good in future to make configurable via startup parameter.
+		AllowMultiToUseSingle:   controller.ALLOW_MULTI_TO_USE_SINGLE,
+		Scheduler:               leaseScheduler,
+		CleanupJobTemplate:      cleanupJobTemplate,
+		NetworkQuarantine:       *networkQuarantine,
+		NetworkFailureThreshold: *networkFailureThreshold,
+		NetworkFailureWindow:    *networkFailureWindow,
+		ForecastWindow:          *forecastWindow,
+		Audit:                   auditLogger,
+	}
+	if err := leaseReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller")
+		os.Exit(1)
+	}
+
+	forecastHandler.Leases = leaseReconciler
+
 	if err := (&controller.NetworkReconciler{}).
 		SetupWithManager(mgr); err != nil {
-		log.Printf("unable to create controller: %v", err)
+		setupLog.Error(err, "unable to create controller")
+		os.Exit(1)
+	}
+
+	if err := (&controller.UsageRecordReconciler{
+		Retention: *usageRetention,
+	}).
+		SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller")
 		os.Exit(1)
 	}
 
 	if err := (&controller.NamespaceReconciler{}).
 		SetupWithManager(mgr); err != nil {
-		log.Printf("unable to create controller: %v", err)
+		setupLog.Error(err, "unable to create controller")
 		os.Exit(1)
 	}
 
+	clientset, err := versioned.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "could not create clientset")
+		os.Exit(1)
+	}
+
+	if *apiBindAddress != "" {
+		if *apiTokensPath == "" {
+			setupLog.Error(nil, "--api-tokens is required to serve the lease API")
+			os.Exit(1)
+		}
+		tokens, err := restapi.LoadTokens(*apiTokensPath)
+		if err != nil {
+			setupLog.Error(err, "could not load api tokens")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&restapi.Server{
+			BindAddress: *apiBindAddress,
+			CertFile:    *apiCertFile,
+			KeyFile:     *apiKeyFile,
+			Client:      clientset,
+			Tokens:      tokens,
+			MaxWait:     *apiMaxWait,
+		}); err != nil {
+			setupLog.Error(err, "unable to add the lease API")
+			os.Exit(1)
+		}
+	}
+
+	if *boskosBindAddress != "" {
+		boskosServer := &boskos.Server{
+			BindAddress:  *boskosBindAddress,
+			Client:       clientset,
+			Namespace:    *boskosNamespace,
+			ResourceType: *boskosResourceType,
+			Expiry:       *boskosExpiry,
+		}
+		if err := mgr.Add(boskosServer); err != nil {
+			setupLog.Error(err, "unable to add the boskos server")
+			os.Exit(1)
+		}
+		if err := mgr.Add(manager.RunnableFunc(boskosServer.RunReaper)); err != nil {
+			setupLog.Error(err, "unable to add the boskos reaper")
+			os.Exit(1)
+		}
+	}
+
+	if tracerProvider != nil {
+		// the spans still batched are exported when the manager stops.
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return tracerProvider.Shutdown(shutdownCtx)
+		})); err != nil {
+			setupLog.Error(err, "unable to add the tracer provider")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
-		log.Printf("could not start manager: %v", err)
+		setupLog.Error(err, "could not start manager")
 		os.Exit(1)
 	}
 
 }
+
+// newLogger returns the logger of the manager, writing text or JSON lines to stderr.
+func newLogger(format string, verbosity int) (logr.Logger, error) {
+	switch format {
+	case "text":
+		return textlogger.NewLogger(textlogger.NewConfig(textlogger.Verbosity(verbosity))), nil
+	case "json":
+		return funcr.NewJSON(func(obj string) { fmt.Fprintln(os.Stderr, obj) }, funcr.Options{
+			LogTimestamp: true,
+			Verbosity:    verbosity,
+		}), nil
+	}
+	return logr.Logger{}, fmt.Errorf("unknown log format %q, expected text or json", format)
+}
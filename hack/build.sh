@@ -20,6 +20,7 @@ GOFLAGS="${GOFLAGS:--mod=vendor}"
 LDFLAGS="${LDFLAGS} -X github.com/openshift-splat-team/vsphere-capacity-manager/pkg/version.Raw=${GIT_TAG} -X github.com/openshift-splat-team/vsphere-capacity-manager/pkg/version.Commit=${GIT_COMMIT}"
 TAGS="${TAGS:-}"
 OUTPUT="${OUTPUT:-bin/vsphere-capacity-manager}"
+PACKAGE="${PACKAGE:-./cmd}"
 export CGO_ENABLED=0
 
 case "${MODE}" in
@@ -39,4 +40,4 @@ then
 	export CGO_ENABLED=1
 fi
 
-go build "${GOFLAGS}" -ldflags "${LDFLAGS}" -tags "${TAGS}" -o "${OUTPUT}" ./cmd
+go build "${GOFLAGS}" -ldflags "${LDFLAGS}" -tags "${TAGS}" -o "${OUTPUT}" "${PACKAGE}"
@@ -1,21 +1,56 @@
 #!/usr/bin/env bash
 
+# Generates the clientset, listers and informers of the API group under pkg/client with the vendored
+# code generators. Set CODEGEN_OUTPUT to generate in another directory, as hack/verify-codegen.sh does.
+
 if [ "$IS_CONTAINER" != "" ]; then
   set -xe
 
-  SCRIPT_ROOT=$(dirname ${BASH_SOURCE})/..
-  bash ${SCRIPT_ROOT}/vendor/k8s.io/code-generator/kube_codegen.sh all \
-    github.com/openshift-splat-team/vsphere-capacity-manager/pkg/generated \
-    github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis \
-    "vspherecapacitymanager.splat.io:v1" \
-    --go-header-file ${SCRIPT_ROOT}/hack/custom-boilerplate.go.txt
+  SCRIPT_ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+  MODULE=github.com/openshift-splat-team/vsphere-capacity-manager
+  APIS=${MODULE}/pkg/apis
+  CLIENT=${MODULE}/pkg/client
+  OUTPUT=${CODEGEN_OUTPUT:-${SCRIPT_ROOT}/pkg/client}
+  HEADER=${SCRIPT_ROOT}/hack/boilerplate.go.txt
+  export GOFLAGS="${GOFLAGS:--mod=vendor}"
+
+  WORK=$(mktemp -d)
+  trap 'rm -rf "${WORK}"' EXIT
+
+  cd "${SCRIPT_ROOT}"
+  for gen in client-gen lister-gen informer-gen; do
+    go build -o "${WORK}/bin/${gen}" "./vendor/k8s.io/code-generator/cmd/${gen}"
+  done
+
+  "${WORK}/bin/client-gen" \
+    --go-header-file "${HEADER}" \
+    --clientset-name versioned \
+    --input-base "${APIS}" \
+    --input vspherecapacitymanager.splat.io/v1 \
+    --output-base "${WORK}/src" \
+    --output-package "${CLIENT}/clientset"
+  "${WORK}/bin/lister-gen" \
+    --go-header-file "${HEADER}" \
+    --input-dirs "${APIS}/vspherecapacitymanager.splat.io/v1" \
+    --output-base "${WORK}/src" \
+    --output-package "${CLIENT}/listers"
+  "${WORK}/bin/informer-gen" \
+    --go-header-file "${HEADER}" \
+    --input-dirs "${APIS}/vspherecapacitymanager.splat.io/v1" \
+    --versioned-clientset-package "${CLIENT}/clientset/versioned" \
+    --listers-package "${CLIENT}/listers" \
+    --output-base "${WORK}/src" \
+    --output-package "${CLIENT}/informers"
+
+  rm -rf "${OUTPUT}"
+  mkdir -p "$(dirname "${OUTPUT}")"
+  cp -r "${WORK}/src/${CLIENT}" "${OUTPUT}"
   set +ex
-  # git diff --exit-code
 else
   podman run --rm \
     --env IS_CONTAINER=TRUE \
     --volume "${PWD}:/go/src/github.com/openshift-splat-team/vsphere-capacity-manager:z" \
     --workdir /go/src/github.com/openshift-splat-team/vsphere-capacity-manager \
-    docker.io/golang:1.18 \
+    docker.io/golang:1.22 \
     ./hack/update-codegen.sh "${@}"
 fi
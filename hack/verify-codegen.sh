@@ -1,15 +1,25 @@
-#!/bin/sh
+#!/usr/bin/env bash
+
+# Fails if the generated code under pkg/client is not up to date with the API types.
 
 if [ "$IS_CONTAINER" != "" ]; then
-  set -xe
-  go generate ./pkg/apis/vsphere-capacity-manager.splat-team.io/install.go
-  set +ex
-  # git diff --exit-code
+  set -e
+
+  SCRIPT_ROOT=$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)
+  GENERATED=$(mktemp -d)
+  trap 'rm -rf "${GENERATED}"' EXIT
+
+  CODEGEN_OUTPUT="${GENERATED}/client" "${SCRIPT_ROOT}/hack/update-codegen.sh"
+  if ! diff -Naupr "${SCRIPT_ROOT}/pkg/client" "${GENERATED}/client"; then
+    echo "pkg/client is out of date, run hack/update-codegen.sh" >&2
+    exit 1
+  fi
+  echo "pkg/client is up to date"
 else
   podman run --rm \
     --env IS_CONTAINER=TRUE \
-    --volume "${PWD}:/go/src/github.com/openshift/vsphere-capacity-manager:z" \
-    --workdir /go/src/github.com/openshift/vsphere-capacity-manager \
-    docker.io/golang:1.18 \
+    --volume "${PWD}:/go/src/github.com/openshift-splat-team/vsphere-capacity-manager:z" \
+    --workdir /go/src/github.com/openshift-splat-team/vsphere-capacity-manager \
+    docker.io/golang:1.22 \
     ./hack/verify-codegen.sh "${@}"
 fi
@@ -7,15 +7,53 @@ import (
 type NetworkType string
 
 const (
-	LeaseKind               = "Lease"
-	APIGroupName            = "vsphere-capacity-manager.splat-team.io"
-	LeaseFinalizer          = "vsphere-capacity-manager.splat-team.io/lease-finalizer"
-	LeaseNamespace          = "vsphere-capacity-manager.splat-team.io/lease-namespace"
+	LeaseKind      = "Lease"
+	APIGroupName   = "vsphere-capacity-manager.splat-team.io"
+	LeaseFinalizer = "vsphere-capacity-manager.splat-team.io/lease-finalizer"
+	LeaseNamespace = "vsphere-capacity-manager.splat-team.io/lease-namespace"
+	// LeaseCleanupCompleteAnnotation is set to "true" by the holder of a releasing lease once its resources
+	// are cleaned up.
+	LeaseCleanupCompleteAnnotation = "vsphere-capacity-manager.splat-team.io/cleanup-complete"
+	// LeaseBrokenNetworksAnnotation is a comma separated list of the networks, by name or port group, the
+	// holder of a lease found broken.
+	LeaseBrokenNetworksAnnotation = "vsphere-capacity-manager.splat-team.io/broken-networks"
+	// LeaseBrokenNetworksReportedAnnotation lists the broken networks of a lease already recorded by VCM.
+	LeaseBrokenNetworksReportedAnnotation = "vsphere-capacity-manager.splat-team.io/broken-networks-reported"
+	// LeaseOutcomeAnnotation is the outcome of the job which held the lease, set by the holder before the lease
+	// is deleted. See LeaseOutcome for the supported values.
+	LeaseOutcomeAnnotation = "vsphere-capacity-manager.splat-team.io/outcome"
+	// LeaseOutcomeRecordedAnnotation is set to "true" by VCM once the outcome of a lease was counted towards the
+	// health of its pools.
+	LeaseOutcomeRecordedAnnotation = "vsphere-capacity-manager.splat-team.io/outcome-recorded"
+	// LeaseTraceIDAnnotation is the ID of the trace which last assigned or released the pools and networks of
+	// the lease, set when tracing is enabled.
+	LeaseTraceIDAnnotation = "vsphere-capacity-manager.splat-team.io/trace-id"
+	// BoskosIdLabel is the ID of the Boskos lease of the CI job which created the lease. leases with the same
+	// ID share their networks.
+	BoskosIdLabel = "boskos-lease-id"
+	// JobNameLabel is the name of the CI job which created the lease.
+	JobNameLabel = "job-name"
+	// PROW_JOB_TYPE_KEY is the annotation with the type of the Prow job which created the lease, such as
+	// presubmit or periodic.
+	PROW_JOB_TYPE_KEY = "prow-job-type"
+
 	NetworkTypeDisconnected = NetworkType("disconnected")
 	NetworkTypeSingleTenant = NetworkType("single-tenant")
 	NetworkTypeMultiTenant  = NetworkType("multi-tenant")
 )
 
+// LeaseOutcome is the outcome of the job which held a lease.
+type LeaseOutcome string
+
+const (
+	// LeaseOutcomeSuccess means the job succeeded.
+	LeaseOutcomeSuccess LeaseOutcome = "success"
+	// LeaseOutcomeInstallFailure means the cluster failed to install for reasons not related to the pool.
+	LeaseOutcomeInstallFailure LeaseOutcome = "install-failure"
+	// LeaseOutcomeInfraFailure means the job failed because of the infrastructure of the pool.
+	LeaseOutcomeInfraFailure LeaseOutcome = "infra-failure"
+)
+
 // TolerationOperator is the operator for a toleration.
 type TolerationOperator string
 
@@ -49,6 +87,62 @@ type Toleration struct {
 	Effect string `json:"effect,omitempty"`
 }
 
+// WeightedPoolAffinityTerm is a soft preference for pools matching a label selector.
+type WeightedPoolAffinityTerm struct {
+	// Weight is added to the score of pools matching the preference.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+	// Preference is a label selector matched against pool labels.
+	Preference metav1.LabelSelector `json:"preference"`
+}
+
+// WeightedPoolAntiAffinityTerm is a soft preference to avoid pools which already hold leases matching a
+// label selector. For example, it can be used to spread the jobs of one repository across pools.
+type WeightedPoolAntiAffinityTerm struct {
+	// Weight is the penalty applied for each matching lease already assigned to a pool.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Weight int32 `json:"weight"`
+	// LeaseSelector is a label selector matched against the labels of leases already assigned to a pool.
+	LeaseSelector metav1.LabelSelector `json:"leaseSelector"`
+}
+
+// TopologyKey identifies the pool topology a lease's pools are spread across.
+type TopologyKey string
+
+const (
+	// TopologyKeyServer spreads pools across vCenters.
+	TopologyKeyServer = TopologyKey("server")
+	// TopologyKeyRegion spreads pools across failure domain regions.
+	TopologyKeyRegion = TopologyKey("region")
+	// TopologyKeyZone spreads pools across failure domain zones.
+	TopologyKeyZone = TopologyKey("zone")
+	// TopologyKeyIBMDatacenter spreads pools across IBM Cloud datacenters.
+	TopologyKeyIBMDatacenter = TopologyKey("ibm-datacenter")
+	// TopologyKeyIBMPod spreads pools across IBM Cloud pods.
+	TopologyKeyIBMPod = TopologyKey("ibm-pod")
+)
+
+// TopologySpreadConstraint controls how the pools of a multi-pool lease are spread across a topology.
+type TopologySpreadConstraint struct {
+	// TopologyKey is the pool topology to spread across. Pools without a value for the key are not
+	// eligible for the lease.
+	// +kubebuilder:validation:Enum=server;region;zone;ibm-datacenter;ibm-pod
+	TopologyKey TopologyKey `json:"topologyKey"`
+	// MaxSkew is the maximum difference between the number of the lease's pools in any domain and in
+	// the domain with the fewest of the lease's pools.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	MaxSkew int32 `json:"maxSkew,omitempty"`
+	// MinDomains is the minimum number of distinct domains the lease's pools must be spread across.
+	// When the lease needs fewer pools than MinDomains, every pool is placed in a distinct domain.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinDomains *int32 `json:"minDomains,omitempty"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -60,6 +154,8 @@ type Toleration struct {
 // +kubebuilder:printcolumn:name="vCPUs",type=string,JSONPath=`.spec.vcpus`
 // +kubebuilder:printcolumn:name="Memory(GB)",type=string,JSONPath=`.spec.memory`
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Position",type=integer,JSONPath=`.status.queuePosition`
+// +kubebuilder:printcolumn:name="Wait",type=string,JSONPath=`.status.estimatedWait`,priority=1
 type Lease struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -104,11 +200,43 @@ type LeaseSpec struct {
 	// +optional
 	PoolSelector map[string]string `json:"poolSelector,omitempty"`
 
+	// PoolSelectorExpressions are set-based label selector requirements for pools. Supported
+	// operators are In, NotIn, Exists and DoesNotExist. The requirements are ANDed with each
+	// other and with PoolSelector.
+	// +optional
+	PoolSelectorExpressions []metav1.LabelSelectorRequirement `json:"poolSelectorExpressions,omitempty"`
+
 	// Tolerations are tolerations that allow this lease to be scheduled on pools with matching taints.
 	// This works like Kubernetes pod tolerations for scheduling on nodes with taints.
 	// +optional
 	Tolerations []Toleration `json:"tolerations,omitempty"`
 
+	// PreferredPoolAffinity are soft preferences for pools. Pools matching more, or heavier, terms are
+	// preferred over other fitting pools but pools which do not match are still eligible.
+	// +optional
+	PreferredPoolAffinity []WeightedPoolAffinityTerm `json:"preferredPoolAffinity,omitempty"`
+
+	// PoolAntiAffinity are soft preferences to avoid pools already holding leases with matching labels.
+	// +optional
+	PoolAntiAffinity []WeightedPoolAntiAffinityTerm `json:"poolAntiAffinity,omitempty"`
+
+	// TopologySpreadConstraints spread the pools of a multi-pool lease across vCenters, regions, zones or
+	// IBM Cloud datacenters and pods. All constraints must be satisfied by every pool assigned.
+	// +optional
+	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// Priority orders pending leases in the scheduling queue. Leases with a higher priority are
+	// scheduled first; leases with the same priority are scheduled oldest first.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// ReleaseGracePeriod is how long the pools' resources and networks of a deleted lease stay blocked while
+	// the holder cleans up. The lease is Releasing until the holder confirms the cleanup, the cleanup Job
+	// finishes or the grace period expires. When unset, the resources are released right away unless a
+	// cleanup Job is configured.
+	// +optional
+	ReleaseGracePeriod *metav1.Duration `json:"releaseGracePeriod,omitempty"`
+
 	// NetworkType defines the type of network required by the lease.
 	// by default, all networks are treated as single-tenant. single-tenant networks
 	// are only used by one CI jobs.  multi-tenant networks reside on a
@@ -124,6 +252,16 @@ type LeaseSpec struct {
 	BoskosLeaseID string `json:"boskos-lease-id,omitempty"`
 }
 
+// LeaseResources are the resources held by a lease in each of its pools
+type LeaseResources struct {
+	// VCpus is the number of virtual CPUs held in each pool
+	VCpus int `json:"vcpus"`
+	// Memory is the amount of memory in GB held in each pool
+	Memory int `json:"memory"`
+	// Networks is the number of networks held in each pool
+	Networks int `json:"networks"`
+}
+
 // LeaseStatus defines the status for a lease
 type LeaseStatus struct {
 	// Deprecated: The inline FailureDomainSpec fields (name, server, region, zone, topology, shortName)
@@ -153,6 +291,34 @@ type LeaseStatus struct {
 	// +optional
 	Phase Phase `json:"phase,omitempty"`
 
+	// Allocated are the resources held by the lease in each of its pools. It is set once the lease is
+	// fulfilled and follows updates of the vcpus, memory and networks in the spec once they are honoured.
+	// +optional
+	Allocated *LeaseResources `json:"allocated,omitempty"`
+
+	// PendingSince is when the lease started waiting for its resources
+	// +optional
+	PendingSince *metav1.Time `json:"pendingSince,omitempty"`
+
+	// FulfilledAt is when the lease was first fulfilled
+	// +optional
+	FulfilledAt *metav1.Time `json:"fulfilledAt,omitempty"`
+
+	// QueuePosition is the position of a waiting lease among the waiting leases competing with it for the same
+	// pools, starting at 1. It is not set once the lease is fulfilled.
+	// +optional
+	QueuePosition int `json:"queuePosition,omitempty"`
+
+	// BlockedBy are the first of the waiting leases, as namespace/name, which are scheduled before this lease
+	// and compete with it for the same pools.
+	// +optional
+	BlockedBy []string `json:"blockedBy,omitempty"`
+
+	// EstimatedWait is how long the lease is expected to wait for its pools, from the capacity forecast. It is
+	// not set if the wait can not be estimated.
+	// +optional
+	EstimatedWait *metav1.Duration `json:"estimatedWait,omitempty"`
+
 	// conditions defines the current state of the Machine
 	// +listType=map
 	// +listMapKey=type
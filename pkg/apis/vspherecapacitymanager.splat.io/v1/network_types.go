@@ -9,8 +9,19 @@ const (
 	NetworkFinalizer                      = "vsphere-capacity-manager.splat-team.io/network-finalizer"
 	NetworkKind                           = "Network"
 	NetworkTypeLabel                      = "vsphere-capacity-manager.splat-team.io/network-type"
+	// NetworkCleanupCheckedAnnotation is set to "true" on a quarantined network once a cleanup check found no
+	// stale VMs, DHCP leases or ARP entries left by its previous lease. it ends the quarantine early.
+	NetworkCleanupCheckedAnnotation = "vsphere-capacity-manager.splat-team.io/cleanup-checked"
 )
 
+// NetworkFailureReport is a report by a lease that a network was broken.
+type NetworkFailureReport struct {
+	// Lease is the name of the lease which reported the failure.
+	Lease string `json:"lease"`
+	// Time is when the failure was recorded.
+	Time metav1.Time `json:"time"`
+}
+
 // +genclient
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -18,8 +29,11 @@ const (
 // +k8s:openapi-gen=true
 // +kubebuilder:object:root=true
 // +kubebuilder:scope=Namespaced
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Port Group",type=string,JSONPath=`.spec.portGroupName`
 // +kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.spec.podName`
+// +kubebuilder:printcolumn:name="Quarantined",type=boolean,JSONPath=`.status.quarantined`
+// +kubebuilder:printcolumn:name="NoSchedule",type=boolean,JSONPath=`.spec.noSchedule`
 type Network struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -89,10 +103,40 @@ type NetworkSpec struct {
 	// Nameservers an array of the nameservers to use
 	// +optional
 	Nameservers []string `json:"nameservers"`
+
+	// NoSchedule when true, the network is not assigned to new leases. Leases holding the network keep it.
+	// It is set by VCM when too many leases report the network as broken, and cleared by an administrator
+	// once the network is fixed.
+	// +optional
+	NoSchedule bool `json:"noSchedule,omitempty"`
 }
 
 // NetworkStatus defines the status for a pool
 type NetworkStatus struct {
+	// Quarantined is true while the network cools down after it was released by a lease. A quarantined
+	// network is not assigned to leases.
+	// +optional
+	Quarantined bool `json:"quarantined,omitempty"`
+
+	// QuarantinedUntil is when the quarantine ends, unless a cleanup check passes before.
+	// +optional
+	QuarantinedUntil *metav1.Time `json:"quarantinedUntil,omitempty"`
+
+	// ReleasedBy is the name of the lease which last released the network.
+	// +optional
+	ReleasedBy string `json:"releasedBy,omitempty"`
+
+	// FailureReports are the recent reports by leases that the network was broken. Reports older than the
+	// failure window are dropped.
+	// +optional
+	FailureReports []NetworkFailureReport `json:"failureReports,omitempty"`
+
+	// Conditions are the conditions of the network. The Disabled condition tells why the network is not
+	// assigned to new leases.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
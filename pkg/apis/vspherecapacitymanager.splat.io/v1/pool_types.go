@@ -8,6 +8,14 @@ const (
 	POOLS_LAST_LEASE_UPDATE_ANNOTATION = "vspherecapacitymanager.splat.io/last-pool-update"
 	PoolFinalizer                      = "vsphere-capacity-manager.splat-team.io/pool-finalizer"
 	PoolKind                           = "Pool"
+	// PoolDrainingAnnotation is set on leases holding a pool that is being drained. The value is the name of the pool.
+	PoolDrainingAnnotation = "vsphere-capacity-manager.splat-team.io/pool-draining"
+	// PoolCordonedByDrainAnnotation is set on pools cordoned by a drain, so they are uncordoned once the drain
+	// is removed.
+	PoolCordonedByDrainAnnotation = "vsphere-capacity-manager.splat-team.io/cordoned-by-drain"
+	// PoolInfraFailuresTaintKey is the key of the PreferNoSchedule taint set on pools where too many leases
+	// reported an infrastructure failure.
+	PoolInfraFailuresTaintKey = "vsphere-capacity-manager.splat-team.io/infra-failures"
 )
 
 // TaintEffect defines the effect of a taint on pools that do not tolerate the taint.
@@ -48,6 +56,8 @@ type Taint struct {
 // +kubebuilder:printcolumn:name="Networks",type=string,JSONPath=`.status.network-available`
 // +kubebuilder:printcolumn:name="Disabled",type=string,JSONPath=`.spec.noSchedule`
 // +kubebuilder:printcolumn:name="Excluded",type=string,JSONPath=`.spec.exclude`
+// +kubebuilder:printcolumn:name="Drain",type=string,JSONPath=`.status.drain.phase`
+// +kubebuilder:printcolumn:name="Infra Failures(%)",type=string,JSONPath=`.status.health.infraFailurePercent`
 type Pool struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -89,6 +99,68 @@ type PoolSpec struct {
 	// unless they have matching tolerations. This works like Kubernetes node taints.
 	// +optional
 	Taints []Taint `json:"taints,omitempty"`
+	// Drain when set, the pool is cordoned and the holders of its leases are asked to release them.
+	// The drain is complete once no leases remain on the pool. Removing the drain uncordons the pool,
+	// unless it was already cordoned when the drain was set.
+	// +optional
+	Drain *PoolDrainSpec `json:"drain,omitempty"`
+}
+
+// PoolDrainSpec describes a request to empty a pool for maintenance
+type PoolDrainSpec struct {
+	// DeleteLeasesOlderThan when set, leases holding the pool which are older than this
+	// duration are deleted. When unset, leases are only notified and the drain waits for
+	// them to be released.
+	// +optional
+	DeleteLeasesOlderThan *metav1.Duration `json:"deleteLeasesOlderThan,omitempty"`
+}
+
+// DrainPhase is the phase of a pool drain
+type DrainPhase string
+
+const (
+	// DrainPhaseDraining means leases are still assigned to the pool
+	DrainPhaseDraining DrainPhase = "Draining"
+	// DrainPhaseDrained means no leases remain on the pool
+	DrainPhaseDrained DrainPhase = "Drained"
+)
+
+// PoolDrainStatus reports the progress of a pool drain
+type PoolDrainStatus struct {
+	// Phase is the current phase of the drain
+	Phase DrainPhase `json:"phase"`
+	// StartTime is when the drain was first observed
+	StartTime metav1.Time `json:"startTime"`
+	// LeasesRemaining is the number of leases still assigned to the pool
+	LeasesRemaining int `json:"leasesRemaining"`
+	// LeasesEvicted is the number of leases deleted by the drain
+	// +optional
+	LeasesEvicted int `json:"leasesEvicted,omitempty"`
+}
+
+// PoolOutcomeBucket counts the outcomes reported by the leases released from a pool within an hour
+type PoolOutcomeBucket struct {
+	// Start is the start of the hour
+	Start metav1.Time `json:"start"`
+	// Success is the number of leases which reported a success
+	// +optional
+	Success int `json:"success,omitempty"`
+	// InstallFailure is the number of leases which reported an install failure
+	// +optional
+	InstallFailure int `json:"installFailure,omitempty"`
+	// InfraFailure is the number of leases which reported an infrastructure failure
+	// +optional
+	InfraFailure int `json:"infraFailure,omitempty"`
+}
+
+// PoolHealth summarizes the outcomes reported by the leases released from a pool over the outcome window
+type PoolHealth struct {
+	// Leases is the number of leases which reported an outcome
+	Leases int `json:"leases"`
+	// InstallFailurePercent is the percentage of leases which reported an install failure
+	InstallFailurePercent int `json:"installFailurePercent"`
+	// InfraFailurePercent is the percentage of leases which reported an infrastructure failure
+	InfraFailurePercent int `json:"infraFailurePercent"`
 }
 
 // PoolStatus defines the status for a pool
@@ -111,6 +183,19 @@ type PoolStatus struct {
 	// Initialized when true, the status fields have been initialized
 	// +optional
 	Initialized bool `json:"initialized"`
+
+	// Drain reports the progress of a drain requested by spec.drain
+	// +optional
+	Drain *PoolDrainStatus `json:"drain,omitempty"`
+
+	// Outcomes are the outcomes reported by the leases released from the pool, in hourly buckets over the
+	// outcome window
+	// +optional
+	Outcomes []PoolOutcomeBucket `json:"outcomes,omitempty"`
+
+	// Health summarizes Outcomes
+	// +optional
+	Health *PoolHealth `json:"health,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -30,6 +30,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&PoolList{},
 		&Network{},
 		&NetworkList{},
+		&LeaseUsageRecord{},
+		&LeaseUsageRecordList{},
 	)
 
 	metav1.AddToGroupVersion(scheme, GroupVersion)
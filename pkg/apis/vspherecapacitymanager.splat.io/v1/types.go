@@ -64,6 +64,18 @@ const (
 	LeaseConditionTypeFulfilled ConditionType = "Fulfilled"
 	LeaseConditionTypePartial   ConditionType = "Partial"
 	LeaseConditionTypePending   ConditionType = "Pending"
+	// LeaseConditionTypeResizing is True while a fulfilled lease waits for the resources its spec grew by. The
+	// lease stays fulfilled and keeps its previous resources meanwhile.
+	LeaseConditionTypeResizing ConditionType = "Resizing"
+	// LeaseConditionTypeReady is True while a lease is fulfilled and its environment variables can be used, so
+	// holders can wait for it with `oc wait --for=condition=Ready`. It turns False once the lease is released.
+	LeaseConditionTypeReady ConditionType = "Ready"
+	// LeaseConditionTypeCleanupComplete is set to True by the holder of a releasing lease once its resources
+	// are cleaned up.
+	LeaseConditionTypeCleanupComplete ConditionType = "CleanupComplete"
+
+	// NetworkConditionTypeDisabled is True while a network is not assigned to new leases.
+	NetworkConditionTypeDisabled ConditionType = "Disabled"
 )
 
 type ConditionStatus string
@@ -77,6 +89,23 @@ const (
 // all the reasons for various updates
 const (
 	ReasonLeaseDelayed string = "LeaseDelayed"
+	ReasonLeasePending string = "LeasePending"
 	ReasonLeasePartial string = "LeasePartial"
 	ReasonLeaseNoPool  string = "NoAvailablePool"
+	// ReasonLeaseResizing is the reason of the Resizing condition while a fulfilled lease waits for the resources
+	// its spec grew by
+	ReasonLeaseResizing  string = "LeaseResizing"
+	ReasonLeaseReleasing string = "LeaseReleasing"
+	ReasonLeaseReleased  string = "LeaseReleased"
+
+	ReasonPoolDraining string = "PoolDraining"
+	ReasonPoolDrained  string = "PoolDrained"
+	ReasonLeaseEvicted string = "LeaseEvicted"
+
+	ReasonPoolInfraFailures string = "PoolInfraFailures"
+	ReasonPoolRecovered     string = "PoolRecovered"
+
+	ReasonNetworkFailing    string = "NetworkFailing"
+	ReasonNetworkNoSchedule string = "NoSchedule"
+	ReasonNetworkReenabled  string = "NetworkReenabled"
 )
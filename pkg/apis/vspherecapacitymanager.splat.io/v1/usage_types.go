@@ -0,0 +1,100 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	LeaseUsageRecordKind = "LeaseUsageRecord"
+	// LeaseUsageRecordLeaseLabel is the name of the lease a usage record was created for.
+	LeaseUsageRecordLeaseLabel = "vsphere-capacity-manager.splat-team.io/lease"
+)
+
+// LeaseUsageJob is the Prow metadata of the job which held a lease
+type LeaseUsageJob struct {
+	// Name is the name of the Prow job
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Type is the type of the Prow job, such as periodic or presubmit
+	// +optional
+	Type string `json:"type,omitempty"`
+	// BuildID is the build ID of the Prow job
+	// +optional
+	BuildID string `json:"buildID,omitempty"`
+	// Org is the GitHub organization of the repository under test
+	// +optional
+	Org string `json:"org,omitempty"`
+	// Repo is the repository under test
+	// +optional
+	Repo string `json:"repo,omitempty"`
+	// PR is the pull request under test
+	// +optional
+	PR string `json:"pr,omitempty"`
+	// Link is the link to the job
+	// +optional
+	Link string `json:"link,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LeaseUsageRecord records the resources a lease held and for how long. It is created when the lease is released.
+// +k8s:openapi-gen=true
+// +kubebuilder:object:root=true
+// +kubebuilder:scope=Namespaced
+// +kubebuilder:printcolumn:name="Lease",type=string,JSONPath=`.spec.lease`
+// +kubebuilder:printcolumn:name="Namespace",type=string,JSONPath=`.spec.leaseNamespace`
+// +kubebuilder:printcolumn:name="vCPUs",type=string,JSONPath=`.spec.vcpus`
+// +kubebuilder:printcolumn:name="Memory(GB)",type=string,JSONPath=`.spec.memory`
+// +kubebuilder:printcolumn:name="Networks",type=string,JSONPath=`.spec.networks`
+// +kubebuilder:printcolumn:name="Released",type=date,JSONPath=`.spec.releasedAt`
+type LeaseUsageRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec LeaseUsageRecordSpec `json:"spec"`
+}
+
+// LeaseUsageRecordSpec defines the usage of a released lease
+type LeaseUsageRecordSpec struct {
+	// Lease is the name of the lease
+	Lease string `json:"lease"`
+	// LeaseNamespace is the namespace the job which held the lease ran in
+	// +optional
+	LeaseNamespace string `json:"leaseNamespace,omitempty"`
+	// BoskosLeaseID is the boskos lease the lease was created for
+	// +optional
+	BoskosLeaseID string `json:"boskosLeaseID,omitempty"`
+	// NetworkType is the network type of the lease
+	// +optional
+	NetworkType NetworkType `json:"networkType,omitempty"`
+	// VCpus is the number of vCPUs the lease held in each pool
+	VCpus int `json:"vcpus"`
+	// Memory is the memory in GB the lease held in each pool
+	Memory int `json:"memory"`
+	// Networks is the number of networks the lease held
+	Networks int `json:"networks"`
+	// Pools are the names of the pools the lease held
+	// +optional
+	Pools []string `json:"pools,omitempty"`
+	// CreatedAt is when the lease was created
+	CreatedAt metav1.Time `json:"createdAt"`
+	// FulfilledAt is when the lease was first fulfilled. It is not set for leases which were never fulfilled.
+	// +optional
+	FulfilledAt *metav1.Time `json:"fulfilledAt,omitempty"`
+	// ReleasedAt is when the resources of the lease were released
+	ReleasedAt metav1.Time `json:"releasedAt"`
+	// Job is the Prow metadata of the job which held the lease
+	// +optional
+	Job LeaseUsageJob `json:"job,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LeaseUsageRecordList is a list of usage records
+type LeaseUsageRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []LeaseUsageRecord `json:"items"`
+}
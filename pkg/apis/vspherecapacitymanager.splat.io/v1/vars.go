@@ -8,6 +8,7 @@ const (
 	PHASE_PARTIAL   Phase = "Partial"
 	PHASE_PENDING   Phase = "Pending"
 	PHASE_FAILED    Phase = "Failed"
+	PHASE_RELEASING Phase = "Releasing"
 )
 
 type (
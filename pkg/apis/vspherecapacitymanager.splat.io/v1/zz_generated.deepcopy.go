@@ -6,6 +6,7 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -115,6 +116,21 @@ func (in *LeaseList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseResources) DeepCopyInto(out *LeaseResources) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseResources.
+func (in *LeaseResources) DeepCopy() *LeaseResources {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LeaseSpec) DeepCopyInto(out *LeaseSpec) {
 	*out = *in
@@ -125,11 +141,44 @@ func (in *LeaseSpec) DeepCopyInto(out *LeaseSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.PoolSelectorExpressions != nil {
+		in, out := &in.PoolSelectorExpressions, &out.PoolSelectorExpressions
+		*out = make([]metav1.LabelSelectorRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]Toleration, len(*in))
 		copy(*out, *in)
 	}
+	if in.PreferredPoolAffinity != nil {
+		in, out := &in.PreferredPoolAffinity, &out.PreferredPoolAffinity
+		*out = make([]WeightedPoolAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PoolAntiAffinity != nil {
+		in, out := &in.PoolAntiAffinity, &out.PoolAntiAffinity
+		*out = make([]WeightedPoolAntiAffinityTerm, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReleaseGracePeriod != nil {
+		in, out := &in.ReleaseGracePeriod, &out.ReleaseGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseSpec.
@@ -160,6 +209,29 @@ func (in *LeaseStatus) DeepCopyInto(out *LeaseStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Allocated != nil {
+		in, out := &in.Allocated, &out.Allocated
+		*out = new(LeaseResources)
+		**out = **in
+	}
+	if in.PendingSince != nil {
+		in, out := &in.PendingSince, &out.PendingSince
+		*out = (*in).DeepCopy()
+	}
+	if in.FulfilledAt != nil {
+		in, out := &in.FulfilledAt, &out.FulfilledAt
+		*out = (*in).DeepCopy()
+	}
+	if in.BlockedBy != nil {
+		in, out := &in.BlockedBy, &out.BlockedBy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EstimatedWait != nil {
+		in, out := &in.EstimatedWait, &out.EstimatedWait
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
@@ -179,6 +251,106 @@ func (in *LeaseStatus) DeepCopy() *LeaseStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseUsageJob) DeepCopyInto(out *LeaseUsageJob) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseUsageJob.
+func (in *LeaseUsageJob) DeepCopy() *LeaseUsageJob {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseUsageJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseUsageRecord) DeepCopyInto(out *LeaseUsageRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseUsageRecord.
+func (in *LeaseUsageRecord) DeepCopy() *LeaseUsageRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseUsageRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LeaseUsageRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseUsageRecordList) DeepCopyInto(out *LeaseUsageRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LeaseUsageRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseUsageRecordList.
+func (in *LeaseUsageRecordList) DeepCopy() *LeaseUsageRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseUsageRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LeaseUsageRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaseUsageRecordSpec) DeepCopyInto(out *LeaseUsageRecordSpec) {
+	*out = *in
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.CreatedAt.DeepCopyInto(&out.CreatedAt)
+	if in.FulfilledAt != nil {
+		in, out := &in.FulfilledAt, &out.FulfilledAt
+		*out = (*in).DeepCopy()
+	}
+	in.ReleasedAt.DeepCopyInto(&out.ReleasedAt)
+	out.Job = in.Job
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaseUsageRecordSpec.
+func (in *LeaseUsageRecordSpec) DeepCopy() *LeaseUsageRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaseUsageRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Leases) DeepCopyInto(out *Leases) {
 	{
@@ -210,7 +382,7 @@ func (in *Network) DeepCopyInto(out *Network) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Network.
@@ -231,6 +403,22 @@ func (in *Network) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkFailureReport) DeepCopyInto(out *NetworkFailureReport) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkFailureReport.
+func (in *NetworkFailureReport) DeepCopy() *NetworkFailureReport {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkFailureReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkList) DeepCopyInto(out *NetworkList) {
 	*out = *in
@@ -326,6 +514,24 @@ func (in *NetworkSpec) DeepCopy() *NetworkSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkStatus) DeepCopyInto(out *NetworkStatus) {
 	*out = *in
+	if in.QuarantinedUntil != nil {
+		in, out := &in.QuarantinedUntil, &out.QuarantinedUntil
+		*out = (*in).DeepCopy()
+	}
+	if in.FailureReports != nil {
+		in, out := &in.FailureReports, &out.FailureReports
+		*out = make([]NetworkFailureReport, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkStatus.
@@ -369,7 +575,7 @@ func (in *Pool) DeepCopyInto(out *Pool) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Pool.
@@ -390,6 +596,57 @@ func (in *Pool) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolDrainSpec) DeepCopyInto(out *PoolDrainSpec) {
+	*out = *in
+	if in.DeleteLeasesOlderThan != nil {
+		in, out := &in.DeleteLeasesOlderThan, &out.DeleteLeasesOlderThan
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolDrainSpec.
+func (in *PoolDrainSpec) DeepCopy() *PoolDrainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolDrainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolDrainStatus) DeepCopyInto(out *PoolDrainStatus) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolDrainStatus.
+func (in *PoolDrainStatus) DeepCopy() *PoolDrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolDrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolHealth) DeepCopyInto(out *PoolHealth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolHealth.
+func (in *PoolHealth) DeepCopy() *PoolHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PoolList) DeepCopyInto(out *PoolList) {
 	*out = *in
@@ -422,6 +679,22 @@ func (in *PoolList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PoolOutcomeBucket) DeepCopyInto(out *PoolOutcomeBucket) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolOutcomeBucket.
+func (in *PoolOutcomeBucket) DeepCopy() *PoolOutcomeBucket {
+	if in == nil {
+		return nil
+	}
+	out := new(PoolOutcomeBucket)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PoolSpec) DeepCopyInto(out *PoolSpec) {
 	*out = *in
@@ -432,6 +705,11 @@ func (in *PoolSpec) DeepCopyInto(out *PoolSpec) {
 		*out = make([]Taint, len(*in))
 		copy(*out, *in)
 	}
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(PoolDrainSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolSpec.
@@ -447,6 +725,23 @@ func (in *PoolSpec) DeepCopy() *PoolSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PoolStatus) DeepCopyInto(out *PoolStatus) {
 	*out = *in
+	if in.Drain != nil {
+		in, out := &in.Drain, &out.Drain
+		*out = new(PoolDrainStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outcomes != nil {
+		in, out := &in.Outcomes, &out.Outcomes
+		*out = make([]PoolOutcomeBucket, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(PoolHealth)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PoolStatus.
@@ -513,3 +808,55 @@ func (in *Toleration) DeepCopy() *Toleration {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologySpreadConstraint) DeepCopyInto(out *TopologySpreadConstraint) {
+	*out = *in
+	if in.MinDomains != nil {
+		in, out := &in.MinDomains, &out.MinDomains
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologySpreadConstraint.
+func (in *TopologySpreadConstraint) DeepCopy() *TopologySpreadConstraint {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologySpreadConstraint)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightedPoolAffinityTerm) DeepCopyInto(out *WeightedPoolAffinityTerm) {
+	*out = *in
+	in.Preference.DeepCopyInto(&out.Preference)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightedPoolAffinityTerm.
+func (in *WeightedPoolAffinityTerm) DeepCopy() *WeightedPoolAffinityTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightedPoolAffinityTerm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightedPoolAntiAffinityTerm) DeepCopyInto(out *WeightedPoolAntiAffinityTerm) {
+	*out = *in
+	in.LeaseSelector.DeepCopyInto(&out.LeaseSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightedPoolAntiAffinityTerm.
+func (in *WeightedPoolAntiAffinityTerm) DeepCopy() *WeightedPoolAntiAffinityTerm {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightedPoolAntiAffinityTerm)
+	in.DeepCopyInto(out)
+	return out
+}
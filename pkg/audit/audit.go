@@ -0,0 +1,118 @@
+// Package audit records the allocation and release of pools and networks by leases, as an append-only stream of
+// JSON lines, so who held which resource at what time can be answered after the leases are gone.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Action is what happened to a resource.
+type Action string
+
+const (
+	ActionAllocate Action = "allocate"
+	ActionRelease  Action = "release"
+)
+
+const (
+	KindPool    = "Pool"
+	KindNetwork = "Network"
+)
+
+// Record is the allocation or release of a pool or network by a lease.
+type Record struct {
+	// Time is when the allocation or release was persisted.
+	Time   time.Time `json:"time"`
+	Action Action    `json:"action"`
+	// Kind is Pool or Network.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+
+	Lease     string `json:"lease"`
+	Namespace string `json:"namespace"`
+	BoskosID  string `json:"boskosId,omitempty"`
+	JobLink   string `json:"jobLink,omitempty"`
+
+	// Pool is the pool of a network.
+	Pool string `json:"pool,omitempty"`
+	// VLAN and PortGroup are the VLAN ID and port group of a network.
+	VLAN      string `json:"vlan,omitempty"`
+	PortGroup string `json:"portGroup,omitempty"`
+
+	// Reason is why the resource was released.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Sink receives the audit records.
+type Sink interface {
+	Write(record Record) error
+}
+
+// Logger writes the audit records to its sinks. a nil Logger drops the records.
+type Logger struct {
+	sinks []Sink
+}
+
+// NewLogger returns a Logger writing to sinks.
+func NewLogger(sinks ...Sink) *Logger {
+	return &Logger{sinks: sinks}
+}
+
+// Log writes the records to every sink. errors are logged with the logger of ctx, auditing never fails an
+// allocation.
+func (l *Logger) Log(ctx context.Context, records ...Record) {
+	if l == nil {
+		return
+	}
+	for _, record := range records {
+		for _, sink := range l.sinks {
+			if err := sink.Write(record); err != nil {
+				log.FromContext(ctx).Error(err, "unable to write audit record", "Lease", klog.KRef(record.Namespace, record.Lease), "kind", record.Kind, "name", record.Name)
+			}
+		}
+	}
+}
+
+// Writer writes each record as one line of JSON.
+type Writer struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewWriter returns a Writer writing to out.
+func NewWriter(out io.Writer) *Writer {
+	return &Writer{out: out}
+}
+
+// OpenFile returns a Writer appending to the file at path, created if missing, or writing to stdout if path is -.
+func OpenFile(path string) (*Writer, error) {
+	if path == "-" {
+		return NewWriter(os.Stdout), nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log: %w", err)
+	}
+	return NewWriter(file), nil
+}
+
+func (w *Writer) Write(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// a single write per line keeps the lines whole when other processes append to the same file.
+	_, err = w.out.Write(append(data, '\n'))
+	return err
+}
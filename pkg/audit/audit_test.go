@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := NewLogger(NewWriter(out))
+	now := time.Date(2024, 1, 1, 14, 5, 0, 0, time.UTC)
+	logger.Log(context.TODO(),
+		Record{Time: now, Action: ActionAllocate, Kind: KindPool, Name: "pool-a", Lease: "lease-1", Namespace: "ci"},
+		Record{Time: now, Action: ActionAllocate, Kind: KindNetwork, Name: "net-1", Lease: "lease-1", Namespace: "ci", VLAN: "1302"},
+	)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one line per record, got %q", out.String())
+	}
+	want := `{"time":"2024-01-01T14:05:00Z","action":"allocate","kind":"Network","name":"net-1","lease":"lease-1","namespace":"ci","vlan":"1302"}`
+	if lines[1] != want {
+		t.Errorf("expected %s, got %s", want, lines[1])
+	}
+
+	// a nil logger drops the records.
+	var nilLogger *Logger
+	nilLogger.Log(context.TODO(), Record{})
+}
+
+func TestWebhook(t *testing.T) {
+	received := make(chan Record, 2)
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first attempt fails, the record is sent again.
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var record Record
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			t.Errorf("unexpected body: %v", err)
+		}
+		received <- record
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL)
+	webhook.backoff = time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = webhook.Start(ctx)
+	}()
+
+	if err := webhook.Write(Record{Action: ActionRelease, Kind: KindPool, Name: "pool-a"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	select {
+	case record := <-received:
+		if record.Action != ActionRelease || record.Name != "pool-a" {
+			t.Errorf("unexpected record %+v", record)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the record was not sent")
+	}
+	cancel()
+	<-done
+
+	full := &Webhook{queue: make(chan Record)}
+	if err := full.Write(Record{}); err != ErrWebhookQueueFull {
+		t.Errorf("expected the record to be dropped, got %v", err)
+	}
+}
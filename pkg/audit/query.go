@@ -0,0 +1,87 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// maxLineBytes is the size of the longest line read from an audit log.
+const maxLineBytes = 1 << 20
+
+// Holding is a resource held by a lease: its allocation, and when it was released if it was.
+type Holding struct {
+	Record
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+}
+
+// Read returns the records of an audit log. lines which are not JSON objects are skipped, so an audit log
+// written to stdout can be read from the logs of the controller.
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for line := 1; scanner.Scan(); line++ {
+		if !bytes.HasPrefix(scanner.Bytes(), []byte("{")) {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("error reading audit record at line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// Filter returns the records for which match returns true.
+func Filter(records []Record, match func(Record) bool) []Record {
+	matched := []Record{}
+	for _, record := range records {
+		if match(record) {
+			matched = append(matched, record)
+		}
+	}
+	return matched
+}
+
+// HeldAt returns the resources held at a time, from the oldest allocation. a resource allocated again by the
+// same lease without being released is held since its first allocation.
+func HeldAt(records []Record, at time.Time) []Holding {
+	sorted := append([]Record(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	type holdingKey struct {
+		kind, name, namespace, lease string
+	}
+	held := make(map[holdingKey]*Holding)
+	for _, record := range sorted {
+		key := holdingKey{kind: record.Kind, name: record.Name, namespace: record.Namespace, lease: record.Lease}
+		holding, isHeld := held[key]
+		switch {
+		case !record.Time.After(at) && record.Action == ActionAllocate && !isHeld:
+			held[key] = &Holding{Record: record}
+		case !record.Time.After(at) && record.Action == ActionRelease:
+			delete(held, key)
+		case record.Time.After(at) && record.Action == ActionRelease && isHeld && holding.ReleasedAt == nil:
+			releasedAt := record.Time
+			holding.ReleasedAt = &releasedAt
+		}
+	}
+
+	holdings := make([]Holding, 0, len(held))
+	for _, holding := range held {
+		holdings = append(holdings, *holding)
+	}
+	sort.Slice(holdings, func(i, j int) bool {
+		if !holdings[i].Time.Equal(holdings[j].Time) {
+			return holdings[i].Time.Before(holdings[j].Time)
+		}
+		return holdings[i].Name < holdings[j].Name
+	})
+	return holdings
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRead(t *testing.T) {
+	log := `I1019 14:05:00.000000 1 leases.go:100] scheduling lease ci/lease-1
+{"time":"2024-01-01T14:05:00Z","action":"allocate","kind":"Pool","name":"pool-a","lease":"lease-1","namespace":"ci"}
+
+{"time":"2024-01-01T15:05:00Z","action":"release","kind":"Pool","name":"pool-a","lease":"lease-1","namespace":"ci"}
+`
+	records, err := Read(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 2 || records[0].Action != ActionAllocate || records[1].Action != ActionRelease {
+		t.Errorf("expected the lines of the controller to be skipped, got %+v", records)
+	}
+
+	if _, err := Read(strings.NewReader(`{"time":`)); err == nil {
+		t.Error("expected an error for a truncated record")
+	}
+}
+
+func TestHeldAt(t *testing.T) {
+	base := time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	network := func(minutes int, action Action, lease string) Record {
+		return Record{Time: at(minutes), Action: action, Kind: KindNetwork, Name: "net-1", VLAN: "1302", Lease: lease, Namespace: "ci"}
+	}
+	records := []Record{
+		network(0, ActionAllocate, "lease-1"),
+		network(4, ActionRelease, "lease-1"),
+		network(5, ActionAllocate, "lease-2"),
+		// the records of different writers may be out of order.
+		network(30, ActionRelease, "lease-2"),
+		network(6, ActionAllocate, "lease-2"),
+		network(40, ActionAllocate, "lease-3"),
+	}
+
+	tests := []struct {
+		name         string
+		at           time.Time
+		wantLease    string
+		wantReleased time.Time
+	}{
+		{name: "before any allocation", at: at(-1)},
+		{name: "first lease", at: at(2), wantLease: "lease-1", wantReleased: at(4)},
+		{name: "second lease", at: at(5), wantLease: "lease-2", wantReleased: at(30)},
+		{name: "released", at: at(35)},
+		{name: "still held", at: at(45), wantLease: "lease-3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			held := HeldAt(records, tt.at)
+			if tt.wantLease == "" {
+				if len(held) != 0 {
+					t.Errorf("expected nothing held, got %+v", held)
+				}
+				return
+			}
+			if len(held) != 1 || held[0].Lease != tt.wantLease {
+				t.Fatalf("expected %s, got %+v", tt.wantLease, held)
+			}
+			if tt.wantReleased.IsZero() != (held[0].ReleasedAt == nil) || (held[0].ReleasedAt != nil && !held[0].ReleasedAt.Equal(tt.wantReleased)) {
+				t.Errorf("expected the release at %v, got %v", tt.wantReleased, held[0].ReleasedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// webhookQueueSize is the number of records waiting to be sent before new records are dropped.
+	webhookQueueSize = 1000
+	// webhookAttempts is how many times a record is sent before it is dropped.
+	webhookAttempts = 3
+	// webhookTimeout is the timeout of each request, and how long the queued records are sent for on shutdown.
+	webhookTimeout = 10 * time.Second
+)
+
+// ErrWebhookQueueFull is returned when a record is dropped because the webhook is not keeping up.
+var ErrWebhookQueueFull = errors.New("audit webhook queue is full")
+
+// Webhook posts each record as JSON to a URL. records are sent in the background by Start, so a slow webhook
+// does not slow down the scheduling of leases.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+
+	queue   chan Record
+	backoff time.Duration
+}
+
+// NewWebhook returns a Webhook posting to url.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:     url,
+		Client:  &http.Client{Timeout: webhookTimeout},
+		queue:   make(chan Record, webhookQueueSize),
+		backoff: time.Second,
+	}
+}
+
+// Write queues the record to be sent.
+func (w *Webhook) Write(record Record) error {
+	select {
+	case w.queue <- record:
+		return nil
+	default:
+		return ErrWebhookQueueFull
+	}
+}
+
+// Start sends the queued records until ctx is done, then the records still queued for up to webhookTimeout.
+func (w *Webhook) Start(ctx context.Context) error {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithName("audit-webhook"))
+	for {
+		select {
+		case record := <-w.queue:
+			w.send(ctx, record)
+		case <-ctx.Done():
+			w.flush(log.FromContext(ctx))
+			return nil
+		}
+	}
+}
+
+// NeedLeaderElection is false so the records of a replica which lost the leadership are still sent.
+func (w *Webhook) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *Webhook) flush(logger logr.Logger) {
+	ctx, cancel := context.WithTimeout(log.IntoContext(context.Background(), logger), webhookTimeout)
+	defer cancel()
+	for {
+		select {
+		case record := <-w.queue:
+			w.send(ctx, record)
+		default:
+			return
+		}
+	}
+}
+
+// send posts a record, retrying with a backoff. records which can not be sent are logged and dropped.
+func (w *Webhook) send(ctx context.Context, record Record) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to encode audit record", "Lease", klog.KRef(record.Namespace, record.Lease))
+		return
+	}
+	backoff := w.backoff
+	for attempt := 1; ; attempt++ {
+		err = w.post(ctx, data)
+		if err == nil {
+			return
+		}
+		if attempt == webhookAttempts || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+		}
+	}
+	log.FromContext(ctx).Error(err, "dropping audit record", "Lease", klog.KRef(record.Namespace, record.Lease), "kind", record.Kind, "name", record.Name)
+}
+
+func (w *Webhook) post(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Package boskos serves the Boskos resource protocol on top of leases, so CI which already talks to Boskos can
+// acquire capacity without first taking a Boskos quota slice and then creating a lease.
+//
+// A resource is a lease. Acquire creates the lease and answers not found until the lease is fulfilled, like an
+// exhausted Boskos resource type, heartbeats keep the lease alive, and release deletes it.
+package boskos
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// DEFAULT_RESOURCE_TYPE is the Boskos resource type served by default.
+	DEFAULT_RESOURCE_TYPE = "vsphere-elastic"
+	// DEFAULT_EXPIRY is how long a lease is kept without a heartbeat from its Boskos owner by default.
+	DEFAULT_EXPIRY = 10 * time.Minute
+
+	// ResourceTypeLabel is the Boskos resource type of the leases created through Boskos.
+	ResourceTypeLabel = "vsphere-capacity-manager.splat-team.io/boskos-resource-type"
+	// OwnerAnnotation is the Boskos owner of a lease.
+	OwnerAnnotation = "vsphere-capacity-manager.splat-team.io/boskos-owner"
+	// StateAnnotation is the Boskos state the owner of a fulfilled lease last set.
+	StateAnnotation = "vsphere-capacity-manager.splat-team.io/boskos-state"
+	// LastUpdateAnnotation is the time of the last heartbeat of the owner of a lease.
+	LastUpdateAnnotation = "vsphere-capacity-manager.splat-team.io/boskos-last-update"
+
+	// Boskos states. leases waiting for capacity are pending, a state Boskos does not have, and leases being
+	// released are dirty, as Boskos resources waiting for the janitor.
+	StateFree    = "free"
+	StateBusy    = "busy"
+	StateDirty   = "dirty"
+	StatePending = "pending"
+
+	// default shape of a lease acquired without vcpus, memory or networks.
+	defaultVCpus    = 24
+	defaultMemory   = 96
+	defaultNetworks = 1
+)
+
+// Resource is a Boskos resource, as in sigs.k8s.io/boskos/common.
+type Resource struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name"`
+	State      string            `json:"state"`
+	Owner      string            `json:"owner"`
+	LastUpdate time.Time         `json:"lastupdate"`
+	UserData   map[string]string `json:"userdata"`
+}
+
+// Metric is the Boskos metric of a resource type, as in sigs.k8s.io/boskos/common.
+type Metric struct {
+	Type    string         `json:"type"`
+	Current map[string]int `json:"current"`
+	Owners  map[string]int `json:"owner"`
+}
+
+// leaseName returns the name of the lease acquired for a request. repeated acquires with the same request ID,
+// or the same owner without a request ID, find the same lease.
+func leaseName(resourceType, key string) string {
+	sum := sha256.Sum256([]byte(resourceType + "/" + key))
+	return "boskos-" + hex.EncodeToString(sum[:])[:12]
+}
+
+// leaseState returns the Boskos state of a lease.
+func leaseState(lease *v1.Lease) string {
+	switch {
+	case lease.DeletionTimestamp != nil || lease.Status.Phase == v1.PHASE_RELEASING:
+		return StateDirty
+	case lease.Status.Phase != v1.PHASE_FULFILLED:
+		return StatePending
+	case lease.Annotations[StateAnnotation] != "":
+		return lease.Annotations[StateAnnotation]
+	}
+	return StateBusy
+}
+
+// lastUpdate returns the time of the last heartbeat of a lease, or its creation time.
+func lastUpdate(lease *v1.Lease) time.Time {
+	if t, err := time.Parse(time.RFC3339, lease.Annotations[LastUpdateAnnotation]); err == nil {
+		return t
+	}
+	return lease.CreationTimestamp.Time
+}
+
+// newResource returns the Boskos resource of a lease. the user data holds the environment variables of the
+// lease and the pools it holds.
+func newResource(resourceType string, lease *v1.Lease) *Resource {
+	userData := map[string]string{
+		"lease": lease.Namespace + "/" + lease.Name,
+	}
+	if lease.Status.EnvVars != "" {
+		userData["envVars"] = lease.Status.EnvVars
+	}
+	for pool, envVars := range lease.Status.EnvVarsMap {
+		userData["envVars."+pool] = envVars
+	}
+	return &Resource{
+		Type:       resourceType,
+		Name:       lease.Name,
+		State:      leaseState(lease),
+		Owner:      lease.Annotations[OwnerAnnotation],
+		LastUpdate: lastUpdate(lease),
+		UserData:   userData,
+	}
+}
+
+// expiredLeases returns the leases in a state whose owners did not send a heartbeat for expiry, all states if
+// state is empty. leases already being released are skipped.
+func expiredLeases(leases []v1.Lease, state string, expiry time.Duration, now time.Time) []*v1.Lease {
+	var expired []*v1.Lease
+	for i := range leases {
+		lease := &leases[i]
+		if lease.DeletionTimestamp != nil || (state != "" && leaseState(lease) != state) {
+			continue
+		}
+		if now.Sub(lastUpdate(lease)) > expiry {
+			expired = append(expired, lease)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Name < expired[j].Name })
+	return expired
+}
+
+// freeResources returns how many more leases of the default shape the schedulable pools can hold.
+func freeResources(pools []v1.Pool) int {
+	free := 0
+	for i := range pools {
+		pool := &pools[i]
+		if pool.Spec.NoSchedule || pool.Spec.Exclude || pool.Spec.Drain != nil {
+			continue
+		}
+		free += min(pool.Status.VCpusAvailable/defaultVCpus, pool.Status.MemoryAvailable/defaultMemory,
+			pool.Status.NetworkAvailable/defaultNetworks)
+	}
+	return free
+}
+
+// metric counts the leases of a resource type by Boskos state and by owner.
+func metric(resourceType string, leases []v1.Lease, pools []v1.Pool) *Metric {
+	m := &Metric{
+		Type:    resourceType,
+		Current: map[string]int{StateFree: freeResources(pools)},
+		Owners:  map[string]int{},
+	}
+	for i := range leases {
+		lease := &leases[i]
+		m.Current[leaseState(lease)]++
+		m.Owners[lease.Annotations[OwnerAnnotation]]++
+	}
+	return m
+}
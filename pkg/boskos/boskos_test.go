@@ -0,0 +1,113 @@
+package boskos
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func newTestLease(name string, phase v1.Phase, lastUpdate time.Time) v1.Lease {
+	return v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "ci",
+			Labels:    map[string]string{ResourceTypeLabel: DEFAULT_RESOURCE_TYPE},
+			Annotations: map[string]string{
+				OwnerAnnotation:      "owner-" + name,
+				LastUpdateAnnotation: lastUpdate.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec:   v1.LeaseSpec{VCpus: defaultVCpus, Memory: defaultMemory, Networks: defaultNetworks},
+		Status: v1.LeaseStatus{Phase: phase},
+	}
+}
+
+func TestLeaseState(t *testing.T) {
+	now := time.Now()
+	deleting := newTestLease("deleting", v1.PHASE_FULFILLED, now)
+	deleting.DeletionTimestamp = &metav1.Time{Time: now}
+	cleaning := newTestLease("cleaning", v1.PHASE_FULFILLED, now)
+	cleaning.Annotations[StateAnnotation] = "cleaning"
+
+	tests := []struct {
+		name  string
+		lease v1.Lease
+		want  string
+	}{
+		{name: "new", lease: newTestLease("new", "", now), want: StatePending},
+		{name: "pending", lease: newTestLease("pending", v1.PHASE_PENDING, now), want: StatePending},
+		{name: "partial", lease: newTestLease("partial", v1.PHASE_PARTIAL, now), want: StatePending},
+		{name: "fulfilled", lease: newTestLease("fulfilled", v1.PHASE_FULFILLED, now), want: StateBusy},
+		{name: "state set by the owner", lease: cleaning, want: "cleaning"},
+		{name: "releasing", lease: newTestLease("releasing", v1.PHASE_RELEASING, now), want: StateDirty},
+		{name: "deleting", lease: deleting, want: StateDirty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := leaseState(&tt.lease); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestExpiredLeases(t *testing.T) {
+	now := time.Now()
+	deleting := newTestLease("deleting", v1.PHASE_FULFILLED, now.Add(-time.Hour))
+	deleting.DeletionTimestamp = &metav1.Time{Time: now}
+	leases := []v1.Lease{
+		newTestLease("busy-expired", v1.PHASE_FULFILLED, now.Add(-time.Hour)),
+		newTestLease("busy", v1.PHASE_FULFILLED, now.Add(-time.Minute)),
+		newTestLease("pending-expired", v1.PHASE_PENDING, now.Add(-time.Hour)),
+		deleting,
+	}
+
+	tests := []struct {
+		name  string
+		state string
+		want  []string
+	}{
+		{name: "all states", want: []string{"busy-expired", "pending-expired"}},
+		{name: "busy", state: StateBusy, want: []string{"busy-expired"}},
+		{name: "dirty", state: StateDirty},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expired := expiredLeases(leases, tt.state, 10*time.Minute, now)
+			if len(expired) != len(tt.want) {
+				t.Fatalf("expected %v, got %d leases", tt.want, len(expired))
+			}
+			for i, lease := range expired {
+				if lease.Name != tt.want[i] {
+					t.Errorf("expected %v, got %s at %d", tt.want, lease.Name, i)
+				}
+			}
+		})
+	}
+}
+
+func TestMetric(t *testing.T) {
+	now := time.Now()
+	pools := []v1.Pool{
+		{Status: v1.PoolStatus{VCpusAvailable: 100, MemoryAvailable: 400, NetworkAvailable: 3}},
+		{Status: v1.PoolStatus{VCpusAvailable: 100, MemoryAvailable: 150, NetworkAvailable: 3}},
+		{Spec: v1.PoolSpec{NoSchedule: true}, Status: v1.PoolStatus{VCpusAvailable: 100, MemoryAvailable: 400, NetworkAvailable: 3}},
+	}
+	leases := []v1.Lease{
+		newTestLease("busy", v1.PHASE_FULFILLED, now),
+		newTestLease("pending", v1.PHASE_PENDING, now),
+	}
+	leases[1].Annotations[OwnerAnnotation] = "owner-busy"
+
+	m := metric(DEFAULT_RESOURCE_TYPE, leases, pools)
+	// the first pool fits 3 leases by networks, the second 1 by memory, the third is cordoned.
+	if m.Current[StateFree] != 4 || m.Current[StateBusy] != 1 || m.Current[StatePending] != 1 {
+		t.Errorf("unexpected states %v", m.Current)
+	}
+	if m.Owners["owner-busy"] != 2 {
+		t.Errorf("unexpected owners %v", m.Owners)
+	}
+}
@@ -0,0 +1,373 @@
+package boskos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned"
+)
+
+const (
+	// reapInterval is how often leases without heartbeat are looked for.
+	reapInterval = time.Minute
+	// maxRequestBytes is the maximum size of the user data sent with a heartbeat.
+	maxRequestBytes = 1 << 20
+)
+
+// Server serves the Boskos protocol for one resource type. Boskos has no authentication, expose it only inside
+// the cluster.
+type Server struct {
+	// BindAddress is the address the server listens on, such as ":8081".
+	BindAddress string
+	// Client reads and writes the leases and pools.
+	Client versioned.Interface
+	// Namespace is the namespace of the leases.
+	Namespace string
+	// ResourceType is the Boskos resource type served. DEFAULT_RESOURCE_TYPE if empty.
+	ResourceType string
+	// Expiry is how long a lease is kept without a heartbeat from its owner. DEFAULT_EXPIRY if 0.
+	Expiry time.Duration
+}
+
+// Handler returns the routes of the Boskos protocol.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /acquire", s.acquire)
+	mux.HandleFunc("POST /release", s.release)
+	mux.HandleFunc("POST /update", s.update)
+	mux.HandleFunc("POST /reset", s.reset)
+	mux.HandleFunc("GET /metric", s.metric)
+	return mux
+}
+
+// Start serves the Boskos protocol until ctx is done.
+func (s *Server) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("boskos")
+	ctx = log.IntoContext(ctx, logger)
+	server := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		logger.Info("serving boskos", "resourceType", s.resourceType(), "address", s.BindAddress)
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// NeedLeaderElection is false so every replica of the manager serves the protocol.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// RunReaper releases the leases whose owners stopped sending heartbeats. It runs on the leader only.
+func (s *Server) RunReaper(ctx context.Context) error {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithName("boskos"))
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := s.releaseExpired(ctx, "", s.expiry()); err != nil {
+				log.FromContext(ctx).Error(err, "unable to release expired boskos leases")
+			}
+		}
+	}
+}
+
+// acquire creates a lease for a request, and returns it as a busy resource once it is fulfilled. until then
+// the resource is not found, so Boskos clients keep retrying with the same request ID.
+func (s *Server) acquire(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	owner := query.Get("owner")
+	if owner == "" {
+		http.Error(w, "owner is required", http.StatusBadRequest)
+		return
+	}
+	if query.Get("type") != s.resourceType() || query.Get("state") != StateFree {
+		http.Error(w, fmt.Sprintf("no %s resource of type %s", query.Get("state"), query.Get("type")), http.StatusNotFound)
+		return
+	}
+	dest := query.Get("dest")
+	if dest == "" {
+		dest = StateBusy
+	}
+	key := query.Get("request_id")
+	if key == "" {
+		key = owner
+	}
+
+	leases := s.Client.VspherecapacitymanagerV1().Leases(s.Namespace)
+	name := leaseName(s.resourceType(), key)
+	lease, err := leases.Get(r.Context(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease, err = s.newLease(name, owner, query)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if lease, err = leases.Create(r.Context(), lease, metav1.CreateOptions{}); err == nil {
+			log.FromContext(r.Context()).V(2).Info("lease created for boskos owner", "Lease", klog.KObj(lease), "owner", owner)
+		}
+	}
+	if err != nil {
+		writeAPIError(r.Context(), w, fmt.Sprintf("error acquiring lease %s", name), err)
+		return
+	}
+	if lease.Annotations[OwnerAnnotation] != owner {
+		http.Error(w, fmt.Sprintf("lease %s is owned by %s", name, lease.Annotations[OwnerAnnotation]), http.StatusUnauthorized)
+		return
+	}
+
+	state := ""
+	if lease.Status.Phase == v1.PHASE_FULFILLED {
+		state = dest
+	}
+	// each attempt counts as a heartbeat, so leases of clients which gave up waiting expire.
+	if lease, err = s.heartbeat(r.Context(), lease, state); err != nil {
+		writeAPIError(r.Context(), w, fmt.Sprintf("error updating lease %s", name), err)
+		return
+	}
+	if leaseState(lease) != dest {
+		http.Error(w, fmt.Sprintf("lease %s is %s", name, leaseState(lease)), http.StatusNotFound)
+		return
+	}
+	writeJSON(r.Context(), w, newResource(s.resourceType(), lease))
+}
+
+// newLease returns the lease of an acquire. the vcpus, memory, networks, pools and network-type query
+// parameters, which Boskos does not have, change the shape of the lease.
+func (s *Server) newLease(name, owner string, query map[string][]string) (*v1.Lease, error) {
+	spec := v1.LeaseSpec{
+		VCpus:       defaultVCpus,
+		Memory:      defaultMemory,
+		Networks:    defaultNetworks,
+		NetworkType: v1.NetworkTypeSingleTenant,
+	}
+	for key, value := range map[string]*int{"vcpus": &spec.VCpus, "memory": &spec.Memory, "networks": &spec.Networks, "pools": &spec.Pools} {
+		values := query[key]
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(values[0])
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("invalid %s %q", key, values[0])
+		}
+		*value = parsed
+	}
+	if values := query["network-type"]; len(values) > 0 && values[0] != "" {
+		spec.NetworkType = v1.NetworkType(values[0])
+	}
+
+	return &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: s.Namespace,
+			Labels: map[string]string{
+				ResourceTypeLabel: s.resourceType(),
+				v1.BoskosIdLabel:  name,
+			},
+			Annotations: map[string]string{
+				OwnerAnnotation:      owner,
+				LastUpdateAnnotation: time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: spec,
+	}, nil
+}
+
+// heartbeat records a heartbeat of the owner of a lease, and its new state if state is not empty.
+func (s *Server) heartbeat(ctx context.Context, lease *v1.Lease, state string) (*v1.Lease, error) {
+	annotations := map[string]string{LastUpdateAnnotation: time.Now().UTC().Format(time.RFC3339)}
+	if state != "" {
+		annotations[StateAnnotation] = state
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": annotations}})
+	if err != nil {
+		return nil, err
+	}
+	return s.Client.VspherecapacitymanagerV1().Leases(lease.Namespace).Patch(ctx, lease.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+}
+
+// ownedLease returns the lease of a resource if owner owns it, or writes the error.
+func (s *Server) ownedLease(w http.ResponseWriter, r *http.Request) *v1.Lease {
+	query := r.URL.Query()
+	name, owner := query.Get("name"), query.Get("owner")
+	lease, err := s.Client.VspherecapacitymanagerV1().Leases(s.Namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeAPIError(r.Context(), w, fmt.Sprintf("error getting lease %s", name), err)
+		return nil
+	}
+	if lease.Labels[ResourceTypeLabel] != s.resourceType() {
+		http.Error(w, fmt.Sprintf("lease %s was not acquired through boskos", name), http.StatusNotFound)
+		return nil
+	}
+	if lease.Annotations[OwnerAnnotation] != owner {
+		http.Error(w, fmt.Sprintf("lease %s is owned by %s", name, lease.Annotations[OwnerAnnotation]), http.StatusUnauthorized)
+		return nil
+	}
+	return lease
+}
+
+// release deletes a lease. the destination state is ignored, the resources of the lease are always cleaned up
+// before they are leased again.
+func (s *Server) release(w http.ResponseWriter, r *http.Request) {
+	lease := s.ownedLease(w, r)
+	if lease == nil {
+		return
+	}
+	err := s.Client.VspherecapacitymanagerV1().Leases(lease.Namespace).Delete(r.Context(), lease.Name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		writeAPIError(r.Context(), w, fmt.Sprintf("error releasing lease %s", lease.Name), err)
+		return
+	}
+	log.FromContext(r.Context()).V(2).Info("lease released by boskos owner", "Lease", klog.KObj(lease), "owner", lease.Annotations[OwnerAnnotation])
+}
+
+// update is the heartbeat of the owner of a lease. the user data sent by the owner is ignored.
+func (s *Server) update(w http.ResponseWriter, r *http.Request) {
+	lease := s.ownedLease(w, r)
+	if lease == nil {
+		return
+	}
+	if _, err := io.Copy(io.Discard, http.MaxBytesReader(w, r.Body, maxRequestBytes)); err != nil {
+		http.Error(w, fmt.Sprintf("invalid user data: %v", err), http.StatusBadRequest)
+		return
+	}
+	state := r.URL.Query().Get("state")
+	if current := leaseState(lease); state != current {
+		http.Error(w, fmt.Sprintf("lease %s is %s, not %s", lease.Name, current, state), http.StatusConflict)
+		return
+	}
+	if _, err := s.heartbeat(r.Context(), lease, ""); err != nil {
+		writeAPIError(r.Context(), w, fmt.Sprintf("error updating lease %s", lease.Name), err)
+	}
+}
+
+// reset releases the leases in a state whose owners did not send a heartbeat within expire, as the Boskos
+// reaper does, and returns their owners by lease.
+func (s *Server) reset(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("type") != s.resourceType() {
+		http.Error(w, fmt.Sprintf("unknown resource type %s", query.Get("type")), http.StatusNotFound)
+		return
+	}
+	expire, err := time.ParseDuration(query.Get("expire"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid expire %q", query.Get("expire")), http.StatusBadRequest)
+		return
+	}
+	released, err := s.releaseExpired(r.Context(), query.Get("state"), expire)
+	if err != nil {
+		writeAPIError(r.Context(), w, "error releasing expired leases", err)
+		return
+	}
+	writeJSON(r.Context(), w, released)
+}
+
+// releaseExpired deletes the leases in a state whose owners did not send a heartbeat within expiry, and
+// returns their owners by lease.
+func (s *Server) releaseExpired(ctx context.Context, state string, expiry time.Duration) (map[string]string, error) {
+	leases, err := s.listLeases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	released := make(map[string]string)
+	for _, lease := range expiredLeases(leases, state, expiry, time.Now()) {
+		err := s.Client.VspherecapacitymanagerV1().Leases(lease.Namespace).Delete(ctx, lease.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return released, err
+		}
+		owner := lease.Annotations[OwnerAnnotation]
+		log.FromContext(ctx).Info("lease of boskos owner released after no heartbeat", "Lease", klog.KObj(lease), "owner", owner, "lastUpdate", lastUpdate(lease))
+		released[lease.Name] = owner
+	}
+	return released, nil
+}
+
+func (s *Server) metric(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("type") != s.resourceType() {
+		http.Error(w, fmt.Sprintf("unknown resource type %s", r.URL.Query().Get("type")), http.StatusNotFound)
+		return
+	}
+	leases, err := s.listLeases(r.Context())
+	if err != nil {
+		writeAPIError(r.Context(), w, "error listing leases", err)
+		return
+	}
+	pools, err := s.Client.VspherecapacitymanagerV1().Pools(metav1.NamespaceAll).List(r.Context(), metav1.ListOptions{})
+	if err != nil {
+		writeAPIError(r.Context(), w, "error listing pools", err)
+		return
+	}
+	writeJSON(r.Context(), w, metric(s.resourceType(), leases, pools.Items))
+}
+
+// listLeases returns the leases acquired through Boskos for the resource type.
+func (s *Server) listLeases(ctx context.Context) ([]v1.Lease, error) {
+	leases, err := s.Client.VspherecapacitymanagerV1().Leases(s.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: ResourceTypeLabel + "=" + s.resourceType(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return leases.Items, nil
+}
+
+func (s *Server) resourceType() string {
+	if s.ResourceType != "" {
+		return s.ResourceType
+	}
+	return DEFAULT_RESOURCE_TYPE
+}
+
+func (s *Server) expiry() time.Duration {
+	if s.Expiry > 0 {
+		return s.Expiry
+	}
+	return DEFAULT_EXPIRY
+}
+
+func writeJSON(ctx context.Context, w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.FromContext(ctx).Error(err, "unable to write boskos response")
+	}
+}
+
+// writeAPIError returns the status of an error of the API server, or an internal error.
+func writeAPIError(ctx context.Context, w http.ResponseWriter, message string, err error) {
+	if status, ok := err.(apierrors.APIStatus); ok && status.Status().Code != 0 {
+		http.Error(w, fmt.Sprintf("%s: %s", message, status.Status().Message), int(status.Status().Code))
+		return
+	}
+	log.FromContext(ctx).Error(err, message)
+	http.Error(w, message, http.StatusInternalServerError)
+}
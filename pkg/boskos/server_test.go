@@ -0,0 +1,234 @@
+package boskos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned/fake"
+)
+
+var errNotFound = errors.New("resource not found")
+
+// boskosClient sends the requests of the Boskos client in sigs.k8s.io/boskos/client.
+type boskosClient struct {
+	url       string
+	owner     string
+	requestID string
+}
+
+func (c *boskosClient) do(method, path string, values url.Values, body string) (*http.Response, error) {
+	req, err := http.NewRequest(method, fmt.Sprintf("%s%s?%s", c.url, path, values.Encode()), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func (c *boskosClient) acquire(resourceType, state, dest string) (*Resource, error) {
+	values := url.Values{"type": {resourceType}, "state": {state}, "dest": {dest}, "owner": {c.owner}}
+	if c.requestID != "" {
+		values.Set("request_id", c.requestID)
+	}
+	resp, err := c.do(http.MethodPost, "/acquire", values, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		resource := &Resource{}
+		return resource, json.NewDecoder(resp.Body).Decode(resource)
+	case http.StatusNotFound:
+		return nil, errNotFound
+	}
+	return nil, fmt.Errorf("status %s", resp.Status)
+}
+
+func (c *boskosClient) update(name, state string) (int, error) {
+	values := url.Values{"name": {name}, "owner": {c.owner}, "state": {state}}
+	resp, err := c.do(http.MethodPost, "/update", values, `{"leased-resources":"[]"}`)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func (c *boskosClient) release(name, dest string) (int, error) {
+	values := url.Values{"name": {name}, "owner": {c.owner}, "dest": {dest}}
+	resp, err := c.do(http.MethodPost, "/release", values, "")
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func (c *boskosClient) metric(resourceType string) (*Metric, error) {
+	resp, err := c.do(http.MethodGet, "/metric", url.Values{"type": {resourceType}}, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %s", resp.Status)
+	}
+	m := &Metric{}
+	return m, json.NewDecoder(resp.Body).Decode(m)
+}
+
+func newTestServer() (*httptest.Server, *fake.Clientset) {
+	client := fake.NewSimpleClientset()
+	server := &Server{Client: client, Namespace: "ci"}
+	return httptest.NewServer(server.Handler()), client
+}
+
+func fulfill(t *testing.T, client *fake.Clientset, name string) {
+	t.Helper()
+	leases := client.VspherecapacitymanagerV1().Leases("ci")
+	lease, err := leases.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	lease.Status.Phase = v1.PHASE_FULFILLED
+	lease.Status.EnvVars = "export GOVC_URL=vcenter"
+	if _, err := leases.UpdateStatus(context.TODO(), lease, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestAcquireRelease(t *testing.T) {
+	server, client := newTestServer()
+	defer server.Close()
+	c := &boskosClient{url: server.URL, owner: "job-1", requestID: "request-1"}
+
+	if _, err := c.acquire("other-type", StateFree, StateBusy); !errors.Is(err, errNotFound) {
+		t.Errorf("expected no resource of another type, got %v", err)
+	}
+
+	// the lease is created by the first acquire, and not found until it is fulfilled.
+	for i := 0; i < 2; i++ {
+		if _, err := c.acquire(DEFAULT_RESOURCE_TYPE, StateFree, StateBusy); !errors.Is(err, errNotFound) {
+			t.Fatalf("expected no resource while the lease is pending, got %v", err)
+		}
+	}
+	leases, _ := client.VspherecapacitymanagerV1().Leases("ci").List(context.TODO(), metav1.ListOptions{})
+	if len(leases.Items) != 1 {
+		t.Fatalf("expected one lease for the request, got %d", len(leases.Items))
+	}
+	lease := leases.Items[0]
+	if lease.Spec.VCpus != defaultVCpus || lease.Labels[v1.BoskosIdLabel] != lease.Name || lease.Annotations[OwnerAnnotation] != "job-1" {
+		t.Errorf("unexpected lease %+v", lease.ObjectMeta)
+	}
+
+	other := &boskosClient{url: server.URL, owner: "job-2", requestID: "request-1"}
+	if _, err := other.acquire(DEFAULT_RESOURCE_TYPE, StateFree, StateBusy); err == nil || errors.Is(err, errNotFound) {
+		t.Errorf("expected the lease of another owner to be refused, got %v", err)
+	}
+
+	fulfill(t, client, lease.Name)
+	resource, err := c.acquire(DEFAULT_RESOURCE_TYPE, StateFree, StateBusy)
+	if err != nil {
+		t.Fatalf("expected the fulfilled lease, got %v", err)
+	}
+	if resource.Name != lease.Name || resource.State != StateBusy || resource.Owner != "job-1" || resource.UserData["envVars"] != "export GOVC_URL=vcenter" {
+		t.Errorf("unexpected resource %+v", resource)
+	}
+
+	tests := []struct {
+		name     string
+		client   *boskosClient
+		state    string
+		wantCode int
+	}{
+		{name: "heartbeat", client: c, state: StateBusy, wantCode: http.StatusOK},
+		{name: "wrong state", client: c, state: StateFree, wantCode: http.StatusConflict},
+		{name: "wrong owner", client: other, state: StateBusy, wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := tt.client.update(resource.Name, tt.state)
+			if err != nil || code != tt.wantCode {
+				t.Errorf("expected status %d, got %d %v", tt.wantCode, code, err)
+			}
+		})
+	}
+
+	m, err := c.metric(DEFAULT_RESOURCE_TYPE)
+	if err != nil || m.Current[StateBusy] != 1 || m.Owners["job-1"] != 1 {
+		t.Errorf("unexpected metric %+v %v", m, err)
+	}
+
+	if code, err := other.release(resource.Name, StateDirty); err != nil || code != http.StatusUnauthorized {
+		t.Errorf("expected the release of another owner to be refused, got %d %v", code, err)
+	}
+	if code, err := c.release(resource.Name, StateDirty); err != nil || code != http.StatusOK {
+		t.Fatalf("expected the lease to be released, got %d %v", code, err)
+	}
+	if leases, _ := client.VspherecapacitymanagerV1().Leases("ci").List(context.TODO(), metav1.ListOptions{}); len(leases.Items) != 0 {
+		t.Errorf("expected the lease to be deleted, got %d leases", len(leases.Items))
+	}
+}
+
+func TestAcquireShape(t *testing.T) {
+	server, client := newTestServer()
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/acquire?type=vsphere-elastic&state=free&dest=busy&owner=job-1&vcpus=48&pools=2&network-type=multi-tenant", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	resp.Body.Close()
+	lease, err := client.VspherecapacitymanagerV1().Leases("ci").Get(context.TODO(), leaseName(DEFAULT_RESOURCE_TYPE, "job-1"), metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("expected a lease keyed by the owner, got %v", err)
+	}
+	if lease.Spec.VCpus != 48 || lease.Spec.Memory != defaultMemory || lease.Spec.Pools != 2 || lease.Spec.NetworkType != v1.NetworkTypeMultiTenant {
+		t.Errorf("unexpected spec %+v", lease.Spec)
+	}
+
+	resp, err = http.Post(server.URL+"/acquire?type=vsphere-elastic&state=free&dest=busy&owner=job-2&vcpus=many", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected an invalid shape to be refused, got %d", resp.StatusCode)
+	}
+}
+
+func TestReset(t *testing.T) {
+	now := time.Now()
+	expired := newTestLease("expired", v1.PHASE_FULFILLED, now.Add(-time.Hour))
+	alive := newTestLease("alive", v1.PHASE_FULFILLED, now)
+	client := fake.NewSimpleClientset(&expired, &alive)
+	server := httptest.NewServer((&Server{Client: client, Namespace: "ci"}).Handler())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/reset?type=vsphere-elastic&state=busy&expire=30m&dest=dirty", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer resp.Body.Close()
+	released := map[string]string{}
+	if err := json.NewDecoder(resp.Body).Decode(&released); err != nil {
+		t.Fatalf("unexpected response: %v", err)
+	}
+	if len(released) != 1 || released["expired"] != "owner-expired" {
+		t.Errorf("expected only the expired lease to be released, got %v", released)
+	}
+	if leases, _ := client.VspherecapacitymanagerV1().Leases("ci").List(context.TODO(), metav1.ListOptions{}); len(leases.Items) != 1 || leases.Items[0].Name != "alive" {
+		t.Errorf("expected only the alive lease to remain, got %v", leases.Items)
+	}
+}
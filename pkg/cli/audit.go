@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/audit"
+)
+
+// auditTimeLayouts are the layouts of the time of an audit query. times without a zone are local.
+var auditTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"}
+
+func parseAuditTime(value string) (time.Time, error) {
+	for _, layout := range auditTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected a time such as %q", value, "2006-01-02 15:04")
+}
+
+// auditQuery selects the records of an audit log. empty fields match every record.
+type auditQuery struct {
+	Namespace string
+	VLAN      string
+	Network   string
+	Pool      string
+	Lease     string
+}
+
+func (q auditQuery) matches(record audit.Record) bool {
+	return (q.Namespace == "" || record.Namespace == q.Namespace) &&
+		(q.VLAN == "" || record.VLAN == q.VLAN) &&
+		(q.Network == "" || (record.Kind == audit.KindNetwork && record.Name == q.Network)) &&
+		(q.Pool == "" || record.Pool == q.Pool) &&
+		(q.Lease == "" || record.Lease == q.Lease)
+}
+
+func auditRecordsTable(records []audit.Record) *table {
+	t := newTable("TIME", "ACTION", "KIND", "NAME", "POOL", "VLAN", "LEASE", "BOSKOS ID", "REASON")
+	for _, record := range records {
+		t.addRow(record.Time.Local().Format(time.DateTime), record.Action, record.Kind, record.Name, record.Pool,
+			record.VLAN, record.Lease, record.BoskosID, record.Reason)
+	}
+	return t
+}
+
+func auditHoldingsTable(holdings []audit.Holding) *table {
+	t := newTable("KIND", "NAME", "POOL", "VLAN", "LEASE", "BOSKOS ID", "JOB", "ALLOCATED", "RELEASED")
+	for _, holding := range holdings {
+		released := "-"
+		if holding.ReleasedAt != nil {
+			released = holding.ReleasedAt.Local().Format(time.DateTime)
+		}
+		t.addRow(holding.Kind, holding.Name, holding.Pool, holding.VLAN, holding.Lease, holding.BoskosID,
+			holding.JobLink, holding.Time.Local().Format(time.DateTime), released)
+	}
+	return t
+}
+
+// queryAudit prints the records of the audit log in matching query, or what they held at a time if at is set.
+func (o *Options) queryAudit(in io.Reader, query auditQuery, at string) error {
+	records, err := audit.Read(in)
+	if err != nil {
+		return err
+	}
+	query.Namespace = o.Namespace
+	records = audit.Filter(records, query.matches)
+
+	if at == "" {
+		return o.print(records, func() *table { return auditRecordsTable(records) })
+	}
+	atTime, err := parseAuditTime(at)
+	if err != nil {
+		return err
+	}
+	holdings := audit.HeldAt(records, atTime)
+	if err := o.print(holdings, func() *table { return auditHoldingsTable(holdings) }); err != nil {
+		return err
+	}
+	if len(holdings) == 0 {
+		o.printf("\nNothing matching was held at %s", atTime.Format(time.DateTime))
+	}
+	return nil
+}
+
+func newAuditCommand(o *Options) *cobra.Command {
+	var file, at string
+	query := auditQuery{}
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show who held pools and networks, from the audit log of the controller",
+		Long: "Show who held pools and networks, from the audit log written by the controller with --audit-log. " +
+			"With --at, the pools and networks held at that time are shown, otherwise every matching allocation " +
+			"and release. Times without a zone are local. The audit log is read from stdin by default, so an audit " +
+			"log written to stdout can be piped from the logs of the controller.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "-" {
+				return o.queryAudit(cmd.InOrStdin(), query, at)
+			}
+			f, err := os.Open(file)
+			if err != nil {
+				return fmt.Errorf("error opening audit log: %w", err)
+			}
+			defer f.Close()
+			return o.queryAudit(f, query, at)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&file, "file", "-", "path to the audit log, or - for stdin")
+	flags.StringVar(&at, "at", "", "show what was held at this time, such as \"2026-10-18 14:05\"")
+	flags.StringVar(&query.VLAN, "vlan", "", "only the networks with this VLAN ID")
+	flags.StringVar(&query.Network, "network", "", "only this network")
+	flags.StringVar(&query.Pool, "pool", "", "only this pool and its networks")
+	flags.StringVar(&query.Lease, "lease", "", "only the resources of this lease")
+	return cmd
+}
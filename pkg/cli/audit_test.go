@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryAudit(t *testing.T) {
+	at := func(hour, minute int) string {
+		return time.Date(2024, 1, 1, hour, minute, 0, 0, time.Local).UTC().Format(time.RFC3339)
+	}
+	log := strings.Join([]string{
+		`{"time":"` + at(13, 0) + `","action":"allocate","kind":"Network","name":"net-1","lease":"lease-1","namespace":"ci","vlan":"1302"}`,
+		`{"time":"` + at(13, 0) + `","action":"allocate","kind":"Network","name":"net-2","lease":"lease-1","namespace":"ci","vlan":"1303"}`,
+		`{"time":"` + at(15, 0) + `","action":"release","kind":"Network","name":"net-1","lease":"lease-1","namespace":"ci","vlan":"1302"}`,
+		`{"time":"` + at(15, 0) + `","action":"allocate","kind":"Network","name":"net-1","lease":"lease-2","namespace":"other","vlan":"1302"}`,
+	}, "\n")
+
+	tests := []struct {
+		name      string
+		namespace string
+		query     auditQuery
+		at        string
+		wantLines int
+		want      string
+	}{
+		{name: "who had the VLAN", namespace: "ci", query: auditQuery{VLAN: "1302"}, at: "2024-01-01 14:05", wantLines: 2, want: "lease-1"},
+		{name: "nothing held", namespace: "ci", query: auditQuery{VLAN: "1302"}, at: "2024-01-01 12:00", wantLines: 3, want: "Nothing matching"},
+		{name: "history", namespace: "ci", query: auditQuery{VLAN: "1302"}, wantLines: 3, want: "release"},
+		{name: "other namespace", namespace: "other", query: auditQuery{VLAN: "1302"}, wantLines: 2, want: "lease-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			o := &Options{Namespace: tt.namespace, Output: OutputTable, Out: out}
+			if err := o.queryAudit(strings.NewReader(log), tt.query, tt.at); err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+			if len(lines) != tt.wantLines || !strings.Contains(out.String(), tt.want) {
+				t.Errorf("expected %d lines with %q, got\n%s", tt.wantLines, tt.want, out.String())
+			}
+		})
+	}
+
+	if _, err := parseAuditTime("yesterday"); err == nil {
+		t.Error("expected an error for an invalid time")
+	}
+}
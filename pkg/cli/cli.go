@@ -0,0 +1,95 @@
+// Package cli implements the oc-vcm command, an oc and kubectl plugin to inspect and manage the pools, leases and
+// networks of the capacity manager.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned"
+)
+
+const (
+	// DefaultNamespace is the namespace of the capacity manager resources
+	DefaultNamespace = "vsphere-infra-helpers"
+)
+
+// Options are the options shared by all commands.
+type Options struct {
+	// Kubeconfig is the path of the kubeconfig. the default loading rules apply if empty.
+	Kubeconfig string
+	// Namespace is the namespace of the pools, leases and networks.
+	Namespace string
+	// Output is the output format, one of table, json or yaml.
+	Output string
+
+	// Out is where the output is written.
+	Out io.Writer
+	// Client is the client used by the commands. it is built from the kubeconfig if not set.
+	Client versioned.Interface
+}
+
+// NewCommand returns the root oc-vcm command.
+func NewCommand() *cobra.Command {
+	o := &Options{Out: os.Stdout}
+	cmd := &cobra.Command{
+		Use:           "oc-vcm",
+		Short:         "Inspect and manage vSphere Capacity Manager pools, leases and networks",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return validateOutput(o.Output)
+		},
+	}
+	cmd.SetOut(o.Out)
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&o.Kubeconfig, "kubeconfig", "", "path to the kubeconfig file")
+	flags.StringVarP(&o.Namespace, "namespace", "n", DefaultNamespace, "namespace of the capacity manager resources")
+	flags.StringVarP(&o.Output, "output", "o", OutputTable, "output format, one of table, json or yaml")
+
+	cmd.AddCommand(
+		newStatusCommand(o),
+		newPoolCommand(o, "cordon", "Stop scheduling new leases on a pool", cordonPool),
+		newPoolCommand(o, "uncordon", "Resume scheduling new leases on a pool", uncordonPool),
+		newPoolCommand(o, "exclude", "Exclude a pool from the default pools", excludePool),
+		newPoolCommand(o, "include", "Include a pool in the default pools", includePool),
+		newSetCapacityCommand(o),
+		newVLANCommand(o, "add-vlan", "Add a VLAN to pools", addVLAN),
+		newVLANCommand(o, "drop-vlan", "Drop a VLAN from pools", dropVLAN),
+		newDrainCommand(o),
+		newNetworksCommand(o),
+		newSplitNetworkCommand(o),
+		newJobsCommand(o),
+		newLeasesCommand(o),
+		newExplainCommand(o),
+		newSimulateCommand(o),
+		newWatchCommand(o),
+		newAuditCommand(o),
+	)
+	return cmd
+}
+
+// client returns the client of the commands, building it from the kubeconfig the first time.
+func (o *Options) client() (versioned.Interface, error) {
+	if o.Client != nil {
+		return o.Client, nil
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = o.Kubeconfig
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubeconfig: %w", err)
+	}
+
+	o.Client, err = versioned.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client: %w", err)
+	}
+	return o.Client, nil
+}
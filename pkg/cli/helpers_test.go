@@ -0,0 +1,46 @@
+package cli
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func newTestPool(name string, vcpusAvailable, memoryAvailable, networksAvailable int) v1.Pool {
+	return v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PoolSpec{
+			FailureDomainSpec: v1.FailureDomainSpec{
+				VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+					Topology: configv1.VSpherePlatformTopology{
+						Datacenter: "dc1",
+						Networks:   []string{"/dc1/network/ci-vlan-1", "/dc1/network/ci-vlan-2", "/dc1/network/ci-vlan-3", "/dc1/network/ci-vlan-4"},
+					},
+				},
+			},
+			VCpus:  100,
+			Memory: 400,
+		},
+		Status: v1.PoolStatus{
+			VCpusAvailable:   vcpusAvailable,
+			MemoryAvailable:  memoryAvailable,
+			NetworkAvailable: networksAvailable,
+		},
+	}
+}
+
+func newTestLease(name, boskosID string, networkType v1.NetworkType, phase v1.Phase, pools ...string) v1.Lease {
+	lease := v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+		Spec:       v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 1, NetworkType: networkType},
+		Status:     v1.LeaseStatus{Phase: phase},
+	}
+	if boskosID != "" {
+		lease.Labels["boskos-lease-id"] = boskosID
+	}
+	for _, pool := range pools {
+		lease.OwnerReferences = append(lease.OwnerReferences, metav1.OwnerReference{Kind: v1.PoolKind, Name: pool})
+	}
+	return lease
+}
@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+const unknown = "unknown"
+
+// JobLeases is the number of leases held by a CI job.
+type JobLeases struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Leases int    `json:"leases"`
+}
+
+// LeaseStatus is a lease and the job holding it.
+type LeaseStatus struct {
+	BoskosLeaseID string         `json:"boskosLeaseID"`
+	JobName       string         `json:"jobName"`
+	JobType       string         `json:"jobType"`
+	NetworkType   v1.NetworkType `json:"networkType"`
+	Phase         v1.Phase       `json:"phase"`
+	Pools         []string       `json:"pools"`
+}
+
+func leaseJob(lease *v1.Lease) (string, string) {
+	name, ok := lease.Labels[v1.JobNameLabel]
+	if !ok {
+		name = unknown
+	}
+	jobType, ok := lease.Annotations[v1.PROW_JOB_TYPE_KEY]
+	if !ok {
+		jobType = unknown
+	}
+	return name, jobType
+}
+
+func leasePoolNames(lease *v1.Lease) []string {
+	names := []string{}
+	for _, ref := range utils.GetLeasePoolRefs(lease) {
+		names = append(names, ref.Name)
+	}
+	return names
+}
+
+// buildJobLeases counts the leases of each job, from the job with the most leases.
+func buildJobLeases(leases []v1.Lease) []JobLeases {
+	counts := make(map[[2]string]int)
+	for i := range leases {
+		name, jobType := leaseJob(&leases[i])
+		counts[[2]string{name, jobType}]++
+	}
+
+	jobs := make([]JobLeases, 0, len(counts))
+	for key, count := range counts {
+		jobs = append(jobs, JobLeases{Name: key[0], Type: key[1], Leases: count})
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if jobs[i].Leases != jobs[j].Leases {
+			return jobs[i].Leases > jobs[j].Leases
+		}
+		return jobs[i].Name+jobs[i].Type < jobs[j].Name+jobs[j].Type
+	})
+	return jobs
+}
+
+// buildLeaseStatus returns a status for each boskos lease, from its first lease.
+func buildLeaseStatus(leases []v1.Lease) []LeaseStatus {
+	statuses := []LeaseStatus{}
+	for _, lease := range uniqueLeases(leases) {
+		id, ok := lease.Labels[v1.BoskosIdLabel]
+		if !ok {
+			id = lease.Name
+		}
+		name, jobType := leaseJob(lease)
+		statuses = append(statuses, LeaseStatus{
+			BoskosLeaseID: id,
+			JobName:       name,
+			JobType:       jobType,
+			NetworkType:   lease.Spec.NetworkType,
+			Phase:         lease.Status.Phase,
+			Pools:         leasePoolNames(lease),
+		})
+	}
+	return statuses
+}
+
+func newJobsCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "jobs",
+		Short: "List the CI jobs holding leases",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := o.listResources(cmd.Context())
+			if err != nil {
+				return err
+			}
+			jobs := buildJobLeases(res.leases)
+			return o.print(jobs, func() *table {
+				t := newTable("JOB", "TYPE", "LEASES")
+				for _, job := range jobs {
+					t.addRow(job.Name, job.Type, job.Leases)
+				}
+				return t
+			})
+		},
+	}
+}
+
+func newLeasesCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "leases",
+		Short: "List the leases by boskos lease",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := o.listResources(cmd.Context())
+			if err != nil {
+				return err
+			}
+			statuses := buildLeaseStatus(res.leases)
+			return o.print(statuses, func() *table {
+				t := newTable("BOSKOS LEASE ID", "JOB", "TYPE", "NETWORK TYPE", "PHASE", "POOLS")
+				for _, status := range statuses {
+					t.addRow(status.BoskosLeaseID, status.JobName, status.JobType, status.NetworkType, status.Phase, strings.Join(status.Pools, ","))
+				}
+				return t
+			})
+		},
+	}
+}
+
+// LeaseExplanation tells why a lease holds the pools it holds, and why the other pools were rejected.
+type LeaseExplanation struct {
+	Lease         string         `json:"lease"`
+	Phase         v1.Phase       `json:"phase"`
+	NetworkType   v1.NetworkType `json:"networkType"`
+	Profile       string         `json:"profile"`
+	RequiredPools int            `json:"requiredPools"`
+	Conditions    []v1.Condition `json:"conditions,omitempty"`
+	Pools         []PoolResult   `json:"pools"`
+}
+
+func (e *LeaseExplanation) table() *table {
+	return poolResultsTable(e.Pools)
+}
+
+func newExplainCommand(o *Options) *cobra.Command {
+	var schedulerConfig string
+	cmd := &cobra.Command{
+		Use:   "explain <lease>",
+		Short: "Explain why a lease holds its pools and why other pools are rejected",
+		Long: "Explain why a lease holds its pools and why other pools are rejected. The scheduler plugins are " +
+			"run locally against the current pools, networks are not considered.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			lease, err := c.VspherecapacitymanagerV1().Leases(o.Namespace).Get(ctx, args[0], metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("error getting lease %s: %w", args[0], err)
+			}
+			res, err := o.listResources(ctx)
+			if err != nil {
+				return err
+			}
+			sched, err := newScheduler(schedulerConfig)
+			if err != nil {
+				return err
+			}
+
+			explanation := explainLease(ctx, sched, lease, res)
+			if err := o.print(explanation, explanation.table); err != nil {
+				return err
+			}
+			o.printf("\nLease %s is %s, %d/%d pools assigned, scheduled with profile %s", lease.Name, explanation.Phase,
+				len(utils.GetLeasePoolRefs(lease)), explanation.RequiredPools, explanation.Profile)
+			if lease.Status.QueuePosition > 0 {
+				o.printf("  queue position %d, blocked by [%s], estimated wait %s", lease.Status.QueuePosition,
+					strings.Join(lease.Status.BlockedBy, ", "), estimatedWait(lease))
+			}
+			for _, condition := range explanation.Conditions {
+				if condition.Status != v1.ConditionTrue {
+					continue
+				}
+				o.printf("  %s: %s %s", condition.Type, condition.Reason, condition.Message)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&schedulerConfig, "scheduler-config", "", "path to the scheduler profile configuration of the controller. the default profiles are used if not set")
+	return cmd
+}
+
+// estimatedWait returns the estimated wait of a waiting lease, or unknown.
+func estimatedWait(lease *v1.Lease) string {
+	if lease.Status.EstimatedWait == nil {
+		return "unknown"
+	}
+	return lease.Status.EstimatedWait.Duration.String()
+}
+
+// explainLease evaluates the pools for a lease with the scheduler.
+func explainLease(ctx context.Context, sched *scheduler.Scheduler, lease *v1.Lease, res *resources) *LeaseExplanation {
+	lease = lease.DeepCopy()
+	if lease.Spec.NetworkType == "" {
+		lease.Spec.NetworkType = v1.NetworkTypeSingleTenant
+	}
+	framework := sched.ForLease(lease)
+	pools := poolPointers(res.pools)
+
+	var assigned []*v1.Pool
+	for _, pool := range pools {
+		if utils.LeaseHasPool(lease, pool.Name) {
+			assigned = append(assigned, pool)
+		}
+	}
+	state := scheduler.NewCycleState(lease, assigned, leasePointers(res.leases))
+
+	return &LeaseExplanation{
+		Lease:         lease.Name,
+		Phase:         lease.Status.Phase,
+		NetworkType:   lease.Spec.NetworkType,
+		Profile:       framework.ProfileName(),
+		RequiredPools: state.RequiredPools,
+		Conditions:    lease.Status.Conditions,
+		Pools:         evaluatePools(ctx, framework, lease, assigned, pools, leasePointers(res.leases)),
+	}
+}
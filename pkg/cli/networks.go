@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// splitNetworkAddresses is the number of addresses of each network split from a network
+	splitNetworkAddresses = 4
+)
+
+// NetworkStatus is a network and the number of leases holding it.
+type NetworkStatus struct {
+	Name        string         `json:"name"`
+	Type        v1.NetworkType `json:"type"`
+	CIDR        string         `json:"cidr"`
+	Leases      int            `json:"leases"`
+	Quarantined bool           `json:"quarantined"`
+	NoSchedule  bool           `json:"noSchedule"`
+}
+
+// buildNetworkStatus returns the networks of a type, or all networks if networkType is empty.
+func buildNetworkStatus(networks []v1.Network, leases []v1.Lease, filter v1.NetworkType) []NetworkStatus {
+	leaseCounts := make(map[string]int)
+	for _, lease := range leases {
+		for _, ref := range lease.OwnerReferences {
+			if ref.Kind == v1.NetworkKind {
+				leaseCounts[ref.Name]++
+			}
+		}
+	}
+
+	statuses := []NetworkStatus{}
+	for i := range networks {
+		network := &networks[i]
+		if filter != "" && networkType(network) != filter {
+			continue
+		}
+		statuses = append(statuses, NetworkStatus{
+			Name:        network.Name,
+			Type:        networkType(network),
+			CIDR:        network.Spec.MachineNetworkCidr,
+			Leases:      leaseCounts[network.Name],
+			Quarantined: network.Status.Quarantined,
+			NoSchedule:  network.Spec.NoSchedule,
+		})
+	}
+	return statuses
+}
+
+func newNetworksCommand(o *Options) *cobra.Command {
+	var filter string
+	cmd := &cobra.Command{
+		Use:   "networks",
+		Short: "List the networks and the number of leases holding them",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := o.listResources(cmd.Context())
+			if err != nil {
+				return err
+			}
+			statuses := buildNetworkStatus(res.networks, res.leases, v1.NetworkType(filter))
+			return o.print(statuses, func() *table {
+				t := newTable("NETWORK", "TYPE", "CIDR", "LEASES", "QUARANTINED", "NOSCHEDULE")
+				for _, status := range statuses {
+					t.addRow(status.Name, status.Type, status.CIDR, status.Leases, status.Quarantined, status.NoSchedule)
+				}
+				return t
+			})
+		},
+	}
+	cmd.Flags().StringVar(&filter, "network-type", "", "only list the networks of this type")
+	cmd.Flags().StringVar(&filter, "networkType", "", "only list the networks of this type")
+	_ = cmd.Flags().MarkDeprecated("networkType", "use --network-type instead")
+	return cmd
+}
+
+// splitNetwork returns subnets multi-tenant networks carved out of a network. each network gets its own port
+// group and splitNetworkAddresses of the addresses of the network.
+func splitNetwork(network *v1.Network, subnets int) ([]*v1.Network, error) {
+	if subnets < 1 {
+		return nil, fmt.Errorf("at least one subnet is required")
+	}
+	if network.Spec.Gateway == nil || network.Spec.IpAddressCount == nil {
+		return nil, fmt.Errorf("network %s has no gateway or address count", network.Name)
+	}
+	octets := strings.Split(*network.Spec.Gateway, ".")
+	if len(octets) != 4 {
+		return nil, fmt.Errorf("network %s has an invalid gateway %q", network.Name, *network.Spec.Gateway)
+	}
+	prefix := strings.Join(octets[:3], ".")
+	addresses := make([]string, 0, *network.Spec.IpAddressCount)
+	for i := uint(0); i < *network.Spec.IpAddressCount; i++ {
+		addresses = append(addresses, fmt.Sprintf("%s.%d", prefix, i))
+	}
+
+	var split []*v1.Network
+	for i := 1; i <= subnets; i++ {
+		// the network and gateway addresses are skipped, and neighbouring subnets share half their addresses.
+		start := 2 + (i-1)*2
+		if start+splitNetworkAddresses > len(addresses) {
+			return nil, fmt.Errorf("network %s has %d addresses, not enough for %d subnets", network.Name, len(addresses), subnets)
+		}
+		subnet := &v1.Network{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-multi-%d", network.Name, i),
+				Namespace: network.Namespace,
+				Labels:    map[string]string{v1.NetworkTypeLabel: string(v1.NetworkTypeMultiTenant)},
+			},
+			Spec: *network.Spec.DeepCopy(),
+		}
+		subnet.Spec.PortGroupName = fmt.Sprintf("%s-%d", network.Spec.PortGroupName, i)
+		subnet.Spec.IpAddresses = append([]string{}, addresses[start:start+splitNetworkAddresses]...)
+		split = append(split, subnet)
+	}
+	return split, nil
+}
+
+// applyNetwork creates a network, or updates the labels and spec of the network if it exists.
+func (o *Options) applyNetwork(ctx context.Context, network *v1.Network) error {
+	c, err := o.client()
+	if err != nil {
+		return err
+	}
+	networks := c.VspherecapacitymanagerV1().Networks(network.Namespace)
+	_, err = networks.Create(ctx, network, metav1.CreateOptions{})
+	if err == nil || !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing, err := networks.Get(ctx, network.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if existing.Labels == nil {
+		existing.Labels = make(map[string]string)
+	}
+	for key, value := range network.Labels {
+		existing.Labels[key] = value
+	}
+	existing.Spec = network.Spec
+	_, err = networks.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+func newSplitNetworkCommand(o *Options) *cobra.Command {
+	var name string
+	var subnets int
+	cmd := &cobra.Command{
+		Use:   "split-network --network <network> --subnets <count>",
+		Short: "Split a network into multi-tenant networks",
+		Long: "Split a network into multi-tenant networks. The network is labeled multi-tenant and a network " +
+			"named <network>-multi-<n> with its own port group is created or updated for each subnet.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if name == "" {
+				return fmt.Errorf("--network is required")
+			}
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+
+			network, err := c.VspherecapacitymanagerV1().Networks(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return fmt.Errorf("error getting network %s: %w", name, err)
+			}
+			split, err := splitNetwork(network, subnets)
+			if err != nil {
+				return err
+			}
+
+			if networkType(network) != v1.NetworkTypeMultiTenant {
+				if network.Labels == nil {
+					network.Labels = make(map[string]string)
+				}
+				network.Labels[v1.NetworkTypeLabel] = string(v1.NetworkTypeMultiTenant)
+				if _, err := c.VspherecapacitymanagerV1().Networks(o.Namespace).Update(ctx, network, metav1.UpdateOptions{}); err != nil {
+					return fmt.Errorf("error labeling network %s multi-tenant: %w", name, err)
+				}
+				o.printf("network %s labeled multi-tenant", name)
+			}
+			for _, subnet := range split {
+				if err := o.applyNetwork(ctx, subnet); err != nil {
+					return fmt.Errorf("error applying network %s: %w", subnet.Name, err)
+				}
+				o.printf("network %s applied with addresses %s", subnet.Name, strings.Join(subnet.Spec.IpAddresses, ", "))
+			}
+			if o.Output != OutputTable {
+				return o.print(split, nil)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&name, "network", "", "the network to split")
+	cmd.Flags().IntVar(&subnets, "subnets", 0, "the number of networks to create")
+	return cmd
+}
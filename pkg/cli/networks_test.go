@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func TestSplitNetwork(t *testing.T) {
+	gateway := "10.0.1.1"
+	count := uint(16)
+	network := &v1.Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "ci-vlan-1", Namespace: DefaultNamespace},
+		Spec: v1.NetworkSpec{
+			PortGroupName:  "ci-vlan-1",
+			Gateway:        &gateway,
+			IpAddressCount: &count,
+		},
+	}
+
+	split, err := splitNetwork(network, 3)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(split) != 3 {
+		t.Fatalf("expected 3 networks, got %d", len(split))
+	}
+	second := split[1]
+	if second.Name != "ci-vlan-1-multi-2" || second.Spec.PortGroupName != "ci-vlan-1-2" || second.Namespace != DefaultNamespace {
+		t.Errorf("unexpected network %s/%s with port group %s", second.Namespace, second.Name, second.Spec.PortGroupName)
+	}
+	if second.Labels[v1.NetworkTypeLabel] != string(v1.NetworkTypeMultiTenant) {
+		t.Errorf("expected a multi-tenant network, got labels %v", second.Labels)
+	}
+	want := []string{"10.0.1.4", "10.0.1.5", "10.0.1.6", "10.0.1.7"}
+	if len(second.Spec.IpAddresses) != len(want) {
+		t.Fatalf("expected addresses %v, got %v", want, second.Spec.IpAddresses)
+	}
+	for i := range want {
+		if second.Spec.IpAddresses[i] != want[i] {
+			t.Errorf("expected addresses %v, got %v", want, second.Spec.IpAddresses)
+			break
+		}
+	}
+
+	if _, err := splitNetwork(network, 7); err == nil {
+		t.Errorf("expected an error splitting 16 addresses in 7 networks")
+	}
+	if _, err := splitNetwork(&v1.Network{}, 1); err == nil {
+		t.Errorf("expected an error splitting a network without gateway")
+	}
+}
+
+func TestBuildNetworkStatus(t *testing.T) {
+	networks := []v1.Network{
+		{ObjectMeta: metav1.ObjectMeta{Name: "net-1"}, Spec: v1.NetworkSpec{MachineNetworkCidr: "10.0.1.0/24"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "net-2", Labels: map[string]string{v1.NetworkTypeLabel: string(v1.NetworkTypeMultiTenant)}}},
+	}
+	lease := newTestLease("lease-1", "", v1.NetworkTypeSingleTenant, v1.PHASE_FULFILLED, "pool-a")
+	lease.OwnerReferences = append(lease.OwnerReferences, metav1.OwnerReference{Kind: v1.NetworkKind, Name: "net-1"})
+
+	statuses := buildNetworkStatus(networks, []v1.Lease{lease}, "")
+	if len(statuses) != 2 || statuses[0].Leases != 1 || statuses[0].CIDR != "10.0.1.0/24" || statuses[1].Type != v1.NetworkTypeMultiTenant {
+		t.Errorf("unexpected networks %+v", statuses)
+	}
+	statuses = buildNetworkStatus(networks, []v1.Lease{lease}, v1.NetworkTypeMultiTenant)
+	if len(statuses) != 1 || statuses[0].Name != "net-2" {
+		t.Errorf("expected only net-2, got %+v", statuses)
+	}
+}
@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// updateAttempts is how many times an update conflicting with another writer is retried
+	updateAttempts = 3
+
+	// drainPollInterval is how often drain --wait checks the progress of the drain
+	drainPollInterval = 5 * time.Second
+)
+
+// poolMutation changes the spec of a pool. it returns a message describing the change, or an empty message if
+// the pool did not change.
+type poolMutation func(pool *v1.Pool) (string, error)
+
+func cordonPool(pool *v1.Pool) (string, error) {
+	if _, cordonedByDrain := pool.Annotations[v1.PoolCordonedByDrainAnnotation]; cordonedByDrain {
+		// the pool stays cordoned once the drain is removed.
+		delete(pool.Annotations, v1.PoolCordonedByDrainAnnotation)
+		return fmt.Sprintf("pool %s cordoned, it stays cordoned after the drain", pool.Name), nil
+	}
+	if pool.Spec.NoSchedule {
+		return "", nil
+	}
+	pool.Spec.NoSchedule = true
+	return fmt.Sprintf("pool %s cordoned", pool.Name), nil
+}
+
+func uncordonPool(pool *v1.Pool) (string, error) {
+	if !pool.Spec.NoSchedule {
+		return "", nil
+	}
+	pool.Spec.NoSchedule = false
+	return fmt.Sprintf("pool %s uncordoned", pool.Name), nil
+}
+
+func excludePool(pool *v1.Pool) (string, error) {
+	if pool.Spec.Exclude {
+		return "", nil
+	}
+	pool.Spec.Exclude = true
+	return fmt.Sprintf("pool %s excluded", pool.Name), nil
+}
+
+func includePool(pool *v1.Pool) (string, error) {
+	if !pool.Spec.Exclude {
+		return "", nil
+	}
+	pool.Spec.Exclude = false
+	return fmt.Sprintf("pool %s included", pool.Name), nil
+}
+
+// vlanPortGroup returns the suffix of the networks of a pool on a VLAN.
+func vlanPortGroup(vlan string) string {
+	return "ci-vlan-" + vlan
+}
+
+// addVLAN adds the port group of a VLAN to the networks of a pool.
+func addVLAN(vlan string) poolMutation {
+	return func(pool *v1.Pool) (string, error) {
+		for _, network := range pool.Spec.Topology.Networks {
+			if strings.HasSuffix(network, vlanPortGroup(vlan)) {
+				return "", nil
+			}
+		}
+		pool.Spec.Topology.Networks = append(pool.Spec.Topology.Networks,
+			fmt.Sprintf("/%s/network/%s", pool.Spec.Topology.Datacenter, vlanPortGroup(vlan)))
+		return fmt.Sprintf("added VLAN %s to pool %s", vlan, pool.Name), nil
+	}
+}
+
+// dropVLAN removes the port group of a VLAN from the networks of a pool.
+func dropVLAN(vlan string) poolMutation {
+	return func(pool *v1.Pool) (string, error) {
+		var networks []string
+		for _, network := range pool.Spec.Topology.Networks {
+			if !strings.HasSuffix(network, vlanPortGroup(vlan)) {
+				networks = append(networks, network)
+			}
+		}
+		if len(networks) == len(pool.Spec.Topology.Networks) {
+			return "", nil
+		}
+		pool.Spec.Topology.Networks = networks
+		return fmt.Sprintf("dropped VLAN %s from pool %s", vlan, pool.Name), nil
+	}
+}
+
+// setCapacity sets the vCPUs and memory of a pool. nil values are left unchanged.
+func setCapacity(vcpus, memory *int) poolMutation {
+	return func(pool *v1.Pool) (string, error) {
+		var changes []string
+		if vcpus != nil && pool.Spec.VCpus != *vcpus {
+			pool.Spec.VCpus = *vcpus
+			changes = append(changes, fmt.Sprintf("vcpus to %d", *vcpus))
+		}
+		if memory != nil && pool.Spec.Memory != *memory {
+			pool.Spec.Memory = *memory
+			changes = append(changes, fmt.Sprintf("memory to %d", *memory))
+		}
+		if len(changes) == 0 {
+			return "", nil
+		}
+		return fmt.Sprintf("set %s for pool %s", strings.Join(changes, " and "), pool.Name), nil
+	}
+}
+
+// drainPool requests a drain of a pool. leases older than deleteOlderThan are deleted if it is not 0.
+func drainPool(deleteOlderThan time.Duration) poolMutation {
+	return func(pool *v1.Pool) (string, error) {
+		drain := &v1.PoolDrainSpec{}
+		if deleteOlderThan > 0 {
+			drain.DeleteLeasesOlderThan = &metav1.Duration{Duration: deleteOlderThan}
+		}
+		if pool.Spec.Drain != nil && durationEqual(pool.Spec.Drain.DeleteLeasesOlderThan, drain.DeleteLeasesOlderThan) {
+			return "", nil
+		}
+		pool.Spec.Drain = drain
+		return fmt.Sprintf("pool %s draining", pool.Name), nil
+	}
+}
+
+// cancelDrain cancels the drain of a pool. VCM uncordons the pool unless it was cordoned before the drain.
+func cancelDrain(pool *v1.Pool) (string, error) {
+	if pool.Spec.Drain == nil {
+		return "", nil
+	}
+	pool.Spec.Drain = nil
+	return fmt.Sprintf("drain of pool %s cancelled", pool.Name), nil
+}
+
+func durationEqual(a, b *metav1.Duration) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Duration == b.Duration
+}
+
+// updatePool applies mutate to a pool and updates it, retrying if the pool changed in between.
+func (o *Options) updatePool(ctx context.Context, name string, mutate poolMutation) (*v1.Pool, string, error) {
+	c, err := o.client()
+	if err != nil {
+		return nil, "", err
+	}
+
+	pools := c.VspherecapacitymanagerV1().Pools(o.Namespace)
+	for attempt := 1; ; attempt++ {
+		pool, err := pools.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, "", fmt.Errorf("error getting pool %s: %w", name, err)
+		}
+		message, err := mutate(pool)
+		if err != nil || message == "" {
+			return pool, message, err
+		}
+		updated, err := pools.Update(ctx, pool, metav1.UpdateOptions{})
+		if err == nil {
+			return updated, message, nil
+		}
+		if !errors.IsConflict(err) || attempt == updateAttempts {
+			return nil, "", fmt.Errorf("error updating pool %s: %w", name, err)
+		}
+	}
+}
+
+// updatePools applies mutate to the named pool, or to every pool accepted by filter if name is empty.
+func (o *Options) updatePools(ctx context.Context, name string, filter func(pool *v1.Pool) bool, mutate poolMutation) error {
+	names := []string{name}
+	if name == "" {
+		res, err := o.listResources(ctx)
+		if err != nil {
+			return err
+		}
+		names = nil
+		for i := range res.pools {
+			if filter(&res.pools[i]) {
+				names = append(names, res.pools[i].Name)
+			}
+		}
+	}
+
+	var updated []*v1.Pool
+	for _, name := range names {
+		pool, message, err := o.updatePool(ctx, name, mutate)
+		if err != nil {
+			return err
+		}
+		if message == "" {
+			o.printf("pool %s unchanged", pool.Name)
+		} else {
+			o.printf("%s", message)
+		}
+		updated = append(updated, pool)
+	}
+	if o.Output != OutputTable {
+		return o.print(updated, nil)
+	}
+	return nil
+}
+
+// poolName returns the pool given as argument or with --pool.
+func poolName(flag string, args []string) (string, error) {
+	switch {
+	case len(args) > 0 && flag != "" && args[0] != flag:
+		return "", fmt.Errorf("pool given both as argument and with --pool")
+	case len(args) > 0:
+		return args[0], nil
+	case flag != "":
+		return flag, nil
+	}
+	return "", fmt.Errorf("a pool is required")
+}
+
+func newPoolCommand(o *Options, use, short string, mutate poolMutation) *cobra.Command {
+	var pool string
+	cmd := &cobra.Command{
+		Use:   use + " <pool>",
+		Short: short,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := poolName(pool, args)
+			if err != nil {
+				return err
+			}
+			return o.updatePools(cmd.Context(), name, nil, mutate)
+		},
+	}
+	cmd.Flags().StringVar(&pool, "pool", "", "the pool")
+	return cmd
+}
+
+func newSetCapacityCommand(o *Options) *cobra.Command {
+	var pool string
+	var vcpus, memory int
+	cmd := &cobra.Command{
+		Use:   "set-capacity <pool>",
+		Short: "Set the vCPUs and memory of a pool",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := poolName(pool, args)
+			if err != nil {
+				return err
+			}
+			var vcpusValue, memoryValue *int
+			if cmd.Flags().Changed("cpu") {
+				vcpusValue = &vcpus
+			}
+			if cmd.Flags().Changed("memory") {
+				memoryValue = &memory
+			}
+			if vcpusValue == nil && memoryValue == nil {
+				return fmt.Errorf("at least one of --cpu or --memory is required")
+			}
+			return o.updatePools(cmd.Context(), name, nil, setCapacity(vcpusValue, memoryValue))
+		},
+	}
+	cmd.Flags().StringVar(&pool, "pool", "", "the pool")
+	cmd.Flags().IntVar(&vcpus, "cpu", 0, "the number of vCPUs of the pool")
+	cmd.Flags().IntVar(&memory, "memory", 0, "the memory of the pool in GB")
+	return cmd
+}
+
+func newVLANCommand(o *Options, use, short string, mutate func(vlan string) poolMutation) *cobra.Command {
+	var pool, vlan string
+	cmd := &cobra.Command{
+		Use:   use + " --vlan <id> [--pool <pool>]",
+		Short: short,
+		Long: short + ". Without --pool, the VLAN is added to every pool which is neither cordoned nor excluded, " +
+			"and dropped from every pool.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if vlan == "" {
+				return fmt.Errorf("--vlan is required")
+			}
+			filter := func(pool *v1.Pool) bool { return true }
+			if use == "add-vlan" {
+				filter = func(pool *v1.Pool) bool { return !pool.Spec.NoSchedule && !pool.Spec.Exclude }
+			}
+			return o.updatePools(cmd.Context(), pool, filter, mutate(vlan))
+		},
+	}
+	cmd.Flags().StringVar(&vlan, "vlan", "", "the VLAN ID")
+	cmd.Flags().StringVar(&pool, "pool", "", "the pool. all pools if not set")
+	return cmd
+}
+
+func newDrainCommand(o *Options) *cobra.Command {
+	var pool string
+	var deleteOlderThan time.Duration
+	var cancel, waitDrained bool
+	cmd := &cobra.Command{
+		Use:   "drain <pool>",
+		Short: "Drain a pool for maintenance",
+		Long: "Drain a pool for maintenance. The pool is cordoned and the holders of its leases are asked to " +
+			"release them. With --delete-older-than, leases older than the duration are deleted.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := poolName(pool, args)
+			if err != nil {
+				return err
+			}
+			mutate := drainPool(deleteOlderThan)
+			if cancel {
+				mutate = cancelDrain
+			}
+			if err := o.updatePools(cmd.Context(), name, nil, mutate); err != nil {
+				return err
+			}
+			if cancel || !waitDrained {
+				return nil
+			}
+			return o.waitDrained(cmd.Context(), name)
+		},
+	}
+	cmd.Flags().StringVar(&pool, "pool", "", "the pool")
+	cmd.Flags().DurationVar(&deleteOlderThan, "delete-older-than", 0, "delete the leases holding the pool which are older than this duration")
+	cmd.Flags().BoolVar(&cancel, "cancel", false, "cancel the drain. the pool is uncordoned unless it was cordoned before the drain")
+	cmd.Flags().BoolVar(&waitDrained, "wait", false, "wait until no leases remain on the pool")
+	return cmd
+}
+
+// waitDrained waits until the drain of a pool is complete.
+func (o *Options) waitDrained(ctx context.Context, name string) error {
+	c, err := o.client()
+	if err != nil {
+		return err
+	}
+	remaining := -1
+	return wait.PollUntilContextCancel(ctx, drainPollInterval, true, func(ctx context.Context) (bool, error) {
+		pool, err := c.VspherecapacitymanagerV1().Pools(o.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("error getting pool %s: %w", name, err)
+		}
+		drain := pool.Status.Drain
+		if drain == nil {
+			return false, nil
+		}
+		if drain.Phase == v1.DrainPhaseDrained {
+			o.printf("pool %s drained", name)
+			return true, nil
+		}
+		if drain.LeasesRemaining != remaining {
+			remaining = drain.LeasesRemaining
+			o.printf("pool %s draining, %d leases remaining", name, remaining)
+		}
+		return false, nil
+	})
+}
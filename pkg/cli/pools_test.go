@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned/fake"
+)
+
+func TestPoolMutations(t *testing.T) {
+	vcpus, memory := 200, 400
+
+	tests := []struct {
+		name         string
+		mutate       poolMutation
+		setup        func(pool *v1.Pool)
+		wantChanged  bool
+		wantNetworks int
+		check        func(pool *v1.Pool) bool
+	}{
+		{name: "cordon", mutate: cordonPool, wantChanged: true, check: func(pool *v1.Pool) bool { return pool.Spec.NoSchedule }},
+		{name: "cordon a cordoned pool", mutate: cordonPool, setup: func(pool *v1.Pool) { pool.Spec.NoSchedule = true }},
+		{name: "cordon a pool cordoned by a drain", mutate: cordonPool, wantChanged: true,
+			setup: func(pool *v1.Pool) {
+				pool.Spec.NoSchedule = true
+				pool.Annotations = map[string]string{v1.PoolCordonedByDrainAnnotation: "true"}
+			},
+			check: func(pool *v1.Pool) bool {
+				_, cordonedByDrain := pool.Annotations[v1.PoolCordonedByDrainAnnotation]
+				return pool.Spec.NoSchedule && !cordonedByDrain
+			}},
+		{name: "uncordon", mutate: uncordonPool, setup: func(pool *v1.Pool) { pool.Spec.NoSchedule = true }, wantChanged: true,
+			check: func(pool *v1.Pool) bool { return !pool.Spec.NoSchedule }},
+		{name: "exclude", mutate: excludePool, wantChanged: true, check: func(pool *v1.Pool) bool { return pool.Spec.Exclude }},
+		{name: "include an included pool", mutate: includePool},
+		{name: "add a VLAN", mutate: addVLAN("5"), wantChanged: true, wantNetworks: 5,
+			check: func(pool *v1.Pool) bool { return pool.Spec.Topology.Networks[4] == "/dc1/network/ci-vlan-5" }},
+		{name: "add an existing VLAN", mutate: addVLAN("2"), wantNetworks: 4},
+		{name: "drop a VLAN", mutate: dropVLAN("2"), wantChanged: true, wantNetworks: 3},
+		{name: "drop a missing VLAN", mutate: dropVLAN("5"), wantNetworks: 4},
+		{name: "set the capacity", mutate: setCapacity(&vcpus, nil), wantChanged: true,
+			check: func(pool *v1.Pool) bool { return pool.Spec.VCpus == 200 && pool.Spec.Memory == 400 }},
+		{name: "set the same capacity", mutate: setCapacity(nil, &memory)},
+		{name: "drain", mutate: drainPool(time.Hour), wantChanged: true,
+			check: func(pool *v1.Pool) bool { return pool.Spec.Drain.DeleteLeasesOlderThan.Duration == time.Hour }},
+		{name: "drain a draining pool", mutate: drainPool(0), setup: func(pool *v1.Pool) { pool.Spec.Drain = &v1.PoolDrainSpec{} }},
+		{name: "cancel a drain", mutate: cancelDrain, setup: func(pool *v1.Pool) { pool.Spec.Drain = &v1.PoolDrainSpec{} }, wantChanged: true,
+			check: func(pool *v1.Pool) bool { return pool.Spec.Drain == nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newTestPool("pool-a", 100, 400, 4)
+			if tt.setup != nil {
+				tt.setup(&pool)
+			}
+			message, err := tt.mutate(&pool)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if changed := message != ""; changed != tt.wantChanged {
+				t.Errorf("expected changed %v, got %q", tt.wantChanged, message)
+			}
+			if tt.wantNetworks != 0 && len(pool.Spec.Topology.Networks) != tt.wantNetworks {
+				t.Errorf("expected %d networks, got %v", tt.wantNetworks, pool.Spec.Topology.Networks)
+			}
+			if tt.check != nil && !tt.check(&pool) {
+				t.Errorf("unexpected pool spec %+v", pool.Spec)
+			}
+		})
+	}
+}
+
+func TestPoolName(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{name: "argument", args: []string{"pool-a"}, want: "pool-a"},
+		{name: "flag", flag: "pool-a", want: "pool-a"},
+		{name: "both", flag: "pool-a", args: []string{"pool-b"}, wantErr: true},
+		{name: "none", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := poolName(tt.flag, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if name != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, name)
+			}
+		})
+	}
+}
+
+func TestUpdatePool(t *testing.T) {
+	pool := newTestPool("pool-a", 100, 400, 4)
+	pool.Namespace = DefaultNamespace
+	c := fake.NewSimpleClientset(&pool)
+	o := &Options{Namespace: DefaultNamespace, Client: c}
+
+	updated, message, err := o.updatePool(context.TODO(), "pool-a", cordonPool)
+	if err != nil || message != "pool pool-a cordoned" || !updated.Spec.NoSchedule {
+		t.Fatalf("expected the pool to be cordoned, got %q, %v", message, err)
+	}
+	stored, err := c.VspherecapacitymanagerV1().Pools(DefaultNamespace).Get(context.TODO(), "pool-a", metav1.GetOptions{})
+	if err != nil || !stored.Spec.NoSchedule {
+		t.Errorf("expected the cordon to be stored, got %v", err)
+	}
+
+	if _, message, err := o.updatePool(context.TODO(), "pool-a", cordonPool); err != nil || message != "" {
+		t.Errorf("expected no change, got %q, %v", message, err)
+	}
+	if _, _, err := o.updatePool(context.TODO(), "pool-b", cordonPool); err == nil {
+		t.Error("expected an error for a missing pool")
+	}
+}
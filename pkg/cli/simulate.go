@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler/plugins"
+)
+
+const (
+	PoolResultAssigned = "assigned"
+	PoolResultFeasible = "feasible"
+	PoolResultRejected = "rejected"
+)
+
+// PoolResult is how the scheduler sees a pool for a lease.
+type PoolResult struct {
+	Pool string `json:"pool"`
+	// Result is assigned if the lease holds the pool, feasible if the pool passed the filters, or rejected.
+	Result string `json:"result"`
+	// Score is the score of a feasible pool. the feasible pool with the highest score is picked.
+	Score float64 `json:"score,omitempty"`
+	// Reason is why the pool was rejected.
+	Reason string `json:"reason,omitempty"`
+}
+
+func poolResultsTable(results []PoolResult) *table {
+	t := newTable("POOL", "RESULT", "SCORE", "REASON")
+	for _, result := range results {
+		score := ""
+		if result.Result == PoolResultFeasible {
+			score = fmt.Sprintf("%.1f", result.Score)
+		}
+		t.addRow(result.Pool, result.Result, score, result.Reason)
+	}
+	return t
+}
+
+func poolPointers(pools []v1.Pool) []*v1.Pool {
+	pointers := make([]*v1.Pool, 0, len(pools))
+	for i := range pools {
+		pointers = append(pointers, &pools[i])
+	}
+	return pointers
+}
+
+func leasePointers(leases []v1.Lease) []*v1.Lease {
+	pointers := make([]*v1.Lease, 0, len(leases))
+	for i := range leases {
+		pointers = append(pointers, &leases[i])
+	}
+	return pointers
+}
+
+// newScheduler returns the scheduler built from the configuration at path, or with the default profiles.
+func newScheduler(path string) (*scheduler.Scheduler, error) {
+	var config *scheduler.Config
+	if path != "" {
+		var err error
+		if config, err = scheduler.LoadConfig(path); err != nil {
+			return nil, fmt.Errorf("error loading scheduler config: %w", err)
+		}
+	}
+	return plugins.NewScheduler(config)
+}
+
+// evaluatePools runs the filter, post filter and score plugins for the pools the lease does not hold yet. the
+// assigned pools come first, then the feasible pools from the highest score, then the rejected pools by name.
+func evaluatePools(ctx context.Context, framework *scheduler.Framework, lease *v1.Lease, assigned, pools []*v1.Pool, leases []*v1.Lease) []PoolResult {
+	results := []PoolResult{}
+	isAssigned := make(map[string]bool)
+	for _, pool := range assigned {
+		isAssigned[pool.Name] = true
+		results = append(results, PoolResult{Pool: pool.Name, Result: PoolResultAssigned})
+	}
+
+	var available []*v1.Pool
+	for _, pool := range pools {
+		if !isAssigned[pool.Name] {
+			available = append(available, pool)
+		}
+	}
+	if len(available) == 0 {
+		return results
+	}
+
+	state := scheduler.NewCycleState(lease, assigned, leases)
+	feasible, rejected := framework.RunFilterPlugins(ctx, state, lease, available)
+	feasible, rejected = framework.RunPostFilterPlugins(ctx, state, lease, available, feasible, rejected)
+	if len(feasible) > 0 {
+		for _, score := range framework.RunScorePlugins(ctx, state, lease, feasible) {
+			results = append(results, PoolResult{Pool: score.Pool.Name, Result: PoolResultFeasible, Score: score.Score})
+		}
+	}
+
+	sort.SliceStable(rejected, func(i, j int) bool { return rejected[i].Pool.Name < rejected[j].Pool.Name })
+	for _, info := range rejected {
+		results = append(results, PoolResult{Pool: info.Pool.Name, Result: PoolResultRejected, Reason: info.MatchResults})
+	}
+	return results
+}
+
+// Simulation is where the scheduler would place a lease.
+type Simulation struct {
+	NetworkType v1.NetworkType `json:"networkType"`
+	Profile     string         `json:"profile"`
+	// Placed are the pools picked for the lease, from the highest score.
+	Placed []string `json:"placed"`
+	// Schedulable is true if enough pools were found for the lease.
+	Schedulable bool `json:"schedulable"`
+	// Reason is why the lease can not be placed.
+	Reason string `json:"reason,omitempty"`
+	// Pools is how the scheduler sees each pool for the first pool of the lease.
+	Pools []PoolResult `json:"pools"`
+}
+
+// simulateLease picks the pools of a lease as the scheduler would with the current pools: the feasible pool
+// with the highest score for a single pool, or the placement solver for multiple pools.
+func simulateLease(ctx context.Context, sched *scheduler.Scheduler, lease *v1.Lease, res *resources) *Simulation {
+	framework := sched.ForLease(lease)
+	pools := poolPointers(res.pools)
+	leases := leasePointers(res.leases)
+	simulation := &Simulation{NetworkType: lease.Spec.NetworkType, Profile: framework.ProfileName(), Placed: []string{}}
+	simulation.Pools = evaluatePools(ctx, framework, lease, nil, pools, leases)
+
+	if required := scheduler.NewCycleState(lease, nil, leases).RequiredPools; required > 1 {
+		placement, err := placePools(ctx, framework, lease, pools, leases)
+		if err != nil {
+			simulation.Reason = err.Error()
+			return simulation
+		}
+		for _, pool := range placement.Pools {
+			simulation.Placed = append(simulation.Placed, pool.Name)
+		}
+		simulation.Schedulable = true
+		return simulation
+	}
+
+	for _, result := range simulation.Pools {
+		if result.Result == PoolResultFeasible {
+			simulation.Placed = append(simulation.Placed, result.Pool)
+			simulation.Schedulable = true
+			return simulation
+		}
+	}
+	simulation.Reason = "no pool passed the filters"
+	return simulation
+}
+
+// placePools picks all the pools of a multi-pool lease at once, as the controller does: the pools must pass the
+// filter plugins and together satisfy the vCenter cap and the topology spread constraints. networks are not
+// considered.
+func placePools(ctx context.Context, framework *scheduler.Framework, lease *v1.Lease, pools []*v1.Pool, leases []*v1.Lease) (*scheduler.Placement, error) {
+	state := scheduler.NewCycleState(lease, nil, leases)
+	feasible, _ := framework.RunFilterPlugins(ctx, state, lease, pools)
+	if len(feasible) == 0 {
+		return nil, fmt.Errorf("no pool passed the filters")
+	}
+
+	problem := &scheduler.PlacementProblem{
+		RequiredPools: state.RequiredPools,
+		VCenterCap:    lease.Spec.VCenters,
+		Accept: func(placed []*v1.Pool) bool {
+			return plugins.TopologySpreadSatisfied(lease, placed, feasible)
+		},
+	}
+	for _, score := range framework.RunScorePlugins(ctx, state, lease, feasible) {
+		problem.Candidates = append(problem.Candidates, scheduler.PlacementCandidate{Pool: score.Pool, Score: score.Score})
+	}
+	return scheduler.SolvePlacement(problem)
+}
+
+func newSimulateCommand(o *Options) *cobra.Command {
+	var schedulerConfig, networkType, requiredPool string
+	var vcpus, memory, networks, poolCount, vcenters int
+	var poolSelector map[string]string
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Show where the scheduler would place a lease, without creating it",
+		Long: "Show where the scheduler would place a lease, without creating it. The scheduler plugins are run " +
+			"locally against the current pools, and the pools of a multi-pool lease are placed together as the " +
+			"controller does. Networks are not considered.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := o.listResources(cmd.Context())
+			if err != nil {
+				return err
+			}
+			sched, err := newScheduler(schedulerConfig)
+			if err != nil {
+				return err
+			}
+
+			lease := &v1.Lease{
+				ObjectMeta: metav1.ObjectMeta{Name: "simulated", Namespace: o.Namespace},
+				Spec: v1.LeaseSpec{
+					VCpus:        vcpus,
+					Memory:       memory,
+					Networks:     networks,
+					Pools:        poolCount,
+					VCenters:     vcenters,
+					RequiredPool: requiredPool,
+					PoolSelector: poolSelector,
+					NetworkType:  v1.NetworkType(networkType),
+				},
+			}
+			simulation := simulateLease(cmd.Context(), sched, lease, res)
+			if err := o.print(simulation, func() *table { return poolResultsTable(simulation.Pools) }); err != nil {
+				return err
+			}
+			if simulation.Schedulable {
+				o.printf("\nThe lease would be placed on %s with profile %s", strings.Join(simulation.Placed, ", "), simulation.Profile)
+			} else {
+				o.printf("\nThe lease can not be placed: %s", simulation.Reason)
+			}
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.IntVar(&vcpus, "vcpus", 24, "the vCPUs of the lease in each pool")
+	flags.IntVar(&memory, "memory", 96, "the memory of the lease in GB in each pool")
+	flags.IntVar(&networks, "networks", 1, "the networks of the lease")
+	flags.IntVar(&poolCount, "pools", 1, "the number of pools of the lease")
+	flags.IntVar(&vcenters, "vcenters", 0, "the maximum number of vCenters of the lease. no limit if 0")
+	flags.StringVar(&networkType, "network-type", string(v1.NetworkTypeSingleTenant), "the network type of the lease")
+	flags.StringVar(&requiredPool, "required-pool", "", "the pool the lease requires")
+	flags.StringToStringVar(&poolSelector, "pool-selector", nil, "the labels the pools of the lease must have, as key=value pairs")
+	flags.StringVar(&schedulerConfig, "scheduler-config", "", "path to the scheduler profile configuration of the controller. the default profiles are used if not set")
+	return cmd
+}
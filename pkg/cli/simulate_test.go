@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestSimulateLease(t *testing.T) {
+	cordoned := newTestPool("pool-c", 100, 400, 4)
+	cordoned.Spec.NoSchedule = true
+	res := &resources{pools: []v1.Pool{newTestPool("pool-a", 20, 400, 4), newTestPool("pool-b", 100, 400, 4), cordoned}}
+	sched, err := newScheduler("")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		pools           int
+		wantPlaced      []string
+		wantSchedulable bool
+	}{
+		{name: "single pool", pools: 1, wantPlaced: []string{"pool-b"}, wantSchedulable: true},
+		{name: "more pools than fit", pools: 2, wantPlaced: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{Spec: v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 1, Pools: tt.pools, NetworkType: v1.NetworkTypeSingleTenant}}
+			simulation := simulateLease(context.TODO(), sched, lease, res)
+			if simulation.Schedulable != tt.wantSchedulable {
+				t.Errorf("expected schedulable %v, got %v", tt.wantSchedulable, simulation.Schedulable)
+			}
+			if !reflect.DeepEqual(simulation.Placed, tt.wantPlaced) {
+				t.Errorf("expected pools %v, got %v", tt.wantPlaced, simulation.Placed)
+			}
+
+			reasons := make(map[string]string)
+			for _, result := range simulation.Pools {
+				reasons[result.Pool] = result.Result + ":" + result.Reason
+			}
+			if reasons["pool-a"] != PoolResultRejected+":"+utils.PoolInsufficientVCPU {
+				t.Errorf("expected pool-a rejected for vCPUs, got %q", reasons["pool-a"])
+			}
+			if reasons["pool-c"] != PoolResultRejected+":"+utils.PoolNotSchedulable {
+				t.Errorf("expected pool-c rejected as not schedulable, got %q", reasons["pool-c"])
+			}
+		})
+	}
+}
+
+func TestSimulateLeaseVCenterCap(t *testing.T) {
+	newPool := func(name, server string, vcpusAvailable int) v1.Pool {
+		pool := newTestPool(name, vcpusAvailable, 400, 4)
+		pool.Spec.Server = server
+		return pool
+	}
+	// picking the pool with the highest score first leaves only a low scoring pool on its vCenter, the two pools
+	// of the other vCenter have the highest total score.
+	res := &resources{pools: []v1.Pool{
+		newPool("pool-a", "vcenter-1", 100),
+		newPool("pool-a2", "vcenter-1", 30),
+		newPool("pool-b", "vcenter-2", 90),
+		newPool("pool-c", "vcenter-2", 90),
+	}}
+	sched, err := newScheduler("")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	lease := &v1.Lease{Spec: v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 1, Pools: 2, VCenters: 1, NetworkType: v1.NetworkTypeSingleTenant}}
+	simulation := simulateLease(context.TODO(), sched, lease, res)
+	if !simulation.Schedulable {
+		t.Fatalf("expected the lease to be schedulable, got %+v", simulation)
+	}
+	placed := append([]string(nil), simulation.Placed...)
+	sort.Strings(placed)
+	if !reflect.DeepEqual(placed, []string{"pool-b", "pool-c"}) {
+		t.Errorf("expected pool-b and pool-c, got %v", simulation.Placed)
+	}
+}
+
+func TestExplainLease(t *testing.T) {
+	res := &resources{
+		pools:  []v1.Pool{newTestPool("pool-a", 100, 400, 4), newTestPool("pool-b", 100, 400, 4)},
+		leases: []v1.Lease{newTestLease("lease-1", "", "", v1.PHASE_FULFILLED, "pool-a")},
+	}
+	sched, err := newScheduler("")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	explanation := explainLease(context.TODO(), sched, &res.leases[0], res)
+	if explanation.NetworkType != v1.NetworkTypeSingleTenant || explanation.RequiredPools != 1 {
+		t.Errorf("unexpected explanation %+v", explanation)
+	}
+	if len(explanation.Pools) != 2 {
+		t.Fatalf("expected 2 pools, got %+v", explanation.Pools)
+	}
+	if explanation.Pools[0].Pool != "pool-a" || explanation.Pools[0].Result != PoolResultAssigned {
+		t.Errorf("expected pool-a assigned first, got %+v", explanation.Pools[0])
+	}
+	if explanation.Pools[1].Pool != "pool-b" || explanation.Pools[1].Result != PoolResultFeasible {
+		t.Errorf("expected pool-b feasible, got %+v", explanation.Pools[1])
+	}
+}
@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+const (
+	HealthHealthy  = "healthy"
+	HealthDegraded = "degraded"
+	HealthCritical = "critical"
+)
+
+// StatusSummary counts the pools and leases of the capacity manager.
+type StatusSummary struct {
+	TotalPools         int     `json:"totalPools"`
+	ActivePools        int     `json:"activePools"`
+	CordonedPools      int     `json:"cordonedPools"`
+	ExcludedPools      int     `json:"excludedPools"`
+	TotalLeases        int     `json:"totalLeases"`
+	SingleTenantLeases int     `json:"singleTenantLeases"`
+	MultiTenantLeases  int     `json:"multiTenantLeases"`
+	SingleTenantUsage  float64 `json:"singleTenantUsage"`
+	MultiTenantUsage   float64 `json:"multiTenantUsage"`
+	PendingLeases      int     `json:"pendingLeases"`
+	PartialLeases      int     `json:"partialLeases"`
+}
+
+// PoolStatus is the available capacity of a pool, as a ratio of its capacity.
+type PoolStatus struct {
+	Name             string  `json:"name"`
+	Health           string  `json:"health"`
+	CPUAvailable     float64 `json:"cpuAvailable"`
+	MemoryAvailable  float64 `json:"memoryAvailable"`
+	NetworkAvailable float64 `json:"networkAvailable"`
+	Leases           int     `json:"leases"`
+	Cordoned         bool    `json:"cordoned"`
+	Excluded         bool    `json:"excluded"`
+	Drain            string  `json:"drain,omitempty"`
+}
+
+// Status is the output of the status command.
+type Status struct {
+	Summary StatusSummary `json:"summary"`
+	Pools   []PoolStatus  `json:"pools"`
+}
+
+func ratio(numerator, denominator int) float64 {
+	if denominator == 0 {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+// networkType returns the network type of a network. networks without the label are single-tenant.
+func networkType(network *v1.Network) v1.NetworkType {
+	if value, ok := network.Labels[v1.NetworkTypeLabel]; ok {
+		return v1.NetworkType(value)
+	}
+	return v1.NetworkTypeSingleTenant
+}
+
+// leasesByPool returns the leases holding each pool, by pool name.
+func leasesByPool(leases []v1.Lease) map[string][]*v1.Lease {
+	byPool := make(map[string][]*v1.Lease)
+	for i := range leases {
+		for _, ref := range utils.GetLeasePoolRefs(&leases[i]) {
+			byPool[ref.Name] = append(byPool[ref.Name], &leases[i])
+		}
+	}
+	return byPool
+}
+
+// uniqueLeases returns the first lease of each boskos lease. leases without a boskos lease count on their own.
+func uniqueLeases(leases []v1.Lease) []*v1.Lease {
+	seen := make(map[string]bool)
+	var unique []*v1.Lease
+	for i := range leases {
+		id, ok := leases[i].Labels[v1.BoskosIdLabel]
+		if !ok {
+			id = leases[i].Name
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, &leases[i])
+	}
+	return unique
+}
+
+func newPoolStatus(pool *v1.Pool, leases int) PoolStatus {
+	status := PoolStatus{
+		Name:             pool.Name,
+		CPUAvailable:     ratio(pool.Status.VCpusAvailable, pool.Spec.VCpus),
+		MemoryAvailable:  ratio(pool.Status.MemoryAvailable, pool.Spec.Memory),
+		NetworkAvailable: ratio(pool.Status.NetworkAvailable, len(pool.Spec.Topology.Networks)),
+		Leases:           leases,
+		Cordoned:         pool.Spec.NoSchedule,
+		Excluded:         pool.Spec.Exclude,
+	}
+	if pool.Status.Drain != nil {
+		status.Drain = string(pool.Status.Drain.Phase)
+	}
+
+	available := math.Min(status.CPUAvailable, math.Min(status.MemoryAvailable, status.NetworkAvailable))
+	switch {
+	case available >= 0.5:
+		status.Health = HealthHealthy
+	case available >= 0.25:
+		status.Health = HealthDegraded
+	default:
+		status.Health = HealthCritical
+	}
+	return status
+}
+
+// buildStatus summarizes the pools, leases and networks. excluded pools are left out unless includeExcluded is
+// set. pools are sorted by name, by available capacity, lowest first, or by lease count, highest first.
+func buildStatus(pools []v1.Pool, leases []v1.Lease, networks []v1.Network, includeExcluded bool, sortBy string) (*Status, error) {
+	status := &Status{Pools: []PoolStatus{}}
+	byPool := leasesByPool(leases)
+	for i := range pools {
+		pool := &pools[i]
+		if pool.Spec.Exclude && !includeExcluded {
+			continue
+		}
+		status.Pools = append(status.Pools, newPoolStatus(pool, len(byPool[pool.Name])))
+	}
+
+	var less func(a, b PoolStatus) bool
+	switch sortBy {
+	case "name":
+		less = func(a, b PoolStatus) bool { return a.Name < b.Name }
+	case "capacity":
+		less = func(a, b PoolStatus) bool {
+			return math.Min(a.CPUAvailable, math.Min(a.MemoryAvailable, a.NetworkAvailable)) <
+				math.Min(b.CPUAvailable, math.Min(b.MemoryAvailable, b.NetworkAvailable))
+		}
+	case "leases":
+		less = func(a, b PoolStatus) bool { return a.Leases > b.Leases }
+	default:
+		return nil, fmt.Errorf("unknown sort %q, expected name, capacity or leases", sortBy)
+	}
+	sort.SliceStable(status.Pools, func(i, j int) bool { return less(status.Pools[i], status.Pools[j]) })
+
+	summary := &status.Summary
+	for _, pool := range status.Pools {
+		summary.TotalPools++
+		if pool.Cordoned {
+			summary.CordonedPools++
+		}
+		if pool.Excluded {
+			summary.ExcludedPools++
+		}
+		if !pool.Cordoned && !pool.Excluded {
+			summary.ActivePools++
+		}
+	}
+
+	var singleTenantNetworks, multiTenantNetworks int
+	for i := range networks {
+		if networkType(&networks[i]) == v1.NetworkTypeMultiTenant {
+			multiTenantNetworks++
+		} else {
+			singleTenantNetworks++
+		}
+	}
+	for _, lease := range uniqueLeases(leases) {
+		summary.TotalLeases++
+		if lease.Spec.NetworkType == v1.NetworkTypeMultiTenant {
+			summary.MultiTenantLeases++
+		} else {
+			summary.SingleTenantLeases++
+		}
+		switch lease.Status.Phase {
+		case v1.PHASE_PENDING:
+			summary.PendingLeases++
+		case v1.PHASE_PARTIAL:
+			summary.PartialLeases++
+		}
+	}
+	summary.SingleTenantUsage = ratio(summary.SingleTenantLeases, singleTenantNetworks)
+	summary.MultiTenantUsage = ratio(summary.MultiTenantLeases, multiTenantNetworks)
+	return status, nil
+}
+
+func (s *Status) table() *table {
+	t := newTable("POOL", "HEALTH", "CPU", "MEMORY", "NETWORK", "LEASES", "CORDONED", "EXCLUDED", "DRAIN")
+	for _, pool := range s.Pools {
+		t.addRow(pool.Name, pool.Health, percent(pool.CPUAvailable), percent(pool.MemoryAvailable), percent(pool.NetworkAvailable),
+			pool.Leases, pool.Cordoned, pool.Excluded, pool.Drain)
+	}
+	return t
+}
+
+// resources are the pools, leases and networks of the capacity manager.
+type resources struct {
+	pools    []v1.Pool
+	leases   []v1.Lease
+	networks []v1.Network
+}
+
+func (o *Options) listResources(ctx context.Context) (*resources, error) {
+	c, err := o.client()
+	if err != nil {
+		return nil, err
+	}
+	pools, err := c.VspherecapacitymanagerV1().Pools(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing pools: %w", err)
+	}
+	leases, err := c.VspherecapacitymanagerV1().Leases(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing leases: %w", err)
+	}
+	networks, err := c.VspherecapacitymanagerV1().Networks(o.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing networks: %w", err)
+	}
+	return &resources{pools: pools.Items, leases: leases.Items, networks: networks.Items}, nil
+}
+
+func newStatusCommand(o *Options) *cobra.Command {
+	var includeExcluded bool
+	var sortBy string
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the available capacity of the pools and the lease counts",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			res, err := o.listResources(cmd.Context())
+			if err != nil {
+				return err
+			}
+			status, err := buildStatus(res.pools, res.leases, res.networks, includeExcluded, sortBy)
+			if err != nil {
+				return err
+			}
+			if err := o.print(status, status.table); err != nil {
+				return err
+			}
+
+			summary := status.Summary
+			o.printf("\nPools: %d, active: %d, cordoned: %d, excluded: %d", summary.TotalPools, summary.ActivePools, summary.CordonedPools, summary.ExcludedPools)
+			o.printf("Leases: %d, single-tenant usage: %s, multi-tenant usage: %s", summary.TotalLeases, percent(summary.SingleTenantUsage), percent(summary.MultiTenantUsage))
+			o.printf("Pending leases: %d, partial leases: %d", summary.PendingLeases, summary.PartialLeases)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&includeExcluded, "include-excluded", false, "include the excluded pools")
+	cmd.Flags().StringVar(&sortBy, "sort", "name", "sort the pools by name, capacity or leases")
+	return cmd
+}
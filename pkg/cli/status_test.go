@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func TestBuildStatus(t *testing.T) {
+	excluded := newTestPool("pool-c", 100, 400, 4)
+	excluded.Spec.Exclude = true
+	cordoned := newTestPool("pool-b", 10, 400, 4)
+	cordoned.Spec.NoSchedule = true
+	pools := []v1.Pool{newTestPool("pool-a", 60, 200, 2), cordoned, excluded}
+
+	leases := []v1.Lease{
+		newTestLease("lease-1", "boskos-1", v1.NetworkTypeSingleTenant, v1.PHASE_FULFILLED, "pool-a"),
+		newTestLease("lease-2", "boskos-1", v1.NetworkTypeSingleTenant, v1.PHASE_FULFILLED, "pool-b"),
+		newTestLease("lease-3", "boskos-2", v1.NetworkTypeMultiTenant, v1.PHASE_PENDING),
+		newTestLease("lease-4", "", v1.NetworkTypeSingleTenant, v1.PHASE_PARTIAL, "pool-a"),
+	}
+	networks := []v1.Network{
+		{ObjectMeta: metav1.ObjectMeta{Name: "net-1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "net-2"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "net-3", Labels: map[string]string{v1.NetworkTypeLabel: string(v1.NetworkTypeMultiTenant)}}},
+	}
+
+	tests := []struct {
+		name            string
+		includeExcluded bool
+		sortBy          string
+		wantPools       []string
+		wantErr         bool
+	}{
+		{name: "by name", sortBy: "name", wantPools: []string{"pool-a", "pool-b"}},
+		{name: "by capacity", sortBy: "capacity", wantPools: []string{"pool-b", "pool-a"}},
+		{name: "by leases", sortBy: "leases", wantPools: []string{"pool-a", "pool-b"}},
+		{name: "with excluded pools", includeExcluded: true, sortBy: "name", wantPools: []string{"pool-a", "pool-b", "pool-c"}},
+		{name: "unknown sort", sortBy: "age", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := buildStatus(pools, leases, networks, tt.includeExcluded, tt.sortBy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			var names []string
+			for _, pool := range status.Pools {
+				names = append(names, pool.Name)
+			}
+			if len(names) != len(tt.wantPools) {
+				t.Fatalf("expected pools %v, got %v", tt.wantPools, names)
+			}
+			for i := range names {
+				if names[i] != tt.wantPools[i] {
+					t.Errorf("expected pools %v, got %v", tt.wantPools, names)
+					break
+				}
+			}
+		})
+	}
+
+	status, _ := buildStatus(pools, leases, networks, false, "name")
+	want := StatusSummary{
+		TotalPools: 2, ActivePools: 1, CordonedPools: 1,
+		TotalLeases: 3, SingleTenantLeases: 2, MultiTenantLeases: 1,
+		SingleTenantUsage: 1, MultiTenantUsage: 1,
+		PendingLeases: 1, PartialLeases: 1,
+	}
+	if status.Summary != want {
+		t.Errorf("expected summary %+v, got %+v", want, status.Summary)
+	}
+
+	poolA := status.Pools[0]
+	if poolA.Leases != 2 || poolA.CPUAvailable != 0.6 || poolA.MemoryAvailable != 0.5 || poolA.NetworkAvailable != 0.5 || poolA.Health != HealthHealthy {
+		t.Errorf("unexpected status of pool-a %+v", poolA)
+	}
+	if poolB := status.Pools[1]; poolB.Health != HealthCritical || !poolB.Cordoned {
+		t.Errorf("unexpected status of pool-b %+v", poolB)
+	}
+}
+
+func TestBuildJobLeases(t *testing.T) {
+	leases := []v1.Lease{
+		newTestLease("lease-1", "boskos-1", v1.NetworkTypeSingleTenant, v1.PHASE_FULFILLED),
+		newTestLease("lease-2", "boskos-2", v1.NetworkTypeSingleTenant, v1.PHASE_FULFILLED),
+		newTestLease("lease-3", "boskos-3", v1.NetworkTypeSingleTenant, v1.PHASE_FULFILLED),
+	}
+	for i := range leases[:2] {
+		leases[i].Labels["job-name"] = "e2e-vsphere"
+		leases[i].Annotations = map[string]string{"prow-job-type": "presubmit"}
+	}
+
+	jobs := buildJobLeases(leases)
+	want := []JobLeases{{Name: "e2e-vsphere", Type: "presubmit", Leases: 2}, {Name: unknown, Type: unknown, Leases: 1}}
+	if len(jobs) != len(want) || jobs[0] != want[0] || jobs[1] != want[1] {
+		t.Errorf("expected %+v, got %+v", want, jobs)
+	}
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned"
+)
+
+// watchKind is a kind which can be watched, with the columns printed for each event.
+type watchKind struct {
+	watch  func(ctx context.Context, c versioned.Interface, namespace string) (watch.Interface, error)
+	header []string
+	row    func(obj runtime.Object) []interface{}
+}
+
+var watchKinds = map[string]func() watchKind{
+	"leases": func() watchKind {
+		return watchKind{
+			watch: func(ctx context.Context, c versioned.Interface, namespace string) (watch.Interface, error) {
+				return c.VspherecapacitymanagerV1().Leases(namespace).Watch(ctx, metav1.ListOptions{})
+			},
+			header: []string{"LEASE", "PHASE", "NETWORK TYPE", "POOLS"},
+			row: func(obj runtime.Object) []interface{} {
+				lease := obj.(*v1.Lease)
+				return []interface{}{lease.Name, lease.Status.Phase, lease.Spec.NetworkType, strings.Join(leasePoolNames(lease), ",")}
+			},
+		}
+	},
+	"pools": func() watchKind {
+		return watchKind{
+			watch: func(ctx context.Context, c versioned.Interface, namespace string) (watch.Interface, error) {
+				return c.VspherecapacitymanagerV1().Pools(namespace).Watch(ctx, metav1.ListOptions{})
+			},
+			header: []string{"POOL", "VCPUS", "MEMORY", "NETWORKS", "CORDONED", "EXCLUDED", "DRAIN"},
+			row: func(obj runtime.Object) []interface{} {
+				pool := obj.(*v1.Pool)
+				drain := ""
+				if pool.Status.Drain != nil {
+					drain = string(pool.Status.Drain.Phase)
+				}
+				return []interface{}{pool.Name, pool.Status.VCpusAvailable, pool.Status.MemoryAvailable, pool.Status.NetworkAvailable,
+					pool.Spec.NoSchedule, pool.Spec.Exclude, drain}
+			},
+		}
+	},
+	"networks": func() watchKind {
+		return watchKind{
+			watch: func(ctx context.Context, c versioned.Interface, namespace string) (watch.Interface, error) {
+				return c.VspherecapacitymanagerV1().Networks(namespace).Watch(ctx, metav1.ListOptions{})
+			},
+			header: []string{"NETWORK", "TYPE", "QUARANTINED", "NOSCHEDULE"},
+			row: func(obj runtime.Object) []interface{} {
+				network := obj.(*v1.Network)
+				return []interface{}{network.Name, networkType(network), network.Status.Quarantined, network.Spec.NoSchedule}
+			},
+		}
+	},
+}
+
+// WatchEvent is an event of the watch command in the JSON and YAML output.
+type WatchEvent struct {
+	Type   watch.EventType `json:"type"`
+	Object runtime.Object  `json:"object"`
+}
+
+// printEvent writes an event as a table row, a line of JSON or a YAML document.
+func (o *Options) printEvent(w *tabwriter.Writer, kind watchKind, event watch.Event) error {
+	switch o.Output {
+	case OutputJSON:
+		data, err := json.Marshal(WatchEvent{Type: event.Type, Object: event.Object})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(o.Out, string(data))
+		return err
+	case OutputYAML:
+		data, err := yaml.Marshal(WatchEvent{Type: event.Type, Object: event.Object})
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(o.Out, "---\n%s", data)
+		return err
+	}
+
+	values := append([]interface{}{event.Type}, kind.row(event.Object)...)
+	row := make([]string, 0, len(values))
+	for _, value := range values {
+		row = append(row, fmt.Sprint(value))
+	}
+	fmt.Fprintln(w, strings.Join(row, "\t"))
+	return w.Flush()
+}
+
+func newWatchCommand(o *Options) *cobra.Command {
+	return &cobra.Command{
+		Use:       "watch [leases|pools|networks]",
+		Short:     "Watch the changes of the leases, pools or networks",
+		Long:      "Watch the changes of the leases, pools or networks. Leases are watched by default.",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"leases", "pools", "networks"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := "leases"
+			if len(args) > 0 {
+				name = args[0]
+			}
+			newKind, ok := watchKinds[name]
+			if !ok {
+				return fmt.Errorf("unknown kind %q, expected leases, pools or networks", name)
+			}
+			kind := newKind()
+
+			c, err := o.client()
+			if err != nil {
+				return err
+			}
+			watcher, err := kind.watch(cmd.Context(), c, o.Namespace)
+			if err != nil {
+				return fmt.Errorf("error watching %s: %w", name, err)
+			}
+			defer watcher.Stop()
+
+			// rows are flushed as they come, the minimum width keeps the columns of most rows aligned.
+			w := tabwriter.NewWriter(o.Out, 16, 4, 2, ' ', 0)
+			if o.Output == OutputTable {
+				fmt.Fprintln(w, "EVENT\t"+strings.Join(kind.header, "\t"))
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+			for {
+				select {
+				case <-cmd.Context().Done():
+					return nil
+				case event, ok := <-watcher.ResultChan():
+					if !ok {
+						return fmt.Errorf("the watch of %s ended", name)
+					}
+					if event.Type == watch.Error {
+						return fmt.Errorf("error watching %s: %v", name, event.Object)
+					}
+					if event.Type == watch.Bookmark {
+						continue
+					}
+					if err := o.printEvent(w, kind, event); err != nil {
+						return err
+					}
+				}
+			}
+		},
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakePools implements PoolInterface
+type FakePools struct {
+	Fake *FakeVspherecapacitymanagerV1
+	ns   string
+}
+
+var poolsResource = v1.SchemeGroupVersion.WithResource("pools")
+
+var poolsKind = v1.SchemeGroupVersion.WithKind("Pool")
+
+// Get takes name of the pool, and returns the corresponding pool object, and an error if there is any.
+func (c *FakePools) Get(ctx context.Context, name string, options metav1.GetOptions) (result *v1.Pool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(poolsResource, c.ns, name), &v1.Pool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1.Pool), err
+}
+
+// List takes label and field selectors, and returns the list of Pools that match those selectors.
+func (c *FakePools) List(ctx context.Context, opts metav1.ListOptions) (result *v1.PoolList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(poolsResource, poolsKind, c.ns, opts), &v1.PoolList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1.PoolList{ListMeta: obj.(*v1.PoolList).ListMeta}
+	for _, item := range obj.(*v1.PoolList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested pools.
+func (c *FakePools) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(poolsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a pool and creates it.  Returns the server's representation of the pool, and an error, if there is any.
+func (c *FakePools) Create(ctx context.Context, pool *v1.Pool, opts metav1.CreateOptions) (result *v1.Pool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(poolsResource, c.ns, pool), &v1.Pool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1.Pool), err
+}
+
+// Update takes the representation of a pool and updates it. Returns the server's representation of the pool, and an error, if there is any.
+func (c *FakePools) Update(ctx context.Context, pool *v1.Pool, opts metav1.UpdateOptions) (result *v1.Pool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(poolsResource, c.ns, pool), &v1.Pool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1.Pool), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakePools) UpdateStatus(ctx context.Context, pool *v1.Pool, opts metav1.UpdateOptions) (*v1.Pool, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(poolsResource, "status", c.ns, pool), &v1.Pool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1.Pool), err
+}
+
+// Delete takes name of the pool and deletes it. Returns an error if one occurs.
+func (c *FakePools) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(poolsResource, c.ns, name, opts), &v1.Pool{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakePools) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(poolsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1.PoolList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched pool.
+func (c *FakePools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.Pool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(poolsResource, c.ns, name, pt, data, subresources...), &v1.Pool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1.Pool), err
+}
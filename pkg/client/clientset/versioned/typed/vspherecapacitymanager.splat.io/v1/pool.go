@@ -0,0 +1,194 @@
+/*
+Copyright 2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	scheme "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned/scheme"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// PoolsGetter has a method to return a PoolInterface.
+// A group's client should implement this interface.
+type PoolsGetter interface {
+	Pools(namespace string) PoolInterface
+}
+
+// PoolInterface has methods to work with Pool resources.
+type PoolInterface interface {
+	Create(ctx context.Context, pool *v1.Pool, opts metav1.CreateOptions) (*v1.Pool, error)
+	Update(ctx context.Context, pool *v1.Pool, opts metav1.UpdateOptions) (*v1.Pool, error)
+	UpdateStatus(ctx context.Context, pool *v1.Pool, opts metav1.UpdateOptions) (*v1.Pool, error)
+	Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error
+	Get(ctx context.Context, name string, opts metav1.GetOptions) (*v1.Pool, error)
+	List(ctx context.Context, opts metav1.ListOptions) (*v1.PoolList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.Pool, err error)
+	PoolExpansion
+}
+
+// pools implements PoolInterface
+type pools struct {
+	client rest.Interface
+	ns     string
+}
+
+// newPools returns a Pools
+func newPools(c *VspherecapacitymanagerV1Client, namespace string) *pools {
+	return &pools{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the pool, and returns the corresponding pool object, and an error if there is any.
+func (c *pools) Get(ctx context.Context, name string, options metav1.GetOptions) (result *v1.Pool, err error) {
+	result = &v1.Pool{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("pools").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of Pools that match those selectors.
+func (c *pools) List(ctx context.Context, opts metav1.ListOptions) (result *v1.PoolList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1.PoolList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("pools").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested pools.
+func (c *pools) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("pools").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a pool and creates it.  Returns the server's representation of the pool, and an error, if there is any.
+func (c *pools) Create(ctx context.Context, pool *v1.Pool, opts metav1.CreateOptions) (result *v1.Pool, err error) {
+	result = &v1.Pool{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("pools").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(pool).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a pool and updates it. Returns the server's representation of the pool, and an error, if there is any.
+func (c *pools) Update(ctx context.Context, pool *v1.Pool, opts metav1.UpdateOptions) (result *v1.Pool, err error) {
+	result = &v1.Pool{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("pools").
+		Name(pool.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(pool).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *pools) UpdateStatus(ctx context.Context, pool *v1.Pool, opts metav1.UpdateOptions) (result *v1.Pool, err error) {
+	result = &v1.Pool{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("pools").
+		Name(pool.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(pool).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the pool and deletes it. Returns an error if one occurs.
+func (c *pools) Delete(ctx context.Context, name string, opts metav1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("pools").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *pools) DeleteCollection(ctx context.Context, opts metav1.DeleteOptions, listOpts metav1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("pools").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched pool.
+func (c *pools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (result *v1.Pool, err error) {
+	result = &v1.Pool{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("pools").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/audit"
+)
+
+const (
+	// releaseReasonDeleted is the reason of the records of the resources released by a deleted lease.
+	releaseReasonDeleted = "lease deleted"
+	// releaseReasonResized is the reason of the records of the networks released by a shrinking lease.
+	releaseReasonResized = "lease resized"
+)
+
+// resourceRefs returns a copy of the pool and network owner references in refs.
+func resourceRefs(refs []metav1.OwnerReference) []metav1.OwnerReference {
+	var resources []metav1.OwnerReference
+	for _, ref := range refs {
+		if ref.Kind == v1.PoolKind || ref.Kind == v1.NetworkKind {
+			resources = append(resources, ref)
+		}
+	}
+	return resources
+}
+
+// diffResources returns the pool and network owner references in after and not in before, and in before and not
+// in after.
+func diffResources(before, after []metav1.OwnerReference) (added, removed []metav1.OwnerReference) {
+	type refKey struct{ kind, name string }
+	keys := func(refs []metav1.OwnerReference) map[refKey]bool {
+		set := make(map[refKey]bool, len(refs))
+		for _, ref := range refs {
+			set[refKey{kind: ref.Kind, name: ref.Name}] = true
+		}
+		return set
+	}
+	beforeKeys, afterKeys := keys(before), keys(after)
+	for _, ref := range resourceRefs(after) {
+		if !beforeKeys[refKey{kind: ref.Kind, name: ref.Name}] {
+			added = append(added, ref)
+		}
+	}
+	for _, ref := range resourceRefs(before) {
+		if !afterKeys[refKey{kind: ref.Kind, name: ref.Name}] {
+			removed = append(removed, ref)
+		}
+	}
+	return added, removed
+}
+
+// auditRecords returns the records of the allocation or release of resources by a lease. the VLAN of the
+// networks is looked up in the cache, and their pool among leasePools. reconcileLock must be held.
+func auditRecords(lease *v1.Lease, action audit.Action, refs []metav1.OwnerReference, leasePools []*v1.Pool, reason string, now time.Time) []audit.Record {
+	records := make([]audit.Record, 0, len(refs))
+	for _, ref := range refs {
+		record := audit.Record{
+			Time:      now.UTC(),
+			Action:    action,
+			Kind:      ref.Kind,
+			Name:      ref.Name,
+			Lease:     lease.Name,
+			Namespace: lease.Namespace,
+			BoskosID:  lease.Labels[v1.BoskosIdLabel],
+			JobLink:   lease.Status.JobLink,
+			Reason:    reason,
+		}
+		if ref.Kind == v1.PoolKind {
+			record.Pool = ref.Name
+		} else if network, exists := networks[fmt.Sprintf("%s/%s", lease.Namespace, ref.Name)]; exists {
+			record.VLAN = network.Spec.VlanId
+			record.PortGroup = network.Spec.PortGroupName
+			for _, pool := range leasePools {
+				if _, inPool := getNetworksForPool(pool)[network.Name]; inPool {
+					record.Pool = pool.Name
+					break
+				}
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// auditResources records the pools and networks a lease gained and lost from before to after. it is called
+// once after is persisted.
+func (l *LeaseReconciler) auditResources(ctx context.Context, lease *v1.Lease, before, after []metav1.OwnerReference, reason string) {
+	if l.Audit == nil {
+		return
+	}
+	now := time.Now()
+	added, removed := diffResources(before, after)
+	// the networks released with their pools are looked up in the pools the lease held before.
+	held := &v1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: lease.Namespace, OwnerReferences: append(resourceRefs(before), added...)}}
+	leasePools := getLeasePools(held)
+	l.Audit.Log(ctx, auditRecords(lease, audit.ActionRelease, removed, leasePools, reason, now)...)
+	l.Audit.Log(ctx, auditRecords(lease, audit.ActionAllocate, added, leasePools, "", now)...)
+}
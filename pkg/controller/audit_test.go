@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/audit"
+)
+
+func TestDiffResources(t *testing.T) {
+	pool := metav1.OwnerReference{Kind: v1.PoolKind, Name: "pool-a"}
+	net1 := metav1.OwnerReference{Kind: v1.NetworkKind, Name: "net-1"}
+	net2 := metav1.OwnerReference{Kind: v1.NetworkKind, Name: "net-2"}
+	job := metav1.OwnerReference{Kind: "Job", Name: "cleanup"}
+
+	added, removed := diffResources([]metav1.OwnerReference{pool, net1, job}, []metav1.OwnerReference{pool, net2})
+	if len(added) != 1 || added[0].Name != "net-2" {
+		t.Errorf("expected net-2 to be added, got %v", added)
+	}
+	if len(removed) != 1 || removed[0].Name != "net-1" {
+		t.Errorf("expected net-1 to be removed, got %v", removed)
+	}
+}
+
+func TestAuditResources(t *testing.T) {
+	pod := "pod1"
+	cleanupNetworks := setupTestNetworks(map[string]*v1.Network{
+		"default/net-1": {
+			ObjectMeta: metav1.ObjectMeta{Name: "net-1", Namespace: "default"},
+			Spec:       v1.NetworkSpec{PortGroupName: "ci-vlan-1302", VlanId: "1302", PodName: &pod},
+		},
+	})
+	defer cleanupNetworks()
+	oldPools := pools
+	defer func() { pools = oldPools }()
+	pools = map[string]*v1.Pool{"default/pool-a": {
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-a", Namespace: "default"},
+		Spec: v1.PoolSpec{
+			IBMPoolSpec: v1.IBMPoolSpec{Pod: pod},
+			FailureDomainSpec: v1.FailureDomainSpec{
+				VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+					Topology: configv1.VSpherePlatformTopology{Networks: []string{"/dc1/network/ci-vlan-1302"}},
+				},
+			},
+		},
+	}}
+
+	out := &bytes.Buffer{}
+	l := &LeaseReconciler{Audit: audit.NewLogger(audit.NewWriter(out))}
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "lease-1", Namespace: "default", Labels: map[string]string{v1.BoskosIdLabel: "boskos-1"}},
+		Status:     v1.LeaseStatus{JobLink: "https://prow/job"},
+	}
+	held := []metav1.OwnerReference{{Kind: v1.PoolKind, Name: "pool-a"}, {Kind: v1.NetworkKind, Name: "net-1"}}
+
+	l.auditResources(context.TODO(), lease, nil, held, "")
+	l.auditResources(context.TODO(), lease, held, nil, releaseReasonDeleted)
+
+	records, err := audit.Read(out)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("expected 4 records, got %d", len(records))
+	}
+	for i, record := range records {
+		wantAction := audit.ActionAllocate
+		if i >= 2 {
+			wantAction = audit.ActionRelease
+		}
+		if record.Action != wantAction || record.Lease != "lease-1" || record.BoskosID != "boskos-1" || record.JobLink != "https://prow/job" || record.Pool != "pool-a" {
+			t.Errorf("unexpected record %d %+v", i, record)
+		}
+		if record.Kind == v1.NetworkKind && (record.VLAN != "1302" || record.PortGroup != "ci-vlan-1302") {
+			t.Errorf("expected the VLAN of the network, got %+v", record)
+		}
+	}
+	if records[3].Reason != releaseReasonDeleted {
+		t.Errorf("expected the release reason, got %q", records[3].Reason)
+	}
+}
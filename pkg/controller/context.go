@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
 )
 
 var (
@@ -11,4 +12,7 @@ var (
 	pools         = make(map[string]*v1.Pool)
 	leases        = make(map[string]*v1.Lease)
 	networks      = make(map[string]*v1.Network)
+
+	// schedulingQueue holds the leases waiting for pools. it is drained by the scheduling loop.
+	schedulingQueue = scheduler.NewSchedulingQueue(0, 0, LEASE_PENDING_RETRY_INTERVAL)
 )
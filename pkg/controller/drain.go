@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// POOL_DRAIN_RETRY_INTERVAL controls how often a draining pool is checked for remaining leases
+	POOL_DRAIN_RETRY_INTERVAL = 30 * time.Second
+)
+
+// getLeasesForPool returns the leases which hold the provided pool.
+func getLeasesForPool(pool *v1.Pool) []*v1.Lease {
+	var poolLeases []*v1.Lease
+	for _, lease := range leases {
+		if lease.Namespace != pool.Namespace {
+			continue
+		}
+		for _, ownerRef := range lease.OwnerReferences {
+			if ownerRef.Kind == v1.PoolKind && ownerRef.Name == pool.Name {
+				poolLeases = append(poolLeases, lease)
+				break
+			}
+		}
+	}
+	return poolLeases
+}
+
+// reconcileDrainCordon cordons a pool being drained, and uncordons it once the drain is removed if the drain
+// cordoned it. pools cordoned before the drain stay cordoned. returns true if the pool changed.
+func reconcileDrainCordon(ctx context.Context, pool *v1.Pool) bool {
+	_, cordonedByDrain := pool.Annotations[v1.PoolCordonedByDrainAnnotation]
+	switch {
+	case pool.Spec.Drain != nil && !pool.Spec.NoSchedule:
+		if pool.Annotations == nil {
+			pool.Annotations = make(map[string]string)
+		}
+		pool.Annotations[v1.PoolCordonedByDrainAnnotation] = "true"
+		pool.Spec.NoSchedule = true
+		log.FromContext(ctx).Info("cordoning pool for drain")
+		return true
+	case pool.Spec.Drain == nil && cordonedByDrain:
+		delete(pool.Annotations, v1.PoolCordonedByDrainAnnotation)
+		pool.Spec.NoSchedule = false
+		log.FromContext(ctx).Info("uncordoning pool, the drain was removed")
+		return true
+	}
+	return false
+}
+
+// getLeasesToEvict returns the leases which are older than the drain's DeleteLeasesOlderThan. if the drain
+// does not request deletion, no leases are returned.
+func getLeasesToEvict(drain *v1.PoolDrainSpec, poolLeases []*v1.Lease, now time.Time) []*v1.Lease {
+	var toEvict []*v1.Lease
+	if drain == nil || drain.DeleteLeasesOlderThan == nil {
+		return toEvict
+	}
+	for _, lease := range poolLeases {
+		if lease.DeletionTimestamp != nil {
+			continue
+		}
+		if now.Sub(lease.CreationTimestamp.Time) > drain.DeleteLeasesOlderThan.Duration {
+			toEvict = append(toEvict, lease)
+		}
+	}
+	return toEvict
+}
+
+// reconcileDrain notifies the holders of leases on a draining pool, evicts leases past the drain deadline and
+// updates the drain status of the pool. the pool must already be cordoned and have its status reconciled.
+// returns true if the drain is still in progress.
+func (l *PoolReconciler) reconcileDrain(ctx context.Context, pool *v1.Pool) bool {
+	if pool.Spec.Drain == nil {
+		pool.Status.Drain = nil
+		return false
+	}
+
+	logger := log.FromContext(ctx)
+	if pool.Status.Drain == nil {
+		logger.Info("pool is being drained")
+		pool.Status.Drain = &v1.PoolDrainStatus{
+			StartTime: metav1.Now(),
+		}
+		l.Recorder.Event(pool, corev1.EventTypeNormal, v1.ReasonPoolDraining, "pool is cordoned and being drained")
+	}
+
+	poolLeases := getLeasesForPool(pool)
+	for _, lease := range poolLeases {
+		if lease.Annotations[v1.PoolDrainingAnnotation] == pool.Name {
+			continue
+		}
+
+		toNotify := &v1.Lease{}
+		err := l.Client.Get(ctx, types.NamespacedName{Name: lease.Name, Namespace: lease.Namespace}, toNotify)
+		if err != nil {
+			logger.Error(err, "unable to get lease", "Lease", klog.KObj(lease))
+			continue
+		}
+		if toNotify.Annotations == nil {
+			toNotify.Annotations = make(map[string]string)
+		}
+		toNotify.Annotations[v1.PoolDrainingAnnotation] = pool.Name
+		err = l.Client.Update(ctx, toNotify)
+		if err != nil {
+			logger.Error(err, "unable to notify lease of the drain", "Lease", klog.KObj(lease))
+			continue
+		}
+		l.Recorder.Eventf(toNotify, corev1.EventTypeWarning, v1.ReasonPoolDraining, "pool %s is being drained, release this lease as soon as possible", pool.Name)
+	}
+
+	for _, lease := range getLeasesToEvict(pool.Spec.Drain, poolLeases, time.Now()) {
+		// the cached lease may not show the deletion of an earlier reconcile yet, it is only evicted once.
+		toEvict := &v1.Lease{}
+		err := l.Client.Get(ctx, types.NamespacedName{Name: lease.Name, Namespace: lease.Namespace}, toEvict)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				logger.Error(err, "unable to get lease", "Lease", klog.KObj(lease))
+			}
+			continue
+		}
+		if toEvict.DeletionTimestamp != nil {
+			continue
+		}
+		logger.Info("deleting lease to drain pool", "Lease", klog.KObj(lease))
+		err = l.Client.Delete(ctx, toEvict)
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				logger.Error(err, "unable to delete lease", "Lease", klog.KObj(lease))
+			}
+			continue
+		}
+		pool.Status.Drain.LeasesEvicted++
+		l.Recorder.Eventf(pool, corev1.EventTypeWarning, v1.ReasonLeaseEvicted, "deleted lease %s older than %v", lease.Name, pool.Spec.Drain.DeleteLeasesOlderThan.Duration)
+	}
+
+	pool.Status.Drain.LeasesRemaining = pool.Status.LeaseCount
+	if pool.Status.LeaseCount > 0 {
+		pool.Status.Drain.Phase = v1.DrainPhaseDraining
+		return true
+	}
+
+	if pool.Status.Drain.Phase != v1.DrainPhaseDrained {
+		logger.Info("pool is drained")
+		l.Recorder.Event(pool, corev1.EventTypeNormal, v1.ReasonPoolDrained, "no leases remain on the pool")
+	}
+	pool.Status.Drain.Phase = v1.DrainPhaseDrained
+	return false
+}
@@ -0,0 +1,303 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func TestGetLeasesForPool(t *testing.T) {
+	cleanup := setupTestLeases(map[string]*v1.Lease{
+		"default/lease-1": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "lease-1",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Pool", Name: "pool-1"},
+					{Kind: "Network", Name: "net-1"},
+				},
+			},
+		},
+		"default/lease-2": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "lease-2",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Pool", Name: "pool-2"},
+				},
+			},
+		},
+		"default/lease-3": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "lease-3",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Network", Name: "pool-1"},
+				},
+			},
+		},
+		"other/lease-4": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "lease-4",
+				Namespace: "other",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Pool", Name: "pool-1"},
+				},
+			},
+		},
+	})
+	defer cleanup()
+
+	pool := &v1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool-1", Namespace: "default"}}
+	got := getLeasesForPool(pool)
+	if len(got) != 1 || got[0].Name != "lease-1" {
+		names := []string{}
+		for _, lease := range got {
+			names = append(names, lease.Name)
+		}
+		t.Errorf("getLeasesForPool() = %v, want [lease-1]", names)
+	}
+}
+
+func TestGetLeasesToEvict(t *testing.T) {
+	now := time.Now()
+	deleting := metav1.NewTime(now)
+
+	poolLeases := []*v1.Lease{
+		{ObjectMeta: metav1.ObjectMeta{Name: "new", CreationTimestamp: metav1.NewTime(now.Add(-10 * time.Minute))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "old", CreationTimestamp: metav1.NewTime(now.Add(-3 * time.Hour))}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "old-deleting", CreationTimestamp: metav1.NewTime(now.Add(-3 * time.Hour)), DeletionTimestamp: &deleting}},
+	}
+
+	tests := []struct {
+		name     string
+		drain    *v1.PoolDrainSpec
+		expected []string
+	}{
+		{
+			name:     "no drain evicts nothing",
+			drain:    nil,
+			expected: nil,
+		},
+		{
+			name:     "drain without deadline evicts nothing",
+			drain:    &v1.PoolDrainSpec{},
+			expected: nil,
+		},
+		{
+			name:     "drain with deadline evicts old leases not already being deleted",
+			drain:    &v1.PoolDrainSpec{DeleteLeasesOlderThan: &metav1.Duration{Duration: time.Hour}},
+			expected: []string{"old"},
+		},
+		{
+			name:     "drain with short deadline evicts all leases",
+			drain:    &v1.PoolDrainSpec{DeleteLeasesOlderThan: &metav1.Duration{Duration: time.Minute}},
+			expected: []string{"new", "old"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getLeasesToEvict(tt.drain, poolLeases, now)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("getLeasesToEvict() returned %d leases, want %d", len(got), len(tt.expected))
+			}
+			for i, lease := range got {
+				if lease.Name != tt.expected[i] {
+					t.Errorf("getLeasesToEvict()[%d] = %s, want %s", i, lease.Name, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileDrainStatus(t *testing.T) {
+	cleanup := setupTestLeases(map[string]*v1.Lease{})
+	defer cleanup()
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &PoolReconciler{Recorder: recorder}
+
+	pool := &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-1", Namespace: "default"},
+		Spec: v1.PoolSpec{
+			NoSchedule: true,
+			Drain:      &v1.PoolDrainSpec{},
+		},
+		Status: v1.PoolStatus{LeaseCount: 2},
+	}
+
+	if draining := reconciler.reconcileDrain(context.TODO(), pool); !draining {
+		t.Errorf("expected pool with leases to still be draining")
+	}
+	if pool.Status.Drain == nil || pool.Status.Drain.Phase != v1.DrainPhaseDraining {
+		t.Fatalf("expected drain phase %s, got %+v", v1.DrainPhaseDraining, pool.Status.Drain)
+	}
+	if pool.Status.Drain.LeasesRemaining != 2 {
+		t.Errorf("expected 2 leases remaining, got %d", pool.Status.Drain.LeasesRemaining)
+	}
+
+	pool.Status.LeaseCount = 0
+	if draining := reconciler.reconcileDrain(context.TODO(), pool); draining {
+		t.Errorf("expected pool without leases to be drained")
+	}
+	if pool.Status.Drain.Phase != v1.DrainPhaseDrained {
+		t.Errorf("expected drain phase %s, got %s", v1.DrainPhaseDrained, pool.Status.Drain.Phase)
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("expected a draining and a drained event, got %d events", len(recorder.Events))
+	}
+
+	pool.Spec.Drain = nil
+	if draining := reconciler.reconcileDrain(context.TODO(), pool); draining {
+		t.Errorf("expected pool without drain to not be draining")
+	}
+	if pool.Status.Drain != nil {
+		t.Errorf("expected drain status to be cleared, got %+v", pool.Status.Drain)
+	}
+}
+
+func TestReconcileDrainCordon(t *testing.T) {
+	cordonedByDrain := map[string]string{v1.PoolCordonedByDrainAnnotation: "true"}
+
+	tests := []struct {
+		name             string
+		drain            *v1.PoolDrainSpec
+		noSchedule       bool
+		annotations      map[string]string
+		expectChanged    bool
+		expectNoSchedule bool
+		expectAnnotation bool
+	}{
+		{
+			name:             "drain cordons the pool",
+			drain:            &v1.PoolDrainSpec{},
+			expectChanged:    true,
+			expectNoSchedule: true,
+			expectAnnotation: true,
+		},
+		{
+			name:             "drain of a cordoned pool",
+			drain:            &v1.PoolDrainSpec{},
+			noSchedule:       true,
+			expectNoSchedule: true,
+		},
+		{
+			name:             "pool cordoned by a drain stays cordoned while draining",
+			drain:            &v1.PoolDrainSpec{},
+			noSchedule:       true,
+			annotations:      cordonedByDrain,
+			expectNoSchedule: true,
+			expectAnnotation: true,
+		},
+		{
+			name:          "removed drain uncordons the pool",
+			noSchedule:    true,
+			annotations:   cordonedByDrain,
+			expectChanged: true,
+		},
+		{
+			name:             "removed drain keeps the pool cordoned before the drain",
+			noSchedule:       true,
+			expectNoSchedule: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := &v1.Pool{
+				ObjectMeta: metav1.ObjectMeta{Name: "pool-1", Annotations: make(map[string]string)},
+				Spec:       v1.PoolSpec{NoSchedule: tt.noSchedule, Drain: tt.drain},
+			}
+			for key, value := range tt.annotations {
+				pool.Annotations[key] = value
+			}
+
+			if changed := reconcileDrainCordon(context.TODO(), pool); changed != tt.expectChanged {
+				t.Errorf("expected changed %v, got %v", tt.expectChanged, changed)
+			}
+			if pool.Spec.NoSchedule != tt.expectNoSchedule {
+				t.Errorf("expected noSchedule %v, got %v", tt.expectNoSchedule, pool.Spec.NoSchedule)
+			}
+			if _, annotated := pool.Annotations[v1.PoolCordonedByDrainAnnotation]; annotated != tt.expectAnnotation {
+				t.Errorf("expected the cordoned by drain annotation %v, got %v", tt.expectAnnotation, pool.Annotations)
+			}
+		})
+	}
+}
+
+func TestReconcileDrainCordonByAdmin(t *testing.T) {
+	pool := &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-1"},
+		Spec:       v1.PoolSpec{Drain: &v1.PoolDrainSpec{}},
+	}
+	if changed := reconcileDrainCordon(context.TODO(), pool); !changed || !pool.Spec.NoSchedule {
+		t.Fatalf("expected the drain to cordon the pool")
+	}
+
+	// an admin cordoning the pool during the drain takes the cordon over, as vcm pool cordon does.
+	delete(pool.Annotations, v1.PoolCordonedByDrainAnnotation)
+	if changed := reconcileDrainCordon(context.TODO(), pool); changed {
+		t.Errorf("expected the draining pool not to change")
+	}
+
+	pool.Spec.Drain = nil
+	if changed := reconcileDrainCordon(context.TODO(), pool); changed || !pool.Spec.NoSchedule {
+		t.Errorf("expected the pool cordoned by an admin to stay cordoned once the drain is removed")
+	}
+}
+
+func TestReconcileDrainEvictsOnce(t *testing.T) {
+	now := time.Now()
+	deleting := metav1.NewTime(now)
+	newLease := func(name string) *v1.Lease {
+		return &v1.Lease{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(now.Add(-3 * time.Hour)),
+			Annotations:       map[string]string{v1.PoolDrainingAnnotation: "pool-1"},
+			OwnerReferences:   []metav1.OwnerReference{{Kind: v1.PoolKind, Name: "pool-1"}},
+		}}
+	}
+	// the cache has not seen the deletion of the leases evicted by an earlier reconcile yet.
+	terminating := newLease("terminating")
+	terminating.DeletionTimestamp = &deleting
+	terminating.Finalizers = []string{"test"}
+	cleanup := setupTestLeases(map[string]*v1.Lease{
+		"default/old":         newLease("old"),
+		"default/terminating": newLease("terminating"),
+		"default/gone":        newLease("gone"),
+	})
+	defer cleanup()
+
+	c := newTestClient(newLease("old"), terminating)
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &PoolReconciler{Client: c, Recorder: recorder}
+	pool := &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-1", Namespace: "default"},
+		Spec: v1.PoolSpec{
+			NoSchedule: true,
+			Drain:      &v1.PoolDrainSpec{DeleteLeasesOlderThan: &metav1.Duration{Duration: time.Hour}},
+		},
+		Status: v1.PoolStatus{LeaseCount: 3},
+	}
+
+	reconciler.reconcileDrain(context.TODO(), pool)
+	if pool.Status.Drain.LeasesEvicted != 1 {
+		t.Errorf("expected 1 lease evicted, got %d", pool.Status.Drain.LeasesEvicted)
+	}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "old", Namespace: "default"}, &v1.Lease{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected the old lease to be deleted, got %v", err)
+	}
+	// a draining and an eviction event.
+	if len(recorder.Events) != 2 {
+		t.Errorf("expected 2 events, got %d", len(recorder.Events))
+	}
+}
@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"context"
 	"testing"
 
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
-	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler/plugins"
 	configv1 "github.com/openshift/api/config/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -153,20 +155,7 @@ func TestDynamicVCenterFiltering(t *testing.T) {
 			t.Logf("%s", tt.description)
 			t.Logf("Remaining slots: %d, Remaining pools: %d", remainingSlots, remainingPools)
 
-			var excludedVCenters map[string]bool
-
-			// Replicate the controller's logic
-			if len(vcentersInUse) >= lease.Spec.VCenters {
-				// Cap reached
-				excludedVCenters = make(map[string]bool)
-				for _, p := range tt.availablePools {
-					if !vcentersInUse[p.Spec.Server] {
-						excludedVCenters[p.Spec.Server] = true
-					}
-				}
-				t.Logf("Cap reached - excluded %d vCenters", len(excludedVCenters))
-			} else if remainingSlots > 0 && remainingPools > remainingSlots {
-				// Dynamic filtering
+			if remainingSlots > 0 && remainingPools > remainingSlots {
 				minPoolsPerVCenter := (remainingPools-1)/remainingSlots + 1
 				t.Logf("Dynamic filtering: minPoolsPerVCenter = %d", minPoolsPerVCenter)
 
@@ -174,33 +163,20 @@ func TestDynamicVCenterFiltering(t *testing.T) {
 					t.Errorf("Expected minPoolsPerVCenter=%d, got %d",
 						tt.expectedMinPoolsNeeded, minPoolsPerVCenter)
 				}
+			}
 
-				// Count pools per vCenter
-				fittingPools, _ := utils.GetFittingPools(lease, tt.availablePools, nil)
-				poolsPerVCenter := make(map[string]int)
-				for _, p := range fittingPools {
-					if !vcentersInUse[p.Spec.Server] {
-						poolsPerVCenter[p.Spec.Server]++
-					}
-				}
-
-				// Exclude vCenters with insufficient pools
-				excludedVCenters = make(map[string]bool)
-				for _, p := range tt.availablePools {
-					if !vcentersInUse[p.Spec.Server] {
-						if poolsPerVCenter[p.Spec.Server] < minPoolsPerVCenter {
-							excludedVCenters[p.Spec.Server] = true
-						}
-					}
-				}
-
-				t.Logf("Dynamic filter excluded %d vCenters (with < %d pools)",
-					len(excludedVCenters), minPoolsPerVCenter)
-
-				for server, count := range poolsPerVCenter {
-					excluded := excludedVCenters[server]
-					t.Logf("  %s: %d pools → excluded=%v", server, count, excluded)
-				}
+			// Run the filters of the default scheduler, the VCenterCap plugin records the excluded vCenters
+			leaseScheduler, err := plugins.NewScheduler(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			framework := leaseScheduler.ForLease(lease)
+			state := scheduler.NewCycleState(lease, assignedPools, nil)
+			feasible, results := framework.RunFilterPlugins(context.TODO(), state, lease, tt.availablePools)
+			framework.RunPostFilterPlugins(context.TODO(), state, lease, tt.availablePools, feasible, results)
+			excludedVCenters := state.ExcludedVCenters
+			for server := range excludedVCenters {
+				t.Logf("  %s excluded", server)
 			}
 
 			// Verify expectations
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// leaseTenantFunc returns a function returning the fair share tenant of a lease for the tenant key. the Prow
+// annotations are the ones also used by generateJobLink.
+func leaseTenantFunc(key scheduler.TenantKey) func(*v1.Lease) string {
+	return func(lease *v1.Lease) string {
+		var tenant string
+		switch key {
+		case scheduler.TenantKeyNamespace:
+			tenant = lease.Namespace
+		case scheduler.TenantKeyJobType:
+			tenant = lease.Annotations[v1.PROW_JOB_TYPE_KEY]
+		case scheduler.TenantKeyRepo:
+			org, repo := lease.Annotations[GIT_ORG_KEY], lease.Annotations[GIT_REPO_KEY]
+			if org != "" && repo != "" {
+				tenant = org + "/" + repo
+			}
+		}
+		if tenant == "" {
+			return scheduler.DefaultTenant
+		}
+		return tenant
+	}
+}
+
+// getTenantAllocations returns the resources held by the leases of each tenant and the total capacity of the
+// pools. a lease holds its vCPUs and memory in each of its pools.
+func getTenantAllocations(tenant func(*v1.Lease) string) (map[string]scheduler.Resources, scheduler.Resources) {
+	allocated := make(map[string]scheduler.Resources)
+	for _, lease := range leases {
+		poolCount, networkCount := 0, 0
+		for _, ownerRef := range lease.OwnerReferences {
+			switch ownerRef.Kind {
+			case v1.PoolKind:
+				poolCount++
+			case "Network":
+				networkCount++
+			}
+		}
+		if poolCount == 0 && networkCount == 0 {
+			continue
+		}
+
+		name := tenant(lease)
+		held := utils.GetLeaseAllocatedResources(lease)
+		resources := allocated[name]
+		resources.VCpus += held.VCpus * poolCount
+		resources.Memory += held.Memory * poolCount
+		resources.Networks += networkCount
+		allocated[name] = resources
+	}
+
+	var capacity scheduler.Resources
+	for _, pool := range pools {
+		overCommitRatio, err := strconv.ParseFloat(pool.Spec.OverCommitRatio, 64)
+		if err != nil {
+			overCommitRatio = 1.0
+		}
+		capacity.VCpus += int(float64(pool.Spec.VCpus) * overCommitRatio)
+		capacity.Memory += pool.Spec.Memory
+		capacity.Networks += len(pool.Spec.Topology.Networks)
+	}
+	return allocated, capacity
+}
+
+// updateFairShare records the current allocations of each tenant with the fair share tracker and exports the
+// share and usage of each tenant. fairShare may be nil if fair sharing is disabled.
+func updateFairShare(fairShare *scheduler.FairShare) {
+	if fairShare == nil {
+		return
+	}
+	fairShare.Update(getTenantAllocations(leaseTenantFunc(fairShare.Config().TenantKey)))
+
+	for _, tenant := range fairShare.Tenants() {
+		labels := prometheus.Labels{"tenant": tenant.Tenant}
+		FairShareTenantShare.With(labels).Set(tenant.Share)
+		FairShareTenantDominantShare.With(labels).Set(tenant.DominantShare)
+		FairShareTenantUsage.With(labels).Set(tenant.Usage)
+	}
+}
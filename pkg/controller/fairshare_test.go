@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+)
+
+func TestLeaseTenantFunc(t *testing.T) {
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "lease",
+			Namespace: "ci",
+			Annotations: map[string]string{
+				v1.PROW_JOB_TYPE_KEY: PRESUBMIT_JOB_TYPE,
+				GIT_ORG_KEY:          "openshift",
+				GIT_REPO_KEY:         "installer",
+			},
+		},
+	}
+	periodic := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "periodic",
+			Namespace:   "ci",
+			Annotations: map[string]string{v1.PROW_JOB_TYPE_KEY: PERIODICAL_JOB_TYPE},
+		},
+	}
+
+	tests := []struct {
+		key      scheduler.TenantKey
+		lease    *v1.Lease
+		expected string
+	}{
+		{key: scheduler.TenantKeyNamespace, lease: lease, expected: "ci"},
+		{key: scheduler.TenantKeyJobType, lease: lease, expected: PRESUBMIT_JOB_TYPE},
+		{key: scheduler.TenantKeyJobType, lease: periodic, expected: PERIODICAL_JOB_TYPE},
+		{key: scheduler.TenantKeyJobType, lease: &v1.Lease{}, expected: scheduler.DefaultTenant},
+		{key: scheduler.TenantKeyRepo, lease: lease, expected: "openshift/installer"},
+		{key: scheduler.TenantKeyRepo, lease: periodic, expected: scheduler.DefaultTenant},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.key)+"/"+tt.lease.Name, func(t *testing.T) {
+			if tenant := leaseTenantFunc(tt.key)(tt.lease); tenant != tt.expected {
+				t.Errorf("expected tenant %q, got %q", tt.expected, tenant)
+			}
+		})
+	}
+}
+
+func TestGetTenantAllocations(t *testing.T) {
+	cleanupLeases := setupTestLeases(map[string]*v1.Lease{
+		"ci/multi-pool": {
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ci",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: v1.PoolKind, Name: "pool-a"},
+					{Kind: v1.PoolKind, Name: "pool-b"},
+					{Kind: "Network", Name: "network-a"},
+					{Kind: "Network", Name: "network-b"},
+				},
+			},
+			Spec: v1.LeaseSpec{VCpus: 8, Memory: 16},
+		},
+		"ci/single-pool": {
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "ci",
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: v1.PoolKind, Name: "pool-a"},
+					{Kind: "Network", Name: "network-c"},
+				},
+			},
+			Spec: v1.LeaseSpec{VCpus: 4, Memory: 8},
+		},
+		"other/pending": {
+			ObjectMeta: metav1.ObjectMeta{Namespace: "other"},
+			Spec:       v1.LeaseSpec{VCpus: 4, Memory: 8},
+		},
+	})
+	defer cleanupLeases()
+
+	oldPools := pools
+	defer func() { pools = oldPools }()
+	newPool := func(vcpus, memory int, overCommitRatio string, networks ...string) *v1.Pool {
+		return &v1.Pool{Spec: v1.PoolSpec{
+			VCpus:           vcpus,
+			Memory:          memory,
+			OverCommitRatio: overCommitRatio,
+			FailureDomainSpec: v1.FailureDomainSpec{
+				VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+					Topology: configv1.VSpherePlatformTopology{Networks: networks},
+				},
+			},
+		}}
+	}
+	pools = map[string]*v1.Pool{
+		"ci/pool-a": newPool(100, 400, "2.0", "pg-1", "pg-2"),
+		"ci/pool-b": newPool(50, 200, "", "pg-3"),
+	}
+
+	allocated, capacity := getTenantAllocations(leaseTenantFunc(scheduler.TenantKeyNamespace))
+	if capacity != (scheduler.Resources{VCpus: 250, Memory: 600, Networks: 3}) {
+		t.Errorf("unexpected capacity: %+v", capacity)
+	}
+	if len(allocated) != 1 {
+		t.Fatalf("expected only tenants holding resources, got %+v", allocated)
+	}
+	if allocated["ci"] != (scheduler.Resources{VCpus: 20, Memory: 40, Networks: 3}) {
+		t.Errorf("unexpected allocation: %+v", allocated["ci"])
+	}
+}
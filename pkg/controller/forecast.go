@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/forecast"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+const (
+	// DEFAULT_FORECAST_WINDOW is how far back the leases used by the forecast go
+	DEFAULT_FORECAST_WINDOW = 7 * 24 * time.Hour
+
+	// FORECAST_INTERVAL is how often the forecast metrics are updated
+	FORECAST_INTERVAL = time.Minute
+
+	// FORECAST_PATH is the path of the forecast on the metrics server
+	FORECAST_PATH = "/forecast"
+)
+
+// forecastNetworkTypes are the network types forecast in the metrics.
+var forecastNetworkTypes = []v1.NetworkType{v1.NetworkTypeSingleTenant, v1.NetworkTypeMultiTenant, v1.NetworkTypeDisconnected}
+
+// forecastLease returns the forecast view of a live lease.
+func forecastLease(lease *v1.Lease) forecast.Lease {
+	allocated := utils.GetLeaseAllocatedResources(lease)
+	fcLease := forecast.Lease{
+		Shape: forecast.Shape{VCpus: allocated.VCpus, Memory: allocated.Memory, Networks: allocated.Networks},
+	}
+	for _, poolRef := range utils.GetLeasePoolRefs(lease) {
+		fcLease.Pools = append(fcLease.Pools, poolRef.Name)
+	}
+	if fulfilledAt := leaseFulfilledAt(lease); fulfilledAt != nil {
+		fcLease.FulfilledAt = &fulfilledAt.Time
+	}
+	return fcLease
+}
+
+// forecastUsageRecord returns the forecast view of a released lease.
+func forecastUsageRecord(record *v1.LeaseUsageRecord) forecast.Lease {
+	fcLease := forecast.Lease{
+		Shape:      forecast.Shape{VCpus: record.Spec.VCpus, Memory: record.Spec.Memory, Networks: record.Spec.Networks},
+		Pools:      record.Spec.Pools,
+		ReleasedAt: &record.Spec.ReleasedAt.Time,
+	}
+	if record.Spec.FulfilledAt != nil {
+		fcLease.FulfilledAt = &record.Spec.FulfilledAt.Time
+	}
+	return fcLease
+}
+
+// listUsageRecords returns the usage records of the leases released within window.
+func (l *LeaseReconciler) listUsageRecords(ctx context.Context, now time.Time, window time.Duration) ([]v1.LeaseUsageRecord, error) {
+	records := &v1.LeaseUsageRecordList{}
+	if err := l.List(ctx, records); err != nil {
+		return nil, fmt.Errorf("error listing usage records: %w", err)
+	}
+	var recent []v1.LeaseUsageRecord
+	for _, record := range records.Items {
+		if now.Sub(record.Spec.ReleasedAt.Time) <= window {
+			recent = append(recent, record)
+		}
+	}
+	return recent, nil
+}
+
+// forecastInput returns the input of the forecast for a network type from the cached leases and pools, and the
+// usage records listed by listUsageRecords. pending leases only count if they need the network type, since the
+// networks of the pools are only available to leases of the network type. the reconcile lock must be held.
+func (l *LeaseReconciler) forecastInput(networkType v1.NetworkType, now time.Time, window time.Duration, records []v1.LeaseUsageRecord) (forecast.Input, []forecast.Lease) {
+	input := forecast.Input{Now: now, Window: window}
+	var sameType []forecast.Lease
+
+	for _, lease := range leases {
+		if lease.DeletionTimestamp != nil {
+			continue
+		}
+		fcLease := forecastLease(lease)
+		if leaseNetworkType(lease) == networkType {
+			sameType = append(sameType, fcLease)
+		} else if fcLease.FulfilledAt == nil {
+			continue
+		}
+		input.Leases = append(input.Leases, fcLease)
+	}
+
+	for i := range records {
+		record := &records[i]
+		fcLease := forecastUsageRecord(record)
+		input.Leases = append(input.Leases, fcLease)
+		if recordType := record.Spec.NetworkType; recordType == networkType || (recordType == "" && networkType == v1.NetworkTypeSingleTenant) {
+			sameType = append(sameType, fcLease)
+		}
+	}
+
+	for _, pool := range pools {
+		input.Pools = append(input.Pools, forecast.Pool{
+			Namespace: pool.Namespace,
+			Name:      pool.Name,
+			Free: forecast.Shape{
+				VCpus:    pool.Status.VCpusAvailable,
+				Memory:   pool.Status.MemoryAvailable,
+				Networks: len(l.getAvailableNetworks(pool, networkType)),
+			},
+			Schedulable: !pool.Spec.NoSchedule,
+		})
+	}
+	return input, sameType
+}
+
+// forecastSeconds returns a forecast duration as a metric value, +Inf when it is unknown or never reached.
+func forecastSeconds(value *float64) float64 {
+	if value == nil {
+		return math.Inf(1)
+	}
+	return *value
+}
+
+// updateForecastMetrics forecasts, for each pool and network type, the wait of a lease of the mean shape of the
+// recent leases of the network type, and the time until the pool runs out of capacity.
+func (l *LeaseReconciler) updateForecastMetrics(ctx context.Context) {
+	now := time.Now()
+	records, err := l.listUsageRecords(ctx, now, l.ForecastWindow)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to forecast leases")
+		return
+	}
+
+	reconcileLock.Lock()
+	defer reconcileLock.Unlock()
+
+	PoolForecastWaitSeconds.Reset()
+	PoolForecastExhaustionSeconds.Reset()
+	for _, networkType := range forecastNetworkTypes {
+		input, sameType := l.forecastInput(networkType, now, l.ForecastWindow, records)
+		if len(sameType) == 0 {
+			continue
+		}
+
+		for _, poolForecast := range forecast.Forecast(input, forecast.MeanShape(sameType)) {
+			promLabels := prometheus.Labels{
+				"namespace":   poolForecast.Namespace,
+				"pool":        poolForecast.Pool,
+				"networkType": string(networkType),
+			}
+			PoolForecastWaitSeconds.With(promLabels).Set(forecastSeconds(poolForecast.ExpectedWaitSeconds))
+			PoolForecastExhaustionSeconds.With(promLabels).Set(forecastSeconds(poolForecast.ExhaustionSeconds))
+		}
+	}
+}
+
+// runForecastLoop updates the forecast metrics, and the queue position and estimated wait of the waiting
+// leases, every FORECAST_INTERVAL until ctx is done.
+func (l *LeaseReconciler) runForecastLoop(ctx context.Context) error {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithName("forecast"))
+	ticker := time.NewTicker(FORECAST_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			l.updateForecastMetrics(ctx)
+			l.updateLeaseQueueStatuses(ctx)
+		}
+	}
+}
+
+// forecastShape returns the shape given by the vcpus, memory and networks query parameters, defaulting each to
+// the mean shape of the recent leases.
+func forecastShape(query map[string][]string, defaults forecast.Shape) (forecast.Shape, error) {
+	shape := defaults
+	for key, value := range map[string]*int{"vcpus": &shape.VCpus, "memory": &shape.Memory, "networks": &shape.Networks} {
+		values := query[key]
+		if len(values) == 0 || values[0] == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(values[0])
+		if err != nil || parsed < 0 {
+			return shape, fmt.Errorf("invalid %s %q", key, values[0])
+		}
+		*value = parsed
+	}
+	return shape, nil
+}
+
+// ForecastHandler serves a dry run of a lease: the forecast wait on each pool, and when the pools run out of
+// capacity, without creating the lease.
+//
+// Query parameters:
+//   - networkType: single-tenant (default), multi-tenant or disconnected
+//   - vcpus, memory and networks: the shape of the lease, the mean shape of the recent leases by default
+type ForecastHandler struct {
+	// Leases is the lease reconciler whose leases and pools are forecast. it is set once the reconciler is created.
+	Leases *LeaseReconciler
+}
+
+func (h *ForecastHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Leases == nil {
+		http.Error(w, "forecast is not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	networkType := v1.NetworkType(query.Get("networkType"))
+	if networkType == "" {
+		networkType = v1.NetworkTypeSingleTenant
+	}
+
+	now := time.Now()
+	records, err := h.Leases.listUsageRecords(r.Context(), now, h.Leases.ForecastWindow)
+	if err != nil {
+		log.FromContext(r.Context()).Error(err, "unable to forecast leases", "networkType", networkType)
+		http.Error(w, "error forecasting", http.StatusInternalServerError)
+		return
+	}
+	reconcileLock.Lock()
+	input, sameType := h.Leases.forecastInput(networkType, now, h.Leases.ForecastWindow, records)
+	reconcileLock.Unlock()
+
+	shape, err := forecastShape(query, forecast.MeanShape(sameType))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		NetworkType v1.NetworkType          `json:"networkType"`
+		Shape       forecast.Shape          `json:"shape"`
+		Pools       []forecast.PoolForecast `json:"pools"`
+	}{NetworkType: networkType, Shape: shape, Pools: forecast.Forecast(input, shape)}); err != nil {
+		log.FromContext(r.Context()).Error(err, "unable to write forecast")
+	}
+}
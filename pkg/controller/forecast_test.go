@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/forecast"
+)
+
+func TestForecastShape(t *testing.T) {
+	defaults := forecast.Shape{VCpus: 16, Memory: 64, Networks: 1}
+	tests := []struct {
+		name    string
+		query   string
+		want    forecast.Shape
+		wantErr bool
+	}{
+		{name: "mean shape by default", query: "", want: defaults},
+		{name: "given resources", query: "vcpus=24&memory=96", want: forecast.Shape{VCpus: 24, Memory: 96, Networks: 1}},
+		{name: "invalid resources", query: "networks=two", wantErr: true},
+		{name: "negative resources", query: "vcpus=-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			shape, err := forecastShape(query, defaults)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && shape != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, shape)
+			}
+		})
+	}
+}
+
+func TestForecastLease(t *testing.T) {
+	fulfilled := metav1.Now()
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "lease-1",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: v1.PoolKind, Name: "pool-1"},
+				{Kind: "Network", Name: "net-1"},
+			},
+		},
+		Spec: v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 1},
+		Status: v1.LeaseStatus{
+			Allocated:   &v1.LeaseResources{VCpus: 16, Memory: 64, Networks: 1},
+			FulfilledAt: &fulfilled,
+		},
+	}
+
+	fcLease := forecastLease(lease)
+	if fcLease.Shape != (forecast.Shape{VCpus: 16, Memory: 64, Networks: 1}) {
+		t.Errorf("expected the allocated resources, got %+v", fcLease.Shape)
+	}
+	if len(fcLease.Pools) != 1 || fcLease.Pools[0] != "pool-1" {
+		t.Errorf("expected pool-1, got %v", fcLease.Pools)
+	}
+	if fcLease.FulfilledAt == nil || !fcLease.FulfilledAt.Equal(fulfilled.Time) || fcLease.ReleasedAt != nil {
+		t.Errorf("expected an active lease fulfilled at %v, got %v and %v", fulfilled, fcLease.FulfilledAt, fcLease.ReleasedAt)
+	}
+
+	if seconds := forecastSeconds(nil); !math.IsInf(seconds, 1) {
+		t.Errorf("expected +Inf without a forecast, got %v", seconds)
+	}
+}
+
+func TestForecastInputNetworkType(t *testing.T) {
+	cleanupLeases := setupTestLeases(map[string]*v1.Lease{
+		"ci/untyped": {ObjectMeta: metav1.ObjectMeta{Name: "untyped", Namespace: "ci"}},
+		"ci/multi":   {ObjectMeta: metav1.ObjectMeta{Name: "multi", Namespace: "ci"}, Spec: v1.LeaseSpec{NetworkType: v1.NetworkTypeMultiTenant}},
+	})
+	defer cleanupLeases()
+	now := time.Now()
+	released := metav1.NewTime(now.Add(-time.Hour))
+	l := &LeaseReconciler{Client: newTestClient(&v1.LeaseUsageRecord{
+		ObjectMeta: metav1.ObjectMeta{Name: "record", Namespace: "ci"},
+		Spec:       v1.LeaseUsageRecordSpec{ReleasedAt: released},
+	})}
+
+	records, err := l.listUsageRecords(context.TODO(), now, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	_, sameType := l.forecastInput(v1.NetworkTypeSingleTenant, now, 24*time.Hour, records)
+	// leases and usage records without a network type are single-tenant.
+	if len(sameType) != 2 {
+		t.Errorf("expected the untyped lease and usage record, got %+v", sameType)
+	}
+}
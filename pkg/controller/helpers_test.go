@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// testClient is an in-memory client for the objects the reconcilers read and write. updates of an object
+// fail with failUpdates while it is not zero, to test retries.
+type testClient struct {
+	client.Client
+	objects     map[string]client.Object
+	failUpdates int
+}
+
+func newTestClient(objects ...client.Object) *testClient {
+	c := &testClient{objects: make(map[string]client.Object)}
+	for _, obj := range objects {
+		c.objects[testObjectKey(obj, obj.GetNamespace(), obj.GetName())] = obj.DeepCopyObject().(client.Object)
+	}
+	return c
+}
+
+func testObjectKey(obj runtime.Object, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", reflect.TypeOf(obj).Elem().Name(), namespace, name)
+}
+
+func (c *testClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	stored, ok := c.objects[testObjectKey(obj, key.Namespace, key.Name)]
+	if !ok {
+		return apierrors.NewNotFound(schema.GroupResource{Resource: reflect.TypeOf(obj).Elem().Name()}, key.Name)
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(stored.DeepCopyObject()).Elem())
+	return nil
+}
+
+func (c *testClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	itemType := reflect.ValueOf(list).Elem().FieldByName("Items").Type().Elem()
+	var items []runtime.Object
+	for _, obj := range c.objects {
+		if reflect.TypeOf(obj).Elem() == itemType {
+			items = append(items, obj.DeepCopyObject())
+		}
+	}
+	return meta.SetList(list, items)
+}
+
+func (c *testClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	key := testObjectKey(obj, obj.GetNamespace(), obj.GetName())
+	if _, ok := c.objects[key]; ok {
+		return apierrors.NewAlreadyExists(schema.GroupResource{Resource: reflect.TypeOf(obj).Elem().Name()}, obj.GetName())
+	}
+	c.objects[key] = obj.DeepCopyObject().(client.Object)
+	return nil
+}
+
+func (c *testClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if c.failUpdates > 0 {
+		c.failUpdates--
+		return apierrors.NewConflict(schema.GroupResource{Resource: reflect.TypeOf(obj).Elem().Name()}, obj.GetName(), fmt.Errorf("the object has been modified"))
+	}
+	c.objects[testObjectKey(obj, obj.GetNamespace(), obj.GetName())] = obj.DeepCopyObject().(client.Object)
+	return nil
+}
+
+func (c *testClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	delete(c.objects, testObjectKey(obj, obj.GetNamespace(), obj.GetName()))
+	return nil
+}
+
+func (c *testClient) Status() client.SubResourceWriter {
+	return &testStatusWriter{c: c}
+}
+
+// testStatusWriter updates the objects of a testClient, the status is not stored separately.
+type testStatusWriter struct {
+	client.SubResourceWriter
+	c *testClient
+}
+
+func (w *testStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return w.c.Update(ctx, obj)
+}
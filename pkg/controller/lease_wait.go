@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils/conditions"
+)
+
+var (
+	// leaseWaitBuckets range from a second to about 9 hours.
+	leaseWaitBuckets = prometheus.ExponentialBuckets(1, 2, 16)
+
+	// leaseWaitLabels describe what a lease asked for, which decides how long it may wait.
+	leaseWaitLabels = []string{"networkType", "pools", "requiredPool", "poolSelector"}
+)
+
+// leaseWaitMetricLabels returns the labels of the wait time metrics of a lease.
+func leaseWaitMetricLabels(lease *v1.Lease) prometheus.Labels {
+	pools := lease.Spec.Pools
+	if pools == 0 {
+		pools = 1
+	}
+	return prometheus.Labels{
+		"networkType":  string(lease.Spec.NetworkType),
+		"pools":        strconv.Itoa(pools),
+		"requiredPool": strconv.FormatBool(lease.Spec.RequiredPool != ""),
+		"poolSelector": strconv.FormatBool(len(lease.Spec.PoolSelector) > 0 || len(lease.Spec.PoolSelectorExpressions) > 0),
+	}
+}
+
+// leasePendingSince returns when a lease started waiting for its resources. leases created before the time was
+// recorded use their creation time.
+func leasePendingSince(lease *v1.Lease) time.Time {
+	if lease.Status.PendingSince != nil {
+		return lease.Status.PendingSince.Time
+	}
+	return lease.CreationTimestamp.Time
+}
+
+// setLeasePendingSince records when a new lease started pending.
+func setLeasePendingSince(lease *v1.Lease) {
+	if lease.Status.PendingSince == nil {
+		now := metav1.Now()
+		lease.Status.PendingSince = &now
+	}
+}
+
+// observeLeaseFulfilled records how long a lease fulfilled at now waited. it must be called before the
+// conditions of the lease are set to fulfilled.
+func observeLeaseFulfilled(lease *v1.Lease, firstFulfillment bool, now time.Time) {
+	promLabels := leaseWaitMetricLabels(lease)
+	if firstFulfillment {
+		LeaseTimeToFulfillSeconds.With(promLabels).Observe(now.Sub(leasePendingSince(lease)).Seconds())
+	}
+	if condition := conditions.Get(lease, v1.LeaseConditionTypePartial); condition != nil && condition.Status == v1.ConditionTrue {
+		LeasePartialSeconds.With(promLabels).Observe(now.Sub(condition.LastTransitionTime.Time).Seconds())
+	}
+	if condition := conditions.Get(lease, v1.LeaseConditionTypeDelayed); condition != nil && condition.Status == v1.ConditionTrue {
+		LeaseDelayedSeconds.With(promLabels).Observe(now.Sub(condition.LastTransitionTime.Time).Seconds())
+	}
+}
+
+// markLeaseDelayed sets the Delayed condition of a lease after its first unsuccessful scheduling attempt. the
+// condition stays set until the lease is fulfilled.
+func (l *LeaseReconciler) markLeaseDelayed(ctx context.Context, lease *v1.Lease, attempts int) error {
+	if conditions.IsTrue(lease, v1.LeaseConditionTypeDelayed) {
+		return nil
+	}
+	conditions.Set(lease, conditions.TrueConditionWithReason(
+		v1.LeaseConditionTypeDelayed,
+		v1.ReasonLeaseDelayed,
+		"the lease was not fulfilled after %d scheduling attempts", attempts,
+	))
+	return l.Status().Update(ctx, lease)
+}
+
+// setLeaseReady sets the Ready condition of a lease from its phase. it must be called whenever the phase of the
+// lease changes.
+func setLeaseReady(lease *v1.Lease) {
+	switch {
+	case lease.DeletionTimestamp != nil || lease.Status.Phase == v1.PHASE_RELEASING:
+		conditions.Set(lease, conditions.FalseConditionWithReason(
+			v1.LeaseConditionTypeReady,
+			v1.ReasonLeaseReleasing,
+			v1.ConditionSeverityInfo,
+			"the lease is being released",
+		))
+	case lease.Status.Phase == v1.PHASE_FULFILLED:
+		conditions.Set(lease, conditions.TrueCondition(
+			v1.LeaseConditionTypeReady,
+		))
+	case lease.Status.Phase == v1.PHASE_PARTIAL:
+		conditions.Set(lease, conditions.FalseConditionWithReason(
+			v1.LeaseConditionTypeReady,
+			v1.ReasonLeasePartial,
+			v1.ConditionSeverityInfo,
+			"the lease holds some of its resources",
+		))
+	default:
+		conditions.Set(lease, conditions.FalseConditionWithReason(
+			v1.LeaseConditionTypeReady,
+			v1.ReasonLeasePending,
+			v1.ConditionSeverityInfo,
+			"the lease is waiting for resources",
+		))
+	}
+}
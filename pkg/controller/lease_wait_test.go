@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils/conditions"
+)
+
+func TestLeaseWaitMetricLabels(t *testing.T) {
+	lease := &v1.Lease{Spec: v1.LeaseSpec{NetworkType: v1.NetworkTypeMultiTenant}}
+	labels := leaseWaitMetricLabels(lease)
+	if labels["pools"] != "1" || labels["requiredPool"] != "false" || labels["poolSelector"] != "false" || labels["networkType"] != "multi-tenant" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+
+	lease.Spec.Pools = 3
+	lease.Spec.RequiredPool = "pool-1"
+	lease.Spec.PoolSelector = map[string]string{"region": "us-east"}
+	labels = leaseWaitMetricLabels(lease)
+	if labels["pools"] != "3" || labels["requiredPool"] != "true" || labels["poolSelector"] != "true" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+}
+
+func TestObserveLeaseFulfilled(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := created.Add(10 * time.Minute)
+
+	LeaseTimeToFulfillSeconds.Reset()
+	LeasePartialSeconds.Reset()
+	LeaseDelayedSeconds.Reset()
+	defer func() {
+		LeaseTimeToFulfillSeconds.Reset()
+		LeasePartialSeconds.Reset()
+		LeaseDelayedSeconds.Reset()
+	}()
+
+	lease := &v1.Lease{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}}
+	if pendingSince := leasePendingSince(lease); !pendingSince.Equal(created) {
+		t.Errorf("expected the creation time without pendingSince, got %v", pendingSince)
+	}
+	setLeasePendingSince(lease)
+	lease.Status.PendingSince = &metav1.Time{Time: created.Add(time.Minute)}
+	setLeasePendingSince(lease)
+	if pendingSince := leasePendingSince(lease); !pendingSince.Equal(created.Add(time.Minute)) {
+		t.Errorf("expected pendingSince to be kept, got %v", pendingSince)
+	}
+
+	// a resized lease which was not delayed is not observed.
+	observeLeaseFulfilled(lease, false, now)
+	if count := testutil.CollectAndCount(LeaseTimeToFulfillSeconds) + testutil.CollectAndCount(LeasePartialSeconds) +
+		testutil.CollectAndCount(LeaseDelayedSeconds); count != 0 {
+		t.Errorf("expected no observations, got %d", count)
+	}
+
+	lease.Status.Conditions = []v1.Condition{
+		{Type: v1.LeaseConditionTypePartial, Status: v1.ConditionTrue, LastTransitionTime: metav1.NewTime(created.Add(5 * time.Minute))},
+		{Type: v1.LeaseConditionTypeDelayed, Status: v1.ConditionTrue, LastTransitionTime: metav1.NewTime(created.Add(2 * time.Minute))},
+	}
+	observeLeaseFulfilled(lease, true, now)
+
+	promLabels := leaseWaitMetricLabels(lease)
+	for name, tt := range map[string]struct {
+		histogram *prometheus.HistogramVec
+		expected  float64
+	}{
+		"time to fulfill": {histogram: LeaseTimeToFulfillSeconds, expected: 9 * 60},
+		"partial":         {histogram: LeasePartialSeconds, expected: 5 * 60},
+		"delayed":         {histogram: LeaseDelayedSeconds, expected: 8 * 60},
+	} {
+		metric := &dto.Metric{}
+		if err := tt.histogram.With(promLabels).(prometheus.Metric).Write(metric); err != nil {
+			t.Fatalf("error reading %s: %v", name, err)
+		}
+		if metric.Histogram.GetSampleCount() != 1 || metric.Histogram.GetSampleSum() != tt.expected {
+			t.Errorf("expected one %s observation of %vs, got %d summing to %v", name, tt.expected,
+				metric.Histogram.GetSampleCount(), metric.Histogram.GetSampleSum())
+		}
+	}
+}
+
+func TestSetLeaseReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		phase      v1.Phase
+		deleting   bool
+		wantStatus v1.ConditionStatus
+		wantReason string
+	}{
+		{name: "new", wantStatus: v1.ConditionFalse, wantReason: v1.ReasonLeasePending},
+		{name: "pending", phase: v1.PHASE_PENDING, wantStatus: v1.ConditionFalse, wantReason: v1.ReasonLeasePending},
+		{name: "partial", phase: v1.PHASE_PARTIAL, wantStatus: v1.ConditionFalse, wantReason: v1.ReasonLeasePartial},
+		{name: "fulfilled", phase: v1.PHASE_FULFILLED, wantStatus: v1.ConditionTrue},
+		{name: "releasing", phase: v1.PHASE_RELEASING, wantStatus: v1.ConditionFalse, wantReason: v1.ReasonLeaseReleasing},
+		{name: "deleted while fulfilled", phase: v1.PHASE_FULFILLED, deleting: true, wantStatus: v1.ConditionFalse, wantReason: v1.ReasonLeaseReleasing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{Status: v1.LeaseStatus{Phase: tt.phase}}
+			if tt.deleting {
+				lease.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+			}
+			setLeaseReady(lease)
+			condition := conditions.Get(lease, v1.LeaseConditionTypeReady)
+			if condition == nil || condition.Status != tt.wantStatus || condition.Reason != tt.wantReason {
+				t.Errorf("expected Ready %s with reason %q, got %+v", tt.wantStatus, tt.wantReason, condition)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package controller
 import (
 	"context"
 	"fmt"
-	"log"
 	"math/rand/v2"
 	"path"
 	"sort"
@@ -11,40 +10,41 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	batchv1 "k8s.io/api/batch/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/audit"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler/plugins"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/tracing"
 	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
 	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils/conditions"
 )
 
 const (
-	BoskosIdLabel             = "boskos-lease-id"
-	JobNameLabel              = "job-name"
 	ALLOW_MULTI_TO_USE_SINGLE = false
 
-	// LEASE_PENDING_RETRY_INTERVAL controls how often PENDING leases are retried
-	// when no pools/networks are available
+	// LEASE_PENDING_RETRY_INTERVAL controls how often PENDING and PARTIAL leases are retried
+	// when no pool, network or lease event wakes up the scheduling queue
 	LEASE_PENDING_RETRY_INTERVAL = 30 * time.Second
 
-	// LEASE_PARTIAL_RETRY_INTERVAL controls how often PARTIAL leases are retried
-	// when not all pools/networks are fulfilled
-	LEASE_PARTIAL_RETRY_INTERVAL = 30 * time.Second
-
 	// PROW_JOB_PERIODICAL_URL is used to generate URL for periodical jobs.  Need to supply PROW_JOB_URL_PREFIX_KEY, PROW_GS_BUCKET_KEY, PROW_JOB and PROW_BUILD_ID.
 	PROW_JOB_PERIODICAL_URL = "%vgs/%v/logs/%v/%v"
 
 	// PROW_JOB_PRESUBMIT_URL is used to generate URL for presubmit jobs.  Need to supply PROW_JOB_URL_PREFIX_KEY, PROW_GS_BUCKET_KEY,GIT_ORG, GIT_REPO, GIT_PR, PROW_JOB, and PROW_BUILD_ID.
 	PROW_JOB_PRESUBMIT_URL = "%vgs/%v/pr-logs/pull/%v_%v/%v/%v/%v"
 
-	PROW_JOB_TYPE_KEY       = "prow-job-type"
 	PROW_JOB_KEY            = "prow-job-name"
 	PROW_JOB_URL_PREFIX_KEY = "prow-url-prefix"
 	PROW_GS_BUCKET_KEY      = "prow-gs-bucket"
@@ -79,21 +79,70 @@ type LeaseReconciler struct {
 
 	// Option to allow multi-tenant lease to use single-tenant networks
 	AllowMultiToUseSingle bool
+
+	// Scheduler picks the pools for leases. the default profiles are used if not set.
+	Scheduler *scheduler.Scheduler
+
+	// CleanupJobTemplate is the Job run for a deleted lease holding resources. the resources are released
+	// once the Job finishes. no Job is run if not set.
+	CleanupJobTemplate *batchv1.JobTemplateSpec
+
+	// NetworkQuarantine is how long networks released by a lease are not assigned to other leases. networks
+	// are available again right away if not set.
+	NetworkQuarantine time.Duration
+
+	// NetworkFailureThreshold is the number of leases reporting a network as broken within the
+	// NetworkFailureWindow which disables the network. networks are never disabled if not set.
+	NetworkFailureThreshold int
+
+	// NetworkFailureWindow is how long failure reports of a network count towards disabling it.
+	NetworkFailureWindow time.Duration
+
+	// ForecastWindow is how far back the leases used to forecast lease wait times and pool capacity go.
+	// DEFAULT_FORECAST_WINDOW is used if not set.
+	ForecastWindow time.Duration
+
+	// Audit records the pools and networks allocated and released by leases. nothing is recorded if not set.
+	Audit *audit.Logger
 }
 
 func (l *LeaseReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	if err := ctrl.NewControllerManagedBy(mgr).
-		For(&v1.Lease{}).
-		Complete(l); err != nil {
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&v1.Lease{})
+	if l.CleanupJobTemplate != nil {
+		builder = builder.Owns(&batchv1.Job{})
+	}
+	if err := builder.Complete(l); err != nil {
 		return fmt.Errorf("error setting up controller: %w", err)
 	}
 
 	// Set up API helpers from the manager.
-	l.Client = mgr.GetClient()
+	l.Client = tracing.WrapClient(mgr.GetClient())
 	l.Scheme = mgr.GetScheme()
 	l.Recorder = mgr.GetEventRecorderFor("leases-controller")
 	l.RESTMapper = mgr.GetRESTMapper()
 
+	if l.Scheduler == nil {
+		var err error
+		l.Scheduler, err = plugins.NewScheduler(nil)
+		if err != nil {
+			return fmt.Errorf("error setting up scheduler: %w", err)
+		}
+	}
+
+	if fairShare := l.Scheduler.FairShare(); fairShare != nil {
+		schedulingQueue.SetFairShare(fairShare, leaseTenantFunc(fairShare.Config().TenantKey))
+	}
+	if err := mgr.Add(manager.RunnableFunc(l.runSchedulingLoop)); err != nil {
+		return fmt.Errorf("error setting up scheduling loop: %w", err)
+	}
+	if l.ForecastWindow == 0 {
+		l.ForecastWindow = DEFAULT_FORECAST_WINDOW
+	}
+	if err := mgr.Add(manager.RunnableFunc(l.runForecastLoop)); err != nil {
+		return fmt.Errorf("error setting up forecast loop: %w", err)
+	}
+
 	leases = make(map[string]*v1.Lease)
 	pools = make(map[string]*v1.Pool)
 	networks = make(map[string]*v1.Network)
@@ -117,6 +166,15 @@ func getNetworksForPool(pool *v1.Pool) map[string]*v1.Network {
 	return networksInPool
 }
 
+// getLeaseList returns all known leases.
+func getLeaseList() []*v1.Lease {
+	leaseList := make([]*v1.Lease, 0, len(leases))
+	for _, lease := range leases {
+		leaseList = append(leaseList, lease)
+	}
+	return leaseList
+}
+
 func getNetworkType(network *v1.Network) string {
 	if network.ObjectMeta.Labels != nil {
 		if val, exists := network.ObjectMeta.Labels[v1.NetworkTypeLabel]; exists {
@@ -167,7 +225,7 @@ func (l *LeaseReconciler) getAvailableNetworks(pool *v1.Pool, networkType v1.Net
 			}
 		}
 
-		if getNetworkType(network) != string(networkType) {
+		if getNetworkType(network) != string(networkType) || network.Status.Quarantined || network.Spec.NoSchedule {
 			continue
 		}
 		if !hasOwner {
@@ -188,7 +246,7 @@ func getIBMDatacenterAndPod(server string) (string, string) {
 
 // reconcilePoolStates updates the states of all pools. this ensures we have the most up-to-date state of the pools
 // before we attempt to reconcile any leases. the pool resource statuses are not updated.
-func reconcilePoolStates() []*v1.Pool {
+func reconcilePoolStates(ctx context.Context) []*v1.Pool {
 	var outList []*v1.Pool
 
 	networksInUse := make(map[string]map[string]string)
@@ -201,8 +259,9 @@ func reconcilePoolStates() []*v1.Pool {
 		for _, lease := range leases {
 			for _, ownerRef := range lease.OwnerReferences {
 				if ownerRef.Kind == pool.Kind && ownerRef.Name == pool.Name {
-					vcpus += lease.Spec.VCpus
-					memory += lease.Spec.Memory
+					allocated := utils.GetLeaseAllocatedResources(lease)
+					vcpus += allocated.VCpus
+					memory += allocated.Memory
 					leaseCount++
 
 					var serverNetworks map[string]string
@@ -226,7 +285,7 @@ func reconcilePoolStates() []*v1.Pool {
 
 		overCommitRatio, err := strconv.ParseFloat(pool.Spec.OverCommitRatio, 32)
 		if err != nil {
-			log.Printf("error converting overCommitRatio to float %v setting to 1.0", err)
+			log.FromContext(ctx).Error(err, "invalid overCommitRatio, using 1.0", "Pool", klog.KObj(pool))
 			overCommitRatio = 1.0
 		}
 
@@ -239,12 +298,19 @@ func reconcilePoolStates() []*v1.Pool {
 	}
 
 	for _, pool := range outList {
+		unavailable := make(map[string]bool)
+		for _, network := range getNetworksForPool(pool) {
+			if network.Status.Quarantined || network.Spec.NoSchedule {
+				unavailable[network.Spec.PortGroupName] = true
+			}
+		}
+
 		availableNetworks := 0
 		for _, network := range pool.Spec.Topology.Networks {
 			_, networkName := path.Split(network)
 			dcId := fmt.Sprintf("dcid-%s-%s", pool.Spec.IBMPoolSpec.Datacenter, pool.Spec.IBMPoolSpec.Pod)
 			serverNetworks := networksInUse[dcId]
-			if _, ok := serverNetworks[networkName]; !ok {
+			if _, ok := serverNetworks[networkName]; !ok && !unavailable[networkName] {
 				availableNetworks++
 			}
 		}
@@ -254,67 +320,6 @@ func reconcilePoolStates() []*v1.Pool {
 	return outList
 }
 
-func (l *LeaseReconciler) triggerPoolUpdates(ctx context.Context) {
-	for _, pool := range pools {
-
-		err := l.Client.Get(ctx, types.NamespacedName{Name: pool.Name, Namespace: pool.Namespace}, pool)
-		if err != nil {
-			log.Printf("error getting pool %s: %v", pool.Name, err)
-			continue
-		}
-
-		if pool.Annotations == nil {
-			pool.Annotations = make(map[string]string)
-		}
-
-		pool.Annotations["last-updated"] = time.Now().Format(time.RFC3339)
-		err = l.Client.Update(ctx, pool)
-		if err != nil {
-			log.Printf("error updating pool %s annotations: %v", pool.Name, err)
-		}
-	}
-}
-
-func (l *LeaseReconciler) triggerLeaseUpdates(ctx context.Context, networkType v1.NetworkType) {
-	var oldestLease *v1.Lease
-	for _, lease := range leases {
-		// If networkType doesn't match desired, then skip
-		if lease.Spec.NetworkType != networkType {
-			continue
-		}
-
-		// We only want to force an update for leases that are Pending or Partial
-		if lease.Status.Phase == v1.PHASE_FULFILLED {
-			continue
-		}
-
-		err := l.Client.Get(ctx, types.NamespacedName{Name: lease.Name, Namespace: lease.Namespace}, lease)
-		if err != nil {
-			log.Printf("error getting lease %s: %v", lease.Name, err)
-			continue
-		}
-
-		// If lease creation time is older than oldestLease, make current lease the oldestLease
-		if oldestLease == nil || lease.CreationTimestamp.Before(&oldestLease.CreationTimestamp) {
-			oldestLease = lease
-		}
-
-	}
-
-	if oldestLease != nil {
-		if oldestLease.Annotations == nil {
-			oldestLease.Annotations = make(map[string]string)
-		}
-
-		log.Printf("triggering lease update %v", oldestLease.Name)
-		oldestLease.Annotations["last-updated"] = time.Now().Format(time.RFC3339)
-		err := l.Client.Update(ctx, oldestLease)
-		if err != nil {
-			log.Printf("error updating lease %s annotations: %v", oldestLease.Name, err)
-		}
-	}
-}
-
 func updateLeaseMetrics() {
 	LeaseCounts.Reset()
 	LeaseAgeSeconds.Reset()
@@ -347,6 +352,7 @@ func updateLeaseMetrics() {
 func updateNetworkTypeMetrics() {
 	PoolNetworksAvailableByType.Reset()
 	PoolNetworksTotalByType.Reset()
+	PoolNetworksQuarantined.Reset()
 	NetworkLeaseCount.Reset()
 
 	networkLeaseCount := make(map[string]float64)
@@ -361,6 +367,7 @@ func updateNetworkTypeMetrics() {
 	for _, pool := range pools {
 		totalByType := make(map[string]float64)
 		availByType := make(map[string]float64)
+		quarantined := 0
 
 		networksInPool := getNetworksForPool(pool)
 		for _, network := range networksInPool {
@@ -368,7 +375,9 @@ func updateNetworkTypeMetrics() {
 			totalByType[netType]++
 
 			count := networkLeaseCount[network.Name]
-			if count == 0 {
+			if network.Status.Quarantined {
+				quarantined++
+			} else if count == 0 && !network.Spec.NoSchedule {
 				availByType[netType]++
 			}
 
@@ -389,19 +398,26 @@ func updateNetworkTypeMetrics() {
 			PoolNetworksTotalByType.With(promLabels).Set(total)
 			PoolNetworksAvailableByType.With(promLabels).Set(availByType[netType])
 		}
+		PoolNetworksQuarantined.With(prometheus.Labels{
+			"namespace": pool.Namespace,
+			"pool":      pool.Name,
+		}).Set(float64(quarantined))
 	}
 }
 
 // returns common portgroups that satisfies all known leases for this job. common port groups are scoped
 // to a single vCenter. for multiple vCenters, a network lease for each vCenter will be claimed.
-func (l *LeaseReconciler) getCommonNetworksForLease(lease *v1.Lease) ([]*v1.Network, error) {
+func (l *LeaseReconciler) getCommonNetworksForLease(ctx context.Context, lease *v1.Lease) ([]*v1.Network, error) {
 	var exists bool
 	var leaseID string
 
+	_, span := tracing.Start(ctx, "GetCommonNetworks", tracing.Lease(lease.Namespace, lease.Name)...)
+	defer span.End()
+
 	if lease.Spec.VCpus == 0 && lease.Spec.Memory == 0 {
 		return nil, fmt.Errorf("network-only lease %s", lease.Name)
 	}
-	if leaseID, exists = lease.Labels[BoskosIdLabel]; !exists {
+	if leaseID, exists = lease.Labels[v1.BoskosIdLabel]; !exists {
 		return nil, fmt.Errorf("no lease label found for %s", lease.Name)
 	}
 
@@ -411,7 +427,7 @@ func (l *LeaseReconciler) getCommonNetworksForLease(lease *v1.Lease) ([]*v1.Netw
 			continue
 		}
 
-		if thisLeaseID, exists := _lease.Labels[BoskosIdLabel]; !exists {
+		if thisLeaseID, exists := _lease.Labels[v1.BoskosIdLabel]; !exists {
 			continue
 		} else if thisLeaseID != leaseID {
 			continue
@@ -434,65 +450,14 @@ func (l *LeaseReconciler) getCommonNetworksForLease(lease *v1.Lease) ([]*v1.Netw
 			}
 		}
 		if len(foundNetworks) > 0 {
+			span.SetAttributes(attribute.String("boskos.id", leaseID), attribute.String("sibling", _lease.Name),
+				attribute.Int("networks", len(foundNetworks)))
 			return foundNetworks, nil
 		}
 	}
 	return nil, fmt.Errorf("no common network found for %s", lease.Name)
 }
 
-// shouldLeaseBeDelayed is used to determine if current lease should be delayed.
-func shouldLeaseBeDelayed(lease *v1.Lease) bool {
-	// Iterate through all leases.  Ignore fulfilled.  If we see Partial, block if needing same pool.  If Pending, we
-	// can only run if there are no other partials that are interested in the same pools as current lease.  If there are
-	// no partials, then we need to make sure we have no other leases that are older.  Oldest should go first.
-	if lease.Status.Phase == v1.PHASE_PENDING {
-		for _, curLease := range leases {
-
-			// skip if lease is the target lease
-			if curLease.Name == lease.Name {
-				continue
-			}
-
-			// If the lease type does not match, continue
-			if curLease.Spec.NetworkType != lease.Spec.NetworkType {
-				continue
-			}
-
-			// If lease is multi network and required pool is blank, then we want to make sure the current assigned pool
-			// is checked instead of desired pool.
-			requiredPool := curLease.Spec.RequiredPool
-			if curLease.Spec.Networks > 1 && requiredPool == "" {
-				requiredPool = curLease.Status.Name
-			}
-
-			log.Printf("lease %v pool '%v', current lease %v (%v) pool '%v'", lease.Name, lease.Spec.RequiredPool, curLease.Name, curLease.Status.Phase, requiredPool)
-
-			switch curLease.Status.Phase {
-			case v1.PHASE_FULFILLED:
-				continue
-			case v1.PHASE_PARTIAL:
-				// We want partial to prevent others wanting same pool.
-				if requiredPool == lease.Spec.RequiredPool || lease.Spec.RequiredPool == "" {
-					return true
-				}
-			case v1.PHASE_PENDING:
-				// If leases are both from the same pool, give priority to oldest.  If either of them are blank for the
-				// desired pool, they could be assigned to the same pool depending on availability.  So in this case,
-				// compare them as well.
-				if requiredPool == lease.Spec.RequiredPool || requiredPool == "" || lease.Spec.RequiredPool == "" {
-					leaseTime := curLease.CreationTimestamp
-					if leaseTime.Time.Before(lease.CreationTimestamp.Time) {
-						return true
-					}
-				}
-			default:
-				log.Printf("unknown lease phase %s", curLease.Status.Phase)
-			}
-		}
-	}
-	return false
-}
-
 // doesLeaseContainPortGroup checks to see if the supplied network is part of a portgroup that is already assigned to the lease.
 func doesLeaseContainPortGroup(lease *v1.Lease, pool *v1.Pool, network *v1.Network) bool {
 	poolNetworks := getNetworksForPool(pool)
@@ -513,7 +478,7 @@ func doesLeaseContainPortGroup(lease *v1.Lease, pool *v1.Pool, network *v1.Netwo
 	return false
 }
 
-func generateJobLink(lease *v1.Lease) string {
+func generateJobLink(ctx context.Context, lease *v1.Lease) string {
 	jobURL := ""
 	if lease.Annotations != nil {
 		jobURLPrefix := lease.Annotations[PROW_JOB_URL_PREFIX_KEY]
@@ -526,27 +491,97 @@ func generateJobLink(lease *v1.Lease) string {
 			prowGSBucket = DEFAULT_PROW_GS_BUCKET
 		}
 
-		switch lease.Annotations[PROW_JOB_TYPE_KEY] {
+		switch lease.Annotations[v1.PROW_JOB_TYPE_KEY] {
 		case PERIODICAL_JOB_TYPE:
 			jobURL = fmt.Sprintf(PROW_JOB_PERIODICAL_URL, jobURLPrefix, prowGSBucket, lease.Annotations[PROW_JOB_KEY], lease.Annotations[PROW_BUILD_ID_KEY])
 		case PRESUBMIT_JOB_TYPE:
 			jobURL = fmt.Sprintf(PROW_JOB_PRESUBMIT_URL, jobURLPrefix, prowGSBucket, lease.Annotations[GIT_ORG_KEY], lease.Annotations[GIT_REPO_KEY], lease.Annotations[GIT_PR_KEY], lease.Annotations[PROW_JOB_KEY], lease.Annotations[PROW_BUILD_ID_KEY])
 		default:
-			log.Printf("unknown job type: %v", lease.Annotations[PROW_JOB_TYPE_KEY])
+			log.FromContext(ctx).V(2).Info("unknown job type, no job link", "jobType", lease.Annotations[v1.PROW_JOB_TYPE_KEY])
 		}
 	} else {
-		log.Printf("Unable to generate job url for lease %v due to missing annotations", lease.Name)
+		log.FromContext(ctx).V(2).Info("no job annotations, no job link")
 	}
 	return jobURL
 }
 
+// releaseLeasePools removes the pool and network owner references of a lease which is stuck with some of its
+// pools, so it goes back to PENDING and tries again with different pools. persisted are the owner references
+// the lease was scheduled with.
+func (l *LeaseReconciler) releaseLeasePools(ctx context.Context, lease *v1.Lease, persisted []metav1.OwnerReference, assignedPools int, reason string) error {
+	logger := log.FromContext(ctx)
+	logger.Info("lease is stuck at PARTIAL, releasing its pools to retry", "reason", reason, "pools", assignedPools)
+
+	// Remove all pool AND network owner references to release them
+	// Networks are tied to pools, so if we're releasing pools, we should also release their networks
+	// to avoid resource leaks (networks staying locked to a lease that no longer owns the pools)
+	newOwnerRefs := []metav1.OwnerReference{}
+	for _, ref := range lease.OwnerReferences {
+		if ref.Kind != "Pool" && ref.Kind != "Network" {
+			newOwnerRefs = append(newOwnerRefs, ref)
+		}
+	}
+	lease.OwnerReferences = newOwnerRefs
+	setLeaseTraceID(ctx, lease)
+
+	// First update the lease metadata (OwnerReferences)
+	if err := l.Client.Update(ctx, lease); err != nil {
+		logger.Error(err, "unable to release the pools of the lease")
+		return err
+	}
+	l.auditResources(ctx, lease, persisted, lease.OwnerReferences, fmt.Sprintf("released to retry after %s", reason))
+
+	// Then update the status (conditions)
+	conditions.Set(lease, conditions.FalseConditionWithReason(
+		v1.LeaseConditionTypeFulfilled,
+		v1.ReasonLeaseNoPool,
+		v1.ConditionSeverityWarning,
+		fmt.Sprintf("Released %d pools due to %s constraint, retrying", assignedPools, reason),
+	))
+
+	if err := l.Client.Status().Update(ctx, lease); err != nil {
+		logger.Error(err, "unable to update the status of the lease")
+		return err
+	}
+
+	updateLeaseMetrics()
+	logger.V(2).Info("lease released its pools and is PENDING")
+	return nil
+}
+
+// setLeasePendingNoPool records why no pool could be assigned to the lease.
+func (l *LeaseReconciler) setLeasePendingNoPool(ctx context.Context, lease *v1.Lease, err error) error {
+	conditions.Set(lease, conditions.FalseConditionWithReason(
+		v1.LeaseConditionTypeFulfilled,
+		v1.ReasonLeaseNoPool,
+		v1.ConditionSeverityWarning,
+		err.Error(),
+	))
+
+	if uErr := l.Client.Status().Update(ctx, lease); uErr != nil {
+		log.FromContext(ctx).Error(uErr, "unable to update the status of the lease")
+	}
+
+	// update metrics in case this is the first status update.
+	updateLeaseMetrics()
+	log.FromContext(ctx).V(2).Info("lease is PENDING, no pool available", "reason", err.Error())
+	return nil
+}
+
 func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	var err error
+	ctx, span := tracing.Start(ctx, "ReconcileLease", tracing.Lease(req.Namespace, req.Name)...)
+	defer span.End()
+	result, err := l.reconcile(ctx, req)
+	tracing.RecordError(span, err)
+	return result, err
+}
+
+func (l *LeaseReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	reconcileLock.Lock()
 	defer reconcileLock.Unlock()
 
-	log.Print("Reconciling lease")
-	defer log.Print("Finished reconciling lease")
+	logger := log.FromContext(ctx)
+	logger.V(4).Info("reconciling lease")
 
 	leaseKey := fmt.Sprintf("%s/%s", req.Namespace, req.Name)
 	// Fetch the Lease instance.
@@ -557,6 +592,7 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 	if len(lease.Status.Phase) == 0 {
 		lease.Status.Phase = v1.PHASE_PENDING
+		setLeasePendingSince(lease)
 		LeaseTransitionsTotal.With(prometheus.Labels{
 			"namespace":   lease.Namespace,
 			"networkType": string(lease.Spec.NetworkType),
@@ -585,8 +621,8 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		poolPending.Topology.Networks = append(poolPending.Topology.Networks, "/pending/network/pending")
 
 		// Add the job link / info to status field.
-		lease.Status.JobLink = generateJobLink(lease)
-		log.Printf("generated job url '%v' for lease '%v'", lease.Status.JobLink, lease.Name)
+		lease.Status.JobLink = generateJobLink(ctx, lease)
+		logger.V(2).Info("generated job link", "jobLink", lease.Status.JobLink)
 
 		conditions.Set(lease, conditions.FalseCondition(
 			v1.LeaseConditionTypeFulfilled,
@@ -600,6 +636,7 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		conditions.Set(lease, conditions.FalseCondition(
 			v1.LeaseConditionTypePartial,
 		))
+		setLeaseReady(lease)
 
 		if err := l.Status().Update(ctx, lease); err != nil {
 			return ctrl.Result{}, fmt.Errorf("unable to set the initial status on the lease %s: %w", lease.Name, err)
@@ -607,7 +644,7 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 
 	if lease.Finalizers == nil {
-		log.Print("setting finalizer on lease")
+		logger.V(2).Info("setting finalizer on lease")
 		lease.Finalizers = []string{v1.LeaseFinalizer}
 		err := l.Client.Update(ctx, lease)
 		if err != nil {
@@ -618,8 +655,27 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	promLabels := make(prometheus.Labels)
 	promLabels["namespace"] = req.Namespace
 
+	if err := l.reportBrokenNetworks(ctx, lease); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if lease.DeletionTimestamp != nil {
-		log.Printf("lease %s is being deleted at %s", lease.Name, lease.DeletionTimestamp.String())
+		logger.Info("lease is being deleted", "deletionTimestamp", lease.DeletionTimestamp)
+
+		// the resources of the lease stay blocked while the holder cleans up.
+		requeueAfter, err := l.releaseLease(ctx, lease)
+		if err != nil || requeueAfter > 0 {
+			return ctrl.Result{RequeueAfter: requeueAfter}, err
+		}
+		if err := l.quarantineNetworks(ctx, lease, getLeaseNetworkNames(lease)); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := l.recordLeaseOutcome(ctx, lease); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := l.recordLeaseUsage(ctx, lease); err != nil {
+			return ctrl.Result{}, err
+		}
 
 		// preserve finalizers not associated with VCM
 		if lease.Finalizers != nil {
@@ -635,10 +691,12 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			lease.Finalizers = preservedFinalizers
 		}
 
-		err := l.Update(ctx, lease)
+		held := resourceRefs(lease.OwnerReferences)
+		err = l.Update(ctx, lease)
 		if err != nil {
 			return ctrl.Result{}, fmt.Errorf("error dropping finalizers from lease: %w", err)
 		}
+		l.auditResources(ctx, lease, held, nil, releaseReasonDeleted)
 
 		if ownRef := utils.DoesLeaseHavePool(lease); ownRef != nil {
 			promLabels["pool"] = ownRef.Name
@@ -648,26 +706,62 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		if len(promLabels) >= 2 {
 			LeasesInUse.With(promLabels).Dec()
 		}
-		reconcilePoolStates()
-		l.triggerPoolUpdates(ctx)
-		l.triggerLeaseUpdates(ctx, lease.Spec.NetworkType)
+		reconcilePoolStates(ctx)
 		updateLeaseMetrics()
+		updateFairShare(l.Scheduler.FairShare())
+
+		// the resources of the lease are free, so leases waiting for resources may now be schedulable.
+		schedulingQueue.Delete(leaseKey)
+		logger.Info("lease deleted, retrying unschedulable leases")
+		schedulingQueue.MoveAllToActiveQueue()
+		updateSchedulingQueueMetrics()
 		return ctrl.Result{}, nil
 	}
 
 	leases[leaseKey] = lease
 
-	if lease.Status.Phase == v1.PHASE_FULFILLED {
-		log.Print("lease is already fulfilled")
+	if lease.Status.Phase == v1.PHASE_FULFILLED && lease.Status.Allocated == nil {
+		// leases fulfilled before resizing was supported hold the resources in their spec.
+		allocated := utils.GetLeaseAllocatedResources(lease)
+		lease.Status.Allocated = &allocated
+		if err := l.Status().Update(ctx, lease); err != nil {
+			return ctrl.Result{}, fmt.Errorf("unable to record the allocated resources of lease %s: %w", lease.Name, err)
+		}
+	}
+
+	if lease.Status.Phase == v1.PHASE_FULFILLED && !leaseNeedsResize(lease) {
+		if conditions.IsTrue(lease, v1.LeaseConditionTypeResizing) {
+			// the spec went back to the resources the lease holds before it could grow.
+			conditions.Set(lease, conditions.FalseCondition(
+				v1.LeaseConditionTypeResizing,
+			))
+			if err := l.Status().Update(ctx, lease); err != nil {
+				return ctrl.Result{}, fmt.Errorf("unable to clear the Resizing condition of lease %s: %w", lease.Name, err)
+			}
+		}
+		logger.V(4).Info("lease is already fulfilled")
 		return ctrl.Result{}, nil
 	}
 
-	updatedPools := reconcilePoolStates()
+	// pools are assigned, and fulfilled leases resized, by the scheduling loop, one lease at a time in queue order.
+	schedulingQueue.Add(lease)
+	updateSchedulingQueueMetrics()
+	return ctrl.Result{}, nil
+}
+
+// scheduleLease assigns pools and networks to a lease and updates its status. a lease which is not FULFILLED
+// afterwards is requeued by the scheduling loop. persisted are the owner references of the lease before it was
+// scheduled, the networks missing from the lease were released by resizeLease.
+func (l *LeaseReconciler) scheduleLease(ctx context.Context, lease *v1.Lease, persisted []metav1.OwnerReference) error {
+	var err error
+	logger := log.FromContext(ctx)
+	updatedPools := reconcilePoolStates(ctx)
 
 	// TODO: How often are we hitting this and can we remove this and just use the one above?
 	if len(lease.Status.Phase) == 0 {
-		log.Printf("setting lease %s status to %s", lease.Name, v1.PHASE_PENDING)
+		logger.V(2).Info("setting lease phase", "phase", v1.PHASE_PENDING)
 		lease.Status.Phase = v1.PHASE_PENDING
+		setLeasePendingSince(lease)
 		LeaseTransitionsTotal.With(prometheus.Labels{
 			"namespace":   lease.Namespace,
 			"networkType": string(lease.Spec.NetworkType),
@@ -686,55 +780,20 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		conditions.Set(lease, conditions.FalseCondition(
 			v1.LeaseConditionTypePartial,
 		))
+		setLeaseReady(lease)
 	}
 
-	jobName, exists := lease.Labels[JobNameLabel]
+	jobName, exists := lease.Labels[v1.JobNameLabel]
 	if !exists {
 		jobName = "Unknown Job"
 	}
-	log.Printf("processing lease %v [%v] with Phase %v", lease.Name, jobName, lease.Status.Phase)
+	logger.V(2).Info("scheduling lease", "job", jobName, "phase", lease.Status.Phase)
 
 	// Set default network type
 	if len(lease.Spec.NetworkType) == 0 {
 		lease.Spec.NetworkType = v1.NetworkTypeSingleTenant
 	}
 
-	// We need to check to see if any other leases are waiting for resources that this lease may want.  We need to
-	// ensure that older leases get to finish getting their requests fulfilled before their Ci jobs timeout.
-	if shouldLeaseBeDelayed(lease) {
-		log.Printf("=========== lease %v is being delayed due to presence of higher priority leases ===========", lease.Name)
-		LeaseDelaysTotal.With(prometheus.Labels{
-			"namespace":   lease.Namespace,
-			"networkType": string(lease.Spec.NetworkType),
-		}).Inc()
-
-		conditions.Set(lease, conditions.TrueCondition(
-			v1.LeaseConditionTypeDelayed,
-		))
-		conditions.Set(lease, conditions.FalseConditionWithReason(
-			v1.LeaseConditionTypeFulfilled,
-			v1.ReasonLeaseDelayed,
-			v1.ConditionSeverityInfo,
-			"lease is being delayed due to presence of higher priority leases",
-		))
-
-		if err := l.Client.Status().Update(ctx, lease); err != nil {
-			return reconcile.Result{}, err
-		}
-
-		// Since we are delaying this lease, let's force the oldest lease to be updated to see if it can now be fulfilled.
-		l.triggerLeaseUpdates(ctx, lease.Spec.NetworkType)
-		updateLeaseMetrics()
-
-		log.Printf("lease %s is DELAYED - requeuing in %v", lease.Name, LEASE_PENDING_RETRY_INTERVAL)
-		return ctrl.Result{RequeueAfter: LEASE_PENDING_RETRY_INTERVAL}, nil
-	}
-
-	// Since lease is not delayed, clear the condition
-	conditions.Set(lease, conditions.FalseCondition(
-		v1.LeaseConditionTypeDelayed,
-	))
-
 	// Determine how many pools are required
 	requiredPools := lease.Spec.Pools
 	if requiredPools == 0 {
@@ -748,20 +807,24 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	for _, poolRef := range assignedPoolRefs {
 		pool := &v1.Pool{}
 		err = l.Get(ctx, types.NamespacedName{
-			Namespace: req.Namespace,
+			Namespace: lease.Namespace,
 			Name:      poolRef.Name,
 		}, pool)
 		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("error getting assigned pool %s: %v", poolRef.Name, err)
+			return fmt.Errorf("error getting assigned pool %s: %v", poolRef.Name, err)
 		}
 		assignedPools = append(assignedPools, pool)
 		assignedPoolNames[pool.Name] = true
 	}
 
 	// Assign additional pools if needed
-	log.Printf("Lease %s requires %d pools, currently has %d pools assigned", lease.Name, requiredPools, len(assignedPools))
-	for len(assignedPools) < requiredPools {
-		// Filter out already assigned pools
+	logger.V(2).Info("assigning pools", "requiredPools", requiredPools, "assignedPools", len(assignedPools))
+	framework := l.Scheduler.ForLease(lease)
+
+	// Multi-pool leases are placed as a whole so the vCenter cap, common VLANs and topology spread are
+	// satisfied by the complete set of pools. The remaining pool of a single-pool lease is picked directly.
+	var placementVLANs map[string]bool
+	if requiredPools-len(assignedPools) > 1 {
 		availablePools := make([]*v1.Pool, 0)
 		for _, p := range updatedPools {
 			if !assignedPoolNames[p.Name] {
@@ -769,182 +832,41 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			}
 		}
 
-		// Enforce the vCenters cap with smart filtering:
-		// 1. If cap reached: only allow vCenters already in use
-		// 2. If approaching cap with remaining pools > remaining slots: require vCenters with multiple pools
-		// 3. Initial selection (no pools assigned): pre-filter to avoid low-capacity vCenters
-		var excludedVCenters map[string]bool
-		if lease.Spec.VCenters > 0 {
-			vcentersInUse := utils.GetVCentersInUse(assignedPools)
-			remainingVCenterSlots := lease.Spec.VCenters - len(vcentersInUse)
-			remainingPools := requiredPools - len(assignedPools)
-
-			log.Printf("Lease %s: cap=%d, using=%d, remaining_slots=%d, remaining_pools=%d",
-				lease.Name, lease.Spec.VCenters, len(vcentersInUse), remainingVCenterSlots, remainingPools)
-
-			if len(vcentersInUse) >= lease.Spec.VCenters {
-				// Cap reached — only allow pools from vCenters already in use
-				excludedVCenters = make(map[string]bool)
-				for _, p := range availablePools {
-					srv := p.Spec.Server
-					if srv != "" && !vcentersInUse[srv] {
-						excludedVCenters[srv] = true
-					}
-				}
-				log.Printf("Lease %s: vCenter cap reached, only allowing vCenters in use", lease.Name)
-			} else if remainingVCenterSlots > 0 && remainingPools > remainingVCenterSlots {
-				// We need multiple pools per remaining vCenter slot
-				// Apply dynamic filtering: exclude vCenters that don't have enough pools
-				minPoolsPerVCenter := (remainingPools-1)/remainingVCenterSlots + 1
-
-				log.Printf("Lease %s: need %d pools from %d remaining slots, min %d pools per vCenter required",
-					lease.Name, remainingPools, remainingVCenterSlots, minPoolsPerVCenter)
-
-				// Count fitting pools per vCenter
-				fittingPools, _ := utils.GetFittingPools(lease, availablePools, nil)
-				fittingPoolsPerVCenter := make(map[string]int)
-				for _, p := range fittingPools {
-					if p.Spec.Server != "" && !vcentersInUse[p.Spec.Server] {
-						fittingPoolsPerVCenter[p.Spec.Server]++
-					}
-				}
-
-				// Exclude vCenters (not already in use) that don't have enough pools
-				excludedVCenters = make(map[string]bool)
-				for _, p := range availablePools {
-					srv := p.Spec.Server
-					if srv != "" && !vcentersInUse[srv] {
-						if fittingPoolsPerVCenter[srv] < minPoolsPerVCenter {
-							excludedVCenters[srv] = true
-						}
-					}
-				}
-
-				if len(excludedVCenters) > 0 {
-					log.Printf("Lease %s: excluded %d vCenters with < %d pools (dynamic filtering)",
-						lease.Name, len(excludedVCenters), minPoolsPerVCenter)
-				}
-			} else if lease.Spec.VCenters < requiredPools && len(assignedPools) == 0 {
-				// Special case: if we need more pools than vCenters allowed (VCenters < Pools),
-				// and we haven't assigned any pools yet, we must ensure we only pick from
-				// vCenters that can participate in a valid combination to fulfill the lease.
-				//
-				// Use greedy selection: sort vCenters by pool count descending, check if
-				// the top VCenters vCenters have enough pools total. Only exclude vCenters
-				// that cannot participate in any valid combination.
-
-				// Reuse GetFittingPools to apply consistent filtering logic
-				fittingPools, _ := utils.GetFittingPools(lease, availablePools, nil)
-
-				// Group fitting pools by vCenter and count them
-				fittingPoolsPerVCenter := make(map[string][]*v1.Pool)
-				for _, p := range fittingPools {
-					if p.Spec.Server != "" {
-						fittingPoolsPerVCenter[p.Spec.Server] = append(fittingPoolsPerVCenter[p.Spec.Server], p)
-					}
-				}
-
-				// Build sorted list of vCenters by pool count (descending)
-				type vcenterPoolCount struct {
-					server string
-					count  int
-				}
-				vcenterCounts := make([]vcenterPoolCount, 0, len(fittingPoolsPerVCenter))
-				for server, pools := range fittingPoolsPerVCenter {
-					vcenterCounts = append(vcenterCounts, vcenterPoolCount{server: server, count: len(pools)})
-				}
-				sort.Slice(vcenterCounts, func(i, j int) bool {
-					return vcenterCounts[i].count > vcenterCounts[j].count
-				})
-
-				// Calculate total pools available from top VCenters vCenters
-				topVCentersPoolCount := 0
-				numVCentersToUse := lease.Spec.VCenters
-				if numVCentersToUse > len(vcenterCounts) {
-					numVCentersToUse = len(vcenterCounts)
-				}
-				for i := 0; i < numVCentersToUse; i++ {
-					topVCentersPoolCount += vcenterCounts[i].count
-				}
-
-				// If the top VCenters vCenters don't have enough pools total, we can't fulfill
-				// In this case, keep all vCenters (no exclusions) and let the normal flow handle it
-				if topVCentersPoolCount < requiredPools {
-					log.Printf("Lease %s: top %d vCenters only have %d pools total, need %d - no exclusions applied",
-						lease.Name, numVCentersToUse, topVCentersPoolCount, requiredPools)
-				} else {
-					// We can potentially fulfill. Use a hybrid strategy that balances:
-					// 1. Allowing valid combinations (maintenance scenario)
-					// 2. Preventing greedy trap (low-pool vCenters exhausting cap)
-
-					// Find minimum vCenters needed from the top
-					cumulativePoolCount := 0
-					minVCentersNeeded := 0
-					for i := 0; i < len(vcenterCounts); i++ {
-						cumulativePoolCount += vcenterCounts[i].count
-						minVCentersNeeded++
-						if cumulativePoolCount >= requiredPools {
-							break
-						}
-					}
-
-					excludedVCenters = make(map[string]bool)
-
-					if minVCentersNeeded < lease.Spec.VCenters {
-						// We have slack (min < cap): can be selective to avoid greedy trap
-						// Keep top minVCentersNeeded, apply ceiling filter to remainder
-						ceiling := (requiredPools-1)/lease.Spec.VCenters + 1
-
-						for i := minVCentersNeeded; i < len(vcenterCounts); i++ {
-							if vcenterCounts[i].count < ceiling {
-								excludedVCenters[vcenterCounts[i].server] = true
-							}
-						}
-
-						if len(excludedVCenters) > 0 {
-							log.Printf("Lease %s: excluded %d low-pool vCenters (need %d pools from %d vCenters, top %d sufficient)",
-								lease.Name, len(excludedVCenters), requiredPools, lease.Spec.VCenters, minVCentersNeeded)
-						}
-					} else {
-						// No slack (min >= cap): use all vCenter slots, apply combination-aware filtering
-						// This handles maintenance scenarios where we need flexibility
-						for idx, current := range vcenterCounts {
-							// For this vCenter, find the best (VCenters-1) OTHER vCenters
-							bestOthersSum := 0
-							othersCollected := 0
-							othersNeeded := lease.Spec.VCenters - 1
-
-							for i := 0; i < len(vcenterCounts) && othersCollected < othersNeeded; i++ {
-								if i != idx {
-									bestOthersSum += vcenterCounts[i].count
-									othersCollected++
-								}
-							}
+		placement, err := l.placePools(ctx, framework, lease, assignedPools, availablePools)
+		if err != nil {
+			logger.V(2).Info("no placement for the pools of the lease", "reason", err.Error())
+			if len(assignedPools) > 0 {
+				return l.releaseLeasePools(ctx, lease, persisted, len(assignedPools), "pool placement")
+			}
+			return l.setLeasePendingNoPool(ctx, lease, err)
+		}
 
-							// Can this vCenter + best others reach the requirement?
-							if current.count+bestOthersSum < requiredPools {
-								excludedVCenters[current.server] = true
-							}
-						}
+		placementVLANs = make(map[string]bool)
+		for _, vlan := range placement.VLANs {
+			placementVLANs[vlan] = true
+		}
+		for _, pool := range placement.Pools {
+			assignedPools = append(assignedPools, pool)
+			assignedPoolNames[pool.Name] = true
+			logger.V(2).Info("assigned pool", "Pool", klog.KObj(pool), "assignedPools", len(assignedPools), "requiredPools", requiredPools)
+		}
+	}
 
-						if len(excludedVCenters) > 0 {
-							log.Printf("Lease %s: excluded %d vCenters that cannot combine to reach requirement (need %d pools from %d vCenters)",
-								lease.Name, len(excludedVCenters), requiredPools, lease.Spec.VCenters)
-						}
-					}
-				}
+	for len(assignedPools) < requiredPools {
+		// Filter out already assigned pools
+		availablePools := make([]*v1.Pool, 0)
+		for _, p := range updatedPools {
+			if !assignedPoolNames[p.Name] {
+				availablePools = append(availablePools, p)
 			}
 		}
 
-		log.Printf("Attempting to assign pool %d/%d for lease %s, %d pools available after filtering", len(assignedPools)+1, requiredPools, lease.Name, len(availablePools))
-		log.Printf("Lease %s currently has %d owner references before GetPoolWithStrategy", lease.Name, len(lease.OwnerReferences))
-		if len(excludedVCenters) > 0 {
-			log.Printf("Lease %s: %d vCenters excluded from pool selection", lease.Name, len(excludedVCenters))
-		}
+		logger.V(4).Info("scheduling a pool", "pool", len(assignedPools)+1, "requiredPools", requiredPools, "availablePools", len(availablePools), "ownerReferences", len(lease.OwnerReferences))
 
-		pool, err := utils.GetPoolWithStrategy(lease, availablePools, v1.RESOURCE_ALLOCATION_STRATEGY_UNDERUTILIZED, excludedVCenters)
+		state := scheduler.NewCycleState(lease, assignedPools, getLeaseList())
+		pool, err := framework.SchedulePool(ctx, state, lease, availablePools)
 		if err != nil {
-			log.Printf("GetPoolWithStrategy error for lease %s: %v", lease.Name, err)
+			logger.V(2).Info("no pool for the lease", "profile", framework.ProfileName(), "reason", err.Error())
 
 			// If we already have some pools assigned but can't get more due to vCenter filtering constraints,
 			// we should release what we have and go back to PENDING to try again later with different pools
@@ -955,89 +877,30 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 				// 1. Cap reached: using all allowed vCenters
 				// 2. Dynamic filtering: excluded remaining vCenters due to insufficient pool count
 				capReached := len(vcentersInUse) >= lease.Spec.VCenters
-				dynamicFilteringApplied := len(excludedVCenters) > 0 && !capReached
+				dynamicFilteringApplied := len(state.ExcludedVCenters) > 0 && !capReached
 
 				if capReached || dynamicFilteringApplied {
 					reason := "vCenter cap"
 					if dynamicFilteringApplied {
 						reason = "dynamic vCenter filtering"
 					}
-					log.Printf("Lease %s: stuck at PARTIAL due to %s - releasing %d assigned pools to retry",
-						lease.Name, reason, len(assignedPools))
-
-					// Remove all pool AND network owner references to release them
-					// Networks are tied to pools, so if we're releasing pools, we should also release their networks
-					// to avoid resource leaks (networks staying locked to a lease that no longer owns the pools)
-					newOwnerRefs := []metav1.OwnerReference{}
-					for _, ref := range lease.OwnerReferences {
-						if ref.Kind != "Pool" && ref.Kind != "Network" {
-							newOwnerRefs = append(newOwnerRefs, ref)
-						}
-					}
-					lease.OwnerReferences = newOwnerRefs
-
-					// First update the lease metadata (OwnerReferences)
-					if err := l.Client.Update(ctx, lease); err != nil {
-						log.Printf("Failed to update lease metadata (release pools): %v", err)
-						return ctrl.Result{}, err
-					}
-
-					// Then update the status (conditions)
-					conditions.Set(lease, conditions.FalseConditionWithReason(
-						v1.LeaseConditionTypeFulfilled,
-						v1.ReasonLeaseNoPool,
-						v1.ConditionSeverityWarning,
-						fmt.Sprintf("Released %d pools due to %s constraint, retrying", len(assignedPools), reason),
-					))
-
-					if err := l.Client.Status().Update(ctx, lease); err != nil {
-						log.Printf("Failed to update lease status (set PENDING): %v", err)
-						return ctrl.Result{}, err
-					}
-
-					updateLeaseMetrics()
-					log.Printf("lease %s released pools and is PENDING - requeuing in %v", lease.Name, LEASE_PENDING_RETRY_INTERVAL)
-					return ctrl.Result{RequeueAfter: LEASE_PENDING_RETRY_INTERVAL}, nil
+					return l.releaseLeasePools(ctx, lease, persisted, len(assignedPools), reason)
 				}
 
 				// Otherwise just mark as partial (not vCenter filtering related)
-				log.Printf("lease %s needs %d pools but only %d available (insufficient pool resources, not vCenter filtering)", lease.Name, requiredPools, len(assignedPools))
+				logger.V(2).Info("not enough pools with the resources of the lease", "requiredPools", requiredPools, "assignedPools", len(assignedPools))
 				break
 			}
 
-			conditions.Set(lease, conditions.FalseConditionWithReason(
-				v1.LeaseConditionTypeFulfilled,
-				v1.ReasonLeaseNoPool,
-				v1.ConditionSeverityWarning,
-				err.Error(),
-			))
-
-			if uErr := l.Client.Status().Update(ctx, lease); uErr != nil {
-				log.Printf("unable to update lease: %v", uErr)
-			}
-
-			// since we do not trigger lease update, we still need to update metrics in case first status update.
-			updateLeaseMetrics()
-			log.Printf("lease %s is PENDING, no pool available - requeuing in %v", lease.Name, LEASE_PENDING_RETRY_INTERVAL)
-			return ctrl.Result{RequeueAfter: LEASE_PENDING_RETRY_INTERVAL}, nil
+			return l.setLeasePendingNoPool(ctx, lease, err)
 		}
 
-		log.Printf("Lease %s now has %d owner references after GetPoolWithStrategy", lease.Name, len(lease.OwnerReferences))
 		assignedPools = append(assignedPools, pool)
 		assignedPoolNames[pool.Name] = true
-		log.Printf("assigned pool %s to lease %s (%d/%d pools)", pool.Name, lease.Name, len(assignedPools), requiredPools)
-
-		// Log all pool owner references
-		poolCount := 0
-		for _, ref := range lease.OwnerReferences {
-			if ref.Kind == "Pool" {
-				poolCount++
-				log.Printf("  Pool owner ref %d: %s", poolCount, ref.Name)
-			}
-		}
+		logger.V(2).Info("assigned pool", "Pool", klog.KObj(pool), "assignedPools", len(assignedPools), "requiredPools", requiredPools)
 	}
 
-	log.Printf("Finished assigning pools for lease %s: %d pools assigned, %d total owner references", lease.Name, len(assignedPools), len(lease.OwnerReferences))
+	logger.V(4).Info("finished assigning pools", "pools", utils.GetLeasePoolRefs(lease), "ownerReferences", len(lease.OwnerReferences))
 
 	// Use the first pool for backward compatibility with status fields
 	pool := assignedPools[0]
@@ -1046,7 +909,6 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	pool.Spec.FailureDomainSpec.DeepCopyInto(&lease.Status.FailureDomainSpec)
 	// Networks will be populated later
 	lease.Status.Topology.Networks = []string{}
-	log.Printf("Set backward compatibility fields from first pool %s", pool.Name)
 
 	// Initialize EnvVarsMap if needed
 	if lease.Status.EnvVarsMap == nil {
@@ -1074,7 +936,7 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		}
 	}
 
-	log.Printf("Lease %v needs %d pools × %d networks = %d total networks", lease.Name, requiredPools, networksPerPool, totalNetworksNeeded)
+	logger.V(4).Info("assigning networks", "requiredPools", requiredPools, "networksPerPool", networksPerPool, "networks", totalNetworksNeeded)
 
 	// Process each pool and assign networks
 	for poolIdx, currentPool := range assignedPools {
@@ -1091,15 +953,16 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			}
 		}
 
-		log.Printf("Pool %v (%d/%d) currently has %d/%d networks", currentPool.Name, poolIdx+1, requiredPools, poolNetworkCount, networksPerPool)
+		logger.V(4).Info("networks of pool", "Pool", klog.KObj(currentPool), "networks", poolNetworkCount, "networksPerPool", networksPerPool)
 
 		// Assign networks to this pool if needed
 		if poolNetworkCount < networksPerPool {
-			log.Printf("Searching for networks to assign to pool %v for lease %v", currentPool.Name, lease.Name)
+			networksCtx, span := tracing.Start(ctx, "SelectNetworks", tracing.PoolNameKey.String(currentPool.Name),
+				attribute.Int("networks.required", networksPerPool))
 
 			// First, try to get common networks (for cross-pool communication)
 			var availableNetworks []*v1.Network
-			availableNetworks, err = l.getCommonNetworksForLease(lease)
+			availableNetworks, err = l.getCommonNetworksForLease(networksCtx, lease)
 			if err == nil {
 				// Filter common networks to only those in the current pool's topology.
 				// Sibling leases may be on different pools whose networks don't exist here.
@@ -1110,31 +973,38 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 					}
 				}
 				if len(poolFiltered) == 0 {
-					log.Printf("common networks not available in pool %s, falling back to pool-local networks", currentPool.Name)
+					logger.V(2).Info("common networks not available in pool, falling back to pool-local networks", "Pool", klog.KObj(currentPool))
 					err = fmt.Errorf("no common networks in pool %s", currentPool.Name)
 				} else {
 					availableNetworks = poolFiltered
 				}
 			}
 			if err != nil {
-				log.Printf("error getting common network for lease, will attempt to allocate new networks: %v", err)
+				logger.V(2).Info("no common network for the lease, allocating new networks", "Pool", klog.KObj(currentPool), "reason", err.Error())
 
 				availableNetworks = l.getAvailableNetworks(currentPool, lease.Spec.NetworkType)
 
 				// We can allow multi-tenant leases to use single-tenant networks if there are not enough multi-tenant leases.
 				if l.AllowMultiToUseSingle && lease.Spec.NetworkType == v1.NetworkTypeMultiTenant {
-					log.Println("Adding single tenant networks to multi-tenant collection...")
+					logger.V(4).Info("adding single-tenant networks to the multi-tenant networks")
 					availableNetworks = append(availableNetworks, l.getAvailableNetworks(currentPool, v1.NetworkTypeSingleTenant)...)
 				}
 			}
 
-			log.Printf("Found %d available networks for pool %s", len(availableNetworks), currentPool.Name)
+			logger.V(4).Info("found available networks", "Pool", klog.KObj(currentPool), "networks", len(availableNetworks))
 
 			// shuffle available networks
 			rand.Shuffle(len(availableNetworks), func(i, j int) {
 				availableNetworks[i], availableNetworks[j] = availableNetworks[j], availableNetworks[i]
 			})
 
+			// prefer the VLANs the pool placement found to be available in every pool
+			if len(placementVLANs) > 0 {
+				sort.SliceStable(availableNetworks, func(i, j int) bool {
+					return placementVLANs[availableNetworks[i].Spec.VlanId] && !placementVLANs[availableNetworks[j].Spec.VlanId]
+				})
+			}
+
 			// For the first pool, we assign networks and track their VLANs
 			// For subsequent pools, we try to match VLANs from the first pool
 			if poolIdx == 0 {
@@ -1150,7 +1020,7 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 						})
 						vlanToNetworks[network.Spec.VlanId] = append(vlanToNetworks[network.Spec.VlanId], network.Name)
 						poolNetworkCount++
-						log.Printf("Assigned network %s (VLAN %s) to pool %s", network.Name, network.Spec.VlanId, currentPool.Name)
+						logger.V(2).Info("assigned network", "Network", klog.KObj(network), "vlan", network.Spec.VlanId, "Pool", klog.KObj(currentPool))
 					}
 				}
 			} else {
@@ -1173,7 +1043,7 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 								})
 								vlanToNetworks[vlanId] = append(vlanToNetworks[vlanId], network.Name)
 								poolNetworkCount++
-								log.Printf("Assigned network %s (VLAN %s) to pool %s to match VLAN from first pool", network.Name, vlanId, currentPool.Name)
+								logger.V(2).Info("assigned network matching the VLAN of the first pool", "Network", klog.KObj(network), "vlan", vlanId, "Pool", klog.KObj(currentPool))
 								break
 							}
 						}
@@ -1182,8 +1052,10 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			}
 
 			if poolNetworkCount < networksPerPool {
-				log.Printf("Warning: Pool %s only has %d/%d networks assigned", currentPool.Name, poolNetworkCount, networksPerPool)
+				logger.V(2).Info("not enough networks in pool", "Pool", klog.KObj(currentPool), "networks", poolNetworkCount, "networksPerPool", networksPerPool)
 			}
+			span.SetAttributes(attribute.Int("networks.available", len(availableNetworks)), attribute.Int("networks.assigned", poolNetworkCount))
+			span.End()
 		}
 
 		// Generate env vars for this pool
@@ -1199,10 +1071,10 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		}
 
 		if networkForEnvVars != nil {
-			log.Printf("Generating env vars for lease %v with pool %v and network %v", lease.Name, currentPool.Name, networkForEnvVars.Name)
+			logger.V(4).Info("generating env vars", "Pool", klog.KObj(currentPool), "Network", klog.KObj(networkForEnvVars))
 			err = utils.GenerateEnvVars(lease, currentPool, networkForEnvVars)
 			if err != nil {
-				log.Printf("error generating env vars: %v", err)
+				logger.Error(err, "unable to generate env vars", "Pool", klog.KObj(currentPool))
 			}
 		}
 	}
@@ -1210,13 +1082,15 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	// CRD validation requires MinItems=1 for topology.networks.
 	// If any pool has zero assigned networks, skip the status update to avoid rejection.
 	if poolName, missing := poolMissingNetworks(lease, assignedPools); missing {
-		log.Printf("pool %s has no networks assigned for lease %s, saving owner refs and requeuing", poolName, lease.Name)
+		logger.V(2).Info("pool has no networks assigned, saving owner references and requeuing", "Pool", klog.KRef(lease.Namespace, poolName))
+		setLeaseTraceID(ctx, lease)
 		err = l.Client.Update(ctx, lease)
 		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("error updating lease owner references: %v", err)
+			return fmt.Errorf("error updating lease owner references: %v", err)
 		}
+		l.auditResources(ctx, lease, persisted, lease.OwnerReferences, releaseReasonResized)
 		updateLeaseMetrics()
-		return ctrl.Result{RequeueAfter: LEASE_PARTIAL_RETRY_INTERVAL}, nil
+		return nil
 	}
 
 	// Populate poolInfo array with FailureDomainSpec from each assigned pool
@@ -1244,7 +1118,7 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		poolFailureDomain.Topology.Networks = assignedNetworks
 
 		lease.Status.PoolInfo = append(lease.Status.PoolInfo, poolFailureDomain)
-		log.Printf("Added pool info for pool %s to lease %s with %d networks", poolItem.Name, lease.Name, len(assignedNetworks))
+		logger.V(4).Info("added pool info", "Pool", klog.KObj(poolItem), "networks", len(assignedNetworks))
 	}
 
 	// Build the status.topology.networks list (using first pool's datacenter for the path)
@@ -1273,7 +1147,7 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	}
 
 	lease.Status.Topology.Networks = allNetworks
-	log.Printf("Lease %v has %d total network owner references, %d unique networks in status", lease.Name, len(vlanToNetworks), len(allNetworks))
+	logger.V(4).Info("networks of the lease", "vlans", len(vlanToNetworks), "networks", len(allNetworks))
 
 	// Check if all pools and networks have been assigned
 	// Each pool must have the full number of networks
@@ -1300,7 +1174,7 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	for poolName, count := range networksPerPoolActual {
 		if count < lease.Spec.Networks {
 			allPoolsHaveNetworks = false
-			log.Printf("Pool %s only has %d/%d networks", poolName, count, lease.Spec.Networks)
+			logger.V(2).Info("not enough networks in pool", "Pool", klog.KRef(lease.Namespace, poolName), "networks", count, "networksPerPool", lease.Spec.Networks)
 		}
 		if count < minNetworksAssigned {
 			minNetworksAssigned = count
@@ -1309,26 +1183,52 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 	networksFulfilled := poolsFulfilled && allPoolsHaveNetworks
 
-	log.Printf("Lease %v has %d/%d pools, each pool needs %d networks, min networks per pool: %d",
-		lease.Name, len(assignedPools), requiredPools, lease.Spec.Networks, minNetworksAssigned)
+	logger.V(2).Info("assigned pools and networks", "assignedPools", len(assignedPools), "requiredPools", requiredPools,
+		"networksPerPool", lease.Spec.Networks, "minNetworks", minNetworksAssigned)
 
+	// a resized lease is already in use and was counted when it was first fulfilled.
+	firstFulfillment := lease.Status.Allocated == nil
 	if poolsFulfilled && networksFulfilled {
+		if lease.Status.Phase != v1.PHASE_FULFILLED {
+			LeaseTransitionsTotal.With(prometheus.Labels{
+				"namespace":   lease.Namespace,
+				"networkType": string(lease.Spec.NetworkType),
+				"phase":       string(v1.PHASE_FULFILLED),
+			}).Inc()
+		}
 		lease.Status.Phase = v1.PHASE_FULFILLED
-		LeaseTransitionsTotal.With(prometheus.Labels{
-			"namespace":   lease.Namespace,
-			"networkType": string(lease.Spec.NetworkType),
-			"phase":       string(v1.PHASE_FULFILLED),
-		}).Inc()
+		lease.Status.Allocated = &v1.LeaseResources{
+			VCpus:    lease.Spec.VCpus,
+			Memory:   lease.Spec.Memory,
+			Networks: lease.Spec.Networks,
+		}
+		now := metav1.Now()
+		if firstFulfillment {
+			lease.Status.FulfilledAt = &now
+		}
+		observeLeaseFulfilled(lease, firstFulfillment, now.Time)
+		setLeaseQueueStatus(lease, nil)
 
 		conditions.Set(lease, conditions.TrueCondition(
 			v1.LeaseConditionTypeFulfilled,
 		))
+		conditions.Set(lease, conditions.FalseCondition(
+			v1.LeaseConditionTypeDelayed,
+		))
 		conditions.Set(lease, conditions.FalseCondition(
 			v1.LeaseConditionTypePending,
 		))
 		conditions.Set(lease, conditions.FalseCondition(
 			v1.LeaseConditionTypePartial,
 		))
+	} else if !firstFulfillment {
+		// a resized lease stays fulfilled with the networks it holds until every pool has the additional ones.
+		conditions.Set(lease, conditions.TrueConditionWithReason(
+			v1.LeaseConditionTypeResizing,
+			v1.ReasonLeaseResizing,
+			"pools do not all have required networks (need %d networks per pool, minimum assigned: %d)",
+			lease.Spec.Networks, minNetworksAssigned,
+		))
 	} else {
 		lease.Status.Phase = v1.PHASE_PARTIAL
 		LeaseTransitionsTotal.With(prometheus.Labels{
@@ -1360,43 +1260,35 @@ func (l *LeaseReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 			v1.LeaseConditionTypePartial,
 		))
 	}
+	setLeaseReady(lease)
 
 	leaseStatus := lease.Status.DeepCopy()
+	setLeaseTraceID(ctx, lease)
 	err = l.Client.Update(ctx, lease)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("error updating lease, requeuing: %v", err)
+		return fmt.Errorf("error updating lease, requeuing: %v", err)
 	}
+	l.auditResources(ctx, lease, persisted, lease.OwnerReferences, releaseReasonResized)
 
 	leaseStatus.DeepCopyInto(&lease.Status)
 
 	err = l.Client.Status().Update(ctx, lease)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("error updating lease status, requeuing: %v", err)
+		return fmt.Errorf("error updating lease status, requeuing: %v", err)
 	}
 
-	if lease.Status.Phase == v1.PHASE_FULFILLED {
-		promLabels["pool"] = pool.Name
-		LeasesInUse.With(promLabels).Add(1)
-
-		if pool.Annotations == nil {
-			pool.Annotations = make(map[string]string)
-		}
-
-		l.triggerPoolUpdates(ctx)
-		l.triggerLeaseUpdates(ctx, lease.Spec.NetworkType)
-		updateLeaseMetrics()
-
-		// FULFILLED leases don't need rescheduling
-		return ctrl.Result{}, nil
+	if lease.Status.Phase == v1.PHASE_FULFILLED && firstFulfillment {
+		LeasesInUse.With(prometheus.Labels{
+			"namespace": lease.Namespace,
+			"pool":      pool.Name,
+		}).Add(1)
+		logger.Info("lease fulfilled", "pools", len(assignedPools), "networks", len(lease.Status.Topology.Networks))
 	}
 
-	// For PARTIAL leases, schedule retry
+	// the pool statuses are updated by the pool controller, which watches leases.
+	updateLeaseMetrics()
 	if lease.Status.Phase == v1.PHASE_PARTIAL {
-		updateLeaseMetrics()
-		log.Printf("lease %s is PARTIAL - requeuing in %v", lease.Name, LEASE_PARTIAL_RETRY_INTERVAL)
-		return ctrl.Result{RequeueAfter: LEASE_PARTIAL_RETRY_INTERVAL}, nil
+		logger.V(2).Info("lease is PARTIAL")
 	}
-
-	updateLeaseMetrics()
-	return ctrl.Result{}, nil
+	return nil
 }
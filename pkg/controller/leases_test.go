@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"sort"
 	"testing"
 
@@ -235,7 +236,7 @@ func TestGetCommonNetworksForLease(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "job-lease-a",
 			Labels: map[string]string{
-				BoskosIdLabel: "job-123",
+				v1.BoskosIdLabel: "job-123",
 			},
 			OwnerReferences: []metav1.OwnerReference{
 				{Kind: "Pool", Name: "pool-a"},
@@ -257,7 +258,7 @@ func TestGetCommonNetworksForLease(t *testing.T) {
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "job-lease-b",
 			Labels: map[string]string{
-				BoskosIdLabel: "job-123",
+				v1.BoskosIdLabel: "job-123",
 			},
 			OwnerReferences: []metav1.OwnerReference{
 				{Kind: "Pool", Name: "pool-b"},
@@ -288,7 +289,7 @@ func TestGetCommonNetworksForLease(t *testing.T) {
 	reconciler := &LeaseReconciler{}
 
 	t.Run("returns sibling networks unfiltered", func(t *testing.T) {
-		got, err := reconciler.getCommonNetworksForLease(targetLease)
+		got, err := reconciler.getCommonNetworksForLease(context.TODO(), targetLease)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -166,7 +166,7 @@ func TestVCenterCapStuckScenario(t *testing.T) {
 		// Test that initial pre-filtering works correctly
 		// Expected behavior: Should select high-capacity vCenters
 
-		// Count pools per vCenter (simulate GetFittingPools)
+		// Count pools per vCenter (as the CPU and Memory filters do)
 		poolsPerVCenter := make(map[string]int)
 		for _, p := range pools {
 			// Check if pool has enough resources
@@ -189,7 +189,7 @@ func TestVCenterCapStuckScenario(t *testing.T) {
 		// vcenter.ci...-2: 66/24 = 2.7, 263/96 = 2.7 → 2 pools
 		// vcenter.ci...: 152/24 = 6.3, 1048/96 = 10.9 → 6 pools
 
-		// But GetFittingPools would return 1 pool per vCenter since we can only assign once
+		// But each pool can only be assigned once, whatever its capacity
 		// Let's verify the counts make sense
 		if poolsPerVCenter["vcenter-1.example.com"] < 1 {
 			t.Error("vcenter-1 should have at least 1 pool available")
@@ -46,6 +46,11 @@ var (
 		Help: "Total number of networks per pool, broken down by network type",
 	}, []string{"namespace", "pool", "networkType"})
 
+	PoolNetworksQuarantined = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pool_networks_quarantined",
+		Help: "Number of networks per pool cooling down after they were released by a lease",
+	}, []string{"namespace", "pool"})
+
 	PoolVcpusUtilizationRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "pool_vcpus_utilization_ratio",
 		Help: "Ratio of vCPUs in use to total available (with overcommit) per pool",
@@ -71,6 +76,26 @@ var (
 		Help: "Whether pool is excluded from default scheduling (1=excluded, 0=included)",
 	}, []string{"namespace", "pool"})
 
+	PoolDrainLeasesRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pool_drain_leases_remaining",
+		Help: "Number of leases remaining on a pool which is being drained",
+	}, []string{"namespace", "pool"})
+
+	PoolLeaseOutcomesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pool_lease_outcomes_total",
+		Help: "Total number of outcomes reported by the leases released from a pool, by outcome",
+	}, []string{"namespace", "pool", "outcome"})
+
+	PoolFailureRatio = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pool_failure_ratio",
+		Help: "Share of the leases released from a pool within the outcome window which reported a failure, by outcome",
+	}, []string{"namespace", "pool", "outcome"})
+
+	PoolOutcomeLeases = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pool_outcome_leases",
+		Help: "Number of leases released from a pool within the outcome window which reported an outcome",
+	}, []string{"namespace", "pool"})
+
 	LeasesInUse = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "leases_in_use",
 		Help: "Number of leases in use",
@@ -93,9 +118,72 @@ var (
 
 	LeaseDelaysTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: "lease_delays_total",
-		Help: "Total number of times leases have been delayed",
+		Help: "Total number of times leases have been requeued without being fulfilled",
 	}, []string{"namespace", "networkType"})
 
+	LeaseTimeToFulfillSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "lease_time_to_fulfill_seconds",
+		Help:    "Time from when a lease started pending until it was first fulfilled",
+		Buckets: leaseWaitBuckets,
+	}, leaseWaitLabels)
+
+	LeasePartialSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "lease_partial_seconds",
+		Help:    "Time a lease spent Partial before it was fulfilled",
+		Buckets: leaseWaitBuckets,
+	}, leaseWaitLabels)
+
+	LeaseDelayedSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "lease_delayed_seconds",
+		Help:    "Time from the first unsuccessful scheduling attempt of a lease until it was fulfilled",
+		Buckets: leaseWaitBuckets,
+	}, leaseWaitLabels)
+
+	PoolForecastWaitSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pool_forecast_wait_seconds",
+		Help: "Forecast wait of a new lease of the mean shape of recent leases of the network type, +Inf if it can not be estimated",
+	}, []string{"namespace", "pool", "networkType"})
+
+	PoolForecastExhaustionSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "pool_forecast_exhaustion_seconds",
+		Help: "Forecast time until a pool runs out of capacity for leases of the network type, +Inf if it is not expected to",
+	}, []string{"namespace", "pool", "networkType"})
+
+	SchedulingQueueLeases = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduling_queue_leases",
+		Help: "Number of leases in the scheduling queue, by queue (active, backoff or unschedulable)",
+	}, []string{"queue"})
+
+	FairShareTenantShare = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "fair_share_tenant_share",
+		Help: "Configured fair share of a tenant",
+	}, []string{"tenant"})
+
+	FairShareTenantDominantShare = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "fair_share_tenant_dominant_share",
+		Help: "Largest share of vCPUs, memory or networks currently held by the leases of a tenant",
+	}, []string{"tenant"})
+
+	FairShareTenantUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "fair_share_tenant_usage",
+		Help: "Dominant share of a tenant integrated over time in seconds, decayed by the fair share half-life",
+	}, []string{"tenant"})
+
+	NetworkDisabled = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "network_disabled",
+		Help: "1 if the network is not assigned to new leases (spec.noSchedule), 0 otherwise",
+	}, []string{"namespace", "network"})
+
+	NetworkFailureReports = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "network_failure_reports",
+		Help: "Number of leases which reported the network as broken within the failure window",
+	}, []string{"namespace", "network"})
+
+	NetworkFailureReportsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "network_failure_reports_total",
+		Help: "Total number of reports by leases that the network was broken",
+	}, []string{"namespace", "network"})
+
 	NetworkLeaseCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "network_lease_count",
 		Help: "Number of leases currently using each network",
@@ -106,12 +194,18 @@ func InitMetrics() {
 	metrics.Registry.MustRegister(
 		PoolMemoryAvailable, PoolMemoryTotal,
 		PoolNetworksAvailable, PoolNetworksTotal,
-		PoolNetworksAvailableByType, PoolNetworksTotalByType,
+		PoolNetworksAvailableByType, PoolNetworksTotalByType, PoolNetworksQuarantined,
 		PoolCpusAvailable, PoolCpusTotal,
 		PoolVcpusUtilizationRatio, PoolMemoryUtilizationRatio, PoolNetworksUtilizationRatio,
-		PoolNoSchedule, PoolExcluded,
+		PoolNoSchedule, PoolExcluded, PoolDrainLeasesRemaining,
+		PoolLeaseOutcomesTotal, PoolFailureRatio, PoolOutcomeLeases,
 		LeasesInUse, LeaseCounts,
 		LeaseAgeSeconds, LeaseTransitionsTotal, LeaseDelaysTotal,
+		LeaseTimeToFulfillSeconds, LeasePartialSeconds, LeaseDelayedSeconds,
+		PoolForecastWaitSeconds, PoolForecastExhaustionSeconds,
+		SchedulingQueueLeases,
+		FairShareTenantShare, FairShareTenantDominantShare, FairShareTenantUsage,
+		NetworkDisabled, NetworkFailureReports, NetworkFailureReportsTotal,
 		NetworkLeaseCount,
 	)
 }
@@ -2,15 +2,16 @@ package controller
 
 import (
 	"context"
-	"log"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 )
@@ -42,9 +43,9 @@ func (l *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	l.RESTMapper = mgr.GetRESTMapper()
 
 	go func() {
-		ctx := context.TODO()
+		ctx := log.IntoContext(context.TODO(), mgr.GetLogger().WithName("namespaces"))
 		for {
-			log.Printf("checking for abandoned leases")
+			log.FromContext(ctx).V(4).Info("checking for abandoned leases")
 			l.PruneAbandonedLeases(ctx)
 			time.Sleep(5 * time.Minute)
 		}
@@ -53,6 +54,7 @@ func (l *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 }
 
 func (l *NamespaceReconciler) PruneAbandonedLeases(ctx context.Context) {
+	logger := log.FromContext(ctx)
 	namespaces := &corev1.NamespaceList{}
 
 	reconcileLock.Lock()
@@ -60,7 +62,7 @@ func (l *NamespaceReconciler) PruneAbandonedLeases(ctx context.Context) {
 
 	err := l.Client.List(ctx, namespaces)
 	if err != nil {
-		log.Printf("Failed to list namespaces: %v", err)
+		logger.Error(err, "unable to list namespaces")
 		return
 	}
 
@@ -78,16 +80,15 @@ func (l *NamespaceReconciler) PruneAbandonedLeases(ctx context.Context) {
 			if nsFound {
 				continue
 			}
-			log.Printf("lease %s is referenced by deleted namespace %s. will delete lease.", lease.Name, leaseNs)
+			logger.Info("lease is referenced by a deleted namespace, deleting it", "Lease", klog.KObj(lease), "namespace", leaseNs)
 			leasesToDelete = append(leasesToDelete, lease.DeepCopy())
 		}
 	}
 
 	for _, lease := range leasesToDelete {
-		log.Printf("deleting lease %s", lease.Name)
 		err = l.Client.Delete(ctx, lease)
 		if err != nil {
-			log.Printf("error deleting lease %s: %s", lease.Name, err)
+			logger.Error(err, "unable to delete lease", "Lease", klog.KObj(lease))
 		}
 	}
 }
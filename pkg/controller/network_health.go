@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils/conditions"
+)
+
+const (
+	// DEFAULT_NETWORK_FAILURE_THRESHOLD is the number of failure reports within the window which disables a network
+	DEFAULT_NETWORK_FAILURE_THRESHOLD = 3
+
+	// DEFAULT_NETWORK_FAILURE_WINDOW is how long failure reports count towards disabling a network
+	DEFAULT_NETWORK_FAILURE_WINDOW = time.Hour
+)
+
+// parseNetworkList returns the entries of a comma separated list of networks.
+func parseNetworkList(value string) []string {
+	var entries []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// getLeaseNetwork returns the network assigned to a lease with the given name, port group name or port group
+// path, or nil if the lease does not hold such a network.
+func getLeaseNetwork(lease *v1.Lease, entry string) *v1.Network {
+	portGroup := path.Base(entry)
+	for _, name := range getLeaseNetworkNames(lease) {
+		network := getNetworkByName(name)
+		if network != nil && (network.Name == entry || network.Spec.PortGroupName == portGroup) {
+			return network
+		}
+	}
+	return nil
+}
+
+// addNetworkFailureReport drops the failure reports of a network older than window and records the report of lease,
+// once per lease. it returns true if the network should be disabled because it has threshold reports or more.
+func addNetworkFailureReport(network *v1.Network, lease string, now time.Time, window time.Duration, threshold int) bool {
+	reports := make([]v1.NetworkFailureReport, 0, len(network.Status.FailureReports)+1)
+	reported := false
+	for _, report := range network.Status.FailureReports {
+		if now.Sub(report.Time.Time) > window {
+			continue
+		}
+		reported = reported || report.Lease == lease
+		reports = append(reports, report)
+	}
+	if !reported {
+		reports = append(reports, v1.NetworkFailureReport{Lease: lease, Time: metav1.NewTime(now)})
+	}
+	network.Status.FailureReports = reports
+
+	return threshold > 0 && len(reports) >= threshold && !network.Spec.NoSchedule
+}
+
+// reportBrokenNetworks records the failure reports of the networks the holder of a lease found broken, and
+// disables the networks with too many reports. each network is only reported once by a lease.
+func (l *LeaseReconciler) reportBrokenNetworks(ctx context.Context, lease *v1.Lease) error {
+	broken := lease.Annotations[v1.LeaseBrokenNetworksAnnotation]
+	if broken == "" || broken == lease.Annotations[v1.LeaseBrokenNetworksReportedAnnotation] {
+		return nil
+	}
+
+	reported := make(map[string]bool)
+	for _, entry := range parseNetworkList(lease.Annotations[v1.LeaseBrokenNetworksReportedAnnotation]) {
+		reported[entry] = true
+	}
+
+	for _, entry := range parseNetworkList(broken) {
+		if reported[entry] {
+			continue
+		}
+		network := getLeaseNetwork(lease, entry)
+		if network == nil {
+			log.FromContext(ctx).Info("lease reported a network it does not hold as broken", "Network", klog.KRef(lease.Namespace, entry))
+			continue
+		}
+		if err := l.recordNetworkFailure(ctx, network, lease); err != nil {
+			return err
+		}
+	}
+
+	lease.Annotations[v1.LeaseBrokenNetworksReportedAnnotation] = broken
+	if err := l.Update(ctx, lease); err != nil {
+		return fmt.Errorf("error recording the broken networks reported by lease %s: %w", lease.Name, err)
+	}
+	return nil
+}
+
+// recordNetworkFailure records the failure report of a lease in the network status and disables the network
+// once it reaches the failure threshold.
+func (l *LeaseReconciler) recordNetworkFailure(ctx context.Context, network *v1.Network, lease *v1.Lease) error {
+	logger := log.FromContext(ctx).WithValues("Network", klog.KObj(network))
+	logger.Info("lease reported network as broken")
+	NetworkFailureReportsTotal.With(prometheus.Labels{
+		"namespace": network.Namespace,
+		"network":   network.Name,
+	}).Inc()
+
+	disable := addNetworkFailureReport(network, lease.Name, time.Now(), l.NetworkFailureWindow, l.NetworkFailureThreshold)
+	status := network.Status.DeepCopy()
+	if disable {
+		network.Spec.NoSchedule = true
+		if err := l.Update(ctx, network); err != nil {
+			return fmt.Errorf("error disabling network %s: %w", network.Name, err)
+		}
+		status.DeepCopyInto(&network.Status)
+		conditions.Set(network, conditions.TrueConditionWithReason(
+			v1.NetworkConditionTypeDisabled,
+			v1.ReasonNetworkFailing,
+			"%d leases reported the network as broken within %v",
+			len(network.Status.FailureReports), l.NetworkFailureWindow,
+		))
+		l.Recorder.Eventf(network, corev1.EventTypeWarning, v1.ReasonNetworkFailing,
+			"disabled after %d leases reported the network as broken within %v", len(network.Status.FailureReports), l.NetworkFailureWindow)
+		logger.Info("network is disabled", "failureReports", len(network.Status.FailureReports))
+	}
+
+	if err := l.Status().Update(ctx, network); err != nil {
+		return fmt.Errorf("error recording failure report of network %s: %w", network.Name, err)
+	}
+	updateNetworkHealthMetrics(network)
+	return nil
+}
+
+// reconcileNetworkDisabled keeps the Disabled condition of a network in line with spec.noSchedule. a network
+// enabled again starts with no failure reports. it returns true if the status changed.
+func reconcileNetworkDisabled(network *v1.Network) bool {
+	disabled := conditions.IsTrue(network, v1.NetworkConditionTypeDisabled)
+	switch {
+	case network.Spec.NoSchedule && !disabled:
+		conditions.Set(network, conditions.TrueConditionWithReason(
+			v1.NetworkConditionTypeDisabled,
+			v1.ReasonNetworkNoSchedule,
+			"the network is not assigned to new leases",
+		))
+		return true
+	case !network.Spec.NoSchedule && disabled:
+		network.Status.FailureReports = nil
+		conditions.Set(network, conditions.FalseConditionWithReason(
+			v1.NetworkConditionTypeDisabled,
+			v1.ReasonNetworkReenabled,
+			v1.ConditionSeverityInfo,
+			"the network was enabled again",
+		))
+		return true
+	}
+	return false
+}
+
+// updateNetworkHealthMetrics records whether a network is disabled and its recent failure reports.
+func updateNetworkHealthMetrics(network *v1.Network) {
+	promLabels := prometheus.Labels{
+		"namespace": network.Namespace,
+		"network":   network.Name,
+	}
+	disabled := float64(0)
+	if network.Spec.NoSchedule {
+		disabled = 1
+	}
+	NetworkDisabled.With(promLabels).Set(disabled)
+	NetworkFailureReports.With(promLabels).Set(float64(len(network.Status.FailureReports)))
+}
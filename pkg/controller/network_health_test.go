@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils/conditions"
+)
+
+func TestGetLeaseNetwork(t *testing.T) {
+	dc := "dc1"
+	pod := "pod1"
+	newNetwork := func(name, portGroup string) *v1.Network {
+		return &v1.Network{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.NetworkSpec{PortGroupName: portGroup, DatacenterName: &dc, PodName: &pod},
+		}
+	}
+	cleanupNetworks := setupTestNetworks(map[string]*v1.Network{
+		"held":     newNetwork("held", "ci-vlan-100"),
+		"not-held": newNetwork("not-held", "ci-vlan-200"),
+	})
+	defer cleanupNetworks()
+
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "lease",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Pool", Name: "held"}, {Kind: "Network", Name: "held"}},
+		},
+	}
+
+	tests := []struct {
+		entry    string
+		expected string
+	}{
+		{entry: "held", expected: "held"},
+		{entry: "ci-vlan-100", expected: "held"},
+		{entry: "/dc1/network/ci-vlan-100", expected: "held"},
+		{entry: "not-held", expected: ""},
+		{entry: "ci-vlan-200", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			network := getLeaseNetwork(lease, tt.entry)
+			name := ""
+			if network != nil {
+				name = network.Name
+			}
+			if name != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, name)
+			}
+		})
+	}
+
+	if entries := parseNetworkList(" held, ,ci-vlan-100 "); len(entries) != 2 || entries[0] != "held" || entries[1] != "ci-vlan-100" {
+		t.Errorf("expected 2 trimmed entries, got %v", entries)
+	}
+}
+
+func TestAddNetworkFailureReport(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	report := func(lease string, age time.Duration) v1.NetworkFailureReport {
+		return v1.NetworkFailureReport{Lease: lease, Time: metav1.NewTime(now.Add(-age))}
+	}
+
+	tests := []struct {
+		name          string
+		reports       []v1.NetworkFailureReport
+		noSchedule    bool
+		lease         string
+		threshold     int
+		expectDisable bool
+		expectReports int
+	}{
+		{
+			name:          "first report",
+			lease:         "lease-1",
+			threshold:     3,
+			expectReports: 1,
+		},
+		{
+			name:          "threshold reached",
+			reports:       []v1.NetworkFailureReport{report("lease-1", time.Minute), report("lease-2", 30*time.Minute)},
+			lease:         "lease-3",
+			threshold:     3,
+			expectDisable: true,
+			expectReports: 3,
+		},
+		{
+			name:          "reports outside the window are dropped",
+			reports:       []v1.NetworkFailureReport{report("lease-1", 2*time.Hour), report("lease-2", 30*time.Minute)},
+			lease:         "lease-3",
+			threshold:     3,
+			expectReports: 2,
+		},
+		{
+			name:          "a lease is counted once",
+			reports:       []v1.NetworkFailureReport{report("lease-1", time.Minute), report("lease-2", 30*time.Minute)},
+			lease:         "lease-2",
+			threshold:     3,
+			expectReports: 2,
+		},
+		{
+			name:          "already disabled",
+			reports:       []v1.NetworkFailureReport{report("lease-1", time.Minute), report("lease-2", 30*time.Minute)},
+			noSchedule:    true,
+			lease:         "lease-3",
+			threshold:     3,
+			expectReports: 3,
+		},
+		{
+			name:          "disabling turned off",
+			reports:       []v1.NetworkFailureReport{report("lease-1", time.Minute), report("lease-2", 30*time.Minute)},
+			lease:         "lease-3",
+			expectReports: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := &v1.Network{
+				Spec:   v1.NetworkSpec{NoSchedule: tt.noSchedule},
+				Status: v1.NetworkStatus{FailureReports: tt.reports},
+			}
+			disable := addNetworkFailureReport(network, tt.lease, now, time.Hour, tt.threshold)
+			if disable != tt.expectDisable {
+				t.Errorf("expected disable %v, got %v", tt.expectDisable, disable)
+			}
+			if len(network.Status.FailureReports) != tt.expectReports {
+				t.Errorf("expected %d reports, got %d", tt.expectReports, len(network.Status.FailureReports))
+			}
+		})
+	}
+}
+
+func TestReconcileNetworkDisabled(t *testing.T) {
+	network := &v1.Network{
+		Status: v1.NetworkStatus{FailureReports: []v1.NetworkFailureReport{{Lease: "lease-1"}}},
+	}
+	if reconcileNetworkDisabled(network) {
+		t.Fatalf("expected no change for an enabled network")
+	}
+
+	network.Spec.NoSchedule = true
+	if !reconcileNetworkDisabled(network) || !conditions.IsTrue(network, v1.NetworkConditionTypeDisabled) {
+		t.Fatalf("expected the Disabled condition to be set")
+	}
+	if reconcileNetworkDisabled(network) {
+		t.Fatalf("expected no change for a disabled network")
+	}
+
+	network.Spec.NoSchedule = false
+	if !reconcileNetworkDisabled(network) || conditions.IsTrue(network, v1.NetworkConditionTypeDisabled) {
+		t.Fatalf("expected the Disabled condition to be cleared")
+	}
+	if len(network.Status.FailureReports) != 0 {
+		t.Errorf("expected the failure reports to be cleared, got %v", network.Status.FailureReports)
+	}
+}
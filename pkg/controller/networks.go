@@ -3,7 +3,6 @@ package controller
 import (
 	"context"
 	"fmt"
-	"log"
 
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -11,6 +10,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 type NetworkReconciler struct {
@@ -49,8 +49,8 @@ func (l *NetworkReconciler) SetupWithManager(mgr ctrl.Manager) error {
 }
 
 func (l *NetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log.Print("Reconciling network")
-	defer log.Print("Finished reconciling network")
+	logger := log.FromContext(ctx)
+	logger.V(4).Info("reconciling network")
 
 	reconcileLock.Lock()
 	defer reconcileLock.Unlock()
@@ -64,7 +64,7 @@ func (l *NetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	if network.DeletionTimestamp != nil {
-		log.Print("Network is being deleted")
+		logger.Info("network is being deleted")
 		if network.Finalizers != nil {
 			network.Finalizers = nil
 			err := l.Update(ctx, network)
@@ -77,7 +77,7 @@ func (l *NetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	}
 
 	if network.Finalizers == nil {
-		log.Print("setting finalizer on network")
+		logger.V(2).Info("setting finalizer on network")
 		network.Finalizers = []string{v1.NetworkFinalizer}
 		err := l.Client.Update(ctx, network)
 		if err != nil {
@@ -85,6 +85,23 @@ func (l *NetworkReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 	}
 
+	if reconcileNetworkDisabled(network) {
+		if err := l.Status().Update(ctx, network); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error updating network status: %w", err)
+		}
+	}
+	updateNetworkHealthMetrics(network)
+
+	previous, exists := networks[networkKey]
 	networks[networkKey] = network
+	if !exists || previous.Generation != network.Generation {
+		logger.V(2).Info("network added or changed, retrying unschedulable leases")
+		schedulingQueue.MoveAllToActiveQueue()
+	}
+
+	if network.Status.Quarantined {
+		requeueAfter, err := l.endQuarantine(ctx, network)
+		return ctrl.Result{RequeueAfter: requeueAfter}, err
+	}
 	return ctrl.Result{}, nil
 }
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler/plugins"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/tracing"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// getPoolVLANs returns the VLAN IDs the lease can use in the pool: VLANs of networks the lease already holds in
+// the pool, of common networks held by sibling leases and of available networks of the lease's network type.
+func (l *LeaseReconciler) getPoolVLANs(lease *v1.Lease, pool *v1.Pool, commonNetworks []*v1.Network) map[string]bool {
+	vlans := make(map[string]bool)
+	poolNetworks := getNetworksForPool(pool)
+
+	for _, ownerRef := range lease.OwnerReferences {
+		if ownerRef.Kind != "Network" {
+			continue
+		}
+		if network, exists := poolNetworks[ownerRef.Name]; exists {
+			vlans[network.Spec.VlanId] = true
+		}
+	}
+	for _, network := range commonNetworks {
+		if _, exists := poolNetworks[network.Name]; exists {
+			vlans[network.Spec.VlanId] = true
+		}
+	}
+
+	availableNetworks := l.getAvailableNetworks(pool, lease.Spec.NetworkType)
+	if l.AllowMultiToUseSingle && lease.Spec.NetworkType == v1.NetworkTypeMultiTenant {
+		availableNetworks = append(availableNetworks, l.getAvailableNetworks(pool, v1.NetworkTypeSingleTenant)...)
+	}
+	for _, network := range availableNetworks {
+		vlans[network.Spec.VlanId] = true
+	}
+	return vlans
+}
+
+// placePools picks all remaining pools of a multi-pool lease at once and reserves them. the pools must pass the
+// filter plugins of the framework and together satisfy the vCenter cap, have the lease's networks available on
+// common VLANs and satisfy the topology spread constraints. among the feasible sets, the one with the highest
+// total score is picked.
+func (l *LeaseReconciler) placePools(ctx context.Context, framework *scheduler.Framework, lease *v1.Lease, assignedPools, availablePools []*v1.Pool) (placement *scheduler.Placement, err error) {
+	ctx, span := tracing.Start(ctx, "PlacePools", attribute.Int("pools.assigned", len(assignedPools)),
+		attribute.Int("pools.available", len(availablePools)))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	state := scheduler.NewCycleState(lease, assignedPools, getLeaseList())
+	feasible, results := framework.RunFilterPlugins(ctx, state, lease, availablePools)
+	if len(feasible) == 0 {
+		return nil, fmt.Errorf("no pools available. %v", utils.GeneratePoolResults(results))
+	}
+
+	commonNetworks, err := l.getCommonNetworksForLease(ctx, lease)
+	if err != nil {
+		commonNetworks = nil
+	}
+
+	problem := &scheduler.PlacementProblem{
+		RequiredPools:   state.RequiredPools,
+		VCenterCap:      lease.Spec.VCenters,
+		NetworksPerPool: lease.Spec.Networks,
+		Accept: func(pools []*v1.Pool) bool {
+			return plugins.TopologySpreadSatisfied(lease, pools, feasible)
+		},
+	}
+	for _, pool := range assignedPools {
+		problem.Assigned = append(problem.Assigned, scheduler.PlacementCandidate{
+			Pool:  pool,
+			VLANs: l.getPoolVLANs(lease, pool, commonNetworks),
+		})
+	}
+	for _, score := range framework.RunScorePlugins(ctx, state, lease, feasible) {
+		problem.Candidates = append(problem.Candidates, scheduler.PlacementCandidate{
+			Pool:  score.Pool,
+			Score: score.Score,
+			VLANs: l.getPoolVLANs(lease, score.Pool, commonNetworks),
+		})
+	}
+
+	placement, err = scheduler.SolvePlacement(problem)
+	if err != nil {
+		return nil, fmt.Errorf("%w. %v", err, utils.GeneratePoolResults(results))
+	}
+	poolNames := make([]string, 0, len(placement.Pools))
+	for _, pool := range placement.Pools {
+		poolNames = append(poolNames, pool.Name)
+	}
+	span.SetAttributes(tracing.PoolNameKey.StringSlice(poolNames), attribute.Float64("score", placement.Score),
+		attribute.Int("nodesExplored", placement.NodesExplored))
+	log.FromContext(ctx).V(2).Info("placed lease", "pools", len(placement.Pools), "score", placement.Score,
+		"nodesExplored", placement.NodesExplored, "exhaustive", placement.Exhaustive)
+
+	for i, pool := range placement.Pools {
+		if err := framework.RunReservePlugins(ctx, state, lease, pool); err != nil {
+			for _, reserved := range placement.Pools[:i] {
+				framework.RunUnreservePlugins(ctx, state, lease, reserved)
+			}
+			return nil, err
+		}
+	}
+	return placement, nil
+}
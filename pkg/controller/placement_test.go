@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func TestGetPoolVLANs(t *testing.T) {
+	dc := "dc1"
+	pod := "pod1"
+	newNetwork := func(name, portGroup, vlan, networkType string) *v1.Network {
+		return &v1.Network{
+			TypeMeta: metav1.TypeMeta{Kind: "Network"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{v1.NetworkTypeLabel: networkType},
+			},
+			Spec: v1.NetworkSpec{
+				PortGroupName:  portGroup,
+				VlanId:         vlan,
+				DatacenterName: &dc,
+				PodName:        &pod,
+			},
+		}
+	}
+
+	cleanupNetworks := setupTestNetworks(map[string]*v1.Network{
+		"free":         newNetwork("free", "pg-100", "100", "single-tenant"),
+		"held-by-self": newNetwork("held-by-self", "pg-200", "200", "single-tenant"),
+		"held-by-peer": newNetwork("held-by-peer", "pg-300", "300", "single-tenant"),
+		"multi":        newNetwork("multi", "pg-400", "400", "multi-tenant"),
+		"other-pool":   newNetwork("other-pool", "pg-500", "500", "single-tenant"),
+	})
+	defer cleanupNetworks()
+
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "lease",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Network", Name: "held-by-self"}},
+		},
+		Spec: v1.LeaseSpec{NetworkType: v1.NetworkTypeSingleTenant},
+	}
+	cleanupLeases := setupTestLeases(map[string]*v1.Lease{
+		"lease": lease,
+		"peer": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "peer",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Network", Name: "held-by-peer"}},
+			},
+		},
+	})
+	defer cleanupLeases()
+
+	pool := &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool"},
+		Spec: v1.PoolSpec{
+			IBMPoolSpec: v1.IBMPoolSpec{Pod: pod},
+			FailureDomainSpec: v1.FailureDomainSpec{
+				VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+					Topology: configv1.VSpherePlatformTopology{
+						Networks: []string{"/dc1/network/pg-100", "/dc1/network/pg-200", "/dc1/network/pg-300", "/dc1/network/pg-400"},
+					},
+				},
+			},
+		},
+	}
+
+	reconciler := &LeaseReconciler{}
+
+	t.Run("owned and available networks of the lease network type", func(t *testing.T) {
+		vlans := reconciler.getPoolVLANs(lease, pool, nil)
+		if len(vlans) != 2 || !vlans["100"] || !vlans["200"] {
+			t.Errorf("expected VLANs 100 and 200, got %v", vlans)
+		}
+	})
+
+	t.Run("common networks of sibling leases", func(t *testing.T) {
+		vlans := reconciler.getPoolVLANs(lease, pool, []*v1.Network{networks["held-by-peer"], networks["other-pool"]})
+		if len(vlans) != 3 || !vlans["300"] {
+			t.Errorf("expected VLANs 100, 200 and 300, got %v", vlans)
+		}
+	})
+
+	t.Run("multi-tenant lease allowed to use single-tenant networks", func(t *testing.T) {
+		multiLease := lease.DeepCopy()
+		multiLease.Spec.NetworkType = v1.NetworkTypeMultiTenant
+		vlans := (&LeaseReconciler{AllowMultiToUseSingle: true}).getPoolVLANs(multiLease, pool, nil)
+		if len(vlans) != 3 || !vlans["400"] || !vlans["100"] {
+			t.Errorf("expected VLANs 100, 200 and 400, got %v", vlans)
+		}
+	})
+}
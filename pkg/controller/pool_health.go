@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// DEFAULT_POOL_OUTCOME_WINDOW is how long the outcomes reported by leases count towards the health of a pool
+	DEFAULT_POOL_OUTCOME_WINDOW = 24 * time.Hour
+
+	// DEFAULT_POOL_INFRA_FAILURE_MIN_LEASES is the number of outcomes a pool needs within the window before it is tainted
+	DEFAULT_POOL_INFRA_FAILURE_MIN_LEASES = 10
+
+	// POOL_OUTCOME_BUCKET is the period counted by each bucket of the pool outcomes
+	POOL_OUTCOME_BUCKET = time.Hour
+)
+
+// validLeaseOutcome returns true if outcome is one of the outcomes a lease may report.
+func validLeaseOutcome(outcome v1.LeaseOutcome) bool {
+	switch outcome {
+	case v1.LeaseOutcomeSuccess, v1.LeaseOutcomeInstallFailure, v1.LeaseOutcomeInfraFailure:
+		return true
+	}
+	return false
+}
+
+// addPoolOutcome counts outcome in the bucket of the pool outcomes for now.
+func addPoolOutcome(pool *v1.Pool, outcome v1.LeaseOutcome, now time.Time) {
+	start := now.Truncate(POOL_OUTCOME_BUCKET)
+	var bucket *v1.PoolOutcomeBucket
+	for i := range pool.Status.Outcomes {
+		if pool.Status.Outcomes[i].Start.Time.Equal(start) {
+			bucket = &pool.Status.Outcomes[i]
+			break
+		}
+	}
+	if bucket == nil {
+		pool.Status.Outcomes = append(pool.Status.Outcomes, v1.PoolOutcomeBucket{Start: metav1.NewTime(start)})
+		bucket = &pool.Status.Outcomes[len(pool.Status.Outcomes)-1]
+	}
+
+	switch outcome {
+	case v1.LeaseOutcomeSuccess:
+		bucket.Success++
+	case v1.LeaseOutcomeInstallFailure:
+		bucket.InstallFailure++
+	case v1.LeaseOutcomeInfraFailure:
+		bucket.InfraFailure++
+	}
+}
+
+// trimPoolOutcomes returns the buckets which are at least partly within window.
+func trimPoolOutcomes(outcomes []v1.PoolOutcomeBucket, now time.Time, window time.Duration) []v1.PoolOutcomeBucket {
+	var trimmed []v1.PoolOutcomeBucket
+	for _, bucket := range outcomes {
+		if now.Sub(bucket.Start.Add(POOL_OUTCOME_BUCKET)) < window {
+			trimmed = append(trimmed, bucket)
+		}
+	}
+	return trimmed
+}
+
+// poolHealth summarizes the outcomes within window, or returns nil if no outcome was reported.
+func poolHealth(outcomes []v1.PoolOutcomeBucket, now time.Time, window time.Duration) *v1.PoolHealth {
+	var success, installFailures, infraFailures int
+	for _, bucket := range trimPoolOutcomes(outcomes, now, window) {
+		success += bucket.Success
+		installFailures += bucket.InstallFailure
+		infraFailures += bucket.InfraFailure
+	}
+
+	leases := success + installFailures + infraFailures
+	if leases == 0 {
+		return nil
+	}
+	return &v1.PoolHealth{
+		Leases:                leases,
+		InstallFailurePercent: installFailures * 100 / leases,
+		InfraFailurePercent:   infraFailures * 100 / leases,
+	}
+}
+
+// recordLeaseOutcome counts the outcome reported by a released lease towards the health of the pools it held.
+// the lease is marked before the pools are updated, so an outcome is counted at most once when the deletion of
+// the lease is retried.
+func (l *LeaseReconciler) recordLeaseOutcome(ctx context.Context, lease *v1.Lease) error {
+	value, reported := lease.Annotations[v1.LeaseOutcomeAnnotation]
+	if !reported || lease.Annotations[v1.LeaseOutcomeRecordedAnnotation] == "true" {
+		return nil
+	}
+	outcome := v1.LeaseOutcome(value)
+	if !validLeaseOutcome(outcome) {
+		log.FromContext(ctx).Info("lease reported an unknown outcome, ignoring it", "outcome", value)
+		return nil
+	}
+
+	lease.Annotations[v1.LeaseOutcomeRecordedAnnotation] = "true"
+	if err := l.Update(ctx, lease); err != nil {
+		return fmt.Errorf("error marking the outcome of lease %s as recorded: %w", lease.Name, err)
+	}
+
+	now := time.Now()
+	for _, pool := range getLeasePools(lease) {
+		addPoolOutcome(pool, outcome, now)
+		if err := l.Status().Update(ctx, pool); err != nil {
+			return fmt.Errorf("error recording the outcome of lease %s on pool %s: %w", lease.Name, pool.Name, err)
+		}
+		PoolLeaseOutcomesTotal.With(prometheus.Labels{
+			"namespace": pool.Namespace,
+			"pool":      pool.Name,
+			"outcome":   string(outcome),
+		}).Inc()
+		log.FromContext(ctx).V(2).Info("lease reported outcome", "Pool", klog.KObj(pool), "outcome", outcome)
+	}
+	return nil
+}
+
+// infraFailuresTainted returns true if the pool carries the infra failures taint.
+func infraFailuresTainted(pool *v1.Pool) bool {
+	for _, taint := range pool.Spec.Taints {
+		if taint.Key == v1.PoolInfraFailuresTaintKey {
+			return true
+		}
+	}
+	return false
+}
+
+// wantInfraFailuresTaint returns true if a pool with health should carry the infra failures taint.
+func wantInfraFailuresTaint(health *v1.PoolHealth, threshold, minLeases int) bool {
+	return health != nil && health.Leases >= minLeases && health.InfraFailurePercent >= threshold
+}
+
+// reconcileInfraFailuresTaint taints a pool whose rate of infrastructure failures crosses the threshold, and
+// removes the taint once the rate drops below it. it does nothing if tainting is disabled. it returns true if
+// the spec changed.
+func (l *PoolReconciler) reconcileInfraFailuresTaint(ctx context.Context, pool *v1.Pool, health *v1.PoolHealth) bool {
+	if l.InfraFailureThreshold <= 0 {
+		return false
+	}
+
+	tainted := infraFailuresTainted(pool)
+	want := wantInfraFailuresTaint(health, l.InfraFailureThreshold, l.InfraFailureMinLeases)
+	switch {
+	case want && !tainted:
+		pool.Spec.Taints = append(pool.Spec.Taints, v1.Taint{
+			Key:    v1.PoolInfraFailuresTaintKey,
+			Effect: v1.TaintEffectPreferNoSchedule,
+		})
+		log.FromContext(ctx).Info("tainting pool, leases reported infrastructure failures", "infraFailurePercent", health.InfraFailurePercent, "leases", health.Leases)
+		l.Recorder.Eventf(pool, corev1.EventTypeWarning, v1.ReasonPoolInfraFailures,
+			"%d%% of %d leases reported an infrastructure failure within %v", health.InfraFailurePercent, health.Leases, l.OutcomeWindow)
+		return true
+	case !want && tainted:
+		var taints []v1.Taint
+		for _, taint := range pool.Spec.Taints {
+			if taint.Key != v1.PoolInfraFailuresTaintKey {
+				taints = append(taints, taint)
+			}
+		}
+		pool.Spec.Taints = taints
+		log.FromContext(ctx).Info("removing the infrastructure failures taint from pool")
+		l.Recorder.Eventf(pool, corev1.EventTypeNormal, v1.ReasonPoolRecovered,
+			"the rate of infrastructure failures dropped below %d%%", l.InfraFailureThreshold)
+		return true
+	}
+	return false
+}
+
+// updatePoolHealthMetrics records the outcomes reported by the leases released from a pool within the window.
+func updatePoolHealthMetrics(pool *v1.Pool) {
+	promLabels := prometheus.Labels{
+		"namespace": pool.Namespace,
+		"pool":      pool.Name,
+	}
+	health := pool.Status.Health
+	if health == nil {
+		health = &v1.PoolHealth{}
+	}
+	PoolOutcomeLeases.With(promLabels).Set(float64(health.Leases))
+
+	promLabels["outcome"] = string(v1.LeaseOutcomeInstallFailure)
+	PoolFailureRatio.With(promLabels).Set(float64(health.InstallFailurePercent) / 100)
+	promLabels["outcome"] = string(v1.LeaseOutcomeInfraFailure)
+	PoolFailureRatio.With(promLabels).Set(float64(health.InfraFailurePercent) / 100)
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler/plugins"
+)
+
+func TestPoolOutcomes(t *testing.T) {
+	now := time.Date(2024, 1, 2, 12, 30, 0, 0, time.UTC)
+	pool := &v1.Pool{
+		Status: v1.PoolStatus{Outcomes: []v1.PoolOutcomeBucket{
+			{Start: metav1.NewTime(now.Add(-26 * time.Hour).Truncate(time.Hour)), InfraFailure: 5},
+			{Start: metav1.NewTime(now.Add(-2 * time.Hour).Truncate(time.Hour)), Success: 2, InstallFailure: 1},
+		}},
+	}
+
+	addPoolOutcome(pool, v1.LeaseOutcomeInfraFailure, now)
+	addPoolOutcome(pool, v1.LeaseOutcomeSuccess, now.Add(10*time.Minute))
+	if len(pool.Status.Outcomes) != 3 {
+		t.Fatalf("expected outcomes of the same hour in one bucket, got %v", pool.Status.Outcomes)
+	}
+
+	trimmed := trimPoolOutcomes(pool.Status.Outcomes, now, 24*time.Hour)
+	if len(trimmed) != 2 {
+		t.Errorf("expected the bucket outside the window to be dropped, got %v", trimmed)
+	}
+
+	health := poolHealth(pool.Status.Outcomes, now, 24*time.Hour)
+	expected := v1.PoolHealth{Leases: 5, InstallFailurePercent: 20, InfraFailurePercent: 20}
+	if health == nil || *health != expected {
+		t.Errorf("expected health %v, got %v", expected, health)
+	}
+
+	if health := poolHealth(nil, now, 24*time.Hour); health != nil {
+		t.Errorf("expected no health without outcomes, got %v", health)
+	}
+	if validLeaseOutcome("flaky") {
+		t.Errorf("expected unknown outcomes to be invalid")
+	}
+}
+
+func TestReconcileInfraFailuresTaint(t *testing.T) {
+	unhealthy := &v1.PoolHealth{Leases: 20, InfraFailurePercent: 50}
+	healthy := &v1.PoolHealth{Leases: 20, InfraFailurePercent: 5}
+	tooFew := &v1.PoolHealth{Leases: 2, InfraFailurePercent: 100}
+
+	tests := []struct {
+		name          string
+		threshold     int
+		tainted       bool
+		health        *v1.PoolHealth
+		expectChange  bool
+		expectTainted bool
+	}{
+		{name: "taints an unhealthy pool", threshold: 30, health: unhealthy, expectChange: true, expectTainted: true},
+		{name: "leaves a healthy pool", threshold: 30, health: healthy},
+		{name: "waits for enough outcomes", threshold: 30, health: tooFew},
+		{name: "keeps the taint while unhealthy", threshold: 30, tainted: true, health: unhealthy, expectTainted: true},
+		{name: "removes the taint once healthy", threshold: 30, tainted: true, health: healthy, expectChange: true},
+		{name: "removes the taint without outcomes", threshold: 30, tainted: true, expectChange: true},
+		{name: "tainting disabled", health: unhealthy},
+		{name: "tainting disabled leaves taints", tainted: true, health: healthy, expectTainted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := &v1.Pool{Spec: v1.PoolSpec{Taints: []v1.Taint{{Key: "dedicated", Effect: v1.TaintEffectNoSchedule}}}}
+			if tt.tainted {
+				pool.Spec.Taints = append(pool.Spec.Taints, v1.Taint{Key: v1.PoolInfraFailuresTaintKey, Effect: v1.TaintEffectPreferNoSchedule})
+			}
+			reconciler := &PoolReconciler{
+				Recorder:              record.NewFakeRecorder(10),
+				InfraFailureThreshold: tt.threshold,
+				InfraFailureMinLeases: DEFAULT_POOL_INFRA_FAILURE_MIN_LEASES,
+			}
+
+			if changed := reconciler.reconcileInfraFailuresTaint(context.TODO(), pool, tt.health); changed != tt.expectChange {
+				t.Errorf("expected change %v, got %v", tt.expectChange, changed)
+			}
+			if tainted := infraFailuresTainted(pool); tainted != tt.expectTainted {
+				t.Errorf("expected tainted %v, got %v", tt.expectTainted, tainted)
+			}
+			if pool.Spec.Taints[0].Key != "dedicated" {
+				t.Errorf("expected other taints to be kept, got %v", pool.Spec.Taints)
+			}
+		})
+	}
+}
+
+func TestRecordLeaseOutcomeOnce(t *testing.T) {
+	pool := &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "default"},
+		Spec:       v1.PoolSpec{OverCommitRatio: "1.0"},
+	}
+	oldPools := pools
+	defer func() { pools = oldPools }()
+	pools = map[string]*v1.Pool{"default/pool1": pool}
+	cleanupNetworks := setupTestNetworks(map[string]*v1.Network{})
+	defer cleanupNetworks()
+	cleanupLeases := setupTestLeases(map[string]*v1.Lease{})
+	defer cleanupLeases()
+
+	deleted := metav1.Now()
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "lease-1",
+			Namespace:         "default",
+			DeletionTimestamp: &deleted,
+			// another finalizer keeps the lease, so it is reconciled again once VCM dropped its finalizer.
+			Finalizers:      []string{v1.LeaseFinalizer, "example.com/finalizer"},
+			Annotations:     map[string]string{v1.LeaseOutcomeAnnotation: string(v1.LeaseOutcomeInfraFailure)},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Pool", Name: "pool1"}},
+		},
+		Status: v1.LeaseStatus{Phase: v1.PHASE_FULFILLED},
+	}
+
+	leaseScheduler, err := plugins.NewScheduler(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reconciler := &LeaseReconciler{Client: newTestClient(lease, pool), Scheduler: leaseScheduler}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "lease-1"}}
+	for i := 0; i < 2; i++ {
+		if _, err := reconciler.Reconcile(context.TODO(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	health := poolHealth(pool.Status.Outcomes, time.Now(), time.Hour)
+	if health == nil || health.Leases != 1 {
+		t.Errorf("expected the outcome to be counted once, got %v", health)
+	}
+}
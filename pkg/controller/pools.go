@@ -3,17 +3,21 @@ package controller
 import (
 	"context"
 	"fmt"
-	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	generator "github.com/docker/docker/pkg/namesgenerator"
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 )
@@ -35,11 +39,21 @@ type PoolReconciler struct {
 
 	// ReleaseVersion is the version of current cluster operator release.
 	ReleaseVersion string
+
+	// OutcomeWindow is how long the outcomes reported by leases count towards the health of a pool.
+	OutcomeWindow time.Duration
+
+	// InfraFailureThreshold is the percentage of infrastructure failures which taints a pool. 0 disables tainting.
+	InfraFailureThreshold int
+
+	// InfraFailureMinLeases is the number of outcomes a pool needs before it is tainted.
+	InfraFailureMinLeases int
 }
 
 func (l *PoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&v1.Pool{}).
+		Watches(&v1.Lease{}, handler.EnqueueRequestsFromMapFunc(poolRequestsForLease)).
 		Complete(l); err != nil {
 		return fmt.Errorf("error setting up controller: %w", err)
 	}
@@ -53,9 +67,24 @@ func (l *PoolReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return nil
 }
 
+// poolRequestsForLease returns a request for each pool held by the lease, so the pool statuses follow the leases
+// assigned to and released from them.
+func poolRequestsForLease(ctx context.Context, obj client.Object) []reconcile.Request {
+	var requests []reconcile.Request
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.Kind == v1.PoolKind {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: obj.GetNamespace(),
+				Name:      ownerRef.Name,
+			}})
+		}
+	}
+	return requests
+}
+
 func (l *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	log.Print("Reconciling pool")
-	defer log.Print("Finished reconciling pool")
+	logger := log.FromContext(ctx)
+	logger.V(4).Info("reconciling pool")
 
 	reconcileLock.Lock()
 	defer reconcileLock.Unlock()
@@ -69,7 +98,7 @@ func (l *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 
 	if pool.DeletionTimestamp != nil {
-		log.Print("Pool is being deleted")
+		logger.Info("pool is being deleted")
 		if pool.Finalizers != nil {
 			pool.Finalizers = nil
 			err := l.Update(ctx, pool)
@@ -82,7 +111,7 @@ func (l *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 
 	if pool.Finalizers == nil {
-		log.Print("setting finalizer on pool")
+		logger.V(2).Info("setting finalizer on pool")
 		pool.Finalizers = []string{v1.PoolFinalizer}
 		err := l.Client.Update(ctx, pool)
 		if err != nil {
@@ -96,7 +125,7 @@ func (l *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		// TODO - Need to enhance this logic to make sure generator does not come up w/ a name that is already in use
 		//        There has been a case where two pools ended up w/ same shortName.
 		pool.Spec.ShortName = strings.ReplaceAll(generator.GetRandomName(0), "_", "-")
-		log.Printf("Setting ShortName for pool %v to %v\n", pool.Name, pool.Spec.ShortName)
+		logger.V(2).Info("setting short name of pool", "shortName", pool.Spec.ShortName)
 		poolUpdateNeeded = true
 	}
 
@@ -105,7 +134,17 @@ func (l *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	// configuration.
 	if strings.Contains(pool.Spec.ShortName, "_") {
 		pool.Spec.ShortName = strings.ReplaceAll(pool.Spec.ShortName, "_", "-")
-		log.Printf("Updating ShortName for pool %v to %v\n", pool.Name, pool.Spec.ShortName)
+		logger.V(2).Info("updating short name of pool", "shortName", pool.Spec.ShortName)
+		poolUpdateNeeded = true
+	}
+
+	// A pool being drained must not accept new leases.
+	if reconcileDrainCordon(ctx, pool) {
+		poolUpdateNeeded = true
+	}
+
+	now := time.Now()
+	if l.reconcileInfraFailuresTaint(ctx, pool, poolHealth(pool.Status.Outcomes, now, l.OutcomeWindow)) {
 		poolUpdateNeeded = true
 	}
 
@@ -123,12 +162,20 @@ func (l *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		pool.Status.Initialized = true
 	}
 
+	var previous *v1.Pool
+	if cached, exists := pools[poolKey]; exists {
+		previous = cached.DeepCopy()
+	}
 	pools[poolKey] = pool
 
-	reconciledPools := reconcilePoolStates()
+	draining := false
+	reconciledPools := reconcilePoolStates(ctx)
 	for _, reconciledPool := range reconciledPools {
 		if reconciledPool.Name == req.Name {
 			reconciledPool.Status.DeepCopyInto(&pool.Status)
+			pool.Status.Outcomes = trimPoolOutcomes(pool.Status.Outcomes, now, l.OutcomeWindow)
+			pool.Status.Health = poolHealth(pool.Status.Outcomes, now, l.OutcomeWindow)
+			draining = l.reconcileDrain(ctx, pool)
 			err := l.Client.Status().Update(ctx, pool)
 			if err != nil {
 				return ctrl.Result{}, fmt.Errorf("error updating pool status: %w", err)
@@ -136,6 +183,11 @@ func (l *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		}
 	}
 
+	if !pool.Spec.NoSchedule && poolCapacityFreed(previous, pool) {
+		logger.V(2).Info("pool changed or has more capacity, retrying unschedulable leases")
+		schedulingQueue.MoveAllToActiveQueue()
+	}
+
 	promLabels := prometheus.Labels{
 		"namespace": req.Namespace,
 		"pool":      req.Name,
@@ -177,5 +229,22 @@ func (l *PoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	}
 	PoolExcluded.With(promLabels).Set(excluded)
 
+	updatePoolHealthMetrics(pool)
+
+	PoolDrainLeasesRemaining.Delete(promLabels)
+	if pool.Status.Drain != nil {
+		PoolDrainLeasesRemaining.With(promLabels).Set(float64(pool.Status.Drain.LeasesRemaining))
+	}
+
+	if draining {
+		logger.V(2).Info("pool is draining, requeuing", "leases", pool.Status.LeaseCount, "requeueAfter", POOL_DRAIN_RETRY_INTERVAL)
+		return ctrl.Result{RequeueAfter: POOL_DRAIN_RETRY_INTERVAL}, nil
+	}
+
+	// outcomes age out of the window even when nothing happens on the pool.
+	if len(pool.Status.Outcomes) > 0 {
+		return ctrl.Result{RequeueAfter: POOL_OUTCOME_BUCKET}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
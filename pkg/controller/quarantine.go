@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+// getNetworkByName returns the cached network with the given name.
+func getNetworkByName(name string) *v1.Network {
+	for _, network := range networks {
+		if network.Name == name {
+			return network
+		}
+	}
+	return nil
+}
+
+// quarantineNetworks starts the quarantine of the networks released by a lease. the networks are not assigned
+// to leases until the quarantine ends. it does nothing if no quarantine is configured. networks already
+// quarantined for the lease are skipped, so a retried deletion neither extends their quarantine nor drops
+// their cleanup check.
+func (l *LeaseReconciler) quarantineNetworks(ctx context.Context, lease *v1.Lease, names []string) error {
+	if l.NetworkQuarantine <= 0 {
+		return nil
+	}
+
+	until := metav1.NewTime(time.Now().Add(l.NetworkQuarantine))
+	for _, name := range names {
+		network := getNetworkByName(name)
+		if network == nil || (network.Status.Quarantined && network.Status.ReleasedBy == lease.Name) {
+			continue
+		}
+
+		// a cleanup check only ends the quarantine it was run for.
+		if _, checked := network.Annotations[v1.NetworkCleanupCheckedAnnotation]; checked {
+			delete(network.Annotations, v1.NetworkCleanupCheckedAnnotation)
+			if err := l.Update(ctx, network); err != nil {
+				return fmt.Errorf("error resetting the cleanup check of network %s: %w", network.Name, err)
+			}
+		}
+
+		network.Status.Quarantined = true
+		network.Status.QuarantinedUntil = &until
+		network.Status.ReleasedBy = lease.Name
+		if err := l.Status().Update(ctx, network); err != nil {
+			return fmt.Errorf("error quarantining network %s: %w", network.Name, err)
+		}
+		log.FromContext(ctx).Info("network released by lease is quarantined", "Network", klog.KObj(network), "until", until)
+	}
+	updateNetworkTypeMetrics()
+	return nil
+}
+
+// getLeaseNetworkNames returns the names of the networks assigned to a lease.
+func getLeaseNetworkNames(lease *v1.Lease) []string {
+	var names []string
+	for _, ownerRef := range lease.OwnerReferences {
+		if ownerRef.Kind == "Network" {
+			names = append(names, ownerRef.Name)
+		}
+	}
+	return names
+}
+
+// networkQuarantineOver returns why the quarantine of a network is over, or how long it lasts otherwise.
+func networkQuarantineOver(network *v1.Network, now time.Time) (string, time.Duration) {
+	if network.Annotations[v1.NetworkCleanupCheckedAnnotation] == "true" {
+		return "the cleanup check passed", 0
+	}
+	if network.Status.QuarantinedUntil == nil {
+		return "no end was set", 0
+	}
+	remaining := network.Status.QuarantinedUntil.Sub(now)
+	if remaining <= 0 {
+		return "the cooldown expired", 0
+	}
+	return "", remaining
+}
+
+// endQuarantine returns a quarantined network to the available networks once its quarantine is over. it returns
+// how long the quarantine lasts otherwise.
+func (l *NetworkReconciler) endQuarantine(ctx context.Context, network *v1.Network) (time.Duration, error) {
+	reason, remaining := networkQuarantineOver(network, time.Now())
+	if remaining > 0 {
+		return remaining, nil
+	}
+
+	network.Status.Quarantined = false
+	network.Status.QuarantinedUntil = nil
+	if err := l.Status().Update(ctx, network); err != nil {
+		return 0, fmt.Errorf("error ending the quarantine of network %s: %w", network.Name, err)
+	}
+	if _, checked := network.Annotations[v1.NetworkCleanupCheckedAnnotation]; checked {
+		delete(network.Annotations, v1.NetworkCleanupCheckedAnnotation)
+		if err := l.Update(ctx, network); err != nil {
+			return 0, fmt.Errorf("error resetting the cleanup check of network %s: %w", network.Name, err)
+		}
+	}
+
+	log.FromContext(ctx).Info("quarantine of network ended, retrying unschedulable leases", "reason", reason)
+	updateNetworkTypeMetrics()
+	schedulingQueue.MoveAllToActiveQueue()
+	return 0, nil
+}
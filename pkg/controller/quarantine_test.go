@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func TestNetworkQuarantineOver(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := metav1.NewTime(now.Add(5 * time.Minute))
+
+	tests := []struct {
+		name            string
+		annotations     map[string]string
+		until           *metav1.Time
+		expectOver      bool
+		expectRemaining time.Duration
+	}{
+		{
+			name:            "cooling down",
+			until:           &until,
+			expectRemaining: 5 * time.Minute,
+		},
+		{
+			name:        "cleanup check passed",
+			annotations: map[string]string{v1.NetworkCleanupCheckedAnnotation: "true"},
+			until:       &until,
+			expectOver:  true,
+		},
+		{
+			name:            "cleanup check failed",
+			annotations:     map[string]string{v1.NetworkCleanupCheckedAnnotation: "false"},
+			until:           &until,
+			expectRemaining: 5 * time.Minute,
+		},
+		{
+			name:       "cooldown expired",
+			until:      &metav1.Time{Time: now.Add(-time.Second)},
+			expectOver: true,
+		},
+		{
+			name:       "no end",
+			expectOver: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := &v1.Network{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Status:     v1.NetworkStatus{Quarantined: true, QuarantinedUntil: tt.until},
+			}
+			reason, remaining := networkQuarantineOver(network, now)
+			if tt.expectOver && (reason == "" || remaining != 0) {
+				t.Errorf("expected the quarantine to be over, got reason %q and %v remaining", reason, remaining)
+			}
+			if !tt.expectOver && (reason != "" || remaining != tt.expectRemaining) {
+				t.Errorf("expected %v remaining, got reason %q and %v remaining", tt.expectRemaining, reason, remaining)
+			}
+		})
+	}
+}
+
+func TestQuarantinedAndDisabledNetworksUnavailable(t *testing.T) {
+	dc := "dc1"
+	pod := "pod1"
+	newNetwork := func(name, portGroup string, quarantined bool) *v1.Network {
+		return &v1.Network{
+			TypeMeta:   metav1.TypeMeta{Kind: "Network"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1.NetworkSpec{
+				PortGroupName:  portGroup,
+				DatacenterName: &dc,
+				PodName:        &pod,
+			},
+			Status: v1.NetworkStatus{Quarantined: quarantined},
+		}
+	}
+
+	oldPools := pools
+	defer func() { pools = oldPools }()
+	cleanupNetworks := setupTestNetworks(map[string]*v1.Network{
+		"default/net-1": newNetwork("net-1", "pg-1", false),
+		"default/net-2": newNetwork("net-2", "pg-2", true),
+		"default/net-3": newNetwork("net-3", "pg-3", true),
+		"default/net-4": newNetwork("net-4", "pg-4", false),
+	})
+	defer cleanupNetworks()
+	networks["default/net-4"].Spec.NoSchedule = true
+	cleanupLeases := setupTestLeases(map[string]*v1.Lease{})
+	defer cleanupLeases()
+
+	pool := &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool1", Namespace: "default"},
+		Spec: v1.PoolSpec{
+			OverCommitRatio: "1.0",
+			IBMPoolSpec:     v1.IBMPoolSpec{Pod: pod},
+			FailureDomainSpec: v1.FailureDomainSpec{
+				VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+					Topology: configv1.VSpherePlatformTopology{
+						Networks: []string{"/dc1/network/pg-1", "/dc1/network/pg-2", "/dc1/network/pg-3", "/dc1/network/pg-4"},
+					},
+				},
+			},
+		},
+	}
+	pools = map[string]*v1.Pool{"default/pool1": pool}
+
+	reconciler := &LeaseReconciler{}
+	available := reconciler.getAvailableNetworks(pool, v1.NetworkTypeSingleTenant)
+	if len(available) != 1 || available[0].Name != "net-1" {
+		t.Errorf("expected only net-1 to be available, got %d networks", len(available))
+	}
+
+	reconcilePoolStates(context.TODO())
+	if pool.Status.NetworkAvailable != 1 {
+		t.Errorf("expected 1 available network in the pool status, got %d", pool.Status.NetworkAvailable)
+	}
+
+	updateNetworkTypeMetrics()
+	if quarantined := testutil.ToFloat64(PoolNetworksQuarantined.WithLabelValues("default", "pool1")); quarantined != 2 {
+		t.Errorf("expected 2 quarantined networks, got %v", quarantined)
+	}
+	if availableByType := testutil.ToFloat64(PoolNetworksAvailableByType.WithLabelValues("default", "pool1", "single-tenant")); availableByType != 1 {
+		t.Errorf("expected 1 available single-tenant network, got %v", availableByType)
+	}
+}
+
+func TestQuarantineNetworksRetried(t *testing.T) {
+	network := &v1.Network{
+		TypeMeta:   metav1.TypeMeta{Kind: "Network"},
+		ObjectMeta: metav1.ObjectMeta{Name: "net-1", Namespace: "default"},
+	}
+	cleanupNetworks := setupTestNetworks(map[string]*v1.Network{"default/net-1": network})
+	defer cleanupNetworks()
+
+	lease := &v1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "lease-1", Namespace: "default"}}
+	reconciler := &LeaseReconciler{Client: newTestClient(network), NetworkQuarantine: time.Hour}
+	if err := reconciler.quarantineNetworks(context.TODO(), lease, []string{"net-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !network.Status.Quarantined || network.Status.ReleasedBy != "lease-1" {
+		t.Fatalf("expected the network to be quarantined for lease-1, got %+v", network.Status)
+	}
+	until := network.Status.QuarantinedUntil
+
+	// the cleanup check passes before the deletion of the lease is retried.
+	network.Annotations = map[string]string{v1.NetworkCleanupCheckedAnnotation: "true"}
+	if err := reconciler.quarantineNetworks(context.TODO(), lease, []string{"net-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if network.Status.QuarantinedUntil != until {
+		t.Errorf("expected the quarantine to end at %v, got %v", until, network.Status.QuarantinedUntil)
+	}
+	if network.Annotations[v1.NetworkCleanupCheckedAnnotation] != "true" {
+		t.Errorf("expected the cleanup check to be kept, got %v", network.Annotations)
+	}
+}
@@ -0,0 +1,181 @@
+package controller
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/forecast"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+)
+
+// maxBlockedBy caps the number of leases in status.blockedBy.
+const maxBlockedBy = 5
+
+// leaseQueueStatus is where a waiting lease stands in the scheduling queue.
+type leaseQueueStatus struct {
+	QueuePosition int
+	BlockedBy     []string
+	EstimatedWait *metav1.Duration
+}
+
+// leaseWaiting returns true if a lease waits in the scheduling queue for its pools. a fulfilled lease waiting to
+// grow holds its pools and does not wait.
+func leaseWaiting(lease *v1.Lease) bool {
+	if lease.DeletionTimestamp != nil || lease.Status.Allocated != nil {
+		return false
+	}
+	switch lease.Status.Phase {
+	case "", v1.PHASE_PENDING, v1.PHASE_PARTIAL:
+		return true
+	}
+	return false
+}
+
+// leaseNetworkType returns the network type of a lease, single-tenant if not set.
+func leaseNetworkType(lease *v1.Lease) v1.NetworkType {
+	if lease.Spec.NetworkType == "" {
+		return v1.NetworkTypeSingleTenant
+	}
+	return lease.Spec.NetworkType
+}
+
+// leasesCompete returns true if two leases may want the same pools: they need the same network type and do not
+// require different pools. pool selectors and taints are not compared.
+func leasesCompete(a, b *v1.Lease) bool {
+	if leaseNetworkType(a) != leaseNetworkType(b) {
+		return false
+	}
+	return a.Spec.RequiredPool == "" || b.Spec.RequiredPool == "" || a.Spec.RequiredPool == b.Spec.RequiredPool
+}
+
+// leaseQueueStatuses returns, keyed by lease, the position of each waiting lease among the waiting leases it
+// competes with, starting at 1, and the first of the competing leases the scheduling queue pops before it, as
+// ordered by before.
+func leaseQueueStatuses(waiting []*v1.Lease, before func(a, b *v1.Lease) bool) map[string]*leaseQueueStatus {
+	sorted := append([]*v1.Lease(nil), waiting...)
+	sort.Slice(sorted, func(i, j int) bool { return before(sorted[i], sorted[j]) })
+
+	statuses := make(map[string]*leaseQueueStatus, len(sorted))
+	for i, lease := range sorted {
+		status := &leaseQueueStatus{QueuePosition: 1}
+		for _, ahead := range sorted[:i] {
+			if !leasesCompete(ahead, lease) {
+				continue
+			}
+			status.QueuePosition++
+			if len(status.BlockedBy) < maxBlockedBy {
+				status.BlockedBy = append(status.BlockedBy, scheduler.LeaseKey(ahead))
+			}
+		}
+		statuses[scheduler.LeaseKey(lease)] = status
+	}
+	return statuses
+}
+
+// leaseEstimatedWait returns the forecast wait of a lease until it can get all its pools, rounded to the
+// minute, or nil if the forecast can not tell. forecasts are sorted from the shortest wait.
+func leaseEstimatedWait(lease *v1.Lease, forecasts []forecast.PoolForecast) *metav1.Duration {
+	pools := lease.Spec.Pools
+	if pools == 0 {
+		pools = 1
+	}
+	for _, poolForecast := range forecasts {
+		if poolForecast.ExpectedWaitSeconds == nil || (lease.Spec.RequiredPool != "" && poolForecast.Pool != lease.Spec.RequiredPool) {
+			continue
+		}
+		if pools--; pools == 0 {
+			wait := time.Duration(*poolForecast.ExpectedWaitSeconds * float64(time.Second))
+			return &metav1.Duration{Duration: wait.Round(time.Minute)}
+		}
+	}
+	return nil
+}
+
+// setLeaseQueueStatus sets the queue fields of a lease status, or clears them if status is nil. it returns
+// false if they did not change.
+func setLeaseQueueStatus(lease *v1.Lease, status *leaseQueueStatus) bool {
+	if status == nil {
+		status = &leaseQueueStatus{}
+	}
+	changed := lease.Status.QueuePosition != status.QueuePosition || len(lease.Status.BlockedBy) != len(status.BlockedBy)
+	for i := 0; !changed && i < len(status.BlockedBy); i++ {
+		changed = lease.Status.BlockedBy[i] != status.BlockedBy[i]
+	}
+	if (lease.Status.EstimatedWait == nil) != (status.EstimatedWait == nil) ||
+		(status.EstimatedWait != nil && lease.Status.EstimatedWait.Duration != status.EstimatedWait.Duration) {
+		changed = true
+	}
+	lease.Status.QueuePosition = status.QueuePosition
+	lease.Status.BlockedBy = status.BlockedBy
+	lease.Status.EstimatedWait = status.EstimatedWait
+	return changed
+}
+
+// updateLeaseQueueStatuses writes where each waiting lease stands in the scheduling queue to its status, and
+// clears it from the leases which stopped waiting.
+func (l *LeaseReconciler) updateLeaseQueueStatuses(ctx context.Context) {
+	now := time.Now()
+	records, err := l.listUsageRecords(ctx, now, l.ForecastWindow)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "unable to forecast the wait of leases")
+	}
+	before := schedulingQueue.LeaseOrder()
+
+	// the lock is only held to copy the leases and the forecast input of each network type they wait for.
+	reconcileLock.Lock()
+	snapshot := make(map[string]*v1.Lease, len(leases))
+	var waiting []*v1.Lease
+	inputs := make(map[v1.NetworkType]forecast.Input)
+	for key, lease := range leases {
+		snapshot[key] = lease.DeepCopy()
+		if !leaseWaiting(lease) {
+			continue
+		}
+		waiting = append(waiting, snapshot[key])
+		networkType := leaseNetworkType(lease)
+		if _, ok := inputs[networkType]; !ok && err == nil {
+			inputs[networkType], _ = l.forecastInput(networkType, now, l.ForecastWindow, records)
+		}
+	}
+	reconcileLock.Unlock()
+
+	statuses := leaseQueueStatuses(waiting, before)
+	// leases of the same network type and shape share a forecast.
+	type forecastKey struct {
+		networkType v1.NetworkType
+		shape       forecast.Shape
+	}
+	forecasts := make(map[forecastKey][]forecast.PoolForecast)
+	for _, lease := range waiting {
+		networkType := leaseNetworkType(lease)
+		input, ok := inputs[networkType]
+		if !ok {
+			continue
+		}
+		key := forecastKey{
+			networkType: networkType,
+			shape:       forecast.Shape{VCpus: lease.Spec.VCpus, Memory: lease.Spec.Memory, Networks: lease.Spec.Networks},
+		}
+		if _, ok := forecasts[key]; !ok {
+			forecasts[key] = forecast.Forecast(input, key.shape)
+		}
+		statuses[scheduler.LeaseKey(lease)].EstimatedWait = leaseEstimatedWait(lease, forecasts[key])
+	}
+
+	for key, lease := range snapshot {
+		modified := lease.DeepCopy()
+		if !setLeaseQueueStatus(modified, statuses[key]) {
+			continue
+		}
+		if err := l.Status().Patch(ctx, modified, client.MergeFrom(lease)); client.IgnoreNotFound(err) != nil {
+			log.FromContext(ctx).Error(err, "unable to update the queue position of lease", "Lease", klog.KObj(lease))
+		}
+	}
+}
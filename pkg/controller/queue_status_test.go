@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/forecast"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+)
+
+func newQueuedLease(name string, age time.Duration, mutate func(*v1.Lease)) *v1.Lease {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ci", CreationTimestamp: metav1.NewTime(created.Add(-age))},
+		Spec:       v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 1, NetworkType: v1.NetworkTypeSingleTenant},
+		Status:     v1.LeaseStatus{Phase: v1.PHASE_PENDING},
+	}
+	if mutate != nil {
+		mutate(lease)
+	}
+	return lease
+}
+
+func TestLeaseQueueStatuses(t *testing.T) {
+	waiting := []*v1.Lease{
+		newQueuedLease("new", time.Minute, nil),
+		newQueuedLease("old", time.Hour, nil),
+		newQueuedLease("urgent", 0, func(l *v1.Lease) { l.Spec.Priority = 10 }),
+		newQueuedLease("unset-network-type", 30*time.Minute, func(l *v1.Lease) { l.Spec.NetworkType = "" }),
+		newQueuedLease("multi-tenant", 2*time.Hour, func(l *v1.Lease) { l.Spec.NetworkType = v1.NetworkTypeMultiTenant }),
+		newQueuedLease("pool-a", 3*time.Hour, func(l *v1.Lease) { l.Spec.RequiredPool = "pool-a" }),
+		newQueuedLease("pool-b", 2*time.Minute, func(l *v1.Lease) { l.Spec.RequiredPool = "pool-b" }),
+	}
+
+	statuses := leaseQueueStatuses(waiting, scheduler.NewSchedulingQueue(0, 0, 0).LeaseOrder())
+	tests := []struct {
+		lease         string
+		wantPosition  int
+		wantBlockedBy []string
+	}{
+		{lease: "urgent", wantPosition: 1},
+		{lease: "pool-a", wantPosition: 2, wantBlockedBy: []string{"ci/urgent"}},
+		{lease: "multi-tenant", wantPosition: 1},
+		{lease: "old", wantPosition: 3, wantBlockedBy: []string{"ci/urgent", "ci/pool-a"}},
+		{lease: "unset-network-type", wantPosition: 4, wantBlockedBy: []string{"ci/urgent", "ci/pool-a", "ci/old"}},
+		// leases requiring different pools do not compete.
+		{lease: "pool-b", wantPosition: 4, wantBlockedBy: []string{"ci/urgent", "ci/old", "ci/unset-network-type"}},
+		{lease: "new", wantPosition: 6, wantBlockedBy: []string{"ci/urgent", "ci/pool-a", "ci/old", "ci/unset-network-type", "ci/pool-b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.lease, func(t *testing.T) {
+			status := statuses["ci/"+tt.lease]
+			if status == nil || status.QueuePosition != tt.wantPosition || !reflect.DeepEqual(status.BlockedBy, tt.wantBlockedBy) {
+				t.Errorf("expected position %d blocked by %v, got %+v", tt.wantPosition, tt.wantBlockedBy, status)
+			}
+		})
+	}
+
+	var many []*v1.Lease
+	for i := 0; i < maxBlockedBy+3; i++ {
+		many = append(many, newQueuedLease(string(rune('a'+i)), time.Duration(i)*time.Minute, nil))
+	}
+	if status := leaseQueueStatuses(many, scheduler.NewSchedulingQueue(0, 0, 0).LeaseOrder())["ci/a"]; status.QueuePosition != maxBlockedBy+3 || len(status.BlockedBy) != maxBlockedBy {
+		t.Errorf("expected the blocking leases to be capped, got %+v", status)
+	}
+}
+
+func TestLeaseEstimatedWait(t *testing.T) {
+	wait := func(seconds float64) *float64 { return &seconds }
+	forecasts := []forecast.PoolForecast{
+		{Pool: "pool-a", ExpectedWaitSeconds: wait(0)},
+		{Pool: "pool-b", ExpectedWaitSeconds: wait(130)},
+		{Pool: "pool-c", ExpectedWaitSeconds: wait(3600)},
+		{Pool: "pool-d"},
+	}
+
+	tests := []struct {
+		name  string
+		lease *v1.Lease
+		// want is nil if the wait can not be estimated.
+		want *metav1.Duration
+	}{
+		{name: "shortest", lease: newQueuedLease("lease", 0, nil), want: &metav1.Duration{}},
+		{name: "multi-pool", lease: newQueuedLease("lease", 0, func(l *v1.Lease) { l.Spec.Pools = 2 }), want: &metav1.Duration{Duration: 2 * time.Minute}},
+		{name: "required pool", lease: newQueuedLease("lease", 0, func(l *v1.Lease) { l.Spec.RequiredPool = "pool-c" }), want: &metav1.Duration{Duration: time.Hour}},
+		{name: "required pool without estimate", lease: newQueuedLease("lease", 0, func(l *v1.Lease) { l.Spec.RequiredPool = "pool-d" })},
+		{name: "more pools than estimates", lease: newQueuedLease("lease", 0, func(l *v1.Lease) { l.Spec.Pools = 4 })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := leaseEstimatedWait(tt.lease, forecasts); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSetLeaseQueueStatus(t *testing.T) {
+	lease := newQueuedLease("lease", 0, nil)
+	status := &leaseQueueStatus{QueuePosition: 2, BlockedBy: []string{"ci/old"}, EstimatedWait: &metav1.Duration{Duration: time.Minute}}
+
+	if !setLeaseQueueStatus(lease, status) || lease.Status.QueuePosition != 2 || lease.Status.EstimatedWait.Duration != time.Minute {
+		t.Errorf("expected the queue status to be set, got %+v", lease.Status)
+	}
+	same := &leaseQueueStatus{QueuePosition: 2, BlockedBy: []string{"ci/old"}, EstimatedWait: &metav1.Duration{Duration: time.Minute}}
+	if setLeaseQueueStatus(lease, same) {
+		t.Error("expected an unchanged queue status")
+	}
+	if !setLeaseQueueStatus(lease, &leaseQueueStatus{QueuePosition: 2, BlockedBy: []string{"ci/other"}, EstimatedWait: same.EstimatedWait}) {
+		t.Error("expected a change of the blocking leases")
+	}
+	if !setLeaseQueueStatus(lease, nil) || lease.Status.QueuePosition != 0 || lease.Status.BlockedBy != nil || lease.Status.EstimatedWait != nil {
+		t.Errorf("expected the queue status to be cleared, got %+v", lease.Status)
+	}
+}
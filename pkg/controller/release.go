@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils/conditions"
+)
+
+const (
+	// DEFAULT_RELEASE_GRACE_PERIOD is how long a lease without spec.releaseGracePeriod waits for its cleanup Job
+	DEFAULT_RELEASE_GRACE_PERIOD = 30 * time.Minute
+
+	// CLEANUP_JOB_SUFFIX is appended to the name of a lease to name its cleanup Job
+	CLEANUP_JOB_SUFFIX = "-cleanup"
+)
+
+// LoadCleanupJobTemplate reads the Job template run when a lease holding resources is deleted.
+func LoadCleanupJobTemplate(path string) (*batchv1.JobTemplateSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cleanup job template %s: %w", path, err)
+	}
+	template := &batchv1.JobTemplateSpec{}
+	if err := yaml.UnmarshalStrict(data, template); err != nil {
+		return nil, fmt.Errorf("error parsing cleanup job template %s: %w", path, err)
+	}
+	return template, nil
+}
+
+// leaseHoldsResources returns true if pools or networks are assigned to the lease.
+func leaseHoldsResources(lease *v1.Lease) bool {
+	for _, ownerRef := range lease.OwnerReferences {
+		if ownerRef.Kind == v1.PoolKind || ownerRef.Kind == "Network" {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseGracePeriod returns how long the resources of a deleted lease stay blocked. it is 0 if the lease holds
+// no resources, or if it has no grace period and no cleanup Job is run for it.
+func releaseGracePeriod(lease *v1.Lease, cleanupJob bool) time.Duration {
+	if !leaseHoldsResources(lease) {
+		return 0
+	}
+	if lease.Spec.ReleaseGracePeriod != nil {
+		return lease.Spec.ReleaseGracePeriod.Duration
+	}
+	if cleanupJob {
+		return DEFAULT_RELEASE_GRACE_PERIOD
+	}
+	return 0
+}
+
+// cleanupJobName returns the name of the cleanup Job of a lease.
+func cleanupJobName(lease *v1.Lease) string {
+	name := lease.Name
+	if len(name) > 63-len(CLEANUP_JOB_SUFFIX) {
+		name = name[:63-len(CLEANUP_JOB_SUFFIX)]
+	}
+	return name + CLEANUP_JOB_SUFFIX
+}
+
+// jobFinished returns true if the Job completed or failed.
+func jobFinished(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) &&
+			condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// leaseCleanupDone returns why the resources of a releasing lease can be released, or how long to wait before
+// checking again. job is the cleanup Job of the lease, nil if none was created.
+func leaseCleanupDone(lease *v1.Lease, job *batchv1.Job, gracePeriod time.Duration, now time.Time) (string, time.Duration) {
+	if lease.Annotations[v1.LeaseCleanupCompleteAnnotation] == "true" ||
+		conditions.IsTrue(lease, v1.LeaseConditionTypeCleanupComplete) {
+		return "the holder confirmed the cleanup", 0
+	}
+	if job != nil && jobFinished(job) {
+		return fmt.Sprintf("cleanup job %s finished", job.Name), 0
+	}
+	remaining := lease.DeletionTimestamp.Add(gracePeriod).Sub(now)
+	if remaining <= 0 {
+		return fmt.Sprintf("the release grace period of %v expired", gracePeriod), 0
+	}
+	return "", remaining
+}
+
+// newCleanupJob returns the cleanup Job of a lease from the template. the Job is owned by the lease and its
+// containers get the name and namespace of the lease.
+func newCleanupJob(lease *v1.Lease, template *batchv1.JobTemplateSpec) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: *template.ObjectMeta.DeepCopy(),
+		Spec:       *template.Spec.DeepCopy(),
+	}
+	job.Name = cleanupJobName(lease)
+	job.Namespace = lease.Namespace
+	controller := true
+	job.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: v1.GroupVersion.String(),
+		Kind:       v1.LeaseKind,
+		Name:       lease.Name,
+		UID:        lease.UID,
+		Controller: &controller,
+	}}
+
+	env := []corev1.EnvVar{
+		{Name: "LEASE_NAME", Value: lease.Name},
+		{Name: "LEASE_NAMESPACE", Value: lease.Namespace},
+	}
+	for i := range job.Spec.Template.Spec.Containers {
+		job.Spec.Template.Spec.Containers[i].Env = append(job.Spec.Template.Spec.Containers[i].Env, env...)
+	}
+	return job
+}
+
+// ensureCleanupJob creates the cleanup Job of a lease if it does not exist yet and returns it.
+func (l *LeaseReconciler) ensureCleanupJob(ctx context.Context, lease *v1.Lease) (*batchv1.Job, error) {
+	job := &batchv1.Job{}
+	err := l.Get(ctx, types.NamespacedName{Namespace: lease.Namespace, Name: cleanupJobName(lease)}, job)
+	if err == nil {
+		return job, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("error getting cleanup job of lease %s: %w", lease.Name, err)
+	}
+
+	job = newCleanupJob(lease, l.CleanupJobTemplate)
+	if err := l.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("error creating cleanup job of lease %s: %w", lease.Name, err)
+	}
+	log.FromContext(ctx).Info("created cleanup job", "Job", klog.KObj(job))
+	return job, nil
+}
+
+// releaseLease keeps the resources of a deleted lease blocked while its holder cleans up. it returns how long to
+// wait before checking the cleanup again, or 0 once the resources can be released.
+func (l *LeaseReconciler) releaseLease(ctx context.Context, lease *v1.Lease) (time.Duration, error) {
+	gracePeriod := releaseGracePeriod(lease, l.CleanupJobTemplate != nil)
+	if gracePeriod == 0 {
+		return 0, nil
+	}
+
+	leaseKey := fmt.Sprintf("%s/%s", lease.Namespace, lease.Name)
+	leases[leaseKey] = lease
+	schedulingQueue.Delete(leaseKey)
+	updateSchedulingQueueMetrics()
+
+	if lease.Status.Phase != v1.PHASE_RELEASING {
+		log.FromContext(ctx).Info("lease is RELEASING", "gracePeriod", gracePeriod)
+		lease.Status.Phase = v1.PHASE_RELEASING
+		setLeaseReady(lease)
+		LeaseTransitionsTotal.With(prometheus.Labels{
+			"namespace":   lease.Namespace,
+			"networkType": string(lease.Spec.NetworkType),
+			"phase":       string(v1.PHASE_RELEASING),
+		}).Inc()
+		if err := l.Status().Update(ctx, lease); err != nil {
+			return 0, fmt.Errorf("error setting lease %s to releasing: %w", lease.Name, err)
+		}
+		l.Recorder.Eventf(lease, corev1.EventTypeNormal, v1.ReasonLeaseReleasing, "resources stay blocked for up to %v while the holder cleans up", gracePeriod)
+		updateLeaseMetrics()
+	}
+
+	reason, requeueAfter := leaseCleanupDone(lease, nil, gracePeriod, time.Now())
+	if requeueAfter > 0 && l.CleanupJobTemplate != nil {
+		job, err := l.ensureCleanupJob(ctx, lease)
+		if err != nil {
+			return 0, err
+		}
+		reason, requeueAfter = leaseCleanupDone(lease, job, gracePeriod, time.Now())
+	}
+	if requeueAfter > 0 {
+		log.FromContext(ctx).V(2).Info("lease is waiting for its cleanup", "releaseIn", requeueAfter.Round(time.Second))
+		return requeueAfter, nil
+	}
+
+	log.FromContext(ctx).Info("releasing lease", "reason", reason)
+	l.Recorder.Eventf(lease, corev1.EventTypeNormal, v1.ReasonLeaseReleased, "resources released, %s", reason)
+	return 0, nil
+}
@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func TestReleaseGracePeriod(t *testing.T) {
+	holding := []metav1.OwnerReference{{Kind: v1.PoolKind, Name: "pool-1"}}
+	gracePeriod := &metav1.Duration{Duration: 10 * time.Minute}
+
+	tests := []struct {
+		name        string
+		ownerRefs   []metav1.OwnerReference
+		gracePeriod *metav1.Duration
+		cleanupJob  bool
+		expected    time.Duration
+	}{
+		{
+			name:      "released right away by default",
+			ownerRefs: holding,
+			expected:  0,
+		},
+		{
+			name:        "grace period",
+			ownerRefs:   holding,
+			gracePeriod: gracePeriod,
+			expected:    10 * time.Minute,
+		},
+		{
+			name:       "default grace period with a cleanup job",
+			ownerRefs:  holding,
+			cleanupJob: true,
+			expected:   DEFAULT_RELEASE_GRACE_PERIOD,
+		},
+		{
+			name:        "grace period with a cleanup job",
+			ownerRefs:   holding,
+			gracePeriod: gracePeriod,
+			cleanupJob:  true,
+			expected:    10 * time.Minute,
+		},
+		{
+			name:        "no resources held",
+			gracePeriod: gracePeriod,
+			cleanupJob:  true,
+			expected:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{
+				ObjectMeta: metav1.ObjectMeta{OwnerReferences: tt.ownerRefs},
+				Spec:       v1.LeaseSpec{ReleaseGracePeriod: tt.gracePeriod},
+			}
+			if result := releaseGracePeriod(lease, tt.cleanupJob); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestLeaseCleanupDone(t *testing.T) {
+	deleted := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := deleted.Add(4 * time.Minute)
+	finishedJob := func(conditionType batchv1.JobConditionType) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "lease-cleanup"},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: conditionType, Status: corev1.ConditionTrue},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		annotations   map[string]string
+		conditions    []v1.Condition
+		job           *batchv1.Job
+		gracePeriod   time.Duration
+		expectRelease bool
+		expectRequeue time.Duration
+	}{
+		{
+			name:          "waiting",
+			gracePeriod:   10 * time.Minute,
+			job:           &batchv1.Job{},
+			expectRequeue: 6 * time.Minute,
+		},
+		{
+			name:          "confirmed by annotation",
+			annotations:   map[string]string{v1.LeaseCleanupCompleteAnnotation: "true"},
+			gracePeriod:   10 * time.Minute,
+			expectRelease: true,
+		},
+		{
+			name:          "confirmed by condition",
+			conditions:    []v1.Condition{{Type: v1.LeaseConditionTypeCleanupComplete, Status: v1.ConditionTrue}},
+			gracePeriod:   10 * time.Minute,
+			expectRelease: true,
+		},
+		{
+			name:          "condition not true",
+			conditions:    []v1.Condition{{Type: v1.LeaseConditionTypeCleanupComplete, Status: v1.ConditionFalse}},
+			gracePeriod:   10 * time.Minute,
+			expectRequeue: 6 * time.Minute,
+		},
+		{
+			name:          "cleanup job complete",
+			job:           finishedJob(batchv1.JobComplete),
+			gracePeriod:   10 * time.Minute,
+			expectRelease: true,
+		},
+		{
+			name:          "cleanup job failed",
+			job:           finishedJob(batchv1.JobFailed),
+			gracePeriod:   10 * time.Minute,
+			expectRelease: true,
+		},
+		{
+			name:          "grace period expired",
+			gracePeriod:   3 * time.Minute,
+			expectRelease: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations:       tt.annotations,
+					DeletionTimestamp: &metav1.Time{Time: deleted},
+				},
+				Status: v1.LeaseStatus{Conditions: tt.conditions},
+			}
+			reason, requeueAfter := leaseCleanupDone(lease, tt.job, tt.gracePeriod, now)
+			if tt.expectRelease && (reason == "" || requeueAfter != 0) {
+				t.Errorf("expected the lease to be released, got reason %q and requeue after %v", reason, requeueAfter)
+			}
+			if !tt.expectRelease && (reason != "" || requeueAfter != tt.expectRequeue) {
+				t.Errorf("expected a requeue after %v, got reason %q and requeue after %v", tt.expectRequeue, reason, requeueAfter)
+			}
+		})
+	}
+}
+
+func TestNewCleanupJob(t *testing.T) {
+	template := &batchv1.JobTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "cleanup"}},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "cleanup",
+						Image: "cleanup:latest",
+						Env:   []corev1.EnvVar{{Name: "DRY_RUN", Value: "false"}},
+					}},
+				},
+			},
+		},
+	}
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      strings.Repeat("l", 70),
+			Namespace: "vsphere-infra-helpers",
+			UID:       "uid",
+		},
+	}
+
+	job := newCleanupJob(lease, template)
+
+	if len(job.Name) != 63 || !strings.HasSuffix(job.Name, CLEANUP_JOB_SUFFIX) {
+		t.Errorf("expected a 63 character name ending with %s, got %s", CLEANUP_JOB_SUFFIX, job.Name)
+	}
+	if job.Namespace != lease.Namespace || job.Labels["app"] != "cleanup" {
+		t.Errorf("expected the job in the lease namespace with the template labels, got %s %v", job.Namespace, job.Labels)
+	}
+	if len(job.OwnerReferences) != 1 || job.OwnerReferences[0].UID != lease.UID || !*job.OwnerReferences[0].Controller {
+		t.Errorf("expected the job to be controlled by the lease, got %v", job.OwnerReferences)
+	}
+	env := job.Spec.Template.Spec.Containers[0].Env
+	if len(env) != 3 || env[1].Name != "LEASE_NAME" || env[1].Value != lease.Name || env[2].Value != lease.Namespace {
+		t.Errorf("expected the lease name and namespace in the environment, got %v", env)
+	}
+	if len(template.Spec.Template.Spec.Containers[0].Env) != 1 {
+		t.Errorf("expected the template to be left unchanged")
+	}
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils/conditions"
+)
+
+// leaseNeedsResize returns true if the vcpus, memory or networks in the spec of a lease which was fulfilled
+// differ from the resources it holds.
+func leaseNeedsResize(lease *v1.Lease) bool {
+	if lease.Status.Allocated == nil {
+		return false
+	}
+	allocated := *lease.Status.Allocated
+	return allocated.VCpus != lease.Spec.VCpus ||
+		allocated.Memory != lease.Spec.Memory ||
+		allocated.Networks != lease.Spec.Networks
+}
+
+// getLeasePools returns the cached pools assigned to a lease.
+func getLeasePools(lease *v1.Lease) []*v1.Pool {
+	var assigned []*v1.Pool
+	for _, poolRef := range utils.GetLeasePoolRefs(lease) {
+		if pool, exists := pools[fmt.Sprintf("%s/%s", lease.Namespace, poolRef.Name)]; exists {
+			assigned = append(assigned, pool)
+		}
+	}
+	return assigned
+}
+
+// resizeHeadroom returns an error naming the first assigned pool without the vCPUs or memory a lease grows by.
+// the available resources of the pools must be up to date.
+func resizeHeadroom(lease *v1.Lease, assignedPools []*v1.Pool) error {
+	allocated := utils.GetLeaseAllocatedResources(lease)
+	vcpus := lease.Spec.VCpus - allocated.VCpus
+	memory := lease.Spec.Memory - allocated.Memory
+
+	for _, pool := range assignedPools {
+		if vcpus > 0 && pool.Status.VCpusAvailable < vcpus {
+			return fmt.Errorf("pool %s has %d of %d additional vCPUs available", pool.Name, pool.Status.VCpusAvailable, vcpus)
+		}
+		if memory > 0 && pool.Status.MemoryAvailable < memory {
+			return fmt.Errorf("pool %s has %dGB of %dGB additional memory available", pool.Name, pool.Status.MemoryAvailable, memory)
+		}
+	}
+	return nil
+}
+
+// releaseSurplusNetworks drops the networks a lease holds in each pool beyond spec.networks. the first pool
+// keeps the networks assigned first, the other pools keep the networks on the same VLANs, so the networks
+// kept stay common to the pools.
+func releaseSurplusNetworks(lease *v1.Lease, assignedPools []*v1.Pool) []string {
+	// the networks of the lease in each pool, in the order they were assigned.
+	poolNetworks := make(map[string][]*v1.Network)
+	inPool := make(map[string]bool)
+	for _, ownerRef := range lease.OwnerReferences {
+		if ownerRef.Kind != "Network" {
+			continue
+		}
+		for _, pool := range assignedPools {
+			if network, exists := getNetworksForPool(pool)[ownerRef.Name]; exists {
+				poolNetworks[pool.Name] = append(poolNetworks[pool.Name], network)
+				inPool[network.Name] = true
+				break
+			}
+		}
+	}
+
+	keep := make(map[string]bool)
+	keptVLANs := make(map[string]bool)
+	for i, pool := range assignedPools {
+		kept := 0
+		if i > 0 {
+			for _, network := range poolNetworks[pool.Name] {
+				if kept < lease.Spec.Networks && keptVLANs[network.Spec.VlanId] {
+					keep[network.Name] = true
+					kept++
+				}
+			}
+		}
+		// pools without enough networks on the VLANs of the first pool keep the networks assigned first.
+		for _, network := range poolNetworks[pool.Name] {
+			if kept < lease.Spec.Networks && !keep[network.Name] {
+				keep[network.Name] = true
+				kept++
+				if i == 0 {
+					keptVLANs[network.Spec.VlanId] = true
+				}
+			}
+		}
+	}
+
+	var released []string
+	ownerRefs := make([]metav1.OwnerReference, 0, len(lease.OwnerReferences))
+	for _, ownerRef := range lease.OwnerReferences {
+		if ownerRef.Kind == "Network" && inPool[ownerRef.Name] && !keep[ownerRef.Name] {
+			released = append(released, ownerRef.Name)
+			continue
+		}
+		ownerRefs = append(ownerRefs, ownerRef)
+	}
+
+	lease.OwnerReferences = ownerRefs
+	return released
+}
+
+// resizeLease applies updates of the vcpus, memory or networks of a lease which was fulfilled. additional vCPUs
+// and memory are only granted if every assigned pool has the headroom, additional networks are allocated on the
+// same pools and surplus networks are released. the lease stays fulfilled with its previous resources and the
+// Resizing condition while it waits to grow. persisted are the owner references of the lease before it was resized.
+func (l *LeaseReconciler) resizeLease(ctx context.Context, lease *v1.Lease, persisted []metav1.OwnerReference) error {
+	reconcilePoolStates(ctx)
+	assignedPools := getLeasePools(lease)
+	allocated := utils.GetLeaseAllocatedResources(lease)
+
+	logger := log.FromContext(ctx)
+	logger.Info("resizing lease", "from", allocated, "to", v1.LeaseResources{VCpus: lease.Spec.VCpus, Memory: lease.Spec.Memory, Networks: lease.Spec.Networks})
+
+	if err := resizeHeadroom(lease, assignedPools); err != nil {
+		logger.V(2).Info("lease can not grow yet", "reason", err.Error())
+		conditions.Set(lease, conditions.TrueConditionWithReason(
+			v1.LeaseConditionTypeResizing,
+			v1.ReasonLeaseResizing,
+			"%s", err.Error(),
+		))
+		return l.Status().Update(ctx, lease)
+	}
+	conditions.Set(lease, conditions.FalseCondition(
+		v1.LeaseConditionTypeResizing,
+	))
+
+	allocated.VCpus = lease.Spec.VCpus
+	allocated.Memory = lease.Spec.Memory
+	lease.Status.Allocated = &allocated
+
+	released := releaseSurplusNetworks(lease, assignedPools)
+	if len(released) > 0 {
+		logger.Info("released surplus networks", "networks", released)
+	}
+
+	// scheduleLease tops up the networks of each pool and rebuilds the status of the lease.
+	if err := l.scheduleLease(ctx, lease, persisted); err != nil {
+		return err
+	}
+	return l.quarantineNetworks(ctx, lease, released)
+}
@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils/conditions"
+)
+
+func TestLeaseNeedsResize(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      v1.LeaseSpec
+		allocated *v1.LeaseResources
+		expected  bool
+	}{
+		{
+			name:     "never fulfilled",
+			spec:     v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 1},
+			expected: false,
+		},
+		{
+			name:      "unchanged",
+			spec:      v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 1},
+			allocated: &v1.LeaseResources{VCpus: 24, Memory: 96, Networks: 1},
+			expected:  false,
+		},
+		{
+			name:      "more vcpus",
+			spec:      v1.LeaseSpec{VCpus: 32, Memory: 96, Networks: 1},
+			allocated: &v1.LeaseResources{VCpus: 24, Memory: 96, Networks: 1},
+			expected:  true,
+		},
+		{
+			name:      "less memory",
+			spec:      v1.LeaseSpec{VCpus: 24, Memory: 64, Networks: 1},
+			allocated: &v1.LeaseResources{VCpus: 24, Memory: 96, Networks: 1},
+			expected:  true,
+		},
+		{
+			name:      "more networks",
+			spec:      v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 2},
+			allocated: &v1.LeaseResources{VCpus: 24, Memory: 96, Networks: 1},
+			expected:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{Spec: tt.spec, Status: v1.LeaseStatus{Allocated: tt.allocated}}
+			if result := leaseNeedsResize(lease); result != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestResizeHeadroom(t *testing.T) {
+	newPool := func(name string, vcpus, memory int) *v1.Pool {
+		return &v1.Pool{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     v1.PoolStatus{VCpusAvailable: vcpus, MemoryAvailable: memory},
+		}
+	}
+	assignedPools := []*v1.Pool{newPool("pool-1", 16, 64), newPool("pool-2", 8, 128)}
+	allocated := &v1.LeaseResources{VCpus: 24, Memory: 96, Networks: 1}
+
+	tests := []struct {
+		name      string
+		spec      v1.LeaseSpec
+		expectErr bool
+	}{
+		{
+			name: "fits every pool",
+			spec: v1.LeaseSpec{VCpus: 32, Memory: 160},
+		},
+		{
+			name:      "vcpus exceed one pool",
+			spec:      v1.LeaseSpec{VCpus: 40, Memory: 96},
+			expectErr: true,
+		},
+		{
+			name:      "memory exceeds one pool",
+			spec:      v1.LeaseSpec{VCpus: 24, Memory: 192},
+			expectErr: true,
+		},
+		{
+			name: "shrinking always fits",
+			spec: v1.LeaseSpec{VCpus: 8, Memory: 32},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{Spec: tt.spec, Status: v1.LeaseStatus{Allocated: allocated}}
+			err := resizeHeadroom(lease, assignedPools)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("expected error %v, got %v", tt.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestResizeLeaseBlocked(t *testing.T) {
+	oldPools := pools
+	defer func() { pools = oldPools }()
+	pools = map[string]*v1.Pool{
+		"ci/pool-a": {
+			TypeMeta:   metav1.TypeMeta{Kind: "Pool"},
+			ObjectMeta: metav1.ObjectMeta{Name: "pool-a", Namespace: "ci"},
+			Spec:       v1.PoolSpec{VCpus: 40, Memory: 192, OverCommitRatio: "1.0"},
+		},
+	}
+
+	running := newQueuedLease("running", time.Hour, func(l *v1.Lease) {
+		l.Spec.VCpus = 48
+		l.OwnerReferences = []metav1.OwnerReference{{Kind: "Pool", Name: "pool-a"}}
+		l.Status.Phase = v1.PHASE_FULFILLED
+		l.Status.Allocated = &v1.LeaseResources{VCpus: 24, Memory: 96, Networks: 1}
+		conditions.Set(l, conditions.TrueCondition(v1.LeaseConditionTypeFulfilled))
+		setLeaseReady(l)
+	})
+	pending := newQueuedLease("pending", time.Minute, func(l *v1.Lease) { l.Spec.VCpus = 16 })
+	defer setupTestLeases(map[string]*v1.Lease{"ci/running": running, "ci/pending": pending})()
+
+	l := &LeaseReconciler{Client: newTestClient(running)}
+	if err := l.resizeLease(context.TODO(), running, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// the pending lease may take the 16 vCPUs left while the running lease waits for 24.
+	if running.Status.Phase != v1.PHASE_FULFILLED || running.Status.Allocated.VCpus != 24 {
+		t.Errorf("expected the lease to stay fulfilled with its previous resources, got %s with %+v", running.Status.Phase, running.Status.Allocated)
+	}
+	for _, conditionType := range []v1.ConditionType{v1.LeaseConditionTypeFulfilled, v1.LeaseConditionTypeReady, v1.LeaseConditionTypeResizing} {
+		if !conditions.IsTrue(running, conditionType) {
+			t.Errorf("expected the %s condition to be true", conditionType)
+		}
+	}
+	if condition := conditions.Get(running, v1.LeaseConditionTypeResizing); condition.Reason != v1.ReasonLeaseResizing {
+		t.Errorf("expected reason %s, got %s", v1.ReasonLeaseResizing, condition.Reason)
+	}
+	if leaseWaiting(running) {
+		t.Errorf("expected a lease waiting to grow not to wait in the queue")
+	}
+	if blocker := leaseHeldBackBy(pending, scheduler.NewSchedulingQueue(0, 0, 0).LeaseOrder()); blocker != nil {
+		t.Errorf("expected the pending lease to be scheduled, held back by %s", scheduler.LeaseKey(blocker))
+	}
+}
+
+func TestReleaseSurplusNetworks(t *testing.T) {
+	dc := "dc1"
+	pod := "pod1"
+	newNetwork := func(name, portGroup, vlan string) *v1.Network {
+		return &v1.Network{
+			TypeMeta:   metav1.TypeMeta{Kind: "Network"},
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.NetworkSpec{
+				PortGroupName:  portGroup,
+				VlanId:         vlan,
+				DatacenterName: &dc,
+				PodName:        &pod,
+			},
+		}
+	}
+	newPool := func(name string, portGroups ...string) *v1.Pool {
+		var topologyNetworks []string
+		for _, portGroup := range portGroups {
+			topologyNetworks = append(topologyNetworks, "/dc1/network/"+portGroup)
+		}
+		return &v1.Pool{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.PoolSpec{
+				IBMPoolSpec: v1.IBMPoolSpec{Pod: pod},
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Topology: configv1.VSpherePlatformTopology{Networks: topologyNetworks},
+					},
+				},
+			},
+		}
+	}
+
+	cleanupNetworks := setupTestNetworks(map[string]*v1.Network{
+		"net-a1": newNetwork("net-a1", "pg-a1", "101"),
+		"net-a2": newNetwork("net-a2", "pg-a2", "102"),
+		"net-a3": newNetwork("net-a3", "pg-a3", "103"),
+		"net-b1": newNetwork("net-b1", "pg-b1", "101"),
+		"net-b2": newNetwork("net-b2", "pg-b2", "102"),
+		"net-b3": newNetwork("net-b3", "pg-b3", "103"),
+		"net-b4": newNetwork("net-b4", "pg-b4", "104"),
+	})
+	defer cleanupNetworks()
+	assignedPools := []*v1.Pool{
+		newPool("pool-a", "pg-a1", "pg-a2", "pg-a3"),
+		newPool("pool-b", "pg-b1", "pg-b2", "pg-b3", "pg-b4"),
+	}
+
+	tests := []struct {
+		name            string
+		networks        int
+		ownerRefs       []string
+		expectReleased  []string
+		expectRemaining []string
+	}{
+		{
+			name:            "first pool keeps the networks assigned first",
+			networks:        1,
+			ownerRefs:       []string{"pool-a", "net-a1", "net-a2", "pool-b", "net-b1", "net-b2"},
+			expectReleased:  []string{"net-a2", "net-b2"},
+			expectRemaining: []string{"pool-a", "net-a1", "pool-b", "net-b1"},
+		},
+		{
+			name:            "other pools keep the VLANs of the first pool",
+			networks:        2,
+			ownerRefs:       []string{"pool-a", "pool-b", "net-b3", "net-a1", "net-b2", "net-a3", "net-b1", "net-a2"},
+			expectReleased:  []string{"net-b2", "net-a2"},
+			expectRemaining: []string{"pool-a", "pool-b", "net-b3", "net-a1", "net-a3", "net-b1"},
+		},
+		{
+			name:            "networks on other VLANs fill the missing networks",
+			networks:        2,
+			ownerRefs:       []string{"pool-a", "net-a1", "net-a2", "net-a3", "pool-b", "net-b4", "net-b3", "net-b1"},
+			expectReleased:  []string{"net-a3", "net-b3"},
+			expectRemaining: []string{"pool-a", "net-a1", "net-a2", "pool-b", "net-b4", "net-b1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{
+				ObjectMeta: metav1.ObjectMeta{Name: "lease"},
+				Spec:       v1.LeaseSpec{Networks: tt.networks},
+			}
+			for _, name := range tt.ownerRefs {
+				kind := "Network"
+				if strings.HasPrefix(name, "pool-") {
+					kind = "Pool"
+				}
+				lease.OwnerReferences = append(lease.OwnerReferences, metav1.OwnerReference{Kind: kind, Name: name})
+			}
+
+			released := releaseSurplusNetworks(lease, assignedPools)
+			if !reflect.DeepEqual(released, tt.expectReleased) {
+				t.Errorf("expected %v to be released, got %v", tt.expectReleased, released)
+			}
+
+			var remaining []string
+			for _, ownerRef := range lease.OwnerReferences {
+				remaining = append(remaining, ownerRef.Name)
+			}
+			if !reflect.DeepEqual(remaining, tt.expectRemaining) {
+				t.Errorf("expected owner references %v, got %v", tt.expectRemaining, remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package controller
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/tracing"
+)
+
+// runSchedulingLoop schedules the leases in the scheduling queue, one lease per cycle, until ctx is done.
+func (l *LeaseReconciler) runSchedulingLoop(ctx context.Context) error {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithName("scheduler"))
+	go schedulingQueue.Run(ctx)
+
+	for {
+		queued, ok := schedulingQueue.Pop()
+		if !ok {
+			return nil
+		}
+		l.scheduleOne(ctx, queued)
+		updateSchedulingQueueMetrics()
+	}
+}
+
+// scheduleOne runs a scheduling cycle for a lease popped from the scheduling queue, or resizes it if its spec
+// changed after it was fulfilled. a lease which is not fulfilled by the cycle goes back to the queue.
+func (l *LeaseReconciler) scheduleOne(ctx context.Context, queued *scheduler.QueuedLease) {
+	reconcileLock.Lock()
+	defer reconcileLock.Unlock()
+
+	cached, exists := leases[queued.Key]
+	if !exists || cached.DeletionTimestamp != nil || (cached.Status.Phase == v1.PHASE_FULFILLED && !leaseNeedsResize(cached)) {
+		schedulingQueue.Done(queued)
+		return
+	}
+
+	lease := &v1.Lease{}
+	if err := l.Get(ctx, types.NamespacedName{Namespace: cached.Namespace, Name: cached.Name}, lease); err != nil {
+		if apierrors.IsNotFound(err) {
+			schedulingQueue.Done(queued)
+			return
+		}
+		log.FromContext(ctx).Error(err, "unable to get lease", "Lease", klog.KObj(cached))
+		schedulingQueue.AddUnschedulable(queued)
+		return
+	}
+	if lease.DeletionTimestamp != nil || (lease.Status.Phase == v1.PHASE_FULFILLED && !leaseNeedsResize(lease)) {
+		schedulingQueue.Done(queued)
+		return
+	}
+	leases[queued.Key] = lease
+
+	resize := leaseNeedsResize(lease)
+	ctx, span := tracing.Start(ctx, "ScheduleLease", append(tracing.Lease(lease.Namespace, lease.Name),
+		attribute.Int("attempt", queued.Attempts+1), attribute.String("tenant", queued.Tenant), attribute.Bool("resize", resize))...)
+	defer span.End()
+
+	logger := log.FromContext(ctx).WithValues("Lease", klog.KObj(lease))
+	ctx = log.IntoContext(ctx, logger)
+	logger.V(2).Info("scheduling lease", "priority", queued.Priority, "tenant", queued.Tenant, "attempt", queued.Attempts+1)
+	var err error
+	persisted := resourceRefs(lease.OwnerReferences)
+	if blocker := leaseHeldBackBy(lease, schedulingQueue.LeaseOrder()); !resize && blocker != nil {
+		logger.V(2).Info("lease held back by a competing lease scheduled before it", "blockedBy", klog.KObj(blocker), "blockedByPhase", blocker.Status.Phase)
+		span.SetAttributes(attribute.String("lease.blockedBy", scheduler.LeaseKey(blocker)))
+	} else {
+		if resize {
+			err = l.resizeLease(ctx, lease, persisted)
+		} else {
+			err = l.scheduleLease(ctx, lease, persisted)
+		}
+		tracing.RecordError(span, err)
+		updateFairShare(l.Scheduler.FairShare())
+	}
+	span.SetAttributes(attribute.String("lease.phase", string(lease.Status.Phase)))
+	if err != nil {
+		logger.Error(err, "unable to schedule lease")
+	} else if lease.Status.Phase == v1.PHASE_FULFILLED && !leaseNeedsResize(lease) {
+		schedulingQueue.Done(queued)
+		// the leases it held back may be scheduled now.
+		schedulingQueue.MoveAllToActiveQueue()
+		return
+	}
+
+	// a fulfilled lease waiting to grow reports it with its Resizing condition.
+	if !resize {
+		if err := l.markLeaseDelayed(ctx, lease, queued.Attempts+1); err != nil {
+			logger.Error(err, "unable to mark lease as delayed")
+		}
+		LeaseDelaysTotal.With(prometheus.Labels{
+			"namespace":   lease.Namespace,
+			"networkType": string(lease.Spec.NetworkType),
+		}).Inc()
+	}
+	schedulingQueue.AddUnschedulable(queued)
+}
+
+// leaseHeldBackBy returns the waiting lease which must get its pools before lease, or nil if lease may be
+// scheduled. a lease which holds no pools yet is held back by a competing lease the scheduling queue pops
+// before it, as ordered by before, so small leases do not keep taking the pools a large lease waits for.
+// leases which could not fit even if their pools were empty hold back nothing.
+func leaseHeldBackBy(lease *v1.Lease, before func(a, b *v1.Lease) bool) *v1.Lease {
+	if lease.Status.Phase == v1.PHASE_PARTIAL {
+		return nil
+	}
+	key := scheduler.LeaseKey(lease)
+	var blocker *v1.Lease
+	for _, other := range leases {
+		if scheduler.LeaseKey(other) == key || !leaseWaiting(other) || !leasesCompete(other, lease) {
+			continue
+		}
+		if !before(other, lease) || !leaseFitsEmptyPools(other) {
+			continue
+		}
+		if blocker == nil || before(other, blocker) {
+			blocker = other
+		}
+	}
+	return blocker
+}
+
+// leaseFitsEmptyPools returns true if enough pools could hold the lease once all their leases are released.
+// cordoned pools and pools excluded unless required by name are left out, pool selectors and taints are not checked.
+func leaseFitsEmptyPools(lease *v1.Lease) bool {
+	want := lease.Spec.Pools
+	if want == 0 {
+		want = 1
+	}
+	for _, pool := range pools {
+		if lease.Spec.RequiredPool != "" && pool.Name != lease.Spec.RequiredPool {
+			continue
+		}
+		if pool.Spec.NoSchedule || (pool.Spec.Exclude && pool.Name != lease.Spec.RequiredPool) {
+			continue
+		}
+		overCommitRatio, err := strconv.ParseFloat(pool.Spec.OverCommitRatio, 64)
+		if err != nil {
+			overCommitRatio = 1.0
+		}
+		if int(float64(pool.Spec.VCpus)*overCommitRatio) < lease.Spec.VCpus || pool.Spec.Memory < lease.Spec.Memory {
+			continue
+		}
+		if leaseNetworkType(lease) == v1.NetworkTypeSingleTenant && len(pool.Spec.Topology.Networks) < lease.Spec.Networks {
+			continue
+		}
+		if want--; want == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// updateSchedulingQueueMetrics records the number of leases in each queue of the scheduling queue.
+func updateSchedulingQueueMetrics() {
+	active, backoff, unschedulable := schedulingQueue.Len()
+	SchedulingQueueLeases.With(prometheus.Labels{"queue": "active"}).Set(float64(active))
+	SchedulingQueueLeases.With(prometheus.Labels{"queue": "backoff"}).Set(float64(backoff))
+	SchedulingQueueLeases.With(prometheus.Labels{"queue": "unschedulable"}).Set(float64(unschedulable))
+}
+
+// poolCapacityFreed returns true if the pool may fit leases it did not fit before: it is new, its spec
+// changed or it has more resources available.
+func poolCapacityFreed(previous, pool *v1.Pool) bool {
+	if previous == nil || previous.Generation != pool.Generation {
+		return true
+	}
+	return pool.Status.VCpusAvailable > previous.Status.VCpusAvailable ||
+		pool.Status.MemoryAvailable > previous.Status.MemoryAvailable ||
+		pool.Status.NetworkAvailable > previous.Status.NetworkAvailable
+}
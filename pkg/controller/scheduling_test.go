@@ -0,0 +1,240 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+)
+
+func TestPoolCapacityFreed(t *testing.T) {
+	newPool := func(generation int64, vcpus, memory, networks int) *v1.Pool {
+		return &v1.Pool{
+			ObjectMeta: metav1.ObjectMeta{Name: "pool", Generation: generation},
+			Status: v1.PoolStatus{
+				VCpusAvailable:   vcpus,
+				MemoryAvailable:  memory,
+				NetworkAvailable: networks,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		previous *v1.Pool
+		pool     *v1.Pool
+		expected bool
+	}{
+		{
+			name:     "new pool",
+			pool:     newPool(1, 10, 10, 1),
+			expected: true,
+		},
+		{
+			name:     "spec changed",
+			previous: newPool(1, 10, 10, 1),
+			pool:     newPool(2, 10, 10, 1),
+			expected: true,
+		},
+		{
+			name:     "unchanged",
+			previous: newPool(1, 10, 10, 1),
+			pool:     newPool(1, 10, 10, 1),
+		},
+		{
+			name:     "lease assigned",
+			previous: newPool(1, 10, 10, 1),
+			pool:     newPool(1, 2, 2, 0),
+		},
+		{
+			name:     "vcpus freed",
+			previous: newPool(1, 2, 10, 1),
+			pool:     newPool(1, 10, 10, 1),
+			expected: true,
+		},
+		{
+			name:     "memory freed",
+			previous: newPool(1, 10, 2, 1),
+			pool:     newPool(1, 10, 10, 1),
+			expected: true,
+		},
+		{
+			name:     "network freed",
+			previous: newPool(1, 10, 10, 0),
+			pool:     newPool(1, 10, 10, 1),
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if freed := poolCapacityFreed(tt.previous, tt.pool); freed != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, freed)
+			}
+		})
+	}
+}
+
+func TestPoolRequestsForLease(t *testing.T) {
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "lease",
+			Namespace: "vsphere-infra-helpers",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: v1.PoolKind, Name: "pool-a"},
+				{Kind: "Network", Name: "network-a"},
+				{Kind: v1.PoolKind, Name: "pool-b"},
+			},
+		},
+	}
+
+	requests := poolRequestsForLease(context.TODO(), lease)
+	if len(requests) != 2 {
+		t.Fatalf("expected 2 requests, got %v", requests)
+	}
+	for i, name := range []string{"pool-a", "pool-b"} {
+		if requests[i].Name != name || requests[i].Namespace != "vsphere-infra-helpers" {
+			t.Errorf("expected request for vsphere-infra-helpers/%s, got %v", name, requests[i])
+		}
+	}
+}
+
+func TestLeaseHeldBackBy(t *testing.T) {
+	oldPools := pools
+	defer func() { pools = oldPools }()
+	newPool := func(name string, vcpus int, mutate func(*v1.Pool)) *v1.Pool {
+		pool := &v1.Pool{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1.PoolSpec{
+				VCpus:           vcpus,
+				Memory:          vcpus * 4,
+				OverCommitRatio: "1.0",
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Topology: configv1.VSpherePlatformTopology{Networks: []string{"ci-vlan-1", "ci-vlan-2"}},
+					},
+				},
+			},
+		}
+		if mutate != nil {
+			mutate(pool)
+		}
+		return pool
+	}
+	pools = map[string]*v1.Pool{
+		"default/pool-a": newPool("pool-a", 48, nil),
+		"default/pool-b": newPool("pool-b", 48, nil),
+		"default/pool-c": newPool("pool-c", 192, func(p *v1.Pool) { p.Spec.NoSchedule = true }),
+	}
+
+	tests := []struct {
+		name     string
+		lease    *v1.Lease
+		others   []*v1.Lease
+		before   func(a, b *v1.Lease) bool
+		expected string
+	}{
+		{
+			name:  "no competing lease",
+			lease: newQueuedLease("new", time.Minute, nil),
+			others: []*v1.Lease{
+				newQueuedLease("fulfilled", time.Hour, func(l *v1.Lease) { l.Status.Phase = v1.PHASE_FULFILLED }),
+				newQueuedLease("multi-tenant", time.Hour, func(l *v1.Lease) { l.Spec.NetworkType = v1.NetworkTypeMultiTenant }),
+				newQueuedLease("younger", 0, nil),
+			},
+		},
+		{
+			name:     "older pending lease",
+			lease:    newQueuedLease("new", time.Minute, nil),
+			others:   []*v1.Lease{newQueuedLease("old", time.Hour, nil), newQueuedLease("older", 2*time.Hour, nil)},
+			expected: "ci/older",
+		},
+		{
+			name:   "younger partial lease",
+			lease:  newQueuedLease("new", time.Hour, nil),
+			others: []*v1.Lease{newQueuedLease("partial", 0, func(l *v1.Lease) { l.Spec.Pools = 2; l.Status.Phase = v1.PHASE_PARTIAL })},
+		},
+		{
+			name:  "lease of a tenant with a lower fair share ratio goes first",
+			lease: newQueuedLease("periodic", 0, func(l *v1.Lease) { l.Annotations = map[string]string{v1.PROW_JOB_TYPE_KEY: "periodic"} }),
+			others: []*v1.Lease{
+				newQueuedLease("presubmit", time.Hour, func(l *v1.Lease) { l.Annotations = map[string]string{v1.PROW_JOB_TYPE_KEY: "presubmit"} }),
+				newQueuedLease("other-periodic", time.Hour, func(l *v1.Lease) { l.Annotations = map[string]string{v1.PROW_JOB_TYPE_KEY: "periodic"} }),
+			},
+			before: func(a, b *v1.Lease) bool {
+				if a.Annotations[v1.PROW_JOB_TYPE_KEY] != b.Annotations[v1.PROW_JOB_TYPE_KEY] {
+					return a.Annotations[v1.PROW_JOB_TYPE_KEY] == "periodic"
+				}
+				return a.CreationTimestamp.Before(&b.CreationTimestamp)
+			},
+			expected: "ci/other-periodic",
+		},
+		{
+			name:  "partial lease is not held back",
+			lease: newQueuedLease("partial", 0, func(l *v1.Lease) { l.Status.Phase = v1.PHASE_PARTIAL }),
+			others: []*v1.Lease{
+				newQueuedLease("old", time.Hour, nil),
+				newQueuedLease("older-partial", 2*time.Hour, func(l *v1.Lease) { l.Status.Phase = v1.PHASE_PARTIAL }),
+			},
+		},
+		{
+			name:   "higher priority lease goes first",
+			lease:  newQueuedLease("urgent", 0, func(l *v1.Lease) { l.Spec.Priority = 10 }),
+			others: []*v1.Lease{newQueuedLease("old", time.Hour, nil)},
+		},
+		{
+			name:   "different required pools",
+			lease:  newQueuedLease("new", time.Minute, func(l *v1.Lease) { l.Spec.RequiredPool = "pool-a" }),
+			others: []*v1.Lease{newQueuedLease("old", time.Hour, func(l *v1.Lease) { l.Spec.RequiredPool = "pool-b" })},
+		},
+		{
+			name:   "deleted lease",
+			lease:  newQueuedLease("new", time.Minute, nil),
+			others: []*v1.Lease{newQueuedLease("old", time.Hour, func(l *v1.Lease) { l.DeletionTimestamp = &metav1.Time{} })},
+		},
+		{
+			name:  "older lease which never fits",
+			lease: newQueuedLease("new", time.Minute, nil),
+			others: []*v1.Lease{
+				newQueuedLease("too-large", time.Hour, func(l *v1.Lease) { l.Spec.VCpus = 96 }),
+				newQueuedLease("too-many-pools", time.Hour, func(l *v1.Lease) { l.Spec.Pools = 3 }),
+				newQueuedLease("too-many-networks", time.Hour, func(l *v1.Lease) { l.Spec.Networks = 3 }),
+			},
+		},
+		{
+			name:  "older multi-tenant lease sharing networks",
+			lease: newQueuedLease("new", time.Minute, func(l *v1.Lease) { l.Spec.NetworkType = v1.NetworkTypeMultiTenant }),
+			others: []*v1.Lease{newQueuedLease("old", time.Hour, func(l *v1.Lease) {
+				l.Spec.NetworkType = v1.NetworkTypeMultiTenant
+				l.Spec.Networks = 3
+			})},
+			expected: "ci/old",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cached := map[string]*v1.Lease{scheduler.LeaseKey(tt.lease): tt.lease}
+			for _, other := range tt.others {
+				cached[scheduler.LeaseKey(other)] = other
+			}
+			defer setupTestLeases(cached)()
+
+			before := tt.before
+			if before == nil {
+				before = scheduler.NewSchedulingQueue(0, 0, 0).LeaseOrder()
+			}
+			blocker := leaseHeldBackBy(tt.lease, before)
+			if blocker == nil && tt.expected != "" {
+				t.Errorf("expected the lease to be held back by %s", tt.expected)
+			} else if blocker != nil && scheduler.LeaseKey(blocker) != tt.expected {
+				t.Errorf("expected the lease to be held back by %q, got %s", tt.expected, scheduler.LeaseKey(blocker))
+			}
+		})
+	}
+}
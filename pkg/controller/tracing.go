@@ -0,0 +1,21 @@
+package controller
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/tracing"
+)
+
+// setLeaseTraceID records the trace of ctx on the lease, so the scheduling which assigned or released its pools
+// and networks can be looked up from the lease. nothing is recorded if ctx is not traced.
+func setLeaseTraceID(ctx context.Context, lease *v1.Lease) {
+	traceID := tracing.TraceID(ctx)
+	if traceID == "" {
+		return
+	}
+	if lease.Annotations == nil {
+		lease.Annotations = make(map[string]string)
+	}
+	lease.Annotations[v1.LeaseTraceIDAnnotation] = traceID
+}
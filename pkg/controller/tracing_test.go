@@ -0,0 +1,28 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func TestSetLeaseTraceID(t *testing.T) {
+	lease := &v1.Lease{}
+	setLeaseTraceID(context.Background(), lease)
+	if _, ok := lease.Annotations[v1.LeaseTraceIDAnnotation]; ok {
+		t.Errorf("expected no trace ID without tracing, got %v", lease.Annotations)
+	}
+
+	provider := sdktrace.NewTracerProvider()
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+	ctx, span := provider.Tracer("test").Start(context.Background(), "ScheduleLease")
+	defer span.End()
+
+	setLeaseTraceID(ctx, lease)
+	if traceID := span.SpanContext().TraceID().String(); lease.Annotations[v1.LeaseTraceIDAnnotation] != traceID {
+		t.Errorf("expected the trace ID %s, got %v", traceID, lease.Annotations)
+	}
+}
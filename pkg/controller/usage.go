@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils/conditions"
+)
+
+const (
+	// DEFAULT_USAGE_RETENTION is how long usage records are kept after the lease was released
+	DEFAULT_USAGE_RETENTION = 90 * 24 * time.Hour
+)
+
+// leaseFulfilledAt returns when a lease was first fulfilled. leases fulfilled before the time was recorded use
+// the last transition of their Fulfilled condition.
+func leaseFulfilledAt(lease *v1.Lease) *metav1.Time {
+	if lease.Status.FulfilledAt != nil {
+		return lease.Status.FulfilledAt
+	}
+	if lease.Status.Allocated == nil {
+		return nil
+	}
+	if condition := conditions.Get(lease, v1.LeaseConditionTypeFulfilled); condition != nil && !condition.LastTransitionTime.IsZero() {
+		return &condition.LastTransitionTime
+	}
+	return nil
+}
+
+// usageRecordName returns the name of the usage record of a lease. the uid keeps the records of leases reusing
+// a name apart.
+func usageRecordName(lease *v1.Lease) string {
+	uid := string(lease.UID)
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	name := lease.Name
+	if len(name) > 240 {
+		name = name[:240]
+	}
+	if uid == "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%s", name, uid)
+}
+
+// newLeaseUsageRecord returns the usage record of a lease released at now.
+func newLeaseUsageRecord(lease *v1.Lease, now time.Time) *v1.LeaseUsageRecord {
+	allocated := utils.GetLeaseAllocatedResources(lease)
+	var poolNames []string
+	for _, poolRef := range utils.GetLeasePoolRefs(lease) {
+		poolNames = append(poolNames, poolRef.Name)
+	}
+	leaseNamespace := lease.Labels[v1.LeaseNamespace]
+	if leaseNamespace == "" {
+		leaseNamespace = lease.Namespace
+	}
+
+	return &v1.LeaseUsageRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      usageRecordName(lease),
+			Namespace: lease.Namespace,
+			Labels:    map[string]string{v1.LeaseUsageRecordLeaseLabel: lease.Name},
+		},
+		Spec: v1.LeaseUsageRecordSpec{
+			Lease:          lease.Name,
+			LeaseNamespace: leaseNamespace,
+			BoskosLeaseID:  lease.Labels[v1.BoskosIdLabel],
+			NetworkType:    lease.Spec.NetworkType,
+			VCpus:          allocated.VCpus,
+			Memory:         allocated.Memory,
+			Networks:       len(getLeaseNetworkNames(lease)),
+			Pools:          poolNames,
+			CreatedAt:      lease.CreationTimestamp,
+			FulfilledAt:    leaseFulfilledAt(lease),
+			ReleasedAt:     metav1.NewTime(now),
+			Job: v1.LeaseUsageJob{
+				Name:    lease.Annotations[PROW_JOB_KEY],
+				Type:    lease.Annotations[v1.PROW_JOB_TYPE_KEY],
+				BuildID: lease.Annotations[PROW_BUILD_ID_KEY],
+				Org:     lease.Annotations[GIT_ORG_KEY],
+				Repo:    lease.Annotations[GIT_REPO_KEY],
+				PR:      lease.Annotations[GIT_PR_KEY],
+				Link:    lease.Status.JobLink,
+			},
+		},
+	}
+}
+
+// recordLeaseUsage creates the usage record of a released lease. a lease is recorded once.
+func (l *LeaseReconciler) recordLeaseUsage(ctx context.Context, lease *v1.Lease) error {
+	record := newLeaseUsageRecord(lease, time.Now())
+	if err := l.Create(ctx, record); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return fmt.Errorf("error recording the usage of lease %s: %w", lease.Name, err)
+	}
+	log.FromContext(ctx).V(2).Info("recorded the usage of lease", "UsageRecord", klog.KObj(record))
+	return nil
+}
+
+// UsageRecordReconciler deletes usage records once they are older than the retention.
+type UsageRecordReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	RESTMapper meta.RESTMapper
+
+	// Retention is how long usage records are kept after the lease was released. 0 keeps them forever.
+	Retention time.Duration
+}
+
+func (l *UsageRecordReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&v1.LeaseUsageRecord{}).
+		Complete(l); err != nil {
+		return fmt.Errorf("error setting up controller: %w", err)
+	}
+
+	// Set up API helpers from the manager.
+	l.Client = mgr.GetClient()
+	l.Scheme = mgr.GetScheme()
+	l.RESTMapper = mgr.GetRESTMapper()
+
+	return nil
+}
+
+// usageRecordExpiresIn returns how long a usage record is kept, or 0 if it has expired.
+func usageRecordExpiresIn(record *v1.LeaseUsageRecord, retention time.Duration, now time.Time) time.Duration {
+	remaining := record.Spec.ReleasedAt.Add(retention).Sub(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (l *UsageRecordReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if l.Retention <= 0 {
+		return ctrl.Result{}, nil
+	}
+
+	record := &v1.LeaseUsageRecord{}
+	if err := l.Get(ctx, req.NamespacedName, record); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if remaining := usageRecordExpiresIn(record, l.Retention, time.Now()); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	log.FromContext(ctx).Info("deleting usage record", "retention", l.Retention)
+	if err := l.Delete(ctx, record); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	return ctrl.Result{}, nil
+}
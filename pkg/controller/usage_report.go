@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// USAGE_REPORT_PATH is the path of the usage report on the metrics server
+	USAGE_REPORT_PATH = "/usage"
+)
+
+// usageGroupKeys are the fields a usage report can be grouped by, with how to read them from a record.
+var usageGroupKeys = map[string]func(record *v1.LeaseUsageRecord) string{
+	"namespace":   func(record *v1.LeaseUsageRecord) string { return record.Spec.LeaseNamespace },
+	"org":         func(record *v1.LeaseUsageRecord) string { return record.Spec.Job.Org },
+	"repo":        func(record *v1.LeaseUsageRecord) string { return record.Spec.Job.Repo },
+	"job":         func(record *v1.LeaseUsageRecord) string { return record.Spec.Job.Name },
+	"networkType": func(record *v1.LeaseUsageRecord) string { return string(record.Spec.NetworkType) },
+}
+
+// UsageReportRow is the usage of a group of leases over the period of a report.
+type UsageReportRow struct {
+	Group         map[string]string `json:"group"`
+	Leases        int               `json:"leases"`
+	VCpuHours     float64           `json:"vcpuHours"`
+	MemoryGBHours float64           `json:"memoryGBHours"`
+	NetworkHours  float64           `json:"networkHours"`
+}
+
+// usageHours returns how many hours a lease held its resources between from and to. the resources are held from
+// the time the lease was fulfilled until it was released.
+func usageHours(record *v1.LeaseUsageRecord, from, to time.Time) float64 {
+	if record.Spec.FulfilledAt == nil {
+		return 0
+	}
+	start := record.Spec.FulfilledAt.Time
+	if start.Before(from) {
+		start = from
+	}
+	end := record.Spec.ReleasedAt.Time
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+// aggregateUsage sums the usage of the records between from and to by the groupBy keys. pools are counted
+// separately, a lease holding two pools uses its vCPUs and memory twice.
+func aggregateUsage(records []v1.LeaseUsageRecord, from, to time.Time, groupBy []string) ([]UsageReportRow, error) {
+	for _, key := range groupBy {
+		if _, ok := usageGroupKeys[key]; !ok {
+			return nil, fmt.Errorf("unknown group %q", key)
+		}
+	}
+
+	rows := make(map[string]*UsageReportRow)
+	for i := range records {
+		record := &records[i]
+		hours := usageHours(record, from, to)
+		if hours == 0 {
+			continue
+		}
+
+		group := make(map[string]string, len(groupBy))
+		values := make([]string, 0, len(groupBy))
+		for _, key := range groupBy {
+			group[key] = usageGroupKeys[key](record)
+			values = append(values, group[key])
+		}
+		rowKey := strings.Join(values, "\x00")
+		row, exists := rows[rowKey]
+		if !exists {
+			row = &UsageReportRow{Group: group}
+			rows[rowKey] = row
+		}
+
+		pools := float64(len(record.Spec.Pools))
+		if pools == 0 {
+			pools = 1
+		}
+		row.Leases++
+		row.VCpuHours += float64(record.Spec.VCpus) * pools * hours
+		row.MemoryGBHours += float64(record.Spec.Memory) * pools * hours
+		row.NetworkHours += float64(record.Spec.Networks) * hours
+	}
+
+	keys := make([]string, 0, len(rows))
+	for key := range rows {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	report := make([]UsageReportRow, 0, len(keys))
+	for _, key := range keys {
+		report = append(report, *rows[key])
+	}
+	return report, nil
+}
+
+// parseReportTime parses a report bound given as RFC 3339 or as a date.
+func parseReportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// usageReportPeriod returns the period of a report from the month, from and to query parameters. it defaults to
+// the current month.
+func usageReportPeriod(query map[string][]string, now time.Time) (time.Time, time.Time, error) {
+	get := func(key string) string {
+		if values := query[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := now
+	if month := get("month"); month != "" {
+		start, err := time.Parse("2006-01", month)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid month %q, expected YYYY-MM", month)
+		}
+		from = start
+		to = start.AddDate(0, 1, 0)
+	}
+	if value := get("from"); value != "" {
+		t, err := parseReportTime(value)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from %q: %w", value, err)
+		}
+		from = t
+	}
+	if value := get("to"); value != "" {
+		t, err := parseReportTime(value)
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to %q: %w", value, err)
+		}
+		to = t
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("the report ends before it starts")
+	}
+	return from, to, nil
+}
+
+// writeUsageCSV writes a report as CSV, with a column per group key followed by the usage.
+func writeUsageCSV(w http.ResponseWriter, rows []UsageReportRow, groupBy []string) error {
+	writer := csv.NewWriter(w)
+	header := append(append([]string{}, groupBy...), "leases", "vcpuHours", "memoryGBHours", "networkHours")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		line := make([]string, 0, len(header))
+		for _, key := range groupBy {
+			line = append(line, row.Group[key])
+		}
+		line = append(line,
+			strconv.Itoa(row.Leases),
+			strconv.FormatFloat(row.VCpuHours, 'f', 2, 64),
+			strconv.FormatFloat(row.MemoryGBHours, 'f', 2, 64),
+			strconv.FormatFloat(row.NetworkHours, 'f', 2, 64),
+		)
+		if err := writer.Write(line); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// UsageReportHandler serves the usage of the leases released over a period, aggregated from the usage records.
+//
+// Query parameters:
+//   - month (YYYY-MM), or from and to (RFC 3339 or YYYY-MM-DD): the period, the current month by default
+//   - groupBy: comma separated list of namespace, org, repo, job and networkType, namespace by default
+//   - format: json (default) or csv
+type UsageReportHandler struct {
+	// Reader reads the usage records. it is set once the manager is created.
+	Reader client.Reader
+	// Namespace limits the report to the records of a namespace. all namespaces are reported if empty.
+	Namespace string
+}
+
+func (h *UsageReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Reader == nil {
+		http.Error(w, "usage report is not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	from, to, err := usageReportPeriod(query, time.Now().UTC())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	groupBy := []string{"namespace"}
+	if value := query.Get("groupBy"); value != "" {
+		groupBy = nil
+		for _, key := range strings.Split(value, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				groupBy = append(groupBy, key)
+			}
+		}
+	}
+
+	records := &v1.LeaseUsageRecordList{}
+	if err := h.Reader.List(r.Context(), records, client.InNamespace(h.Namespace)); err != nil {
+		log.FromContext(r.Context()).Error(err, "unable to list usage records")
+		http.Error(w, "error listing usage records", http.StatusInternalServerError)
+		return
+	}
+
+	rows, err := aggregateUsage(records.Items, from, to, groupBy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch query.Get("format") {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		err = writeUsageCSV(w, rows, groupBy)
+	case "", "json":
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(struct {
+			From time.Time        `json:"from"`
+			To   time.Time        `json:"to"`
+			Rows []UsageReportRow `json:"rows"`
+		}{From: from, To: to, Rows: rows})
+	default:
+		http.Error(w, fmt.Sprintf("unknown format %q, expected json or csv", query.Get("format")), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.FromContext(r.Context()).Error(err, "unable to write usage report")
+	}
+}
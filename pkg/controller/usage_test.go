@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func TestNewLeaseUsageRecord(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fulfilled := metav1.NewTime(created.Add(time.Minute))
+	released := created.Add(2 * time.Hour)
+
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "lease-1",
+			Namespace:         "vsphere-infra-helpers",
+			UID:               "0123456789abcdef",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{v1.LeaseNamespace: "ci-op-1234", v1.BoskosIdLabel: "vsphere-elastic-42"},
+			Annotations:       map[string]string{PROW_JOB_KEY: "e2e-vsphere", GIT_ORG_KEY: "openshift", GIT_REPO_KEY: "installer"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: v1.PoolKind, Name: "pool-1"},
+				{Kind: "Network", Name: "net-1"},
+			},
+		},
+		Spec: v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 1, NetworkType: v1.NetworkTypeSingleTenant},
+		Status: v1.LeaseStatus{
+			Allocated:   &v1.LeaseResources{VCpus: 16, Memory: 64, Networks: 1},
+			FulfilledAt: &fulfilled,
+		},
+	}
+
+	record := newLeaseUsageRecord(lease, released)
+	if record.Name != "lease-1-01234567" || record.Namespace != lease.Namespace {
+		t.Errorf("unexpected record %s/%s", record.Namespace, record.Name)
+	}
+	spec := record.Spec
+	if spec.VCpus != 16 || spec.Memory != 64 || spec.Networks != 1 {
+		t.Errorf("expected the allocated resources, got %d vCPUs, %d GB and %d networks", spec.VCpus, spec.Memory, spec.Networks)
+	}
+	if spec.LeaseNamespace != "ci-op-1234" || spec.BoskosLeaseID != "vsphere-elastic-42" {
+		t.Errorf("unexpected namespace %q or boskos lease %q", spec.LeaseNamespace, spec.BoskosLeaseID)
+	}
+	if len(spec.Pools) != 1 || spec.Pools[0] != "pool-1" {
+		t.Errorf("expected pool-1, got %v", spec.Pools)
+	}
+	if spec.Job.Name != "e2e-vsphere" || spec.Job.Repo != "installer" || spec.Job.Org != "openshift" {
+		t.Errorf("unexpected job %+v", spec.Job)
+	}
+	if !spec.CreatedAt.Time.Equal(created) || !spec.FulfilledAt.Time.Equal(fulfilled.Time) || !spec.ReleasedAt.Time.Equal(released) {
+		t.Errorf("unexpected times %v %v %v", spec.CreatedAt, spec.FulfilledAt, spec.ReleasedAt)
+	}
+
+	// leases fulfilled before the time was recorded fall back to their Fulfilled condition.
+	lease.Status.FulfilledAt = nil
+	lease.Status.Conditions = []v1.Condition{{Type: v1.LeaseConditionTypeFulfilled, Status: v1.ConditionTrue, LastTransitionTime: fulfilled}}
+	if fulfilledAt := leaseFulfilledAt(lease); fulfilledAt == nil || !fulfilledAt.Time.Equal(fulfilled.Time) {
+		t.Errorf("expected the Fulfilled condition time, got %v", fulfilledAt)
+	}
+	lease.Status.Allocated = nil
+	if fulfilledAt := leaseFulfilledAt(lease); fulfilledAt != nil {
+		t.Errorf("expected no fulfilled time for a lease never fulfilled, got %v", fulfilledAt)
+	}
+}
+
+func TestUsageRecordExpiresIn(t *testing.T) {
+	now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	record := &v1.LeaseUsageRecord{Spec: v1.LeaseUsageRecordSpec{ReleasedAt: metav1.NewTime(now.Add(-24 * time.Hour))}}
+
+	if remaining := usageRecordExpiresIn(record, 48*time.Hour, now); remaining != 24*time.Hour {
+		t.Errorf("expected 24h remaining, got %v", remaining)
+	}
+	if remaining := usageRecordExpiresIn(record, time.Hour, now); remaining != 0 {
+		t.Errorf("expected the record to expire, got %v remaining", remaining)
+	}
+}
+
+func newTestUsageRecord(namespace, repo string, vcpus, pools int, fulfilled, released time.Time) v1.LeaseUsageRecord {
+	fulfilledAt := metav1.NewTime(fulfilled)
+	record := v1.LeaseUsageRecord{Spec: v1.LeaseUsageRecordSpec{
+		LeaseNamespace: namespace,
+		VCpus:          vcpus,
+		Memory:         vcpus * 4,
+		Networks:       1,
+		FulfilledAt:    &fulfilledAt,
+		ReleasedAt:     metav1.NewTime(released),
+		Job:            v1.LeaseUsageJob{Repo: repo},
+	}}
+	for i := 0; i < pools; i++ {
+		record.Spec.Pools = append(record.Spec.Pools, "pool")
+	}
+	return record
+}
+
+func TestAggregateUsage(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	records := []v1.LeaseUsageRecord{
+		newTestUsageRecord("ci-op-a", "installer", 16, 1, from.Add(time.Hour), from.Add(3*time.Hour)),
+		// started the month before, only the hour in the period counts.
+		newTestUsageRecord("ci-op-a", "origin", 8, 2, from.Add(-time.Hour), from.Add(time.Hour)),
+		newTestUsageRecord("ci-op-b", "installer", 16, 1, from.Add(time.Hour), from.Add(2*time.Hour)),
+		// released before the period.
+		newTestUsageRecord("ci-op-c", "installer", 16, 1, from.Add(-3*time.Hour), from.Add(-time.Hour)),
+	}
+	// never fulfilled.
+	unfulfilled := newTestUsageRecord("ci-op-d", "installer", 16, 1, from, from.Add(time.Hour))
+	unfulfilled.Spec.FulfilledAt = nil
+	records = append(records, unfulfilled)
+
+	rows, err := aggregateUsage(records, from, to, []string{"namespace"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 namespaces, got %+v", rows)
+	}
+	a := rows[0]
+	if a.Group["namespace"] != "ci-op-a" || a.Leases != 2 || a.VCpuHours != 48 || a.MemoryGBHours != 192 || a.NetworkHours != 3 {
+		t.Errorf("unexpected usage of ci-op-a: %+v", a)
+	}
+	if b := rows[1]; b.Group["namespace"] != "ci-op-b" || b.VCpuHours != 16 {
+		t.Errorf("unexpected usage of ci-op-b: %+v", b)
+	}
+
+	rows, err = aggregateUsage(records, from, to, []string{"repo"})
+	if err != nil || len(rows) != 2 || rows[0].Group["repo"] != "installer" || rows[0].Leases != 2 {
+		t.Errorf("unexpected usage by repo: %+v, %v", rows, err)
+	}
+
+	if _, err := aggregateUsage(records, from, to, []string{"cluster"}); err == nil {
+		t.Errorf("expected an error for an unknown group")
+	}
+}
+
+func TestUsageReportPeriod(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		query       string
+		expectFrom  time.Time
+		expectTo    time.Time
+		expectError bool
+	}{
+		{
+			name:       "current month by default",
+			expectFrom: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			expectTo:   now,
+		},
+		{
+			name:       "month",
+			query:      "month=2024-02",
+			expectFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			expectTo:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "dates",
+			query:      "from=2024-01-10&to=2024-01-20T12:00:00Z",
+			expectFrom: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+			expectTo:   time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC),
+		},
+		{name: "invalid month", query: "month=february", expectError: true},
+		{name: "ends before it starts", query: "from=2024-01-20&to=2024-01-10", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			from, to, err := usageReportPeriod(query, now)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil || !from.Equal(tt.expectFrom) || !to.Equal(tt.expectTo) {
+				t.Errorf("expected %v to %v, got %v to %v, %v", tt.expectFrom, tt.expectTo, from, to, err)
+			}
+		})
+	}
+}
+
+// usageRecordReader lists a fixed set of usage records.
+type usageRecordReader struct {
+	client.Reader
+	records []v1.LeaseUsageRecord
+}
+
+func (r *usageRecordReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	list.(*v1.LeaseUsageRecordList).Items = r.records
+	return nil
+}
+
+func TestUsageReportHandlerCSV(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	handler := &UsageReportHandler{Reader: &usageRecordReader{records: []v1.LeaseUsageRecord{
+		newTestUsageRecord("ci-op-a", "installer", 16, 1, from.Add(time.Hour), from.Add(3*time.Hour)),
+	}}}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/usage?month=2024-02&groupBy=namespace,repo&format=csv", nil))
+	if recorder.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	lines, err := csv.NewReader(recorder.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	expected := [][]string{
+		{"namespace", "repo", "leases", "vcpuHours", "memoryGBHours", "networkHours"},
+		{"ci-op-a", "installer", "1", "32.00", "128.00", "2.00"},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, lines)
+	}
+	for i := range expected {
+		for j := range expected[i] {
+			if lines[i][j] != expected[i][j] {
+				t.Errorf("expected %v, got %v", expected, lines)
+			}
+		}
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/usage?format=xml", nil))
+	if recorder.Code != 400 {
+		t.Errorf("expected 400 for an unknown format, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,274 @@
+// Package forecast estimates how long a new lease waits for a pool, and when pools run out of capacity, from
+// the leases seen over a recent window.
+//
+// The model is deliberately simple. Leases are assumed to arrive on a pool at the rate they were fulfilled on it
+// over the window, and to hold it for the mean time the released leases held it. The leases holding a pool are
+// assumed to leave independently, so N leases holding a pool for a mean time T leave at a rate of N/T. Pending
+// leases are spread evenly over the schedulable pools.
+package forecast
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Shape is the resources a lease needs in each of its pools.
+type Shape struct {
+	VCpus    int `json:"vcpus"`
+	Memory   int `json:"memory"`
+	Networks int `json:"networks"`
+}
+
+// Lease is a lease known to the forecast, still active or released within the window.
+type Lease struct {
+	Shape Shape
+	// Pools are the names of the pools the lease holds or held.
+	Pools []string
+	// FulfilledAt is when the lease was first fulfilled, nil while it is pending.
+	FulfilledAt *time.Time
+	// ReleasedAt is when the lease was released, nil while it is active.
+	ReleasedAt *time.Time
+}
+
+// Pool is the capacity of a pool right now.
+type Pool struct {
+	Namespace string
+	Name      string
+	// Free are the available vCPUs, memory and networks of the network type forecast.
+	Free Shape
+	// Schedulable is false if the pool does not take new leases.
+	Schedulable bool
+}
+
+// Input is what the forecast is computed from.
+type Input struct {
+	Now    time.Time
+	Window time.Duration
+	Leases []Lease
+	Pools  []Pool
+}
+
+// PoolForecast is the forecast for a lease of a given shape on a pool.
+type PoolForecast struct {
+	Namespace string `json:"namespace"`
+	Pool      string `json:"pool"`
+	// Fits is true if the pool has the resources for the lease right now, ignoring pending leases.
+	Fits bool `json:"fits"`
+	// ActiveLeases is the number of leases holding the pool.
+	ActiveLeases int `json:"activeLeases"`
+	// ArrivalsPerHour is the number of leases fulfilled on the pool per hour over the window.
+	ArrivalsPerHour float64 `json:"arrivalsPerHour"`
+	// MeanHoldSeconds is the mean time the leases released within the window held the pool.
+	MeanHoldSeconds float64 `json:"meanHoldSeconds"`
+	// ExpectedWaitSeconds is the expected wait of the lease, nil if it can not be estimated.
+	ExpectedWaitSeconds *float64 `json:"expectedWaitSeconds"`
+	// ExhaustionSeconds is the time until the pool runs out of capacity, nil if it is not expected to.
+	ExhaustionSeconds *float64 `json:"exhaustionSeconds"`
+}
+
+// resources returns the resources of a shape in a fixed order.
+func (s Shape) resources() [3]float64 {
+	return [3]float64{float64(s.VCpus), float64(s.Memory), float64(s.Networks)}
+}
+
+// meanShape returns the mean resources of the leases, as floats.
+func meanShape(leases []Lease) [3]float64 {
+	var mean [3]float64
+	if len(leases) == 0 {
+		return mean
+	}
+	for _, lease := range leases {
+		for i, value := range lease.Shape.resources() {
+			mean[i] += value
+		}
+	}
+	for i := range mean {
+		mean[i] /= float64(len(leases))
+	}
+	return mean
+}
+
+// MeanShape returns the mean shape of the leases, rounded up. it is the shape forecast when none is given.
+func MeanShape(leases []Lease) Shape {
+	mean := meanShape(leases)
+	return Shape{
+		VCpus:    int(math.Ceil(mean[0])),
+		Memory:   int(math.Ceil(mean[1])),
+		Networks: int(math.Ceil(mean[2])),
+	}
+}
+
+func holds(lease Lease, pool string) bool {
+	for _, name := range lease.Pools {
+		if name == pool {
+			return true
+		}
+	}
+	return false
+}
+
+func seconds(d float64) *float64 {
+	return &d
+}
+
+// Forecast returns the forecast for a lease of shape on each pool, from the shortest to the longest wait.
+func Forecast(input Input, shape Shape) []PoolForecast {
+	hours := input.Window.Hours()
+	windowStart := input.Now.Add(-input.Window)
+
+	var pending []Lease
+	var allHolds []float64
+	for _, lease := range input.Leases {
+		if lease.ReleasedAt == nil && lease.FulfilledAt == nil {
+			pending = append(pending, lease)
+		}
+		if lease.ReleasedAt != nil && lease.FulfilledAt != nil && lease.ReleasedAt.After(windowStart) {
+			allHolds = append(allHolds, lease.ReleasedAt.Sub(*lease.FulfilledAt).Seconds())
+		}
+	}
+
+	schedulable := 0
+	for _, pool := range input.Pools {
+		if pool.Schedulable {
+			schedulable++
+		}
+	}
+	// the demand of the pending leases on each schedulable pool.
+	var queued [3]float64
+	if schedulable > 0 {
+		for _, lease := range pending {
+			for i, value := range lease.Shape.resources() {
+				queued[i] += value / float64(schedulable)
+			}
+		}
+	}
+
+	forecasts := make([]PoolForecast, 0, len(input.Pools))
+	for _, pool := range input.Pools {
+		var active, arrivals []Lease
+		var poolHolds []float64
+		for _, lease := range input.Leases {
+			if !holds(lease, pool.Name) {
+				continue
+			}
+			if lease.ReleasedAt == nil {
+				active = append(active, lease)
+			} else if lease.FulfilledAt != nil && lease.ReleasedAt.After(windowStart) {
+				poolHolds = append(poolHolds, lease.ReleasedAt.Sub(*lease.FulfilledAt).Seconds())
+			}
+			if lease.FulfilledAt != nil && lease.FulfilledAt.After(windowStart) {
+				arrivals = append(arrivals, lease)
+			}
+		}
+		// pools without released leases in the window use the mean hold time of all pools.
+		if len(poolHolds) == 0 {
+			poolHolds = allHolds
+		}
+		meanHold := mean(poolHolds)
+
+		forecast := PoolForecast{
+			Namespace:       pool.Namespace,
+			Pool:            pool.Name,
+			Fits:            fits(pool.Free, shape),
+			ActiveLeases:    len(active),
+			MeanHoldSeconds: meanHold,
+		}
+		if hours > 0 {
+			forecast.ArrivalsPerHour = float64(len(arrivals)) / hours
+		}
+		if !pool.Schedulable {
+			forecasts = append(forecasts, forecast)
+			continue
+		}
+
+		free := pool.Free.resources()
+		need := shape.resources()
+		held := meanShape(active)
+		departuresPerSecond := float64(0)
+		if meanHold > 0 {
+			departuresPerSecond = float64(len(active)) / meanHold
+		}
+
+		forecast.ExpectedWaitSeconds = expectedWait(free, queued, need, held, departuresPerSecond)
+		if hours > 0 {
+			consumed := meanShape(arrivals)
+			for i := range consumed {
+				consumed[i] *= forecast.ArrivalsPerHour / 3600
+			}
+			forecast.ExhaustionSeconds = exhaustion(free, queued, need, consumed, held, departuresPerSecond)
+		}
+		forecasts = append(forecasts, forecast)
+	}
+
+	sort.SliceStable(forecasts, func(i, j int) bool {
+		a, b := forecasts[i].ExpectedWaitSeconds, forecasts[j].ExpectedWaitSeconds
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a < *b
+	})
+	return forecasts
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := float64(0)
+	for _, value := range values {
+		sum += value
+	}
+	return sum / float64(len(values))
+}
+
+func fits(free, shape Shape) bool {
+	return free.VCpus >= shape.VCpus && free.Memory >= shape.Memory && free.Networks >= shape.Networks
+}
+
+// expectedWait returns how long until enough leases leave the pool for the pending leases and the new lease to
+// fit, or nil if the leases holding the pool can not free enough.
+func expectedWait(free, queued, need, held [3]float64, departuresPerSecond float64) *float64 {
+	leaving := float64(0)
+	for i := range free {
+		missing := queued[i] + need[i] - free[i]
+		if missing <= 0 {
+			continue
+		}
+		if held[i] == 0 {
+			return nil
+		}
+		leaving = math.Max(leaving, math.Ceil(missing/held[i]))
+	}
+	if leaving == 0 {
+		return seconds(0)
+	}
+	if departuresPerSecond == 0 {
+		return nil
+	}
+	return seconds(leaving / departuresPerSecond)
+}
+
+// exhaustion returns how long until arrivals consuming resources faster than departures free them use up the
+// capacity left after the pending leases, or nil if the pool is not running out. resources neither the new
+// lease, the pending leases nor the arrivals need are ignored.
+func exhaustion(free, queued, need, consumedPerSecond, held [3]float64, departuresPerSecond float64) *float64 {
+	var soonest *float64
+	for i := range free {
+		if need[i] == 0 && queued[i] == 0 && consumedPerSecond[i] == 0 {
+			continue
+		}
+		left := free[i] - queued[i]
+		if left <= 0 {
+			return seconds(0)
+		}
+		net := consumedPerSecond[i] - held[i]*departuresPerSecond
+		if net <= 0 {
+			continue
+		}
+		if until := left / net; soonest == nil || until < *soonest {
+			soonest = seconds(until)
+		}
+	}
+	return soonest
+}
@@ -0,0 +1,208 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func ago(d time.Duration) *time.Time {
+	t := now.Add(-d)
+	return &t
+}
+
+func lease(pool string, fulfilled, released time.Duration) Lease {
+	l := Lease{Shape: Shape{VCpus: 8, Memory: 32, Networks: 1}, Pools: []string{pool}, FulfilledAt: ago(fulfilled)}
+	if released > 0 {
+		l.ReleasedAt = ago(released)
+	}
+	return l
+}
+
+func findPool(t *testing.T, forecasts []PoolForecast, name string) PoolForecast {
+	t.Helper()
+	for _, forecast := range forecasts {
+		if forecast.Pool == name {
+			return forecast
+		}
+	}
+	t.Fatalf("no forecast for pool %s", name)
+	return PoolForecast{}
+}
+
+func equalSeconds(got *float64, want *float64) bool {
+	if got == nil || want == nil {
+		return got == want
+	}
+	return math.Abs(*got-*want) < 1e-6
+}
+
+func format(value *float64) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprintf("%.2fs", *value)
+}
+
+func TestForecast(t *testing.T) {
+	// two leases released after holding pool-1 for 2 hours, and two still holding it.
+	history := []Lease{
+		lease("pool-1", 5*time.Hour, 3*time.Hour),
+		lease("pool-1", 4*time.Hour, 2*time.Hour),
+		lease("pool-1", time.Hour, 0),
+		lease("pool-1", time.Hour, 0),
+	}
+	pending := Lease{Shape: Shape{VCpus: 8, Memory: 32, Networks: 1}}
+
+	tests := []struct {
+		name           string
+		leases         []Lease
+		pools          []Pool
+		shape          Shape
+		pool           string
+		wantWait       *float64
+		wantExhaustion *float64
+	}{
+		{
+			name:   "lease fits",
+			leases: history,
+			pools:  []Pool{{Name: "pool-1", Free: Shape{VCpus: 8, Memory: 32, Networks: 1}, Schedulable: true}},
+			shape:  Shape{VCpus: 8, Memory: 32, Networks: 1},
+			pool:   "pool-1",
+			// arrivals consume 3.2 vCPUs an hour while departures free 8.
+			wantWait: seconds(0),
+		},
+		{
+			name:     "lease waits for a lease to leave",
+			leases:   history,
+			pools:    []Pool{{Name: "pool-1", Free: Shape{VCpus: 8, Memory: 32, Networks: 1}, Schedulable: true}},
+			shape:    Shape{VCpus: 16, Memory: 64, Networks: 1},
+			pool:     "pool-1",
+			wantWait: seconds(3600),
+		},
+		{
+			name:           "pending leases come first",
+			leases:         append([]Lease{pending}, history...),
+			pools:          []Pool{{Name: "pool-1", Free: Shape{VCpus: 8, Memory: 32, Networks: 1}, Schedulable: true}},
+			shape:          Shape{VCpus: 8, Memory: 32, Networks: 1},
+			pool:           "pool-1",
+			wantWait:       seconds(3600),
+			wantExhaustion: seconds(0),
+		},
+		{
+			name:   "pending leases are spread over the schedulable pools",
+			leases: append([]Lease{pending, pending}, history...),
+			pools: []Pool{
+				{Name: "pool-1", Free: Shape{VCpus: 16, Memory: 64, Networks: 2}, Schedulable: true},
+				{Name: "pool-2", Free: Shape{VCpus: 16, Memory: 64, Networks: 2}, Schedulable: true},
+				{Name: "pool-3", Free: Shape{VCpus: 16, Memory: 64, Networks: 2}},
+			},
+			shape:    Shape{VCpus: 8, Memory: 32, Networks: 1},
+			pool:     "pool-2",
+			wantWait: seconds(0),
+		},
+		{
+			name:   "pools without released leases use the mean hold time of all pools",
+			leases: append([]Lease{lease("pool-2", time.Hour, 0)}, history...),
+			pools:  []Pool{{Name: "pool-2", Free: Shape{}, Schedulable: true}},
+			shape:  Shape{VCpus: 8, Memory: 32, Networks: 1},
+			pool:   "pool-2",
+			// one lease holding the pool for a mean of 2 hours leaves at a rate of one every 2 hours.
+			wantWait:       seconds(7200),
+			wantExhaustion: seconds(0),
+		},
+		{
+			name:   "unschedulable pools are not forecast",
+			leases: history,
+			pools:  []Pool{{Name: "pool-1", Free: Shape{VCpus: 8, Memory: 32, Networks: 1}}},
+			shape:  Shape{VCpus: 8, Memory: 32, Networks: 1},
+			pool:   "pool-1",
+		},
+		{
+			name:   "lease can not fit",
+			leases: history,
+			pools:  []Pool{{Name: "pool-1", Free: Shape{VCpus: 8, Memory: 32}, Schedulable: true}},
+			shape:  Shape{VCpus: 8, Memory: 32, Networks: 1},
+			pool:   "pool-1",
+			// the pool has no network, and the leases holding it could free one.
+			wantWait:       seconds(3600),
+			wantExhaustion: seconds(0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forecasts := Forecast(Input{Now: now, Window: 10 * time.Hour, Leases: tt.leases, Pools: tt.pools}, tt.shape)
+			forecast := findPool(t, forecasts, tt.pool)
+			if !equalSeconds(forecast.ExpectedWaitSeconds, tt.wantWait) {
+				t.Errorf("expected wait %s, got %s", format(tt.wantWait), format(forecast.ExpectedWaitSeconds))
+			}
+			if !equalSeconds(forecast.ExhaustionSeconds, tt.wantExhaustion) {
+				t.Errorf("expected exhaustion %s, got %s", format(tt.wantExhaustion), format(forecast.ExhaustionSeconds))
+			}
+		})
+	}
+}
+
+func TestForecastExhaustion(t *testing.T) {
+	// ten leases fulfilled over the last hour, none released yet.
+	var leases []Lease
+	for i := 0; i < 10; i++ {
+		leases = append(leases, Lease{
+			Shape:       Shape{VCpus: 10, Memory: 40, Networks: 1},
+			Pools:       []string{"pool-1"},
+			FulfilledAt: ago(time.Duration(i) * time.Minute),
+		})
+	}
+	pools := []Pool{{Name: "pool-1", Free: Shape{VCpus: 100, Memory: 400, Networks: 10}, Schedulable: true}}
+
+	forecasts := Forecast(Input{Now: now, Window: time.Hour, Leases: leases, Pools: pools}, MeanShape(leases))
+	forecast := findPool(t, forecasts, "pool-1")
+	if forecast.ArrivalsPerHour != 10 || forecast.ActiveLeases != 10 {
+		t.Errorf("expected 10 arrivals an hour and 10 active leases, got %v and %d", forecast.ArrivalsPerHour, forecast.ActiveLeases)
+	}
+	if !equalSeconds(forecast.ExpectedWaitSeconds, seconds(0)) {
+		t.Errorf("expected no wait, got %s", format(forecast.ExpectedWaitSeconds))
+	}
+	// 10 leases an hour use up 100 vCPUs, 400 GB and 10 networks in an hour.
+	if !equalSeconds(forecast.ExhaustionSeconds, seconds(3600)) {
+		t.Errorf("expected exhaustion in an hour, got %s", format(forecast.ExhaustionSeconds))
+	}
+}
+
+func TestForecastOrder(t *testing.T) {
+	leases := []Lease{
+		lease("pool-1", 5*time.Hour, 3*time.Hour),
+		lease("pool-1", time.Hour, 0),
+	}
+	pools := []Pool{
+		{Name: "unschedulable", Free: Shape{VCpus: 8, Memory: 32, Networks: 1}},
+		{Name: "pool-1", Free: Shape{}, Schedulable: true},
+		{Name: "free", Free: Shape{VCpus: 8, Memory: 32, Networks: 1}, Schedulable: true},
+	}
+
+	forecasts := Forecast(Input{Now: now, Window: 10 * time.Hour, Leases: leases, Pools: pools}, Shape{VCpus: 8, Memory: 32, Networks: 1})
+	var names []string
+	for _, forecast := range forecasts {
+		names = append(names, forecast.Pool)
+	}
+	if len(names) != 3 || names[0] != "free" || names[1] != "pool-1" || names[2] != "unschedulable" {
+		t.Errorf("expected free, pool-1 and unschedulable, got %v", names)
+	}
+}
+
+func TestMeanShape(t *testing.T) {
+	shape := MeanShape([]Lease{
+		{Shape: Shape{VCpus: 8, Memory: 32, Networks: 1}},
+		{Shape: Shape{VCpus: 16, Memory: 64, Networks: 2}},
+		{Shape: Shape{VCpus: 16, Memory: 64, Networks: 2}},
+	})
+	if shape != (Shape{VCpus: 14, Memory: 54, Networks: 2}) {
+		t.Errorf("expected the mean rounded up, got %+v", shape)
+	}
+	if MeanShape(nil) != (Shape{}) {
+		t.Errorf("expected an empty shape without leases")
+	}
+}
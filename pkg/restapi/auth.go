@@ -0,0 +1,81 @@
+package restapi
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// AllNamespaces grants a token access to the leases of every namespace.
+const AllNamespaces = "*"
+
+// Token is a bearer token and the namespaces whose leases it may manage.
+type Token struct {
+	// Name identifies the client using the token in logs.
+	Name string `json:"name"`
+	// Token is the bearer token sent by the client.
+	Token string `json:"token"`
+	// Namespaces are the namespaces the client may manage leases in. "*" allows every namespace.
+	Namespaces []string `json:"namespaces"`
+}
+
+// allows returns true if the token may manage the leases of namespace.
+func (t *Token) allows(namespace string) bool {
+	for _, allowed := range t.Namespaces {
+		if allowed == AllNamespaces || allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenConfig is the file mapping bearer tokens to namespaces.
+type TokenConfig struct {
+	Tokens []Token `json:"tokens"`
+}
+
+// LoadTokens reads the tokens of the API from a YAML file, usually mounted from a secret.
+func LoadTokens(path string) ([]Token, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &TokenConfig{}
+	if err := yaml.UnmarshalStrict(content, config); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	seen := make(map[string]bool)
+	for i, token := range config.Tokens {
+		if token.Name == "" || token.Token == "" {
+			return nil, fmt.Errorf("token %d in %s has no name or token", i, path)
+		}
+		if len(token.Namespaces) == 0 {
+			return nil, fmt.Errorf("token %s in %s has no namespaces", token.Name, path)
+		}
+		if seen[token.Token] {
+			return nil, fmt.Errorf("token %s in %s is not unique", token.Name, path)
+		}
+		seen[token.Token] = true
+	}
+	return config.Tokens, nil
+}
+
+// authenticate returns the token of the bearer of a request, or nil if the request has no known token.
+func (s *Server) authenticate(r *http.Request) *Token {
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || bearer == "" {
+		return nil
+	}
+	var found *Token
+	// every token is compared so the time taken does not tell which token matched.
+	for i := range s.Tokens {
+		if subtle.ConstantTimeCompare([]byte(s.Tokens[i].Token), []byte(bearer)) == 1 {
+			found = &s.Tokens[i]
+		}
+	}
+	return found
+}
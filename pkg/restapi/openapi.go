@@ -0,0 +1,28 @@
+package restapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+// openAPIDocument describes the API. keep it in sync with the routes of Server.Handler and the types of the API.
+//
+//go:embed openapi.yaml
+var openAPIDocument []byte
+
+// serveOpenAPI serves the OpenAPI document as JSON.
+func serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	document, err := yaml.YAMLToJSON(openAPIDocument)
+	if err != nil {
+		log.FromContext(r.Context()).Error(err, "unable to convert the OpenAPI document")
+		writeError(r.Context(), w, http.StatusInternalServerError, "error converting the OpenAPI document")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(document); err != nil {
+		log.FromContext(r.Context()).Error(err, "unable to write the OpenAPI document")
+	}
+}
@@ -0,0 +1,325 @@
+// Package restapi serves a JSON API over HTTP for clients which can not use a kubeconfig, such as Jenkins jobs
+// and scripts outside the CI cluster. Requests are authenticated with bearer tokens mapped to namespaces and
+// are translated into Lease CRs.
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned"
+)
+
+const (
+	// DEFAULT_MAX_WAIT is how long a request may wait for a lease to be fulfilled by default.
+	DEFAULT_MAX_WAIT = 10 * time.Minute
+
+	// maxRequestBytes is the maximum size of a request body.
+	maxRequestBytes = 1 << 20
+	// generatedNameLength is the length of the random suffix of generated lease names.
+	generatedNameLength = 5
+	generatedNamePrefix = "api-"
+)
+
+// Server is the HTTP API. It runs as a runnable of the manager, on every replica.
+type Server struct {
+	// BindAddress is the address the API listens on, such as ":8443".
+	BindAddress string
+	// CertFile and KeyFile serve the API over TLS when set.
+	CertFile string
+	KeyFile  string
+	// Client reads and writes the leases and pools.
+	Client versioned.Interface
+	// Tokens are the bearer tokens allowed to use the API.
+	Tokens []Token
+	// MaxWait caps how long a request may wait for a lease to be fulfilled. DEFAULT_MAX_WAIT if 0.
+	MaxWait time.Duration
+
+	streams *leaseStreams
+}
+
+// Handler returns the routes of the API.
+func (s *Server) Handler() http.Handler {
+	if s.streams == nil {
+		s.streams = newLeaseStreams(s.Client)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.json", serveOpenAPI)
+	mux.HandleFunc("GET /api/v1/pools", s.authenticated(s.listPools))
+	mux.HandleFunc("POST /api/v1/namespaces/{namespace}/leases", s.authorized(s.createLease))
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/leases/{name}", s.authorized(s.getLease))
+	mux.HandleFunc("DELETE /api/v1/namespaces/{namespace}/leases/{name}", s.authorized(s.deleteLease))
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/leases/{name}/env", s.authorized(s.getEnvVars))
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/leases/{name}/events", s.authorized(s.streamLease))
+	return mux
+}
+
+// Start serves the API until ctx is done.
+func (s *Server) Start(ctx context.Context) error {
+	logger := log.FromContext(ctx).WithName("restapi")
+	ctx = log.IntoContext(ctx, logger)
+	server := &http.Server{
+		Addr:              s.BindAddress,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// requests waiting for a lease end when the manager stops.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	errs := make(chan error, 2)
+	go func() {
+		if err := s.streams.run(ctx); err != nil {
+			errs <- fmt.Errorf("error watching leases for streams: %w", err)
+		}
+	}()
+	go func() {
+		logger.Info("serving the lease API", "address", s.BindAddress)
+		if s.CertFile != "" {
+			errs <- server.ListenAndServeTLS(s.CertFile, s.KeyFile)
+		} else {
+			errs <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// NeedLeaderElection is false so every replica of the manager serves the API.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+type tokenHandler func(w http.ResponseWriter, r *http.Request, token *Token)
+
+// authenticated rejects requests without a known bearer token.
+func (s *Server) authenticated(next tokenHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := s.authenticate(r)
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(r.Context(), w, http.StatusUnauthorized, "a valid bearer token is required")
+			return
+		}
+		next(w, r, token)
+	}
+}
+
+// authorized rejects requests whose token may not manage the leases of the namespace of the request.
+func (s *Server) authorized(next tokenHandler) http.HandlerFunc {
+	return s.authenticated(func(w http.ResponseWriter, r *http.Request, token *Token) {
+		namespace := r.PathValue("namespace")
+		if !token.allows(namespace) {
+			writeError(r.Context(), w, http.StatusForbidden, fmt.Sprintf("token %s may not manage leases in namespace %s", token.Name, namespace))
+			return
+		}
+		next(w, r, token)
+	})
+}
+
+func (s *Server) listPools(w http.ResponseWriter, r *http.Request, _ *Token) {
+	pools, err := s.Client.VspherecapacitymanagerV1().Pools(metav1.NamespaceAll).List(r.Context(), metav1.ListOptions{})
+	if err != nil {
+		writeAPIError(r.Context(), w, "error listing pools", err)
+		return
+	}
+	writeJSON(r.Context(), w, http.StatusOK, newPools(pools.Items))
+}
+
+func (s *Server) createLease(w http.ResponseWriter, r *http.Request, token *Token) {
+	request := &LeaseRequest{}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		writeError(r.Context(), w, http.StatusBadRequest, fmt.Sprintf("invalid lease request: %v", err))
+		return
+	}
+	lease, err := request.lease(r.PathValue("namespace"))
+	if err != nil {
+		writeError(r.Context(), w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if lease.Name == "" {
+		if lease.Name, err = generateName(); err != nil {
+			writeAPIError(r.Context(), w, "error generating a lease name", err)
+			return
+		}
+	}
+
+	lease, err = s.Client.VspherecapacitymanagerV1().Leases(lease.Namespace).Create(r.Context(), lease, metav1.CreateOptions{})
+	if err != nil {
+		writeAPIError(r.Context(), w, "error creating lease", err)
+		return
+	}
+	log.FromContext(r.Context()).V(2).Info("lease created through the API", "Lease", klog.KObj(lease), "token", token.Name)
+	writeJSON(r.Context(), w, http.StatusCreated, newLease(lease))
+}
+
+// getLease returns a lease. with the wait query parameter, such as wait=5m, the request is held until the lease
+// is fulfilled or the wait, capped by MaxWait, is over.
+func (s *Server) getLease(w http.ResponseWriter, r *http.Request, _ *Token) {
+	namespace, name := r.PathValue("namespace"), r.PathValue("name")
+	var wait time.Duration
+	if value := r.URL.Query().Get("wait"); value != "" {
+		var err error
+		if wait, err = time.ParseDuration(value); err != nil || wait < 0 {
+			writeError(r.Context(), w, http.StatusBadRequest, fmt.Sprintf("invalid wait %q, expected a duration such as 5m", value))
+			return
+		}
+	}
+
+	lease, err := s.Client.VspherecapacitymanagerV1().Leases(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeAPIError(r.Context(), w, "error getting lease", err)
+		return
+	}
+	if wait > 0 && lease.Status.Phase != v1.PHASE_FULFILLED {
+		if lease, err = s.waitFulfilled(r.Context(), lease, min(wait, s.maxWait())); err != nil {
+			writeAPIError(r.Context(), w, "error waiting for lease", err)
+			return
+		}
+	}
+	writeJSON(r.Context(), w, http.StatusOK, newLease(lease))
+}
+
+// waitFulfilled watches a lease until it is fulfilled or timeout passes, and returns the last seen lease.
+func (s *Server) waitFulfilled(ctx context.Context, lease *v1.Lease, timeout time.Duration) (*v1.Lease, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	watcher, err := s.Client.VspherecapacitymanagerV1().Leases(lease.Namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", lease.Name).String(),
+		ResourceVersion: lease.ResourceVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return lease, nil
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return lease, nil
+			}
+			switch event.Type {
+			case watch.Error:
+				return nil, apierrors.FromObject(event.Object)
+			case watch.Deleted:
+				return nil, apierrors.NewNotFound(v1.Resource("leases"), lease.Name)
+			case watch.Added, watch.Modified:
+				updated, ok := event.Object.(*v1.Lease)
+				if !ok || updated.Name != lease.Name {
+					continue
+				}
+				lease = updated
+				if lease.Status.Phase == v1.PHASE_FULFILLED {
+					return lease, nil
+				}
+			}
+		}
+	}
+}
+
+func (s *Server) deleteLease(w http.ResponseWriter, r *http.Request, token *Token) {
+	namespace, name := r.PathValue("namespace"), r.PathValue("name")
+	if err := s.Client.VspherecapacitymanagerV1().Leases(namespace).Delete(r.Context(), name, metav1.DeleteOptions{}); err != nil {
+		writeAPIError(r.Context(), w, "error deleting lease", err)
+		return
+	}
+	log.FromContext(r.Context()).V(2).Info("lease deleted through the API", "Lease", klog.KRef(namespace, name), "token", token.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getEnvVars returns the environment variables of a fulfilled lease as a script to source. the pool query
+// parameter picks the pool of a multi-pool lease.
+func (s *Server) getEnvVars(w http.ResponseWriter, r *http.Request, _ *Token) {
+	namespace, name := r.PathValue("namespace"), r.PathValue("name")
+	lease, err := s.Client.VspherecapacitymanagerV1().Leases(namespace).Get(r.Context(), name, metav1.GetOptions{})
+	if err != nil {
+		writeAPIError(r.Context(), w, "error getting lease", err)
+		return
+	}
+	if lease.Status.Phase != v1.PHASE_FULFILLED {
+		writeError(r.Context(), w, http.StatusConflict, fmt.Sprintf("lease %s is not fulfilled", name))
+		return
+	}
+
+	envVars := lease.Status.EnvVars
+	if pool := r.URL.Query().Get("pool"); pool != "" {
+		var ok bool
+		if envVars, ok = lease.Status.EnvVarsMap[pool]; !ok {
+			writeError(r.Context(), w, http.StatusNotFound, fmt.Sprintf("lease %s has no environment variables for pool %s", name, pool))
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := fmt.Fprintln(w, envVars); err != nil {
+		log.FromContext(r.Context()).Error(err, "unable to write env vars", "Lease", klog.KRef(namespace, name))
+	}
+}
+
+func (s *Server) maxWait() time.Duration {
+	if s.MaxWait > 0 {
+		return s.MaxWait
+	}
+	return DEFAULT_MAX_WAIT
+}
+
+// generateName returns a lease name with a random suffix.
+func generateName() (string, error) {
+	const alphabet = "bcdfghjklmnpqrstvwxz2456789"
+	suffix := make([]byte, generatedNameLength)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	for i := range suffix {
+		suffix[i] = alphabet[int(suffix[i])%len(alphabet)]
+	}
+	return generatedNamePrefix + string(suffix), nil
+}
+
+func writeJSON(ctx context.Context, w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.FromContext(ctx).Error(err, "unable to write response")
+	}
+}
+
+func writeError(ctx context.Context, w http.ResponseWriter, code int, message string) {
+	writeJSON(ctx, w, code, Error{Error: message})
+}
+
+// writeAPIError returns the status of an error of the API server, or an internal error.
+func writeAPIError(ctx context.Context, w http.ResponseWriter, message string, err error) {
+	var status apierrors.APIStatus
+	if errors.As(err, &status) && status.Status().Code != 0 {
+		writeError(ctx, w, int(status.Status().Code), fmt.Sprintf("%s: %s", message, status.Status().Message))
+		return
+	}
+	log.FromContext(ctx).Error(err, message)
+	writeError(ctx, w, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,315 @@
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned/fake"
+)
+
+const (
+	testToken      = "token-ci"
+	testAdminToken = "token-admin"
+	testNamespace  = "ci"
+)
+
+func newTestServer(objects ...runtime.Object) (*Server, *fake.Clientset) {
+	client := fake.NewSimpleClientset(objects...)
+	return &Server{
+		Client: client,
+		Tokens: []Token{
+			{Name: "ci", Token: testToken, Namespaces: []string{testNamespace}},
+			{Name: "admin", Token: testAdminToken, Namespaces: []string{AllNamespaces}},
+		},
+	}, client
+}
+
+func doRequest(handler http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func newTestLease(name string, phase v1.Phase) *v1.Lease {
+	return &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec:       v1.LeaseSpec{VCpus: 24, Memory: 96, Networks: 1, NetworkType: v1.NetworkTypeSingleTenant},
+		Status:     v1.LeaseStatus{Phase: phase},
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	server, _ := newTestServer(newTestLease("lease-1", v1.PHASE_PENDING))
+	handler := server.Handler()
+
+	tests := []struct {
+		name     string
+		path     string
+		token    string
+		wantCode int
+	}{
+		{name: "no token", path: "/api/v1/namespaces/ci/leases/lease-1", wantCode: http.StatusUnauthorized},
+		{name: "unknown token", path: "/api/v1/namespaces/ci/leases/lease-1", token: "unknown", wantCode: http.StatusUnauthorized},
+		{name: "namespace of the token", path: "/api/v1/namespaces/ci/leases/lease-1", token: testToken, wantCode: http.StatusOK},
+		{name: "other namespace", path: "/api/v1/namespaces/other/leases/lease-1", token: testToken, wantCode: http.StatusForbidden},
+		{name: "all namespaces", path: "/api/v1/namespaces/other/leases/lease-1", token: testAdminToken, wantCode: http.StatusNotFound},
+		{name: "pools", path: "/api/v1/pools", token: testToken, wantCode: http.StatusOK},
+		{name: "pools without token", path: "/api/v1/pools", wantCode: http.StatusUnauthorized},
+		{name: "openapi without token", path: "/openapi.json", wantCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(handler, http.MethodGet, tt.path, tt.token, "")
+			if w.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCreateLease(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		check    func(t *testing.T, lease *v1.Lease)
+	}{
+		{
+			name:     "defaults",
+			body:     `{"name": "lease-1", "vcpus": 24, "memory": 96, "boskosLeaseID": "boskos-1"}`,
+			wantCode: http.StatusCreated,
+			check: func(t *testing.T, lease *v1.Lease) {
+				if lease.Spec.Networks != 1 || lease.Spec.NetworkType != v1.NetworkTypeSingleTenant {
+					t.Errorf("expected 1 single-tenant network, got %d %s", lease.Spec.Networks, lease.Spec.NetworkType)
+				}
+				if lease.Labels[v1.BoskosIdLabel] != "boskos-1" || lease.Spec.BoskosLeaseID != "boskos-1" {
+					t.Errorf("expected boskos lease id boskos-1, got labels %v", lease.Labels)
+				}
+			},
+		},
+		{
+			name:     "generated name",
+			body:     `{"vcpus": 24, "memory": 96, "networkType": "multi-tenant", "pools": 2}`,
+			wantCode: http.StatusCreated,
+			check: func(t *testing.T, lease *v1.Lease) {
+				if !strings.HasPrefix(lease.Name, generatedNamePrefix) || len(lease.Name) != len(generatedNamePrefix)+generatedNameLength {
+					t.Errorf("unexpected generated name %q", lease.Name)
+				}
+				if lease.Spec.Pools != 2 || lease.Spec.NetworkType != v1.NetworkTypeMultiTenant {
+					t.Errorf("unexpected spec %+v", lease.Spec)
+				}
+			},
+		},
+		{name: "existing lease", body: `{"name": "existing", "vcpus": 24, "memory": 96}`, wantCode: http.StatusConflict},
+		{name: "no vcpus", body: `{"memory": 96}`, wantCode: http.StatusBadRequest},
+		{name: "unknown network type", body: `{"vcpus": 24, "memory": 96, "networkType": "shared"}`, wantCode: http.StatusBadRequest},
+		{name: "unknown field", body: `{"vcpus": 24, "memory": 96, "cpus": 2}`, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, client := newTestServer(newTestLease("existing", v1.PHASE_PENDING))
+			w := doRequest(server.Handler(), http.MethodPost, "/api/v1/namespaces/ci/leases", testToken, tt.body)
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.check == nil {
+				return
+			}
+			response := &Lease{}
+			if err := json.Unmarshal(w.Body.Bytes(), response); err != nil {
+				t.Fatalf("unexpected response %s: %v", w.Body.String(), err)
+			}
+			lease, err := client.VspherecapacitymanagerV1().Leases(testNamespace).Get(context.TODO(), response.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("lease %s was not created: %v", response.Name, err)
+			}
+			tt.check(t, lease)
+		})
+	}
+}
+
+func TestGetLeaseWait(t *testing.T) {
+	server, client := newTestServer(newTestLease("lease-1", v1.PHASE_PENDING))
+	handler := server.Handler()
+
+	w := doRequest(handler, http.MethodGet, "/api/v1/namespaces/ci/leases/lease-1?wait=10ms", testToken, "")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"phase":"Pending"`) {
+		t.Fatalf("expected the pending lease once the wait is over, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := doRequest(handler, http.MethodGet, "/api/v1/namespaces/ci/leases/lease-1?wait=soon", testToken, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("expected an invalid wait to be rejected, got %d", w.Code)
+	}
+
+	responses := make(chan *httptest.ResponseRecorder)
+	client.ClearActions()
+	go func() {
+		responses <- doRequest(handler, http.MethodGet, "/api/v1/namespaces/ci/leases/lease-1?wait=1m", testToken, "")
+	}()
+	// fulfill the lease once the request watches it.
+	deadline := time.Now().Add(10 * time.Second)
+	for !hasWatch(client) {
+		if time.Now().After(deadline) {
+			t.Fatal("the request never watched the lease")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	fulfilled := newTestLease("lease-1", v1.PHASE_FULFILLED)
+	if _, err := client.VspherecapacitymanagerV1().Leases(testNamespace).UpdateStatus(context.TODO(), fulfilled, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	select {
+	case w := <-responses:
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"phase":"Fulfilled"`) {
+			t.Errorf("expected the fulfilled lease, got %d: %s", w.Code, w.Body.String())
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("the request did not return once the lease was fulfilled")
+	}
+}
+
+func hasWatch(client *fake.Clientset) bool {
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "watch" {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDeleteLease(t *testing.T) {
+	server, client := newTestServer(newTestLease("lease-1", v1.PHASE_FULFILLED))
+	handler := server.Handler()
+
+	if w := doRequest(handler, http.MethodDelete, "/api/v1/namespaces/ci/leases/lease-1", testToken, ""); w.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusNoContent, w.Code, w.Body.String())
+	}
+	if leases, _ := client.VspherecapacitymanagerV1().Leases(testNamespace).List(context.TODO(), metav1.ListOptions{}); len(leases.Items) != 0 {
+		t.Errorf("expected the lease to be deleted, got %d leases", len(leases.Items))
+	}
+	if w := doRequest(handler, http.MethodDelete, "/api/v1/namespaces/ci/leases/lease-1", testToken, ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestGetEnvVars(t *testing.T) {
+	fulfilled := newTestLease("fulfilled", v1.PHASE_FULFILLED)
+	fulfilled.Status.EnvVars = "export GOVC_URL=vcenter-2"
+	fulfilled.Status.EnvVarsMap = map[string]string{"pool-1": "export GOVC_URL=vcenter-1", "pool-2": "export GOVC_URL=vcenter-2"}
+	server, _ := newTestServer(fulfilled, newTestLease("pending", v1.PHASE_PENDING))
+	handler := server.Handler()
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "last pool", path: "/api/v1/namespaces/ci/leases/fulfilled/env", wantCode: http.StatusOK, wantBody: "export GOVC_URL=vcenter-2\n"},
+		{name: "pool", path: "/api/v1/namespaces/ci/leases/fulfilled/env?pool=pool-1", wantCode: http.StatusOK, wantBody: "export GOVC_URL=vcenter-1\n"},
+		{name: "unknown pool", path: "/api/v1/namespaces/ci/leases/fulfilled/env?pool=pool-3", wantCode: http.StatusNotFound},
+		{name: "pending lease", path: "/api/v1/namespaces/ci/leases/pending/env", wantCode: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(handler, http.MethodGet, tt.path, testToken, "")
+			if w.Code != tt.wantCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantCode, w.Code, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("expected %q, got %q", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestListPools(t *testing.T) {
+	cordoned := &v1.Pool{ObjectMeta: metav1.ObjectMeta{Name: "pool-b", Namespace: "infra"}, Spec: v1.PoolSpec{VCpus: 100, NoSchedule: true}}
+	available := &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{Name: "pool-a", Namespace: "infra"},
+		Spec:       v1.PoolSpec{VCpus: 100, Memory: 400},
+		Status:     v1.PoolStatus{VCpusAvailable: 76, MemoryAvailable: 304, NetworkAvailable: 3, LeaseCount: 1},
+	}
+	server, _ := newTestServer(cordoned, available)
+
+	w := doRequest(server.Handler(), http.MethodGet, "/api/v1/pools", testToken, "")
+	pools := []Pool{}
+	if err := json.Unmarshal(w.Body.Bytes(), &pools); err != nil {
+		t.Fatalf("unexpected response %s: %v", w.Body.String(), err)
+	}
+	if len(pools) != 2 {
+		t.Fatalf("expected 2 pools, got %+v", pools)
+	}
+	if pools[0].Name != "pool-a" || !pools[0].Schedulable || pools[0].VCpusAvailable != 76 || pools[0].Leases != 1 {
+		t.Errorf("unexpected pool %+v", pools[0])
+	}
+	if pools[1].Name != "pool-b" || pools[1].Schedulable {
+		t.Errorf("expected pool-b to be unschedulable, got %+v", pools[1])
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	server, _ := newTestServer()
+	w := doRequest(server.Handler(), http.MethodGet, "/openapi.json", "", "")
+	document := struct {
+		Paths map[string]interface{} `json:"paths"`
+	}{}
+	if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	for _, path := range []string{
+		"/api/v1/pools",
+		"/api/v1/namespaces/{namespace}/leases",
+		"/api/v1/namespaces/{namespace}/leases/{name}",
+		"/api/v1/namespaces/{namespace}/leases/{name}/env",
+		"/api/v1/namespaces/{namespace}/leases/{name}/events",
+	} {
+		if _, ok := document.Paths[path]; !ok {
+			t.Errorf("path %s is not documented", path)
+		}
+	}
+}
+
+func TestLoadTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{name: "tokens", content: "tokens:\n- name: ci\n  token: a\n  namespaces: [ci]\n- name: admin\n  token: b\n  namespaces: ['*']\n", want: 2},
+		{name: "no namespaces", content: "tokens:\n- name: ci\n  token: a\n", wantErr: true},
+		{name: "duplicate token", content: "tokens:\n- name: ci\n  token: a\n  namespaces: [ci]\n- name: other\n  token: a\n  namespaces: [other]\n", wantErr: true},
+		{name: "unknown field", content: "tokens:\n- name: ci\n  secret: a\n  namespaces: [ci]\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tokens.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			tokens, err := LoadTokens(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if len(tokens) != tt.want {
+				t.Errorf("expected %d tokens, got %d", tt.want, len(tokens))
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package restapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/informers/externalversions"
+	listers "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/listers/vspherecapacitymanager.splat.io/v1"
+)
+
+// streamKeepalive is how often a comment is sent on an idle stream, so proxies do not close it.
+const streamKeepalive = 30 * time.Second
+
+// leaseStreams shares one informer on the leases between all the streams of the API, so hundreds of clients
+// waiting for their leases cost a single watch on the API server.
+type leaseStreams struct {
+	client versioned.Interface
+	lister listers.LeaseLister
+
+	mu       sync.Mutex
+	synced   bool
+	watchers map[chan struct{}]struct{}
+}
+
+func newLeaseStreams(client versioned.Interface) *leaseStreams {
+	return &leaseStreams{
+		client:   client,
+		watchers: make(map[chan struct{}]struct{}),
+	}
+}
+
+// run keeps the informer of the streams running until ctx is done.
+func (s *leaseStreams) run(ctx context.Context) error {
+	factory := externalversions.NewSharedInformerFactory(s.client, 0)
+	informer := factory.Vspherecapacitymanager().V1().Leases()
+	_, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { s.notify() },
+		UpdateFunc: func(interface{}, interface{}) { s.notify() },
+		DeleteFunc: func(interface{}) { s.notify() },
+	})
+	if err != nil {
+		return err
+	}
+	s.lister = informer.Lister()
+
+	factory.Start(ctx.Done())
+	defer factory.Shutdown()
+	for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return ctx.Err()
+		}
+	}
+	s.mu.Lock()
+	s.synced = true
+	s.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
+
+func (s *leaseStreams) hasSynced() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.synced
+}
+
+// notify wakes up every stream after a lease changed.
+func (s *leaseStreams) notify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for watcher := range s.watchers {
+		select {
+		case watcher <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// subscribe returns a channel woken up after leases change, and the function closing it.
+func (s *leaseStreams) subscribe() (<-chan struct{}, func()) {
+	watcher := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.watchers[watcher] = struct{}{}
+	s.mu.Unlock()
+	return watcher, func() {
+		s.mu.Lock()
+		delete(s.watchers, watcher)
+		s.mu.Unlock()
+	}
+}
+
+// streamLease sends the lease as server-sent events: a lease event each time its phase, queue position,
+// conditions or environment variables change, and a deleted event once it is gone.
+func (s *Server) streamLease(w http.ResponseWriter, r *http.Request, _ *Token) {
+	namespace, name := r.PathValue("namespace"), r.PathValue("name")
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(r.Context(), w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	if !s.streams.hasSynced() {
+		w.Header().Set("Retry-After", "5")
+		writeError(r.Context(), w, http.StatusServiceUnavailable, "lease streams are not ready yet")
+		return
+	}
+	if _, err := s.streams.lister.Leases(namespace).Get(name); err != nil {
+		writeAPIError(r.Context(), w, "error getting lease", err)
+		return
+	}
+
+	changes, unsubscribe := s.streams.subscribe()
+	defer unsubscribe()
+	keepalive := time.NewTicker(streamKeepalive)
+	defer keepalive.Stop()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var last []byte
+	for {
+		event, data, err := s.leaseEvent(namespace, name)
+		if err != nil {
+			log.FromContext(r.Context()).Error(err, "unable to stream lease", "Lease", klog.KRef(namespace, name))
+			return
+		}
+		if !bytes.Equal(data, last) {
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+				return
+			}
+			flusher.Flush()
+			last = data
+		}
+		if event == "deleted" {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changes:
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// leaseEvent returns the event of the current state of a lease and its data.
+func (s *Server) leaseEvent(namespace, name string) (string, []byte, error) {
+	lease, err := s.streams.lister.Leases(namespace).Get(name)
+	if apierrors.IsNotFound(err) {
+		data, err := json.Marshal(map[string]string{"name": name, "namespace": namespace})
+		return "deleted", data, err
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := json.Marshal(newLease(lease))
+	return "lease", data, err
+}
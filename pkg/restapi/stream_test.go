@@ -0,0 +1,145 @@
+package restapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+type sseEvent struct {
+	event string
+	data  string
+}
+
+// readEvents sends the server-sent events of a response on a channel, which is closed when the stream ends.
+func readEvents(resp *http.Response) <-chan sseEvent {
+	events := make(chan sseEvent)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		event := sseEvent{}
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event.event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				event.data = strings.TrimPrefix(line, "data: ")
+			case line == "" && event.event != "":
+				events <- event
+				event = sseEvent{}
+			}
+		}
+	}()
+	return events
+}
+
+func nextLease(t *testing.T, events <-chan sseEvent) *Lease {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		if !ok || event.event != "lease" {
+			t.Fatalf("expected a lease event, got %+v", event)
+		}
+		lease := &Lease{}
+		if err := json.Unmarshal([]byte(event.data), lease); err != nil {
+			t.Fatalf("invalid lease event %s: %v", event.data, err)
+		}
+		return lease
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for a lease event")
+	}
+	return nil
+}
+
+func TestStreamLease(t *testing.T) {
+	lease := newTestLease("lease-1", v1.PHASE_PENDING)
+	lease.Status.QueuePosition = 2
+	lease.Status.BlockedBy = []string{"ci/lease-0"}
+	server, client := newTestServer(lease)
+	handler := server.Handler()
+
+	if w := doRequest(handler, http.MethodGet, "/api/v1/namespaces/ci/leases/lease-1/events", testToken, ""); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected streams to be unavailable before the leases are loaded, got %d", w.Code)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		if err := server.streams.run(ctx); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	}()
+	deadline := time.Now().Add(10 * time.Second)
+	for !server.streams.hasSynced() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	if w := doRequest(handler, http.MethodGet, "/api/v1/namespaces/ci/leases/missing/events", testToken, ""); w.Code != http.StatusNotFound {
+		t.Errorf("expected a missing lease to be not found, got %d", w.Code)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/api/v1/namespaces/ci/leases/lease-1/events", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	events := readEvents(resp)
+
+	if got := nextLease(t, events); got.Phase != v1.PHASE_PENDING || got.QueuePosition != 2 || len(got.BlockedBy) != 1 || got.Ready {
+		t.Errorf("expected the lease second in the queue, got %+v", got)
+	}
+
+	leases := client.VspherecapacitymanagerV1().Leases(testNamespace)
+	lease.Status.QueuePosition = 1
+	lease.Status.BlockedBy = nil
+	lease.Status.EstimatedWait = &metav1.Duration{Duration: 5 * time.Minute}
+	if _, err := leases.UpdateStatus(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := nextLease(t, events); got.QueuePosition != 1 || got.EstimatedWait == nil || got.EstimatedWait.Duration != 5*time.Minute {
+		t.Errorf("expected the lease first in the queue, got %+v", got)
+	}
+
+	lease.Status = v1.LeaseStatus{}
+	lease.Status.Phase = v1.PHASE_FULFILLED
+	lease.Status.EnvVarsMap = map[string]string{"pool-1": "export GOVC_URL=vcenter"}
+	if _, err := leases.UpdateStatus(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got := nextLease(t, events); !got.Ready || got.QueuePosition != 0 || got.EnvVars["pool-1"] != "export GOVC_URL=vcenter" {
+		t.Errorf("expected the fulfilled lease, got %+v", got)
+	}
+
+	if err := leases.Delete(ctx, lease.Name, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	select {
+	case event := <-events:
+		if event.event != "deleted" {
+			t.Errorf("expected a deleted event, got %+v", event)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for the deleted event")
+	}
+	if _, ok := <-events; ok {
+		t.Error("expected the stream to end once the lease is deleted")
+	}
+}
@@ -0,0 +1,183 @@
+package restapi
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// LeaseRequest is the body of a request creating a lease.
+type LeaseRequest struct {
+	// Name of the lease. a name is generated if empty.
+	Name          string            `json:"name,omitempty"`
+	VCpus         int               `json:"vcpus"`
+	Memory        int               `json:"memory"`
+	Networks      int               `json:"networks,omitempty"`
+	Pools         int               `json:"pools,omitempty"`
+	VCenters      int               `json:"vcenters,omitempty"`
+	NetworkType   v1.NetworkType    `json:"networkType,omitempty"`
+	RequiredPool  string            `json:"requiredPool,omitempty"`
+	PoolSelector  map[string]string `json:"poolSelector,omitempty"`
+	Priority      int32             `json:"priority,omitempty"`
+	BoskosLeaseID string            `json:"boskosLeaseID,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// lease returns the lease CR of a request.
+func (r *LeaseRequest) lease(namespace string) (*v1.Lease, error) {
+	if r.VCpus <= 0 || r.Memory <= 0 {
+		return nil, fmt.Errorf("vcpus and memory must be greater than 0")
+	}
+	if r.Networks < 0 || r.Pools < 0 || r.VCenters < 0 {
+		return nil, fmt.Errorf("networks, pools and vcenters can not be negative")
+	}
+	switch r.NetworkType {
+	case "", v1.NetworkTypeSingleTenant, v1.NetworkTypeMultiTenant, v1.NetworkTypeDisconnected:
+	default:
+		return nil, fmt.Errorf("unknown network type %q", r.NetworkType)
+	}
+
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        r.Name,
+			Namespace:   namespace,
+			Labels:      make(map[string]string),
+			Annotations: r.Annotations,
+		},
+		Spec: v1.LeaseSpec{
+			VCpus:         r.VCpus,
+			Memory:        r.Memory,
+			Networks:      r.Networks,
+			Pools:         r.Pools,
+			VCenters:      r.VCenters,
+			NetworkType:   r.NetworkType,
+			RequiredPool:  r.RequiredPool,
+			PoolSelector:  r.PoolSelector,
+			Priority:      r.Priority,
+			BoskosLeaseID: r.BoskosLeaseID,
+		},
+	}
+	for key, value := range r.Labels {
+		lease.Labels[key] = value
+	}
+	if lease.Spec.Networks == 0 {
+		lease.Spec.Networks = 1
+	}
+	if lease.Spec.NetworkType == "" {
+		lease.Spec.NetworkType = v1.NetworkTypeSingleTenant
+	}
+	if r.BoskosLeaseID != "" {
+		lease.Labels[v1.BoskosIdLabel] = r.BoskosLeaseID
+	}
+	return lease, nil
+}
+
+// Lease is a lease as returned by the API.
+type Lease struct {
+	Name          string         `json:"name"`
+	Namespace     string         `json:"namespace"`
+	Phase         v1.Phase       `json:"phase"`
+	NetworkType   v1.NetworkType `json:"networkType"`
+	VCpus         int            `json:"vcpus"`
+	Memory        int            `json:"memory"`
+	Networks      int            `json:"networks"`
+	BoskosLeaseID string         `json:"boskosLeaseID,omitempty"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	FulfilledAt   *time.Time     `json:"fulfilledAt,omitempty"`
+	// AssignedPools are the pools held by the lease.
+	AssignedPools []string `json:"assignedPools"`
+	// EnvVars are the environment variables of each pool of a fulfilled lease, as a script to source.
+	EnvVars    map[string]string `json:"envVars,omitempty"`
+	Conditions []v1.Condition    `json:"conditions,omitempty"`
+	// Ready is true while the lease is fulfilled and not being released.
+	Ready bool `json:"ready"`
+	// QueuePosition is the position of a waiting lease among the leases competing for the same pools, from 1.
+	QueuePosition int `json:"queuePosition,omitempty"`
+	// BlockedBy are the first competing leases scheduled before a waiting lease, as namespace/name.
+	BlockedBy []string `json:"blockedBy,omitempty"`
+	// EstimatedWait is the forecast wait of a waiting lease, such as 5m0s.
+	EstimatedWait *metav1.Duration `json:"estimatedWait,omitempty"`
+}
+
+func newLease(lease *v1.Lease) *Lease {
+	out := &Lease{
+		Name:          lease.Name,
+		Namespace:     lease.Namespace,
+		Phase:         lease.Status.Phase,
+		Ready:         lease.Status.Phase == v1.PHASE_FULFILLED && lease.DeletionTimestamp == nil,
+		NetworkType:   lease.Spec.NetworkType,
+		VCpus:         lease.Spec.VCpus,
+		Memory:        lease.Spec.Memory,
+		Networks:      lease.Spec.Networks,
+		BoskosLeaseID: lease.Labels[v1.BoskosIdLabel],
+		CreatedAt:     lease.CreationTimestamp.Time,
+		AssignedPools: []string{},
+		EnvVars:       lease.Status.EnvVarsMap,
+		Conditions:    lease.Status.Conditions,
+		QueuePosition: lease.Status.QueuePosition,
+		BlockedBy:     lease.Status.BlockedBy,
+		EstimatedWait: lease.Status.EstimatedWait,
+	}
+	if lease.Status.FulfilledAt != nil {
+		out.FulfilledAt = &lease.Status.FulfilledAt.Time
+	}
+	for _, ref := range utils.GetLeasePoolRefs(lease) {
+		out.AssignedPools = append(out.AssignedPools, ref.Name)
+	}
+	return out
+}
+
+// Pool is a pool and its available capacity as returned by the API.
+type Pool struct {
+	Name              string `json:"name"`
+	Namespace         string `json:"namespace"`
+	Server            string `json:"server"`
+	Datacenter        string `json:"datacenter"`
+	VCpus             int    `json:"vcpus"`
+	Memory            int    `json:"memory"`
+	VCpusAvailable    int    `json:"vcpusAvailable"`
+	MemoryAvailable   int    `json:"memoryAvailable"`
+	NetworksAvailable int    `json:"networksAvailable"`
+	Leases            int    `json:"leases"`
+	// Schedulable is false if the pool is cordoned, excluded or draining.
+	Schedulable bool `json:"schedulable"`
+}
+
+// newPools returns the pools sorted by namespace and name.
+func newPools(pools []v1.Pool) []Pool {
+	out := make([]Pool, 0, len(pools))
+	for i := range pools {
+		pool := &pools[i]
+		out = append(out, Pool{
+			Name:              pool.Name,
+			Namespace:         pool.Namespace,
+			Server:            pool.Spec.Server,
+			Datacenter:        pool.Spec.Topology.Datacenter,
+			VCpus:             pool.Spec.VCpus,
+			Memory:            pool.Spec.Memory,
+			VCpusAvailable:    pool.Status.VCpusAvailable,
+			MemoryAvailable:   pool.Status.MemoryAvailable,
+			NetworksAvailable: pool.Status.NetworkAvailable,
+			Leases:            pool.Status.LeaseCount,
+			Schedulable:       !pool.Spec.NoSchedule && !pool.Spec.Exclude && pool.Spec.Drain == nil,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// Error is the body of a failed request.
+type Error struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// DefaultProfileName is the name of the profile used for leases whose network type has no profile.
+	DefaultProfileName = "default"
+
+	// AllPlugins disables every default plugin of an extension point when listed in PluginSet.Disabled.
+	AllPlugins = "*"
+)
+
+// Config configures the scheduling profiles.
+type Config struct {
+	// Profiles configures the plugins used for each lease network type. network types without a profile are
+	// scheduled with the default plugins.
+	Profiles []Profile `json:"profiles,omitempty"`
+
+	// FairShare orders the leases of the same priority between tenants. fair sharing is disabled, and leases of
+	// the same priority are scheduled oldest first, unless it sets a tenant key.
+	FairShare *FairShareConfig `json:"fairShare,omitempty"`
+}
+
+// Profile configures the plugins used to schedule leases of a network type.
+type Profile struct {
+	// NetworkType is the lease network type scheduled by this profile. an empty network type configures the
+	// profile used for network types without a profile of their own.
+	NetworkType v1.NetworkType `json:"networkType,omitempty"`
+
+	// Plugins enables, disables and weights plugins relative to the default plugins.
+	Plugins *Plugins `json:"plugins,omitempty"`
+}
+
+// Plugins configures the plugins of each extension point.
+type Plugins struct {
+	Filter     PluginSet `json:"filter,omitempty"`
+	PostFilter PluginSet `json:"postFilter,omitempty"`
+	Score      PluginSet `json:"score,omitempty"`
+	Reserve    PluginSet `json:"reserve,omitempty"`
+}
+
+// PluginSet enables and disables plugins of an extension point. disabled plugins are removed from the defaults
+// first, then enabled plugins are appended. enabling a default plugin overrides its weight.
+type PluginSet struct {
+	Enabled  []PluginConfig `json:"enabled,omitempty"`
+	Disabled []PluginConfig `json:"disabled,omitempty"`
+}
+
+// PluginConfig names a plugin and its weight. weight is only used by score plugins and defaults to 1.
+type PluginConfig struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight,omitempty"`
+}
+
+// PluginFactory creates a plugin.
+type PluginFactory func() Plugin
+
+// Registry maps plugin names to their factories.
+type Registry map[string]PluginFactory
+
+// LoadConfig reads a YAML or JSON scheduler configuration from path.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading scheduler config %s: %w", path, err)
+	}
+	config := &Config{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("error parsing scheduler config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// mergePluginSet applies the enabled and disabled plugins of custom to defaults.
+func mergePluginSet(defaults []PluginConfig, custom PluginSet) []PluginConfig {
+	disabled := make(map[string]bool)
+	for _, plugin := range custom.Disabled {
+		disabled[plugin.Name] = true
+	}
+
+	var merged []PluginConfig
+	indexes := make(map[string]int)
+	for _, plugin := range defaults {
+		if disabled[AllPlugins] || disabled[plugin.Name] {
+			continue
+		}
+		indexes[plugin.Name] = len(merged)
+		merged = append(merged, plugin)
+	}
+
+	for _, plugin := range custom.Enabled {
+		if i, ok := indexes[plugin.Name]; ok {
+			if plugin.Weight != 0 {
+				merged[i].Weight = plugin.Weight
+			}
+			continue
+		}
+		indexes[plugin.Name] = len(merged)
+		merged = append(merged, plugin)
+	}
+	return merged
+}
+
+// mergePlugins applies custom to defaults. custom may be nil.
+func mergePlugins(defaults *Plugins, custom *Plugins) *Plugins {
+	if custom == nil {
+		custom = &Plugins{}
+	}
+	return &Plugins{
+		Filter:     PluginSet{Enabled: mergePluginSet(defaults.Filter.Enabled, custom.Filter)},
+		PostFilter: PluginSet{Enabled: mergePluginSet(defaults.PostFilter.Enabled, custom.PostFilter)},
+		Score:      PluginSet{Enabled: mergePluginSet(defaults.Score.Enabled, custom.Score)},
+		Reserve:    PluginSet{Enabled: mergePluginSet(defaults.Reserve.Enabled, custom.Reserve)},
+	}
+}
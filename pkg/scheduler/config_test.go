@@ -0,0 +1,105 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMergePluginSet(t *testing.T) {
+	defaults := []PluginConfig{
+		{Name: "A", Weight: 1},
+		{Name: "B", Weight: 1},
+		{Name: "C", Weight: 1},
+	}
+
+	tests := []struct {
+		name     string
+		custom   PluginSet
+		expected []PluginConfig
+	}{
+		{
+			name:     "no changes keeps the defaults",
+			custom:   PluginSet{},
+			expected: defaults,
+		},
+		{
+			name:     "disabled plugin is removed",
+			custom:   PluginSet{Disabled: []PluginConfig{{Name: "B"}}},
+			expected: []PluginConfig{{Name: "A", Weight: 1}, {Name: "C", Weight: 1}},
+		},
+		{
+			name:     "enabled default plugin overrides its weight in place",
+			custom:   PluginSet{Enabled: []PluginConfig{{Name: "B", Weight: 5}}},
+			expected: []PluginConfig{{Name: "A", Weight: 1}, {Name: "B", Weight: 5}, {Name: "C", Weight: 1}},
+		},
+		{
+			name:     "all plugins disabled and some re-enabled",
+			custom:   PluginSet{Disabled: []PluginConfig{{Name: AllPlugins}}, Enabled: []PluginConfig{{Name: "C"}, {Name: "D", Weight: 2}}},
+			expected: []PluginConfig{{Name: "C"}, {Name: "D", Weight: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergePluginSet(defaults, tt.custom)
+			if len(got) != len(tt.expected) {
+				t.Fatalf("mergePluginSet() = %v, expected %v", got, tt.expected)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("mergePluginSet() = %v, expected %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.yaml")
+	err := os.WriteFile(valid, []byte(`profiles:
+- networkType: multi-tenant
+  plugins:
+    score:
+      enabled:
+      - name: LeastAllocated
+        weight: 3
+      disabled:
+      - name: PoolAntiAffinity
+fairShare:
+  tenantKey: repo
+  shares:
+    openshift/installer: 2
+  halfLife: 30m
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(config.Profiles) != 1 || config.Profiles[0].NetworkType != "multi-tenant" {
+		t.Fatalf("unexpected profiles: %+v", config.Profiles)
+	}
+	score := config.Profiles[0].Plugins.Score
+	if len(score.Enabled) != 1 || score.Enabled[0].Weight != 3 || len(score.Disabled) != 1 {
+		t.Errorf("unexpected score plugins: %+v", score)
+	}
+	if config.FairShare == nil || config.FairShare.TenantKey != TenantKeyRepo ||
+		config.FairShare.Shares["openshift/installer"] != 2 || config.FairShare.HalfLife.Duration != 30*time.Minute {
+		t.Errorf("unexpected fair share config: %+v", config.FairShare)
+	}
+
+	unknownField := filepath.Join(dir, "unknown.yaml")
+	if err := os.WriteFile(unknownField, []byte("profile: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfig(unknownField); err == nil {
+		t.Errorf("expected an error for an unknown field")
+	}
+}
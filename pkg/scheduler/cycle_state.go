@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+// CycleState holds the data shared by plugins while a pool is picked for a lease. a new CycleState is created for
+// each pool picked, as the assigned pools change between picks.
+type CycleState struct {
+	// AssignedPools are the pools already held by the lease.
+	AssignedPools []*v1.Pool
+	// RequiredPools is the total number of pools the lease needs.
+	RequiredPools int
+	// Leases are the leases known to the scheduler.
+	Leases []*v1.Lease
+	// ExcludedVCenters are the vCenters rejected by post filter plugins in this cycle.
+	ExcludedVCenters map[string]bool
+}
+
+// NewCycleState returns the state for picking the next pool for lease.
+func NewCycleState(lease *v1.Lease, assignedPools []*v1.Pool, leases []*v1.Lease) *CycleState {
+	requiredPools := lease.Spec.Pools
+	if requiredPools == 0 {
+		requiredPools = 1
+	}
+	return &CycleState{
+		AssignedPools:    assignedPools,
+		RequiredPools:    requiredPools,
+		Leases:           leases,
+		ExcludedVCenters: make(map[string]bool),
+	}
+}
+
+// RemainingPools returns how many more pools the lease needs.
+func (s *CycleState) RemainingPools() int {
+	return s.RequiredPools - len(s.AssignedPools)
+}
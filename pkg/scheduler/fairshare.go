@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TenantKey is what the leases of a tenant have in common.
+type TenantKey string
+
+const (
+	// TenantKeyNone disables fair sharing. leases of the same priority are scheduled oldest first.
+	TenantKeyNone TenantKey = "none"
+	// TenantKeyNamespace makes each lease namespace a tenant.
+	TenantKeyNamespace TenantKey = "namespace"
+	// TenantKeyJobType makes each Prow job type (periodic, presubmit, ...) a tenant.
+	TenantKeyJobType TenantKey = "job-type"
+	// TenantKeyRepo makes each Prow git org/repo a tenant.
+	TenantKeyRepo TenantKey = "repo"
+
+	// DefaultTenant is the tenant of leases without a value for the tenant key.
+	DefaultTenant = "default"
+
+	// DefaultFairShareHalfLife is the default half-life of the historical usage of a tenant.
+	DefaultFairShareHalfLife = time.Hour
+)
+
+// FairShareConfig configures how leases of the same priority are ordered between tenants.
+type FairShareConfig struct {
+	// TenantKey groups leases into tenants. defaults to none, which disables fair sharing.
+	TenantKey TenantKey `json:"tenantKey,omitempty"`
+
+	// Shares are the weights of the tenants. a tenant with twice the share of another may use twice the
+	// resources before the other tenant's leases are scheduled first.
+	Shares map[string]float64 `json:"shares,omitempty"`
+
+	// DefaultShare is the share of tenants not listed in Shares. defaults to 1.
+	DefaultShare float64 `json:"defaultShare,omitempty"`
+
+	// HalfLife is the half-life of the historical usage of a tenant. defaults to 1h.
+	HalfLife metav1.Duration `json:"halfLife,omitempty"`
+}
+
+// Resources is an amount of vCPUs, memory and networks.
+type Resources struct {
+	VCpus    int
+	Memory   int
+	Networks int
+}
+
+// DominantShare returns the largest share of capacity used by r across vCPUs, memory and networks.
+func (r Resources) DominantShare(capacity Resources) float64 {
+	share := 0.0
+	for _, ratio := range [][2]int{
+		{r.VCpus, capacity.VCpus},
+		{r.Memory, capacity.Memory},
+		{r.Networks, capacity.Networks},
+	} {
+		if ratio[1] > 0 {
+			share = math.Max(share, float64(ratio[0])/float64(ratio[1]))
+		}
+	}
+	return share
+}
+
+// TenantUsage is the fair share state of a tenant.
+type TenantUsage struct {
+	Tenant string
+	// Share is the configured share of the tenant.
+	Share float64
+	// DominantShare is the dominant share of the capacity currently allocated to the tenant.
+	DominantShare float64
+	// Usage is the dominant share of the tenant integrated over time in seconds, decayed by the half-life.
+	Usage float64
+}
+
+// FairShare tracks the historical usage of each tenant, following dominant resource fairness: the usage of a
+// tenant is its largest share of any one resource. leases of the tenant with the lowest usage relative to its
+// share are scheduled first.
+type FairShare struct {
+	lock sync.Mutex
+
+	config    FairShareConfig
+	dominant  map[string]float64
+	usage     map[string]float64
+	updatedAt time.Time
+
+	now func() time.Time
+}
+
+// validateFairShareConfig checks config and applies its defaults.
+func validateFairShareConfig(config *FairShareConfig) error {
+	switch config.TenantKey {
+	case "":
+		config.TenantKey = TenantKeyNone
+	case TenantKeyNone, TenantKeyNamespace, TenantKeyJobType, TenantKeyRepo:
+	default:
+		return fmt.Errorf("unknown fair share tenant key %s", config.TenantKey)
+	}
+	for tenant, share := range config.Shares {
+		if share <= 0 {
+			return fmt.Errorf("fair share of tenant %s must be positive", tenant)
+		}
+	}
+	if config.DefaultShare < 0 {
+		return fmt.Errorf("default fair share must be positive")
+	}
+	if config.DefaultShare == 0 {
+		config.DefaultShare = 1
+	}
+	if config.HalfLife.Duration < 0 {
+		return fmt.Errorf("fair share half-life must be positive")
+	}
+	if config.HalfLife.Duration == 0 {
+		config.HalfLife.Duration = DefaultFairShareHalfLife
+	}
+	return nil
+}
+
+// NewFairShare returns a tracker with no usage. config must have its defaults applied.
+func NewFairShare(config FairShareConfig) *FairShare {
+	return &FairShare{
+		config:   config,
+		dominant: make(map[string]float64),
+		usage:    make(map[string]float64),
+		now:      time.Now,
+	}
+}
+
+// Config returns the configuration of the tracker.
+func (f *FairShare) Config() FairShareConfig {
+	return f.config
+}
+
+// Share returns the configured share of tenant.
+func (f *FairShare) Share(tenant string) float64 {
+	if share, ok := f.config.Shares[tenant]; ok {
+		return share
+	}
+	return f.config.DefaultShare
+}
+
+// Update accrues the usage of each tenant since the last update and records the resources now allocated to
+// each tenant out of capacity.
+func (f *FairShare) Update(allocated map[string]Resources, capacity Resources) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	now := f.now()
+	if !f.updatedAt.IsZero() {
+		elapsed := now.Sub(f.updatedAt).Seconds()
+		decay := math.Pow(0.5, elapsed/f.config.HalfLife.Seconds())
+		for tenant := range f.usage {
+			f.usage[tenant] *= decay
+		}
+		for tenant, dominant := range f.dominant {
+			f.usage[tenant] += dominant * elapsed
+		}
+	}
+	f.updatedAt = now
+
+	f.dominant = make(map[string]float64, len(allocated))
+	for tenant, resources := range allocated {
+		f.dominant[tenant] = resources.DominantShare(capacity)
+		if _, ok := f.usage[tenant]; !ok {
+			f.usage[tenant] = 0
+		}
+	}
+}
+
+// Ratio returns the usage of tenant relative to its share. leases of tenants with a lower ratio go first.
+func (f *FairShare) Ratio(tenant string) float64 {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return f.usage[tenant] / f.Share(tenant)
+}
+
+// Tenants returns the state of every tenant seen so far and of every tenant with a configured share, by name.
+func (f *FairShare) Tenants() []TenantUsage {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	names := make(map[string]bool)
+	for tenant := range f.usage {
+		names[tenant] = true
+	}
+	for tenant := range f.config.Shares {
+		names[tenant] = true
+	}
+
+	tenants := make([]TenantUsage, 0, len(names))
+	for tenant := range names {
+		tenants = append(tenants, TenantUsage{
+			Tenant:        tenant,
+			Share:         f.Share(tenant),
+			DominantShare: f.dominant[tenant],
+			Usage:         f.usage[tenant],
+		})
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].Tenant < tenants[j].Tenant
+	})
+	return tenants
+}
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDominantShare(t *testing.T) {
+	capacity := Resources{VCpus: 100, Memory: 400, Networks: 10}
+	tests := []struct {
+		name      string
+		resources Resources
+		capacity  Resources
+		expected  float64
+	}{
+		{
+			name:      "vcpus dominate",
+			resources: Resources{VCpus: 50, Memory: 40, Networks: 1},
+			capacity:  capacity,
+			expected:  0.5,
+		},
+		{
+			name:      "networks dominate",
+			resources: Resources{VCpus: 10, Memory: 40, Networks: 8},
+			capacity:  capacity,
+			expected:  0.8,
+		},
+		{
+			name:      "no capacity",
+			resources: Resources{VCpus: 10},
+			expected:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if share := tt.resources.DominantShare(tt.capacity); share != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, share)
+			}
+		})
+	}
+}
+
+func TestFairShareUpdate(t *testing.T) {
+	now := queueEpoch
+	config := FairShareConfig{
+		TenantKey:    TenantKeyJobType,
+		Shares:       map[string]float64{"presubmit": 2},
+		DefaultShare: 1,
+		HalfLife:     metav1.Duration{Duration: time.Hour},
+	}
+	fairShare := NewFairShare(config)
+	fairShare.now = func() time.Time { return now }
+	capacity := Resources{VCpus: 100, Memory: 100, Networks: 100}
+
+	fairShare.Update(map[string]Resources{
+		"presubmit": {VCpus: 50},
+		"periodic":  {VCpus: 25},
+	}, capacity)
+	if fairShare.Ratio("presubmit") != 0 || fairShare.Ratio("periodic") != 0 {
+		t.Fatalf("expected no usage before time passes")
+	}
+
+	// usage accrues with the allocations of the previous update and is halved every half-life
+	now = now.Add(time.Hour)
+	fairShare.Update(map[string]Resources{"periodic": {VCpus: 25}}, capacity)
+	if ratio := fairShare.Ratio("presubmit"); ratio != 0.5*3600/2 {
+		t.Errorf("expected presubmit ratio %v, got %v", 0.5*3600/2, ratio)
+	}
+	if ratio := fairShare.Ratio("periodic"); ratio != 0.25*3600 {
+		t.Errorf("expected periodic ratio %v, got %v", 0.25*3600, ratio)
+	}
+
+	now = now.Add(time.Hour)
+	fairShare.Update(nil, capacity)
+	if usage := fairShare.Ratio("presubmit") * 2; math.Abs(usage-0.5*3600/2) > 1e-9 {
+		t.Errorf("expected presubmit usage to decay to %v, got %v", 0.5*3600/2, usage)
+	}
+
+	tenants := fairShare.Tenants()
+	if len(tenants) != 2 || tenants[0].Tenant != "periodic" || tenants[1].Tenant != "presubmit" || tenants[1].Share != 2 {
+		t.Errorf("unexpected tenants: %+v", tenants)
+	}
+}
@@ -0,0 +1,162 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/tracing"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+type weightedScorePlugin struct {
+	ScorePlugin
+	weight float64
+}
+
+// Framework runs the plugins of a scheduling profile.
+type Framework struct {
+	profileName string
+
+	filterPlugins     []FilterPlugin
+	postFilterPlugins []PostFilterPlugin
+	scorePlugins      []weightedScorePlugin
+	reservePlugins    []ReservePlugin
+}
+
+// ProfileName returns the name of the profile the framework was built from.
+func (f *Framework) ProfileName() string {
+	return f.profileName
+}
+
+// RunFilterPlugins returns the pools which pass every filter plugin, along with the reason each rejected pool
+// was rejected.
+func (f *Framework) RunFilterPlugins(ctx context.Context, state *CycleState, lease *v1.Lease, pools []*v1.Pool) ([]*v1.Pool, []*utils.PoolFittingInfo) {
+	ctx, span := tracing.Start(ctx, "FilterPools", attribute.String("profile", f.profileName), attribute.Int("pools", len(pools)))
+	defer span.End()
+
+	var feasible []*v1.Pool
+	results := []*utils.PoolFittingInfo{}
+
+	for _, pool := range pools {
+		if status := f.runFilterPlugins(ctx, state, lease, pool); status != nil {
+			results = append(results, &utils.PoolFittingInfo{Pool: pool, MatchResults: status.Reason})
+			continue
+		}
+		feasible = append(feasible, pool)
+	}
+	span.SetAttributes(attribute.Int("feasible", len(feasible)))
+	return feasible, results
+}
+
+// RunPostFilterPlugins returns the feasible pools which pass every post filter plugin. the reasons of the
+// pools rejected are appended to results.
+func (f *Framework) RunPostFilterPlugins(ctx context.Context, state *CycleState, lease *v1.Lease, pools, feasible []*v1.Pool, results []*utils.PoolFittingInfo) ([]*v1.Pool, []*utils.PoolFittingInfo) {
+	for _, plugin := range f.postFilterPlugins {
+		if len(feasible) == 0 {
+			break
+		}
+		pluginCtx, span := tracing.Start(ctx, "PostFilter "+plugin.Name(), attribute.Int("feasible", len(feasible)))
+		rejected := plugin.PostFilter(pluginCtx, state, lease, pools, feasible)
+		span.SetAttributes(attribute.Int("rejected", len(rejected)))
+		span.End()
+		if len(rejected) == 0 {
+			continue
+		}
+		remaining := feasible[:0:0]
+		for _, pool := range feasible {
+			if status, ok := rejected[pool.Name]; ok {
+				results = append(results, &utils.PoolFittingInfo{Pool: pool, MatchResults: status.Reason})
+				continue
+			}
+			remaining = append(remaining, pool)
+		}
+		feasible = remaining
+	}
+	return feasible, results
+}
+
+func (f *Framework) runFilterPlugins(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) *Status {
+	for _, plugin := range f.filterPlugins {
+		if status := plugin.Filter(ctx, state, lease, pool); status != nil {
+			status.Plugin = plugin.Name()
+			return status
+		}
+	}
+	return nil
+}
+
+// RunScorePlugins returns the weighted sum of the normalized plugin scores of each pool, from the highest to
+// the lowest score. pools with equal scores keep their relative order.
+func (f *Framework) RunScorePlugins(ctx context.Context, state *CycleState, lease *v1.Lease, pools []*v1.Pool) []utils.PoolScore {
+	totals := make([]utils.PoolScore, len(pools))
+	for i, pool := range pools {
+		totals[i].Pool = pool
+	}
+
+	for _, plugin := range f.scorePlugins {
+		scores := make(map[string]float64, len(pools))
+		for _, pool := range pools {
+			scores[pool.Name] = plugin.Score(ctx, state, lease, pool)
+		}
+		plugin.NormalizeScores(scores)
+		for i, pool := range pools {
+			totals[i].Score += plugin.weight * scores[pool.Name]
+		}
+	}
+
+	sort.SliceStable(totals, func(i, j int) bool {
+		return totals[i].Score > totals[j].Score
+	})
+	return totals
+}
+
+// RunReservePlugins reserves the pool for the lease. if a plugin fails, the plugins which already ran are
+// unreserved.
+func (f *Framework) RunReservePlugins(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) error {
+	for i, plugin := range f.reservePlugins {
+		if err := plugin.Reserve(ctx, state, lease, pool); err != nil {
+			f.runUnreservePlugins(ctx, state, lease, pool, i)
+			return fmt.Errorf("plugin %s failed to reserve pool %s: %w", plugin.Name(), pool.Name, err)
+		}
+	}
+	return nil
+}
+
+// RunUnreservePlugins reverts a successful RunReservePlugins.
+func (f *Framework) RunUnreservePlugins(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) {
+	f.runUnreservePlugins(ctx, state, lease, pool, len(f.reservePlugins))
+}
+
+// runUnreservePlugins unreserves the pool with the first count reserve plugins, in reverse order.
+func (f *Framework) runUnreservePlugins(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool, count int) {
+	for i := count - 1; i >= 0; i-- {
+		f.reservePlugins[i].Unreserve(ctx, state, lease, pool)
+	}
+}
+
+// SchedulePool filters and scores pools and reserves the best one for the lease.
+func (f *Framework) SchedulePool(ctx context.Context, state *CycleState, lease *v1.Lease, pools []*v1.Pool) (pool *v1.Pool, err error) {
+	ctx, span := tracing.Start(ctx, "SchedulePool", attribute.String("profile", f.profileName), attribute.Int("pools", len(pools)))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	feasible, results := f.RunFilterPlugins(ctx, state, lease, pools)
+	feasible, results = f.RunPostFilterPlugins(ctx, state, lease, pools, feasible, results)
+	if len(feasible) == 0 {
+		return nil, fmt.Errorf("no pools available. %v", utils.GeneratePoolResults(results))
+	}
+
+	scores := f.RunScorePlugins(ctx, state, lease, feasible)
+	pool = scores[0].Pool
+	if err := f.RunReservePlugins(ctx, state, lease, pool); err != nil {
+		return nil, err
+	}
+	span.SetAttributes(tracing.PoolNameKey.String(pool.Name))
+	return pool, nil
+}
@@ -0,0 +1,195 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+// rejectPoolsPlugin is a filter and post filter plugin rejecting the named pools.
+type rejectPoolsPlugin struct {
+	name  string
+	pools map[string]bool
+}
+
+func (p *rejectPoolsPlugin) Name() string { return p.name }
+
+func (p *rejectPoolsPlugin) Filter(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) *Status {
+	if p.pools[pool.Name] {
+		return NewStatus("rejected by " + p.name)
+	}
+	return nil
+}
+
+func (p *rejectPoolsPlugin) PostFilter(ctx context.Context, state *CycleState, lease *v1.Lease, pools, feasible []*v1.Pool) map[string]*Status {
+	rejected := make(map[string]*Status)
+	for _, pool := range feasible {
+		if p.pools[pool.Name] {
+			rejected[pool.Name] = NewStatus("post filtered by " + p.name)
+		}
+	}
+	return rejected
+}
+
+// fixedScorePlugin scores pools from a fixed map.
+type fixedScorePlugin struct {
+	name   string
+	scores map[string]float64
+}
+
+func (p *fixedScorePlugin) Name() string { return p.name }
+
+func (p *fixedScorePlugin) Score(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) float64 {
+	return p.scores[pool.Name]
+}
+
+func (p *fixedScorePlugin) NormalizeScores(scores map[string]float64) {}
+
+// recordingReservePlugin records reservations and optionally fails.
+type recordingReservePlugin struct {
+	name     string
+	fail     bool
+	reserved map[string]bool
+}
+
+func (p *recordingReservePlugin) Name() string { return p.name }
+
+func (p *recordingReservePlugin) Reserve(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) error {
+	if p.fail {
+		return errors.New("reserve failed")
+	}
+	p.reserved[pool.Name] = true
+	return nil
+}
+
+func (p *recordingReservePlugin) Unreserve(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) {
+	delete(p.reserved, pool.Name)
+}
+
+func testPools(names ...string) []*v1.Pool {
+	var pools []*v1.Pool
+	for _, name := range names {
+		pools = append(pools, &v1.Pool{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	return pools
+}
+
+func TestRunFilterPlugins(t *testing.T) {
+	f := &Framework{
+		filterPlugins: []FilterPlugin{
+			&rejectPoolsPlugin{name: "first", pools: map[string]bool{"pool1": true}},
+			&rejectPoolsPlugin{name: "second", pools: map[string]bool{"pool1": true, "pool2": true}},
+		},
+		postFilterPlugins: []PostFilterPlugin{
+			&rejectPoolsPlugin{name: "post", pools: map[string]bool{"pool3": true}},
+		},
+	}
+
+	lease := &v1.Lease{}
+	pools := testPools("pool1", "pool2", "pool3", "pool4")
+	state := NewCycleState(lease, nil, nil)
+	feasible, results := f.RunFilterPlugins(context.TODO(), state, lease, pools)
+	if len(feasible) != 2 || len(results) != 2 {
+		t.Fatalf("expected 2 pools to pass filtering, got %d", len(feasible))
+	}
+	feasible, results = f.RunPostFilterPlugins(context.TODO(), state, lease, pools, feasible, results)
+	if len(feasible) != 1 || feasible[0].Name != "pool4" {
+		t.Fatalf("expected only pool4 to be feasible, got %v", feasible)
+	}
+
+	expected := map[string]string{
+		"pool1": "rejected by first",
+		"pool2": "rejected by second",
+		"pool3": "post filtered by post",
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d rejections, got %d", len(expected), len(results))
+	}
+	for _, result := range results {
+		if expected[result.Pool.Name] != result.MatchResults {
+			t.Errorf("pool %s rejected with %q, expected %q", result.Pool.Name, result.MatchResults, expected[result.Pool.Name])
+		}
+	}
+}
+
+func TestRunScorePlugins(t *testing.T) {
+	f := &Framework{
+		scorePlugins: []weightedScorePlugin{
+			{ScorePlugin: &fixedScorePlugin{name: "a", scores: map[string]float64{"pool1": 10, "pool2": 20}}, weight: 1},
+			{ScorePlugin: &fixedScorePlugin{name: "b", scores: map[string]float64{"pool1": 10, "pool2": 0}}, weight: 3},
+			{ScorePlugin: &fixedScorePlugin{name: "c", scores: map[string]float64{}}, weight: 1},
+		},
+	}
+
+	lease := &v1.Lease{}
+	scores := f.RunScorePlugins(context.TODO(), NewCycleState(lease, nil, nil), lease, testPools("pool2", "pool1", "pool3"))
+	expected := []struct {
+		name  string
+		score float64
+	}{
+		{"pool1", 40},
+		{"pool2", 20},
+		{"pool3", 0},
+	}
+	for i := range expected {
+		if scores[i].Pool.Name != expected[i].name || scores[i].Score != expected[i].score {
+			t.Errorf("score %d = %s:%v, expected %s:%v", i, scores[i].Pool.Name, scores[i].Score, expected[i].name, expected[i].score)
+		}
+	}
+}
+
+func TestSchedulePool(t *testing.T) {
+	newFramework := func(failReserve bool) (*Framework, *recordingReservePlugin) {
+		first := &recordingReservePlugin{name: "first", reserved: make(map[string]bool)}
+		return &Framework{
+			filterPlugins: []FilterPlugin{
+				&rejectPoolsPlugin{name: "filter", pools: map[string]bool{"pool1": true}},
+			},
+			scorePlugins: []weightedScorePlugin{
+				{ScorePlugin: &fixedScorePlugin{name: "score", scores: map[string]float64{"pool2": 10, "pool3": 50}}, weight: 1},
+			},
+			reservePlugins: []ReservePlugin{
+				first,
+				&recordingReservePlugin{name: "second", fail: failReserve, reserved: make(map[string]bool)},
+			},
+		}, first
+	}
+
+	t.Run("highest scoring feasible pool is reserved", func(t *testing.T) {
+		f, first := newFramework(false)
+		lease := &v1.Lease{}
+		pool, err := f.SchedulePool(context.TODO(), NewCycleState(lease, nil, nil), lease, testPools("pool1", "pool2", "pool3"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pool.Name != "pool3" || !first.reserved["pool3"] {
+			t.Errorf("expected pool3 to be scheduled and reserved, got %s", pool.Name)
+		}
+	})
+
+	t.Run("failed reservation is unreserved", func(t *testing.T) {
+		f, first := newFramework(true)
+		lease := &v1.Lease{}
+		_, err := f.SchedulePool(context.TODO(), NewCycleState(lease, nil, nil), lease, testPools("pool1", "pool2", "pool3"))
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if len(first.reserved) != 0 {
+			t.Errorf("expected earlier reservations to be reverted, got %v", first.reserved)
+		}
+	})
+
+	t.Run("no feasible pool reports the rejections", func(t *testing.T) {
+		f, _ := newFramework(false)
+		lease := &v1.Lease{}
+		_, err := f.SchedulePool(context.TODO(), NewCycleState(lease, nil, nil), lease, testPools("pool1"))
+		if err == nil || !strings.Contains(err.Error(), "[pool1: rejected by filter]") {
+			t.Errorf("expected error listing the rejected pool, got %v", err)
+		}
+	})
+}
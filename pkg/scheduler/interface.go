@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+// Plugin is the parent type for all scheduler plugins.
+type Plugin interface {
+	// Name returns the name used to enable and weight the plugin in the scheduler configuration.
+	Name() string
+}
+
+// Status reports why a plugin rejected a pool. a nil Status means the pool was accepted.
+type Status struct {
+	// Plugin is the name of the plugin which rejected the pool.
+	Plugin string
+	// Reason is the human readable reason the pool was rejected. it is surfaced on the lease conditions.
+	Reason string
+}
+
+// NewStatus returns a Status rejecting a pool for reason.
+func NewStatus(reason string) *Status {
+	return &Status{Reason: reason}
+}
+
+// FilterPlugin rejects pools which can not hold the lease. filters look at a single pool at a time and run in
+// the order they are configured, stopping at the first rejection.
+type FilterPlugin interface {
+	Plugin
+	// Filter returns nil if the pool can hold the lease.
+	Filter(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) *Status
+}
+
+// PostFilterPlugin rejects pools based on the set of pools which passed filtering. it is used for constraints
+// which can not be decided one pool at a time, such as the lease vCenter cap.
+type PostFilterPlugin interface {
+	Plugin
+	// PostFilter receives the candidate pools and the subset of them which passed filtering. it returns the
+	// feasible pools it rejects, keyed by pool name.
+	PostFilter(ctx context.Context, state *CycleState, lease *v1.Lease, pools, feasible []*v1.Pool) map[string]*Status
+}
+
+// ScorePlugin ranks the feasible pools for a lease. raw scores are normalized across all feasible pools to
+// [0, utils.MaxPoolScore] and multiplied by the configured weight of the plugin.
+type ScorePlugin interface {
+	Plugin
+	// Score returns the raw score of a pool for the lease.
+	Score(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) float64
+	// NormalizeScores scales raw scores, keyed by pool name, to [0, utils.MaxPoolScore].
+	NormalizeScores(scores map[string]float64)
+}
+
+// ReservePlugin claims the selected pool for the lease. if any reserve plugin fails, Unreserve is called on
+// the plugins which already reserved the pool, in reverse order.
+type ReservePlugin interface {
+	Plugin
+	// Reserve claims the pool for the lease.
+	Reserve(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool) error
+	// Unreserve reverts Reserve. it must be idempotent.
+	Unreserve(ctx context.Context, state *CycleState, lease *v1.Lease, pool *v1.Pool)
+}
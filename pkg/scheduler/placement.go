@@ -0,0 +1,328 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// DefaultPlacementMaxNodes bounds the number of search nodes SolvePlacement explores.
+	DefaultPlacementMaxNodes = 200000
+)
+
+var (
+	// ErrNoPlacement is returned when the search proves no set of candidate pools satisfies the constraints.
+	ErrNoPlacement = errors.New("no feasible pool placement")
+	// ErrPlacementSearchLimit is returned when the search bound is reached before a placement is found.
+	ErrPlacementSearchLimit = errors.New("pool placement search limit reached")
+)
+
+// PlacementCandidate is a pool which may be assigned to a lease.
+type PlacementCandidate struct {
+	Pool *v1.Pool
+	// Score is the score of the pool. the placement with the highest total score is picked.
+	Score float64
+	// VLANs are the VLAN IDs the lease can use in the pool.
+	VLANs map[string]bool
+}
+
+// PlacementProblem describes the pools a multi-pool lease still needs and the constraints on the complete set.
+type PlacementProblem struct {
+	// Assigned are the pools already held by the lease. they are part of every placement.
+	Assigned []PlacementCandidate
+	// Candidates are the pools which passed filtering for the lease.
+	Candidates []PlacementCandidate
+	// RequiredPools is the total number of pools the lease needs, including the assigned pools.
+	RequiredPools int
+	// VCenterCap is the maximum number of distinct vCenters across all pools. 0 is unlimited.
+	VCenterCap int
+	// NetworksPerPool is the number of VLANs which must be available in every pool. 0 disables the check.
+	NetworksPerPool int
+	// Accept is an optional check of a complete set of pools, for constraints which can only be decided
+	// once every pool is known. it may only depend on the vCenter, region, zone and IBM Cloud topology of
+	// the pools, as pools which only differ otherwise are treated as interchangeable.
+	Accept func(pools []*v1.Pool) bool
+	// MaxNodes bounds the search. DefaultPlacementMaxNodes is used when 0.
+	MaxNodes int
+}
+
+// Placement is the result of SolvePlacement.
+type Placement struct {
+	// Pools are the candidate pools picked, from the highest to the lowest score. assigned pools are not included.
+	Pools []*v1.Pool
+	// VLANs are the VLAN IDs available in every assigned and picked pool.
+	VLANs []string
+	// Score is the total score of the picked pools.
+	Score float64
+	// NodesExplored is the number of search nodes explored.
+	NodesExplored int
+	// Exhaustive is true if the search proved the placement is optimal.
+	Exhaustive bool
+}
+
+type placementSearch struct {
+	problem    *PlacementProblem
+	candidates []PlacementCandidate
+	// prefix[i] is the total score of candidates[:i]
+	prefix []float64
+	// next[i] is the index of the first candidate after i which is not equivalent to candidates[i]
+	next []int
+
+	needed   int
+	maxNodes int
+	nodes    int
+	limited  bool
+
+	serverCounts map[string]int
+	chosen       []int
+
+	found     bool
+	bestScore float64
+	best      []int
+	bestVLANs map[string]bool
+}
+
+// SolvePlacement picks the candidate pools which complete the lease with the highest total score, subject to
+// the vCenter cap, a common set of VLANs across all pools and the Accept check. it uses a depth first
+// branch-and-bound search over the candidates sorted by score:
+//   - a branch is pruned if the scores of the best remaining candidates can not beat the best placement found
+//   - a branch is pruned if the vCenter cap or the common VLANs leave too few remaining candidates
+//   - candidates on the same vCenter and topology, with the same VLANs and score, are interchangeable so only
+//     one of them is tried in each position
+//
+// ErrNoPlacement is returned when no placement exists. if MaxNodes is reached, the best placement found so far
+// is returned with Exhaustive false, or ErrPlacementSearchLimit if none was found.
+func SolvePlacement(problem *PlacementProblem) (*Placement, error) {
+	s := &placementSearch{
+		problem:      problem,
+		needed:       problem.RequiredPools - len(problem.Assigned),
+		maxNodes:     problem.MaxNodes,
+		serverCounts: make(map[string]int),
+	}
+	if s.maxNodes <= 0 {
+		s.maxNodes = DefaultPlacementMaxNodes
+	}
+	if s.needed <= 0 {
+		return &Placement{Exhaustive: true}, nil
+	}
+
+	var vlans map[string]bool
+	for _, assigned := range problem.Assigned {
+		s.serverCounts[assigned.Pool.Spec.Server]++
+		vlans = intersectVLANs(vlans, assigned.VLANs)
+	}
+	if problem.VCenterCap > 0 && len(s.serverCounts) > problem.VCenterCap {
+		return nil, fmt.Errorf("%w: assigned pools already use %d vCenters, cap is %d", ErrNoPlacement, len(s.serverCounts), problem.VCenterCap)
+	}
+	if problem.NetworksPerPool > 0 && vlans != nil && len(vlans) < problem.NetworksPerPool {
+		return nil, fmt.Errorf("%w: assigned pools have %d common VLANs, %d needed", ErrNoPlacement, len(vlans), problem.NetworksPerPool)
+	}
+
+	type keyedCandidate struct {
+		PlacementCandidate
+		key string
+	}
+	keyed := make([]keyedCandidate, len(problem.Candidates))
+	for i, candidate := range problem.Candidates {
+		keyed[i] = keyedCandidate{PlacementCandidate: candidate, key: candidateKey(candidate)}
+	}
+	sort.SliceStable(keyed, func(i, j int) bool {
+		if keyed[i].Score != keyed[j].Score {
+			return keyed[i].Score > keyed[j].Score
+		}
+		return keyed[i].key < keyed[j].key
+	})
+	s.candidates = make([]PlacementCandidate, len(keyed))
+	for i := range keyed {
+		s.candidates[i] = keyed[i].PlacementCandidate
+	}
+
+	s.prefix = make([]float64, len(s.candidates)+1)
+	for i, candidate := range s.candidates {
+		s.prefix[i+1] = s.prefix[i] + candidate.Score
+	}
+	s.next = make([]int, len(s.candidates))
+	for i := len(s.candidates) - 1; i >= 0; i-- {
+		if i+1 < len(s.candidates) && keyed[i].key == keyed[i+1].key {
+			s.next[i] = s.next[i+1]
+		} else {
+			s.next[i] = i + 1
+		}
+	}
+
+	s.search(0, vlans, 0)
+
+	if !s.found {
+		if s.limited {
+			return nil, fmt.Errorf("%w after %d nodes", ErrPlacementSearchLimit, s.nodes)
+		}
+		return nil, fmt.Errorf("%w: no %d of %d candidate pools satisfy the vCenter, VLAN and topology constraints", ErrNoPlacement, s.needed, len(s.candidates))
+	}
+
+	placement := &Placement{
+		Score:         s.bestScore,
+		NodesExplored: s.nodes,
+		Exhaustive:    !s.limited,
+	}
+	for _, i := range s.best {
+		placement.Pools = append(placement.Pools, s.candidates[i].Pool)
+	}
+	for vlan := range s.bestVLANs {
+		placement.VLANs = append(placement.VLANs, vlan)
+	}
+	sort.Strings(placement.VLANs)
+	return placement, nil
+}
+
+func (s *placementSearch) search(i int, vlans map[string]bool, score float64) {
+	remaining := s.needed - len(s.chosen)
+	if remaining == 0 {
+		s.visitLeaf(vlans, score)
+		return
+	}
+	if s.nodes >= s.maxNodes {
+		s.limited = true
+		return
+	}
+	s.nodes++
+
+	if len(s.candidates)-i < remaining {
+		return
+	}
+	if s.found && score+s.prefix[i+remaining]-s.prefix[i] <= s.bestScore {
+		return
+	}
+	if !s.canComplete(i, remaining, vlans) {
+		return
+	}
+
+	candidate := s.candidates[i]
+	if s.canInclude(candidate) {
+		include := vlans
+		if s.problem.NetworksPerPool > 0 {
+			include = intersectVLANs(vlans, candidate.VLANs)
+		}
+		if s.problem.NetworksPerPool == 0 || len(include) >= s.problem.NetworksPerPool {
+			s.chosen = append(s.chosen, i)
+			s.serverCounts[candidate.Pool.Spec.Server]++
+			s.search(i+1, include, score+candidate.Score)
+			s.serverCounts[candidate.Pool.Spec.Server]--
+			if s.serverCounts[candidate.Pool.Spec.Server] == 0 {
+				delete(s.serverCounts, candidate.Pool.Spec.Server)
+			}
+			s.chosen = s.chosen[:len(s.chosen)-1]
+		}
+	}
+
+	// excluding a candidate excludes the equivalent candidates after it. including them instead would lead to
+	// placements equivalent to the ones found by including this candidate.
+	s.search(s.next[i], vlans, score)
+}
+
+func (s *placementSearch) visitLeaf(vlans map[string]bool, score float64) {
+	if s.found && score <= s.bestScore {
+		return
+	}
+	if s.problem.Accept != nil {
+		pools := make([]*v1.Pool, 0, len(s.problem.Assigned)+len(s.chosen))
+		for _, assigned := range s.problem.Assigned {
+			pools = append(pools, assigned.Pool)
+		}
+		for _, i := range s.chosen {
+			pools = append(pools, s.candidates[i].Pool)
+		}
+		if !s.problem.Accept(pools) {
+			return
+		}
+	}
+	s.found = true
+	s.bestScore = score
+	s.best = append(s.best[:0], s.chosen...)
+	s.bestVLANs = vlans
+}
+
+// canInclude returns true if adding candidate keeps the placement within the vCenter cap.
+func (s *placementSearch) canInclude(candidate PlacementCandidate) bool {
+	if s.problem.VCenterCap == 0 {
+		return true
+	}
+	if s.serverCounts[candidate.Pool.Spec.Server] > 0 {
+		return true
+	}
+	return len(s.serverCounts) < s.problem.VCenterCap
+}
+
+// canComplete returns false if the candidates from i on can not provide the remaining pools within the vCenter
+// cap and the common VLANs.
+func (s *placementSearch) canComplete(i, remaining int, vlans map[string]bool) bool {
+	usable := 0
+	newServerCounts := make(map[string]int)
+	for _, candidate := range s.candidates[i:] {
+		if s.problem.NetworksPerPool > 0 && vlans != nil && countCommonVLANs(vlans, candidate.VLANs) < s.problem.NetworksPerPool {
+			continue
+		}
+		server := candidate.Pool.Spec.Server
+		if s.problem.VCenterCap == 0 || s.serverCounts[server] > 0 {
+			usable++
+			continue
+		}
+		newServerCounts[server]++
+	}
+	if s.problem.VCenterCap == 0 || usable >= remaining {
+		return usable >= remaining
+	}
+
+	counts := make([]int, 0, len(newServerCounts))
+	for _, count := range newServerCounts {
+		counts = append(counts, count)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(counts)))
+	for j := 0; j < len(counts) && j < s.problem.VCenterCap-len(s.serverCounts); j++ {
+		usable += counts[j]
+	}
+	return usable >= remaining
+}
+
+// intersectVLANs returns the VLANs in both a and b. a nil a is treated as every VLAN.
+func intersectVLANs(a, b map[string]bool) map[string]bool {
+	if a == nil {
+		out := make(map[string]bool, len(b))
+		for vlan := range b {
+			out[vlan] = true
+		}
+		return out
+	}
+	out := make(map[string]bool)
+	for vlan := range a {
+		if b[vlan] {
+			out[vlan] = true
+		}
+	}
+	return out
+}
+
+func countCommonVLANs(a, b map[string]bool) int {
+	count := 0
+	for vlan := range a {
+		if b[vlan] {
+			count++
+		}
+	}
+	return count
+}
+
+// candidateKey identifies candidates which are interchangeable in a placement.
+func candidateKey(candidate PlacementCandidate) string {
+	spec := candidate.Pool.Spec
+	vlans := make([]string, 0, len(candidate.VLANs))
+	for vlan := range candidate.VLANs {
+		vlans = append(vlans, vlan)
+	}
+	sort.Strings(vlans)
+	return fmt.Sprintf("%v|%s|%s|%s|%s|%s|%s", candidate.Score, spec.Server, spec.Region, spec.Zone,
+		spec.IBMPoolSpec.Datacenter, spec.IBMPoolSpec.Pod, strings.Join(vlans, ","))
+}
@@ -0,0 +1,283 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func placementCandidate(name, server string, score float64, vlans ...string) PlacementCandidate {
+	candidate := PlacementCandidate{
+		Pool: &v1.Pool{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+		},
+		Score: score,
+		VLANs: make(map[string]bool),
+	}
+	candidate.Pool.Spec.Server = server
+	for _, vlan := range vlans {
+		candidate.VLANs[vlan] = true
+	}
+	return candidate
+}
+
+func TestSolvePlacement(t *testing.T) {
+	tests := []struct {
+		name          string
+		problem       *PlacementProblem
+		expectedPools []string
+		expectedScore float64
+		expectedVLANs []string
+		expectedErr   error
+	}{
+		{
+			name: "highest scores without constraints",
+			problem: &PlacementProblem{
+				RequiredPools: 2,
+				Candidates: []PlacementCandidate{
+					placementCandidate("pool-a", "vcenter-a", 10),
+					placementCandidate("pool-b", "vcenter-b", 30),
+					placementCandidate("pool-c", "vcenter-c", 20),
+				},
+			},
+			expectedPools: []string{"pool-b", "pool-c"},
+			expectedScore: 50,
+		},
+		{
+			name: "vCenter cap avoids the greedy trap",
+			problem: &PlacementProblem{
+				RequiredPools: 4,
+				VCenterCap:    3,
+				Candidates: []PlacementCandidate{
+					placementCandidate("pool-a", "vcenter-a", 100),
+					placementCandidate("pool-b", "vcenter-b", 99),
+					placementCandidate("pool-c", "vcenter-c", 98),
+					placementCandidate("pool-d1", "vcenter-d", 50),
+					placementCandidate("pool-d2", "vcenter-d", 50),
+				},
+			},
+			expectedPools: []string{"pool-a", "pool-b", "pool-d1", "pool-d2"},
+			expectedScore: 299,
+		},
+		{
+			name: "pools share the networks the lease needs",
+			problem: &PlacementProblem{
+				RequiredPools:   2,
+				NetworksPerPool: 1,
+				Candidates: []PlacementCandidate{
+					placementCandidate("pool-1", "vcenter-a", 100, "10"),
+					placementCandidate("pool-2", "vcenter-a", 90, "20"),
+					placementCandidate("pool-3", "vcenter-a", 80, "10"),
+				},
+			},
+			expectedPools: []string{"pool-1", "pool-3"},
+			expectedScore: 180,
+			expectedVLANs: []string{"10"},
+		},
+		{
+			name: "assigned pools count towards the cap and the networks",
+			problem: &PlacementProblem{
+				RequiredPools:   3,
+				VCenterCap:      2,
+				NetworksPerPool: 1,
+				Assigned: []PlacementCandidate{
+					placementCandidate("pool-a", "vcenter-a", 0, "10", "20"),
+				},
+				Candidates: []PlacementCandidate{
+					placementCandidate("pool-b", "vcenter-b", 100, "30"),
+					placementCandidate("pool-c1", "vcenter-c", 90, "20"),
+					placementCandidate("pool-c2", "vcenter-c", 80, "20"),
+					placementCandidate("pool-d", "vcenter-d", 95, "10"),
+				},
+			},
+			expectedPools: []string{"pool-c1", "pool-c2"},
+			expectedScore: 170,
+			expectedVLANs: []string{"20"},
+		},
+		{
+			name: "accept rejects placements",
+			problem: &PlacementProblem{
+				RequiredPools: 2,
+				Candidates: []PlacementCandidate{
+					placementCandidate("pool-a1", "vcenter-a", 100),
+					placementCandidate("pool-a2", "vcenter-a", 90),
+					placementCandidate("pool-b", "vcenter-b", 10),
+				},
+				Accept: func(pools []*v1.Pool) bool {
+					servers := make(map[string]bool)
+					for _, pool := range pools {
+						servers[pool.Spec.Server] = true
+					}
+					return len(servers) == len(pools)
+				},
+			},
+			expectedPools: []string{"pool-a1", "pool-b"},
+			expectedScore: 110,
+		},
+		{
+			name: "lease already complete",
+			problem: &PlacementProblem{
+				RequiredPools: 1,
+				Assigned:      []PlacementCandidate{placementCandidate("pool-a", "vcenter-a", 0)},
+			},
+		},
+		{
+			name: "too few candidates",
+			problem: &PlacementProblem{
+				RequiredPools: 3,
+				Candidates: []PlacementCandidate{
+					placementCandidate("pool-a", "vcenter-a", 10),
+					placementCandidate("pool-b", "vcenter-b", 10),
+				},
+			},
+			expectedErr: ErrNoPlacement,
+		},
+		{
+			name: "no placement within the cap",
+			problem: &PlacementProblem{
+				RequiredPools: 3,
+				VCenterCap:    2,
+				Candidates: []PlacementCandidate{
+					placementCandidate("pool-a", "vcenter-a", 10),
+					placementCandidate("pool-b", "vcenter-b", 10),
+					placementCandidate("pool-c", "vcenter-c", 10),
+				},
+			},
+			expectedErr: ErrNoPlacement,
+		},
+		{
+			name: "assigned pools exceed the cap",
+			problem: &PlacementProblem{
+				RequiredPools: 3,
+				VCenterCap:    1,
+				Assigned: []PlacementCandidate{
+					placementCandidate("pool-a", "vcenter-a", 0),
+					placementCandidate("pool-b", "vcenter-b", 0),
+				},
+				Candidates: []PlacementCandidate{placementCandidate("pool-a2", "vcenter-a", 10)},
+			},
+			expectedErr: ErrNoPlacement,
+		},
+		{
+			name: "no common networks",
+			problem: &PlacementProblem{
+				RequiredPools:   2,
+				NetworksPerPool: 2,
+				Candidates: []PlacementCandidate{
+					placementCandidate("pool-a", "vcenter-a", 10, "10", "20"),
+					placementCandidate("pool-b", "vcenter-a", 10, "20", "30"),
+					placementCandidate("pool-c", "vcenter-a", 10, "10", "30"),
+				},
+			},
+			expectedErr: ErrNoPlacement,
+		},
+		{
+			name: "search limit without a placement",
+			problem: &PlacementProblem{
+				RequiredPools: 2,
+				MaxNodes:      1,
+				Candidates: []PlacementCandidate{
+					placementCandidate("pool-a", "vcenter-a", 10),
+					placementCandidate("pool-b", "vcenter-b", 10),
+				},
+				Accept: func(pools []*v1.Pool) bool { return false },
+			},
+			expectedErr: ErrPlacementSearchLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placement, err := SolvePlacement(tt.problem)
+			if tt.expectedErr != nil {
+				if !errors.Is(err, tt.expectedErr) {
+					t.Fatalf("expected error %v, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !placement.Exhaustive {
+				t.Errorf("expected an exhaustive search")
+			}
+			if placement.Score != tt.expectedScore {
+				t.Errorf("expected score %v, got %v", tt.expectedScore, placement.Score)
+			}
+			var pools []string
+			for _, pool := range placement.Pools {
+				pools = append(pools, pool.Name)
+			}
+			if fmt.Sprint(pools) != fmt.Sprint(tt.expectedPools) {
+				t.Errorf("expected pools %v, got %v", tt.expectedPools, pools)
+			}
+			if tt.expectedVLANs != nil && fmt.Sprint(placement.VLANs) != fmt.Sprint(tt.expectedVLANs) {
+				t.Errorf("expected VLANs %v, got %v", tt.expectedVLANs, placement.VLANs)
+			}
+		})
+	}
+}
+
+// benchmarkCandidates returns pools spread over vCenters, each with a few of the VLANs of its vCenter.
+func benchmarkCandidates(count, vcenters int) []PlacementCandidate {
+	candidates := make([]PlacementCandidate, count)
+	for i := range candidates {
+		server := i % vcenters
+		candidates[i] = placementCandidate(fmt.Sprintf("pool-%d", i), fmt.Sprintf("vcenter-%d", server),
+			float64((i*7919)%100),
+			fmt.Sprintf("%d", server*10+i%4), fmt.Sprintf("%d", server*10+(i+1)%4), fmt.Sprintf("%d", server*10+(i+2)%4))
+	}
+	return candidates
+}
+
+func BenchmarkSolvePlacement(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		problem *PlacementProblem
+	}{
+		{
+			name: "100 pools",
+			problem: &PlacementProblem{
+				RequiredPools:   4,
+				VCenterCap:      2,
+				NetworksPerPool: 1,
+				Candidates:      benchmarkCandidates(100, 10),
+			},
+		},
+		{
+			name: "250 pools",
+			problem: &PlacementProblem{
+				RequiredPools:   6,
+				VCenterCap:      3,
+				NetworksPerPool: 1,
+				Candidates:      benchmarkCandidates(250, 10),
+			},
+		},
+		{
+			name: "250 pools infeasible",
+			problem: &PlacementProblem{
+				RequiredPools:   30,
+				VCenterCap:      1,
+				NetworksPerPool: 1,
+				Candidates:      benchmarkCandidates(250, 10),
+			},
+		},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			nodes := 0
+			for i := 0; i < b.N; i++ {
+				placement, err := SolvePlacement(bm.problem)
+				if err == nil {
+					nodes = placement.NodesExplored
+				}
+			}
+			b.ReportMetric(float64(nodes), "nodes/op")
+		})
+	}
+}
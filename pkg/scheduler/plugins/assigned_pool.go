@@ -0,0 +1,23 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// AssignedPool rejects pools the lease already holds.
+type AssignedPool struct{}
+
+var _ scheduler.FilterPlugin = &AssignedPool{}
+
+func (p *AssignedPool) Name() string { return AssignedPoolName }
+
+func (p *AssignedPool) Filter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) *scheduler.Status {
+	if utils.LeaseHasPool(lease, pool.Name) {
+		return scheduler.NewStatus(utils.PoolAlreadyAssigned)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestAssignedPoolFilter(t *testing.T) {
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: v1.PoolKind, Name: "pool1"},
+				{Kind: "Network", Name: "pool2"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		pool     *v1.Pool
+		expected string
+	}{
+		{
+			name:     "pool held by the lease is rejected",
+			pool:     newTestPool("pool1", "vcenter-a", 10, 10),
+			expected: utils.PoolAlreadyAssigned,
+		},
+		{
+			name:     "network owner reference with the same name is ignored",
+			pool:     newTestPool("pool2", "vcenter-a", 10, 10),
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statusReason((&AssignedPool{}).Filter(context.TODO(), newTestState(lease), lease, tt.pool))
+			if got != tt.expected {
+				t.Errorf("Filter() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// Exclude rejects pools marked as excluded, unless the lease requires the pool by name.
+type Exclude struct{}
+
+var _ scheduler.FilterPlugin = &Exclude{}
+
+func (p *Exclude) Name() string { return ExcludeName }
+
+func (p *Exclude) Filter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) *scheduler.Status {
+	if pool.Spec.Exclude && lease.Spec.RequiredPool != pool.Name {
+		return scheduler.NewStatus(utils.PoolExcluded)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestExcludeFilter(t *testing.T) {
+	excluded := newTestPool("pool1", "vcenter-a", 10, 10)
+	excluded.Spec.Exclude = true
+
+	tests := []struct {
+		name     string
+		lease    *v1.Lease
+		pool     *v1.Pool
+		expected string
+	}{
+		{
+			name:     "pool not excluded is accepted",
+			lease:    &v1.Lease{},
+			pool:     newTestPool("pool1", "vcenter-a", 10, 10),
+			expected: "",
+		},
+		{
+			name:     "excluded pool is rejected",
+			lease:    &v1.Lease{},
+			pool:     excluded,
+			expected: utils.PoolExcluded,
+		},
+		{
+			name:     "excluded pool required by name is accepted",
+			lease:    &v1.Lease{Spec: v1.LeaseSpec{RequiredPool: "pool1"}},
+			pool:     excluded,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statusReason((&Exclude{}).Filter(context.TODO(), newTestState(tt.lease), tt.lease, tt.pool))
+			if got != tt.expected {
+				t.Errorf("Filter() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
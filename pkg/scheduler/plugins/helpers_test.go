@@ -0,0 +1,59 @@
+package plugins
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+)
+
+func newTestPool(name, server string, vcpusAvailable, memoryAvailable int) *v1.Pool {
+	return &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Spec: v1.PoolSpec{
+			FailureDomainSpec: v1.FailureDomainSpec{
+				VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+					Server: server,
+				},
+			},
+			VCpus:  100,
+			Memory: 100,
+		},
+		Status: v1.PoolStatus{
+			VCpusAvailable:  vcpusAvailable,
+			MemoryAvailable: memoryAvailable,
+		},
+	}
+}
+
+func scoringTestPool(name string, poolLabels map[string]string, vcpusAvailable int, taints []v1.Taint) *v1.Pool {
+	return &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: poolLabels,
+		},
+		Spec: v1.PoolSpec{
+			VCpus:  100,
+			Memory: 100,
+			Taints: taints,
+		},
+		Status: v1.PoolStatus{
+			VCpusAvailable:  vcpusAvailable,
+			MemoryAvailable: vcpusAvailable,
+		},
+	}
+}
+
+func newTestState(lease *v1.Lease, assignedPools ...*v1.Pool) *scheduler.CycleState {
+	return scheduler.NewCycleState(lease, assignedPools, nil)
+}
+
+func statusReason(status *scheduler.Status) string {
+	if status == nil {
+		return ""
+	}
+	return status.Reason
+}
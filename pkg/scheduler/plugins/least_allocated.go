@@ -0,0 +1,31 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// LeastAllocated prefers pools with the largest share of free vCPUs and memory.
+type LeastAllocated struct {
+	scorer utils.PoolScorer
+}
+
+var _ scheduler.ScorePlugin = &LeastAllocated{}
+
+// NewLeastAllocated returns the LeastAllocated plugin.
+func NewLeastAllocated() scheduler.Plugin {
+	return &LeastAllocated{scorer: utils.NewLeastAllocatedScorer(1)}
+}
+
+func (p *LeastAllocated) Name() string { return LeastAllocatedName }
+
+func (p *LeastAllocated) Score(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) float64 {
+	return p.scorer.Score(lease, pool)
+}
+
+func (p *LeastAllocated) NormalizeScores(scores map[string]float64) {
+	p.scorer.NormalizeScores(scores)
+}
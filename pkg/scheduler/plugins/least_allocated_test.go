@@ -0,0 +1,33 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+)
+
+func TestLeastAllocatedScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		pool     *v1.Pool
+		expected float64
+	}{
+		{name: "empty pool", pool: newTestPool("pool1", "vcenter-a", 100, 100), expected: 100},
+		{name: "half allocated pool", pool: newTestPool("pool1", "vcenter-a", 50, 50), expected: 50},
+		{name: "cpu bound pool", pool: newTestPool("pool1", "vcenter-a", 0, 100), expected: 50},
+		{name: "full pool", pool: newTestPool("pool1", "vcenter-a", 0, 0), expected: 0},
+	}
+
+	plugin := NewLeastAllocated().(scheduler.ScorePlugin)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{}
+			got := plugin.Score(context.TODO(), newTestState(lease), lease, tt.pool)
+			if got != tt.expected {
+				t.Errorf("Score() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
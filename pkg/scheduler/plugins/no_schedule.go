@@ -0,0 +1,23 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// NoSchedule rejects cordoned pools.
+type NoSchedule struct{}
+
+var _ scheduler.FilterPlugin = &NoSchedule{}
+
+func (p *NoSchedule) Name() string { return NoScheduleName }
+
+func (p *NoSchedule) Filter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) *scheduler.Status {
+	if pool.Spec.NoSchedule {
+		return scheduler.NewStatus(utils.PoolNotSchedulable)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestNoScheduleFilter(t *testing.T) {
+	cordoned := newTestPool("pool1", "vcenter-a", 10, 10)
+	cordoned.Spec.NoSchedule = true
+
+	tests := []struct {
+		name     string
+		pool     *v1.Pool
+		expected string
+	}{
+		{
+			name:     "schedulable pool is accepted",
+			pool:     newTestPool("pool1", "vcenter-a", 10, 10),
+			expected: "",
+		},
+		{
+			name:     "cordoned pool is rejected",
+			pool:     cordoned,
+			expected: utils.PoolNotSchedulable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{}
+			got := statusReason((&NoSchedule{}).Filter(context.TODO(), newTestState(lease), lease, tt.pool))
+			if got != tt.expected {
+				t.Errorf("Filter() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// PoolAntiAffinity penalizes pools holding leases which match the lease's poolAntiAffinity terms. the leases
+// are taken from the cycle state.
+type PoolAntiAffinity struct{}
+
+var _ scheduler.ScorePlugin = &PoolAntiAffinity{}
+
+func (p *PoolAntiAffinity) Name() string { return PoolAntiAffinityName }
+
+func (p *PoolAntiAffinity) Score(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) float64 {
+	return utils.NewPoolAntiAffinityScorer(1, state.Leases).Score(lease, pool)
+}
+
+func (p *PoolAntiAffinity) NormalizeScores(scores map[string]float64) {
+	utils.NewPoolAntiAffinityScorer(1, nil).NormalizeScores(scores)
+}
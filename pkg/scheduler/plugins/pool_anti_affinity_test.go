@@ -0,0 +1,47 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestPoolAntiAffinityScore(t *testing.T) {
+	crowded := newTestPool("crowded", "vcenter-a", 10, 10)
+	empty := newTestPool("empty", "vcenter-a", 10, 10)
+
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: "lease"},
+		Spec: v1.LeaseSpec{PoolAntiAffinity: []v1.WeightedPoolAntiAffinityTerm{
+			{Weight: 50, LeaseSelector: metav1.LabelSelector{MatchLabels: map[string]string{"git-repo": "installer"}}},
+		}},
+	}
+	state := scheduler.NewCycleState(lease, nil, []*v1.Lease{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "other",
+				Labels:          map[string]string{"git-repo": "installer"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: v1.PoolKind, Name: crowded.Name}},
+			},
+		},
+	})
+
+	plugin := &PoolAntiAffinity{}
+	scores := map[string]float64{
+		crowded.Name: plugin.Score(context.TODO(), state, lease, crowded),
+		empty.Name:   plugin.Score(context.TODO(), state, lease, empty),
+	}
+	if scores[crowded.Name] != 50 || scores[empty.Name] != 0 {
+		t.Fatalf("expected raw penalties of 50 and 0, got %v", scores)
+	}
+
+	plugin.NormalizeScores(scores)
+	if scores[crowded.Name] != 0 || scores[empty.Name] != utils.MaxPoolScore {
+		t.Errorf("expected normalized scores of 0 and %v, got %v", utils.MaxPoolScore, scores)
+	}
+}
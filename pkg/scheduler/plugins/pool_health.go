@@ -0,0 +1,31 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// PoolHealth prefers pools where fewer recent leases reported an infrastructure failure.
+type PoolHealth struct {
+	scorer utils.PoolScorer
+}
+
+var _ scheduler.ScorePlugin = &PoolHealth{}
+
+// NewPoolHealth returns the PoolHealth plugin.
+func NewPoolHealth() scheduler.Plugin {
+	return &PoolHealth{scorer: utils.NewPoolHealthScorer(1)}
+}
+
+func (p *PoolHealth) Name() string { return PoolHealthName }
+
+func (p *PoolHealth) Score(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) float64 {
+	return p.scorer.Score(lease, pool)
+}
+
+func (p *PoolHealth) NormalizeScores(scores map[string]float64) {
+	p.scorer.NormalizeScores(scores)
+}
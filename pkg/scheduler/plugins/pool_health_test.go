@@ -0,0 +1,35 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+)
+
+func TestPoolHealthScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		health   *v1.PoolHealth
+		expected float64
+	}{
+		{name: "no outcomes reported", expected: 100},
+		{name: "healthy pool", health: &v1.PoolHealth{Leases: 20}, expected: 100},
+		{name: "install failures are not the pool's fault", health: &v1.PoolHealth{Leases: 20, InstallFailurePercent: 50}, expected: 100},
+		{name: "infra failures", health: &v1.PoolHealth{Leases: 20, InfraFailurePercent: 30}, expected: 70},
+	}
+
+	plugin := NewPoolHealth().(scheduler.ScorePlugin)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newTestPool("pool1", "vcenter-a", 100, 100)
+			pool.Status.Health = tt.health
+			lease := &v1.Lease{}
+			got := plugin.Score(context.TODO(), newTestState(lease), lease, pool)
+			if got != tt.expected {
+				t.Errorf("Score() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
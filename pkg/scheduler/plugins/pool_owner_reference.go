@@ -0,0 +1,43 @@
+package plugins
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// PoolOwnerReference reserves a pool by adding it to the owner references of the lease. the lease must be
+// updated by the caller for the reservation to be persisted.
+type PoolOwnerReference struct{}
+
+var _ scheduler.ReservePlugin = &PoolOwnerReference{}
+
+func (p *PoolOwnerReference) Name() string { return PoolOwnerReferenceName }
+
+func (p *PoolOwnerReference) Reserve(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) error {
+	if utils.LeaseHasPool(lease, pool.Name) {
+		return nil
+	}
+	lease.OwnerReferences = append(lease.OwnerReferences, metav1.OwnerReference{
+		APIVersion: v1.GroupVersion.String(),
+		Kind:       v1.PoolKind,
+		Name:       pool.Name,
+		UID:        pool.UID,
+	})
+	return nil
+}
+
+func (p *PoolOwnerReference) Unreserve(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) {
+	ownerRefs := lease.OwnerReferences[:0:0]
+	for _, ref := range lease.OwnerReferences {
+		if ref.Kind == v1.PoolKind && ref.Name == pool.Name {
+			continue
+		}
+		ownerRefs = append(ownerRefs, ref)
+	}
+	lease.OwnerReferences = ownerRefs
+}
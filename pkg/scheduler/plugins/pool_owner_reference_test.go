@@ -0,0 +1,36 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func TestPoolOwnerReferenceReserve(t *testing.T) {
+	pool := newTestPool("pool1", "vcenter-a", 10, 10)
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Network", Name: "net1"}},
+		},
+	}
+
+	plugin := &PoolOwnerReference{}
+	state := newTestState(lease)
+	for i := 0; i < 2; i++ {
+		if err := plugin.Reserve(context.TODO(), state, lease, pool); err != nil {
+			t.Fatalf("Reserve() returned error: %v", err)
+		}
+	}
+	if len(lease.OwnerReferences) != 2 || lease.OwnerReferences[1].Name != pool.Name {
+		t.Fatalf("expected the pool to be added once to the owner references, got %v", lease.OwnerReferences)
+	}
+
+	plugin.Unreserve(context.TODO(), state, lease, pool)
+	plugin.Unreserve(context.TODO(), state, lease, pool)
+	if len(lease.OwnerReferences) != 1 || lease.OwnerReferences[0].Name != "net1" {
+		t.Errorf("expected only the network owner reference to remain, got %v", lease.OwnerReferences)
+	}
+}
@@ -0,0 +1,30 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// PoolSelector rejects pools whose labels do not match the lease's poolSelector and poolSelectorExpressions.
+type PoolSelector struct{}
+
+var _ scheduler.FilterPlugin = &PoolSelector{}
+
+func (p *PoolSelector) Name() string { return PoolSelectorName }
+
+func (p *PoolSelector) Filter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) *scheduler.Status {
+	selector, err := utils.LeasePoolSelector(lease)
+	if err != nil {
+		return scheduler.NewStatus(fmt.Sprintf("%v: %v", utils.PoolInvalidSelector, err))
+	}
+	if !selector.Matches(labels.Set(pool.Labels)) {
+		return scheduler.NewStatus(utils.PoolLabelMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package plugins
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestPoolSelectorFilter(t *testing.T) {
+	pool := newTestPool("pool1", "vcenter-a", 10, 10)
+	pool.Labels = map[string]string{"region": "us-east", "tier": "fast"}
+
+	tests := []struct {
+		name     string
+		lease    *v1.Lease
+		expected string
+	}{
+		{
+			name:     "empty selector matches",
+			lease:    &v1.Lease{},
+			expected: "",
+		},
+		{
+			name:     "matching labels",
+			lease:    &v1.Lease{Spec: v1.LeaseSpec{PoolSelector: map[string]string{"region": "us-east"}}},
+			expected: "",
+		},
+		{
+			name:     "mismatched labels",
+			lease:    &v1.Lease{Spec: v1.LeaseSpec{PoolSelector: map[string]string{"region": "us-west"}}},
+			expected: utils.PoolLabelMismatch,
+		},
+		{
+			name: "mismatched expression",
+			lease: &v1.Lease{Spec: v1.LeaseSpec{PoolSelectorExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"fast"}},
+			}}},
+			expected: utils.PoolLabelMismatch,
+		},
+		{
+			name: "invalid expression",
+			lease: &v1.Lease{Spec: v1.LeaseSpec{PoolSelectorExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tier", Operator: metav1.LabelSelectorOpIn},
+			}}},
+			expected: utils.PoolInvalidSelector,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statusReason((&PoolSelector{}).Filter(context.TODO(), newTestState(tt.lease), tt.lease, pool))
+			matched := got == tt.expected
+			if tt.expected != "" {
+				matched = strings.HasPrefix(got, tt.expected)
+			}
+			if !matched {
+				t.Errorf("Filter() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// PreferredPoolAffinity prefers pools matching the lease's preferredPoolAffinity terms.
+type PreferredPoolAffinity struct {
+	scorer utils.PoolScorer
+}
+
+var _ scheduler.ScorePlugin = &PreferredPoolAffinity{}
+
+// NewPreferredPoolAffinity returns the PreferredPoolAffinity plugin.
+func NewPreferredPoolAffinity() scheduler.Plugin {
+	return &PreferredPoolAffinity{scorer: utils.NewPreferredPoolAffinityScorer(1)}
+}
+
+func (p *PreferredPoolAffinity) Name() string { return PreferredPoolAffinityName }
+
+func (p *PreferredPoolAffinity) Score(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) float64 {
+	return p.scorer.Score(lease, pool)
+}
+
+func (p *PreferredPoolAffinity) NormalizeScores(scores map[string]float64) {
+	p.scorer.NormalizeScores(scores)
+}
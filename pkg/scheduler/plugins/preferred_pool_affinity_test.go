@@ -0,0 +1,38 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestPreferredPoolAffinityScore(t *testing.T) {
+	east := newTestPool("east", "vcenter-a", 10, 10)
+	east.Labels = map[string]string{"region": "us-east"}
+	west := newTestPool("west", "vcenter-a", 10, 10)
+	west.Labels = map[string]string{"region": "us-west"}
+
+	lease := &v1.Lease{Spec: v1.LeaseSpec{PreferredPoolAffinity: []v1.WeightedPoolAffinityTerm{
+		{Weight: 80, Preference: metav1.LabelSelector{MatchLabels: map[string]string{"region": "us-east"}}},
+		{Weight: 20, Preference: metav1.LabelSelector{MatchLabels: map[string]string{"region": "us-west"}}},
+	}}}
+
+	plugin := NewPreferredPoolAffinity().(scheduler.ScorePlugin)
+	scores := map[string]float64{
+		east.Name: plugin.Score(context.TODO(), newTestState(lease), lease, east),
+		west.Name: plugin.Score(context.TODO(), newTestState(lease), lease, west),
+	}
+	if scores[east.Name] != 80 || scores[west.Name] != 20 {
+		t.Fatalf("expected raw scores of 80 and 20, got %v", scores)
+	}
+
+	plugin.NormalizeScores(scores)
+	if scores[east.Name] != utils.MaxPoolScore || scores[west.Name] != 25 {
+		t.Errorf("expected normalized scores of %v and 25, got %v", utils.MaxPoolScore, scores)
+	}
+}
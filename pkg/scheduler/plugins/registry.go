@@ -0,0 +1,105 @@
+package plugins
+
+import (
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+const (
+	AssignedPoolName          = "AssignedPool"
+	NoScheduleName            = "NoSchedule"
+	ExcludeName               = "Exclude"
+	RequiredPoolName          = "RequiredPool"
+	PoolSelectorName          = "PoolSelector"
+	TaintTolerationName       = utils.TaintTolerationScorerName
+	CPUName                   = "CPU"
+	MemoryName                = "Memory"
+	VCenterCapName            = "VCenterCap"
+	TopologySpreadName        = "TopologySpread"
+	LeastAllocatedName        = utils.LeastAllocatedScorerName
+	PreferredPoolAffinityName = utils.PreferredPoolAffinityScorerName
+	PoolAntiAffinityName      = utils.PoolAntiAffinityScorerName
+	PoolHealthName            = utils.PoolHealthScorerName
+	PoolOwnerReferenceName    = "PoolOwnerReference"
+)
+
+// NewRegistry returns the registry of all in-tree plugins.
+func NewRegistry() scheduler.Registry {
+	return scheduler.Registry{
+		AssignedPoolName:          func() scheduler.Plugin { return &AssignedPool{} },
+		NoScheduleName:            func() scheduler.Plugin { return &NoSchedule{} },
+		ExcludeName:               func() scheduler.Plugin { return &Exclude{} },
+		RequiredPoolName:          func() scheduler.Plugin { return &RequiredPool{} },
+		PoolSelectorName:          func() scheduler.Plugin { return &PoolSelector{} },
+		TaintTolerationName:       NewTaintToleration,
+		CPUName:                   func() scheduler.Plugin { return &CPU{} },
+		MemoryName:                func() scheduler.Plugin { return &Memory{} },
+		VCenterCapName:            func() scheduler.Plugin { return &VCenterCap{} },
+		TopologySpreadName:        func() scheduler.Plugin { return &TopologySpread{} },
+		LeastAllocatedName:        NewLeastAllocated,
+		PreferredPoolAffinityName: NewPreferredPoolAffinity,
+		PoolAntiAffinityName:      func() scheduler.Plugin { return &PoolAntiAffinity{} },
+		PoolHealthName:            NewPoolHealth,
+		PoolOwnerReferenceName:    func() scheduler.Plugin { return &PoolOwnerReference{} },
+	}
+}
+
+// DefaultPlugins returns the plugins enabled in every profile unless disabled by the scheduler configuration.
+// filters run in order, so the checks on fundamental pool properties come first and report the most specific
+// rejection reason.
+func DefaultPlugins() *scheduler.Plugins {
+	return &scheduler.Plugins{
+		Filter: scheduler.PluginSet{
+			Enabled: []scheduler.PluginConfig{
+				{Name: AssignedPoolName},
+				{Name: NoScheduleName},
+				{Name: ExcludeName},
+				{Name: RequiredPoolName},
+				{Name: PoolSelectorName},
+				{Name: TaintTolerationName},
+				{Name: CPUName},
+				{Name: MemoryName},
+			},
+		},
+		PostFilter: scheduler.PluginSet{
+			Enabled: []scheduler.PluginConfig{
+				{Name: VCenterCapName},
+				{Name: TopologySpreadName},
+			},
+		},
+		Score: scheduler.PluginSet{
+			Enabled: []scheduler.PluginConfig{
+				{Name: LeastAllocatedName, Weight: 1},
+				{Name: PreferredPoolAffinityName, Weight: 1},
+				{Name: PoolAntiAffinityName, Weight: 1},
+				{Name: TaintTolerationName, Weight: 1},
+				{Name: PoolHealthName, Weight: 1},
+			},
+		},
+		Reserve: scheduler.PluginSet{
+			Enabled: []scheduler.PluginConfig{
+				{Name: PoolOwnerReferenceName},
+			},
+		},
+	}
+}
+
+// DefaultConfig returns a profile for each network type, all using the default plugins.
+func DefaultConfig() *scheduler.Config {
+	return &scheduler.Config{
+		Profiles: []scheduler.Profile{
+			{NetworkType: v1.NetworkTypeSingleTenant},
+			{NetworkType: v1.NetworkTypeMultiTenant},
+			{NetworkType: v1.NetworkTypeDisconnected},
+		},
+	}
+}
+
+// NewScheduler returns a scheduler built from config with the in-tree plugins. a nil config uses DefaultConfig.
+func NewScheduler(config *scheduler.Config) (*scheduler.Scheduler, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	return scheduler.New(config, NewRegistry(), DefaultPlugins())
+}
@@ -0,0 +1,1388 @@
+package plugins
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestNewScheduler(t *testing.T) {
+	s, err := NewScheduler(nil)
+	if err != nil {
+		t.Fatalf("unexpected error building the default scheduler: %v", err)
+	}
+
+	for _, networkType := range []v1.NetworkType{v1.NetworkTypeSingleTenant, v1.NetworkTypeMultiTenant, v1.NetworkTypeDisconnected} {
+		f := s.ForLease(&v1.Lease{Spec: v1.LeaseSpec{NetworkType: networkType}})
+		if f.ProfileName() != string(networkType) {
+			t.Errorf("expected profile %s, got %s", networkType, f.ProfileName())
+		}
+	}
+}
+
+func TestDefaultSchedulerSchedulePool(t *testing.T) {
+	s, err := NewScheduler(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cordoned := newTestPool("cordoned", "vcenter-a", 100, 100)
+	cordoned.Spec.NoSchedule = true
+	pools := []*v1.Pool{
+		cordoned,
+		newTestPool("small", "vcenter-a", 4, 4),
+		newTestPool("busy", "vcenter-a", 20, 20),
+		newTestPool("idle", "vcenter-b", 80, 80),
+	}
+
+	lease := &v1.Lease{Spec: v1.LeaseSpec{VCpus: 8, Memory: 8}}
+	pool, err := s.ForLease(lease).SchedulePool(context.TODO(), scheduler.NewCycleState(lease, nil, nil), lease, pools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pool.Name != "idle" {
+		t.Errorf("expected the least allocated pool to be picked, got %s", pool.Name)
+	}
+	if !utils.LeaseHasPool(lease, "idle") {
+		t.Errorf("expected the pool to be reserved in the lease owner references")
+	}
+
+	lease = &v1.Lease{Spec: v1.LeaseSpec{VCpus: 200, Memory: 8}}
+	_, err = s.ForLease(lease).SchedulePool(context.TODO(), scheduler.NewCycleState(lease, nil, nil), lease, pools)
+	if err == nil {
+		t.Fatal("expected an error when no pool fits")
+	}
+	for _, reason := range []string{utils.PoolNotSchedulable, utils.PoolInsufficientVCPU} {
+		if !strings.Contains(err.Error(), reason) {
+			t.Errorf("expected error to contain %q, got %v", reason, err)
+		}
+	}
+}
+
+// TestDefaultSchedulerFilterPools tests the filter and post filter plugins enabled by default.
+func TestDefaultSchedulerFilterPools(t *testing.T) {
+	tests := []struct {
+		name               string
+		lease              *v1.Lease
+		pools              []*v1.Pool
+		vcenterInUse       string // caps the lease to one vCenter and assigns it a pool on this vCenter
+		expectedFittingLen int
+		expectedRejections map[string]string
+	}{
+		{
+			name: "pool selector filters out non-matching pools",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+					PoolSelector: map[string]string{
+						"region": "us-west",
+					},
+				},
+			},
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool1",
+						Labels: map[string]string{
+							"region": "us-west",
+						},
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool2",
+						Labels: map[string]string{
+							"region": "us-east",
+						},
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			expectedFittingLen: 1,
+			expectedRejections: map[string]string{
+				"pool2": utils.PoolLabelMismatch,
+			},
+		},
+		{
+			name: "pool selector expressions filter out non-matching pools",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+					PoolSelectorExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "region", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"us-east"}},
+					},
+				},
+			},
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool1",
+						Labels: map[string]string{
+							"region": "us-west",
+						},
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool2",
+						Labels: map[string]string{
+							"region": "us-east",
+						},
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			expectedFittingLen: 1,
+			expectedRejections: map[string]string{
+				"pool2": utils.PoolLabelMismatch,
+			},
+		},
+		{
+			name: "invalid pool selector expression rejects all pools",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+					PoolSelectorExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "region", Operator: "Bogus", Values: []string{"us-west"}},
+					},
+				},
+			},
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool1",
+						Labels: map[string]string{
+							"region": "us-west",
+						},
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			expectedFittingLen: 0,
+			expectedRejections: map[string]string{
+				"pool1": utils.PoolInvalidSelector + ": \"Bogus\" is not a valid label selector operator",
+			},
+		},
+		{
+			name: "taint toleration filters pools",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+					Tolerations: []v1.Toleration{
+						{
+							Key:      "dedicated",
+							Operator: v1.TolerationOpEqual,
+							Value:    "gpu",
+						},
+					},
+				},
+			},
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool1",
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+						Taints: []v1.Taint{
+							{
+								Key:    "dedicated",
+								Value:  "gpu",
+								Effect: v1.TaintEffectNoSchedule,
+							},
+						},
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool2",
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+						Taints: []v1.Taint{
+							{
+								Key:    "special",
+								Value:  "true",
+								Effect: v1.TaintEffectNoSchedule,
+							},
+						},
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			expectedFittingLen: 1,
+			expectedRejections: map[string]string{
+				"pool2": utils.PoolTaintNotTolerated,
+			},
+		},
+		{
+			name: "combined selector and taint filtering",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+					PoolSelector: map[string]string{
+						"region": "us-west",
+					},
+					Tolerations: []v1.Toleration{
+						{
+							Key:      "dedicated",
+							Operator: v1.TolerationOpEqual,
+							Value:    "gpu",
+						},
+					},
+				},
+			},
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool1-matching",
+						Labels: map[string]string{
+							"region": "us-west",
+						},
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+						Taints: []v1.Taint{
+							{
+								Key:    "dedicated",
+								Value:  "gpu",
+								Effect: v1.TaintEffectNoSchedule,
+							},
+						},
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool2-wrong-region",
+						Labels: map[string]string{
+							"region": "us-east",
+						},
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+						Taints: []v1.Taint{
+							{
+								Key:    "dedicated",
+								Value:  "gpu",
+								Effect: v1.TaintEffectNoSchedule,
+							},
+						},
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool3-wrong-taint",
+						Labels: map[string]string{
+							"region": "us-west",
+						},
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+						Taints: []v1.Taint{
+							{
+								Key:    "special",
+								Value:  "true",
+								Effect: v1.TaintEffectNoSchedule,
+							},
+						},
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			expectedFittingLen: 1,
+			expectedRejections: map[string]string{
+				"pool2-wrong-region": utils.PoolLabelMismatch,
+				"pool3-wrong-taint":  utils.PoolTaintNotTolerated,
+			},
+		},
+		{
+			name: "no pools match due to insufficient resources",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  100,
+					Memory: 200,
+				},
+			},
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool1",
+					},
+					Spec: v1.PoolSpec{
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			expectedFittingLen: 0,
+			expectedRejections: map[string]string{
+				"pool1": utils.PoolInsufficientVCPU,
+			},
+		},
+		{
+			name: "vcenter cap filters pools on other vcenters",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+				},
+			},
+			vcenterInUse: "vcenter3.example.com",
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-vc1",
+					},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-vc2",
+					},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter2.example.com",
+							},
+						},
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-vc3",
+					},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter3.example.com",
+							},
+						},
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			// pool-vc3 is the only pool on the vcenter in use
+			expectedFittingLen: 1,
+			expectedRejections: map[string]string{
+				"pool-vc1": utils.PoolVCenterLimitReached,
+				"pool-vc2": utils.PoolVCenterLimitReached,
+			},
+		},
+		{
+			name: "no vcenter cap applies no vcenter constraint",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+				},
+			},
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-vc1",
+					},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-vc2",
+					},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter2.example.com",
+							},
+						},
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			// Both pools are fitting since no vcenter constraint is applied
+			expectedFittingLen: 2,
+			expectedRejections: map[string]string{},
+		},
+		{
+			name: "pool on the vcenter in use passes vcenter check",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+				},
+			},
+			vcenterInUse: "vcenter2.example.com",
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-excluded",
+					},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-allowed",
+					},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter2.example.com",
+							},
+						},
+						VCpus: 100,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			expectedFittingLen: 1,
+			expectedRejections: map[string]string{
+				"pool-excluded": utils.PoolVCenterLimitReached,
+			},
+		},
+		{
+			name: "vcenter check reports correct reason when pool has NoSchedule",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+				},
+			},
+			vcenterInUse: "vcenter2.example.com",
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-noschedule",
+					},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus:      100,
+						NoSchedule: true, // This should be reported instead of vcenter limit
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			expectedFittingLen: 0,
+			expectedRejections: map[string]string{
+				// Should report NoSchedule, not utils.PoolVCenterLimitReached
+				"pool-noschedule": utils.PoolNotSchedulable,
+			},
+		},
+		{
+			name: "vcenter check reports correct reason when pool is excluded",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					VCpus:  16,
+					Memory: 32,
+				},
+			},
+			vcenterInUse: "vcenter2.example.com",
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pool-excluded",
+					},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus:   100,
+						Exclude: true, // This should be reported instead of vcenter limit
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  50,
+						MemoryAvailable: 100,
+					},
+				},
+			},
+			expectedFittingLen: 0,
+			expectedRejections: map[string]string{
+				// Should report Exclude, not utils.PoolVCenterLimitReached
+				"pool-excluded": utils.PoolExcluded,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewScheduler(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var assignedPools []*v1.Pool
+			if tt.vcenterInUse != "" {
+				tt.lease.Spec.VCenters = 1
+				assignedPools = append(assignedPools, newTestPool("assigned", tt.vcenterInUse, 0, 0))
+			}
+			state := newTestState(tt.lease, assignedPools...)
+			f := s.ForLease(tt.lease)
+			fittingPools, poolResults := f.RunFilterPlugins(context.TODO(), state, tt.lease, tt.pools)
+			fittingPools, poolResults = f.RunPostFilterPlugins(context.TODO(), state, tt.lease, tt.pools, fittingPools, poolResults)
+
+			if len(fittingPools) != tt.expectedFittingLen {
+				t.Errorf("filtering returned %d fitting pools, expected %d",
+					len(fittingPools), tt.expectedFittingLen)
+			}
+
+			for poolName, expectedReason := range tt.expectedRejections {
+				found := false
+				for _, result := range poolResults {
+					if result.Pool.Name == poolName {
+						found = true
+						if result.MatchResults != expectedReason {
+							t.Errorf("Pool %s has reason '%s', expected '%s'",
+								poolName, result.MatchResults, expectedReason)
+						}
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Expected rejection for pool %s not found in results", poolName)
+				}
+			}
+		})
+	}
+}
+
+// TestDefaultSchedulerVCenterCap tests the vCenters the default scheduler excludes from the first pool of a
+// lease when VCenters=1 and multiple pools are needed
+func TestDefaultSchedulerVCenterCap(t *testing.T) {
+	tests := []struct {
+		name                  string
+		lease                 *v1.Lease
+		pools                 []*v1.Pool
+		expectedExcluded      map[string]bool
+		expectedFittingCount  int
+		expectedExcludedCount int
+		expectVCenter         string // Expected vCenter for fitting pools
+	}{
+		{
+			name: "excludes vcenter with insufficient pools when VCenters=1",
+			lease: &v1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-lease",
+					Namespace: "default",
+				},
+				Spec: v1.LeaseSpec{
+					VCpus:    16,
+					Memory:   32,
+					Pools:    3,
+					VCenters: 1,
+				},
+			},
+			pools: []*v1.Pool{
+				// vcenter1 has only 1 pool - should be excluded
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter1-pool1"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus:  100,
+						Memory: 1000,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+				// vcenter2 has 3 pools - should be available
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter2-pool1"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter2.example.com",
+							},
+						},
+						VCpus:  100,
+						Memory: 1000,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter2-pool2"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter2.example.com",
+							},
+						},
+						VCpus:  100,
+						Memory: 1000,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter2-pool3"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter2.example.com",
+							},
+						},
+						VCpus:  100,
+						Memory: 1000,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+			},
+			expectedExcluded: map[string]bool{
+				"vcenter1.example.com": true,
+			},
+			expectedFittingCount:  3,
+			expectedExcludedCount: 1,
+			expectVCenter:         "vcenter2.example.com",
+		},
+		{
+			name: "allows vcenter with sufficient pools when VCenters=1",
+			lease: &v1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-lease",
+					Namespace: "default",
+				},
+				Spec: v1.LeaseSpec{
+					VCpus:    16,
+					Memory:   32,
+					Pools:    2,
+					VCenters: 1,
+				},
+			},
+			pools: []*v1.Pool{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter1-pool1"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus:  100,
+						Memory: 1000,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter1-pool2"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus:  100,
+						Memory: 1000,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+			},
+			expectedFittingCount:  2,
+			expectedExcludedCount: 0,
+			expectVCenter:         "vcenter1.example.com",
+		},
+		{
+			name: "correctly counts only suitable pools (excludes NoSchedule)",
+			lease: &v1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-lease",
+					Namespace: "default",
+				},
+				Spec: v1.LeaseSpec{
+					VCpus:    16,
+					Memory:   32,
+					Pools:    2,
+					VCenters: 1,
+				},
+			},
+			pools: []*v1.Pool{
+				// vcenter1 has 2 pools but one is NoSchedule
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter1-pool1"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus:  100,
+						Memory: 1000,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter1-pool2"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter1.example.com",
+							},
+						},
+						VCpus:      100,
+						Memory:     1000,
+						NoSchedule: true, // This pool should be filtered out
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+				// vcenter2 has 2 schedulable pools
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter2-pool1"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter2.example.com",
+							},
+						},
+						VCpus:  100,
+						Memory: 1000,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "vcenter2-pool2"},
+					Spec: v1.PoolSpec{
+						FailureDomainSpec: v1.FailureDomainSpec{
+							VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+								Server: "vcenter2.example.com",
+							},
+						},
+						VCpus:  100,
+						Memory: 1000,
+					},
+					Status: v1.PoolStatus{
+						VCpusAvailable:  100,
+						MemoryAvailable: 1000,
+					},
+				},
+			},
+			expectedExcluded: map[string]bool{
+				"vcenter1.example.com": true, // Should be excluded (only 1 suitable pool)
+			},
+			expectedFittingCount:  2,
+			expectedExcludedCount: 2, // vcenter1-pool2 (NoSchedule) + vcenter1-pool1 (vcenter excluded)
+			expectVCenter:         "vcenter2.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewScheduler(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			state := newTestState(tt.lease)
+			f := s.ForLease(tt.lease)
+			fittingPools, results := f.RunFilterPlugins(context.TODO(), state, tt.lease, tt.pools)
+			fittingPools, results = f.RunPostFilterPlugins(context.TODO(), state, tt.lease, tt.pools, fittingPools, results)
+
+			if len(state.ExcludedVCenters) != len(tt.expectedExcluded) {
+				t.Errorf("Expected excluded vCenters %v, got %v", tt.expectedExcluded, state.ExcludedVCenters)
+			}
+			for server := range tt.expectedExcluded {
+				if !state.ExcludedVCenters[server] {
+					t.Errorf("Expected vCenter %s to be excluded, got %v", server, state.ExcludedVCenters)
+				}
+			}
+
+			if len(fittingPools) != tt.expectedFittingCount {
+				t.Errorf("Expected %d fitting pools, got %d", tt.expectedFittingCount, len(fittingPools))
+			}
+
+			excludedCount := 0
+			for _, result := range results {
+				if result.MatchResults == utils.PoolVCenterLimitReached || result.MatchResults == utils.PoolNotSchedulable {
+					excludedCount++
+				}
+			}
+
+			if excludedCount != tt.expectedExcludedCount {
+				t.Errorf("Expected %d excluded pools, got %d", tt.expectedExcludedCount, excludedCount)
+			}
+
+			// Verify all fitting pools are from the expected vCenter
+			if tt.expectVCenter != "" {
+				for _, pool := range fittingPools {
+					if pool.Spec.Server != tt.expectVCenter {
+						t.Errorf("Expected pool %s to be from vCenter %s, got %s",
+							pool.Name, tt.expectVCenter, pool.Spec.Server)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestDefaultSchedulerMultiVCenterConstraint tests the full pool selection flow
+// with vCenter constraints
+func TestDefaultSchedulerMultiVCenterConstraint(t *testing.T) {
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-lease",
+			Namespace: "default",
+		},
+		Spec: v1.LeaseSpec{
+			VCpus:    16,
+			Memory:   32,
+			Pools:    3,
+			VCenters: 1,
+		},
+	}
+
+	pools := []*v1.Pool{
+		// vcenter1 has only 1 pool - insufficient
+		{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "vspherecapacitymanager.splat.io/v1",
+				Kind:       "Pool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vcenter1-pool1",
+				UID:  "uid-vcenter1-pool1",
+			},
+			Spec: v1.PoolSpec{
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Server: "vcenter1.example.com",
+					},
+				},
+				VCpus:  100,
+				Memory: 1000,
+			},
+			Status: v1.PoolStatus{
+				VCpusAvailable:  100,
+				MemoryAvailable: 1000,
+			},
+		},
+		// vcenter2 has 3 pools - sufficient
+		{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "vspherecapacitymanager.splat.io/v1",
+				Kind:       "Pool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vcenter2-pool1",
+				UID:  "uid-vcenter2-pool1",
+			},
+			Spec: v1.PoolSpec{
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Server: "vcenter2.example.com",
+					},
+				},
+				VCpus:  100,
+				Memory: 1000,
+			},
+			Status: v1.PoolStatus{
+				VCpusAvailable:  100,
+				MemoryAvailable: 1000,
+			},
+		},
+		{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "vspherecapacitymanager.splat.io/v1",
+				Kind:       "Pool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vcenter2-pool2",
+				UID:  "uid-vcenter2-pool2",
+			},
+			Spec: v1.PoolSpec{
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Server: "vcenter2.example.com",
+					},
+				},
+				VCpus:  100,
+				Memory: 1000,
+			},
+			Status: v1.PoolStatus{
+				VCpusAvailable:  100,
+				MemoryAvailable: 1000,
+			},
+		},
+		{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "vspherecapacitymanager.splat.io/v1",
+				Kind:       "Pool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vcenter2-pool3",
+				UID:  "uid-vcenter2-pool3",
+			},
+			Spec: v1.PoolSpec{
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Server: "vcenter2.example.com",
+					},
+				},
+				VCpus:  100,
+				Memory: 1000,
+			},
+			Status: v1.PoolStatus{
+				VCpusAvailable:  100,
+				MemoryAvailable: 1000,
+			},
+		},
+	}
+
+	assigned := scheduleTestPools(t, lease, pools)
+	for _, pool := range assigned {
+		// vcenter1 is excluded because it doesn't have enough pools
+		if pool.Spec.Server != "vcenter2.example.com" {
+			t.Errorf("Expected pool from vcenter2.example.com, got %s from %s", pool.Name, pool.Spec.Server)
+		}
+	}
+}
+
+// TestDefaultSchedulerVCenters2Pools3 tests the VCenters=2, Pools=3 scenario
+func TestDefaultSchedulerVCenters2Pools3(t *testing.T) {
+	lease := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-lease",
+			Namespace: "default",
+		},
+		Spec: v1.LeaseSpec{
+			VCpus:    16,
+			Memory:   32,
+			Pools:    3,
+			VCenters: 2,
+		},
+	}
+
+	pools := []*v1.Pool{
+		// vcenter1 has only 1 pool - only usable with a vCenter holding the other 2 pools
+		{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "vspherecapacitymanager.splat.io/v1",
+				Kind:       "Pool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vcenter1-pool1",
+				UID:  "uid-vcenter1-pool1",
+			},
+			Spec: v1.PoolSpec{
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Server: "vcenter1.example.com",
+					},
+				},
+				VCpus:  100,
+				Memory: 1000,
+			},
+			Status: v1.PoolStatus{
+				VCpusAvailable:  100,
+				MemoryAvailable: 1000,
+			},
+		},
+		// vcenter2 has 2 pools - sufficient
+		{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "vspherecapacitymanager.splat.io/v1",
+				Kind:       "Pool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vcenter2-pool1",
+				UID:  "uid-vcenter2-pool1",
+			},
+			Spec: v1.PoolSpec{
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Server: "vcenter2.example.com",
+					},
+				},
+				VCpus:  100,
+				Memory: 1000,
+			},
+			Status: v1.PoolStatus{
+				VCpusAvailable:  100,
+				MemoryAvailable: 1000,
+			},
+		},
+		{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "vspherecapacitymanager.splat.io/v1",
+				Kind:       "Pool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vcenter2-pool2",
+				UID:  "uid-vcenter2-pool2",
+			},
+			Spec: v1.PoolSpec{
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Server: "vcenter2.example.com",
+					},
+				},
+				VCpus:  100,
+				Memory: 1000,
+			},
+			Status: v1.PoolStatus{
+				VCpusAvailable:  100,
+				MemoryAvailable: 1000,
+			},
+		},
+		// vcenter3 has 2 pools - sufficient
+		{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "vspherecapacitymanager.splat.io/v1",
+				Kind:       "Pool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vcenter3-pool1",
+				UID:  "uid-vcenter3-pool1",
+			},
+			Spec: v1.PoolSpec{
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Server: "vcenter3.example.com",
+					},
+				},
+				VCpus:  100,
+				Memory: 1000,
+			},
+			Status: v1.PoolStatus{
+				VCpusAvailable:  100,
+				MemoryAvailable: 1000,
+			},
+		},
+		{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "vspherecapacitymanager.splat.io/v1",
+				Kind:       "Pool",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "vcenter3-pool2",
+				UID:  "uid-vcenter3-pool2",
+			},
+			Spec: v1.PoolSpec{
+				FailureDomainSpec: v1.FailureDomainSpec{
+					VSpherePlatformFailureDomainSpec: configv1.VSpherePlatformFailureDomainSpec{
+						Server: "vcenter3.example.com",
+					},
+				},
+				VCpus:  100,
+				Memory: 1000,
+			},
+			Status: v1.PoolStatus{
+				VCpusAvailable:  100,
+				MemoryAvailable: 1000,
+			},
+		},
+	}
+
+	assigned := scheduleTestPools(t, lease, pools)
+	if vcenters := utils.GetVCentersInUse(assigned); len(vcenters) > 2 {
+		t.Errorf("Expected the pools to be on at most 2 vCenters, got %v", vcenters)
+	}
+}
+
+// scheduleTestPools schedules each pool of the lease with the default scheduler, as the controller does, and
+// returns the pools assigned.
+func scheduleTestPools(t *testing.T, lease *v1.Lease, pools []*v1.Pool) []*v1.Pool {
+	s, err := NewScheduler(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var assigned []*v1.Pool
+	for len(assigned) < lease.Spec.Pools {
+		pool, err := s.ForLease(lease).SchedulePool(context.TODO(), newTestState(lease, assigned...), lease, pools)
+		if err != nil {
+			t.Fatalf("unexpected error scheduling pool %d: %v", len(assigned)+1, err)
+		}
+		assigned = append(assigned, pool)
+	}
+	if len(lease.OwnerReferences) != lease.Spec.Pools {
+		t.Errorf("Expected %d owner references, got %d", lease.Spec.Pools, len(lease.OwnerReferences))
+	}
+	return assigned
+}
+
+// TestDefaultSchedulerScorePools tests the order of pools ranked by the score plugins enabled by default.
+func TestDefaultSchedulerScorePools(t *testing.T) {
+	installerLeaseOnPool2 := &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "other-lease",
+			Labels: map[string]string{"git-repo": "installer"},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "Pool", Name: "pool2"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		lease    *v1.Lease
+		pools    []*v1.Pool
+		leases   []*v1.Lease
+		expected []string
+	}{
+		{
+			name:  "least allocated pool first without preferences",
+			lease: &v1.Lease{},
+			pools: []*v1.Pool{
+				scoringTestPool("pool1", nil, 20, nil),
+				scoringTestPool("pool2", nil, 80, nil),
+				scoringTestPool("pool3", nil, 50, nil),
+			},
+			expected: []string{"pool2", "pool3", "pool1"},
+		},
+		{
+			name: "preferred affinity outweighs small capacity differences",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					PreferredPoolAffinity: []v1.WeightedPoolAffinityTerm{
+						{
+							Weight: 50,
+							Preference: metav1.LabelSelector{
+								MatchLabels: map[string]string{"region": "us-east"},
+							},
+						},
+					},
+				},
+			},
+			pools: []*v1.Pool{
+				scoringTestPool("pool1", map[string]string{"region": "us-west"}, 60, nil),
+				scoringTestPool("pool2", map[string]string{"region": "us-east"}, 50, nil),
+			},
+			expected: []string{"pool2", "pool1"},
+		},
+		{
+			name: "heavier affinity term wins",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					PreferredPoolAffinity: []v1.WeightedPoolAffinityTerm{
+						{
+							Weight: 10,
+							Preference: metav1.LabelSelector{
+								MatchLabels: map[string]string{"region": "us-west"},
+							},
+						},
+						{
+							Weight: 90,
+							Preference: metav1.LabelSelector{
+								MatchExpressions: []metav1.LabelSelectorRequirement{
+									{Key: "tier", Operator: metav1.LabelSelectorOpIn, Values: []string{"fast"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			pools: []*v1.Pool{
+				scoringTestPool("pool1", map[string]string{"region": "us-west"}, 50, nil),
+				scoringTestPool("pool2", map[string]string{"tier": "fast"}, 50, nil),
+			},
+			expected: []string{"pool2", "pool1"},
+		},
+		{
+			name: "anti-affinity spreads away from pools holding matching leases",
+			lease: &v1.Lease{
+				ObjectMeta: metav1.ObjectMeta{Name: "lease"},
+				Spec: v1.LeaseSpec{
+					PoolAntiAffinity: []v1.WeightedPoolAntiAffinityTerm{
+						{
+							Weight: 100,
+							LeaseSelector: metav1.LabelSelector{
+								MatchLabels: map[string]string{"git-repo": "installer"},
+							},
+						},
+					},
+				},
+			},
+			pools: []*v1.Pool{
+				scoringTestPool("pool1", nil, 40, nil),
+				scoringTestPool("pool2", nil, 60, nil),
+			},
+			leases:   []*v1.Lease{installerLeaseOnPool2},
+			expected: []string{"pool1", "pool2"},
+		},
+		{
+			name:  "untolerated PreferNoSchedule taint is penalized",
+			lease: &v1.Lease{},
+			pools: []*v1.Pool{
+				scoringTestPool("pool1", nil, 80, []v1.Taint{{Key: "slow-storage", Effect: v1.TaintEffectPreferNoSchedule}}),
+				scoringTestPool("pool2", nil, 60, nil),
+			},
+			expected: []string{"pool2", "pool1"},
+		},
+		{
+			name: "tolerated PreferNoSchedule taint is not penalized",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					Tolerations: []v1.Toleration{
+						{Key: "slow-storage", Operator: v1.TolerationOpExists},
+					},
+				},
+			},
+			pools: []*v1.Pool{
+				scoringTestPool("pool1", nil, 80, []v1.Taint{{Key: "slow-storage", Effect: v1.TaintEffectPreferNoSchedule}}),
+				scoringTestPool("pool2", nil, 60, nil),
+			},
+			expected: []string{"pool1", "pool2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewScheduler(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			state := scheduler.NewCycleState(tt.lease, nil, tt.leases)
+			var got []string
+			for _, score := range s.ForLease(tt.lease).RunScorePlugins(context.TODO(), state, tt.lease, tt.pools) {
+				got = append(got, score.Pool.Name)
+			}
+			for i := range tt.expected {
+				if got[i] != tt.expected[i] {
+					t.Fatalf("RunScorePlugins() = %v, expected %v", got, tt.expected)
+				}
+			}
+		})
+	}
+}
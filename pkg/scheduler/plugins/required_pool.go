@@ -0,0 +1,23 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// RequiredPool rejects pools other than the lease's required pool, if one is set.
+type RequiredPool struct{}
+
+var _ scheduler.FilterPlugin = &RequiredPool{}
+
+func (p *RequiredPool) Name() string { return RequiredPoolName }
+
+func (p *RequiredPool) Filter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) *scheduler.Status {
+	if len(lease.Spec.RequiredPool) > 0 && lease.Spec.RequiredPool != pool.Name {
+		return scheduler.NewStatus(utils.PoolNotMatchRequired)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestRequiredPoolFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		lease    *v1.Lease
+		expected string
+	}{
+		{
+			name:     "no required pool accepts any pool",
+			lease:    &v1.Lease{},
+			expected: "",
+		},
+		{
+			name:     "required pool matches",
+			lease:    &v1.Lease{Spec: v1.LeaseSpec{RequiredPool: "pool1"}},
+			expected: "",
+		},
+		{
+			name:     "other required pool rejects the pool",
+			lease:    &v1.Lease{Spec: v1.LeaseSpec{RequiredPool: "pool2"}},
+			expected: utils.PoolNotMatchRequired,
+		},
+	}
+
+	pool := newTestPool("pool1", "vcenter-a", 10, 10)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statusReason((&RequiredPool{}).Filter(context.TODO(), newTestState(tt.lease), tt.lease, pool))
+			if got != tt.expected {
+				t.Errorf("Filter() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
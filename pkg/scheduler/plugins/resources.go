@@ -0,0 +1,37 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// CPU rejects pools without enough available vCPUs for the lease.
+type CPU struct{}
+
+var _ scheduler.FilterPlugin = &CPU{}
+
+func (p *CPU) Name() string { return CPUName }
+
+func (p *CPU) Filter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) *scheduler.Status {
+	if pool.Status.VCpusAvailable < lease.Spec.VCpus {
+		return scheduler.NewStatus(utils.PoolInsufficientVCPU)
+	}
+	return nil
+}
+
+// Memory rejects pools without enough available memory for the lease.
+type Memory struct{}
+
+var _ scheduler.FilterPlugin = &Memory{}
+
+func (p *Memory) Name() string { return MemoryName }
+
+func (p *Memory) Filter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) *scheduler.Status {
+	if pool.Status.MemoryAvailable < lease.Spec.Memory {
+		return scheduler.NewStatus(utils.PoolInsufficientMemory)
+	}
+	return nil
+}
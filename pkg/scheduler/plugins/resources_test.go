@@ -0,0 +1,55 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestCPUFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		vcpus    int
+		expected string
+	}{
+		{name: "enough vcpus", vcpus: 16, expected: ""},
+		{name: "exactly enough vcpus", vcpus: 24, expected: ""},
+		{name: "insufficient vcpus", vcpus: 32, expected: utils.PoolInsufficientVCPU},
+	}
+
+	pool := newTestPool("pool1", "vcenter-a", 24, 0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{Spec: v1.LeaseSpec{VCpus: tt.vcpus, Memory: 96}}
+			got := statusReason((&CPU{}).Filter(context.TODO(), newTestState(lease), lease, pool))
+			if got != tt.expected {
+				t.Errorf("Filter() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMemoryFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		memory   int
+		expected string
+	}{
+		{name: "enough memory", memory: 64, expected: ""},
+		{name: "exactly enough memory", memory: 96, expected: ""},
+		{name: "insufficient memory", memory: 128, expected: utils.PoolInsufficientMemory},
+	}
+
+	pool := newTestPool("pool1", "vcenter-a", 0, 96)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{Spec: v1.LeaseSpec{VCpus: 24, Memory: tt.memory}}
+			got := statusReason((&Memory{}).Filter(context.TODO(), newTestState(lease), lease, pool))
+			if got != tt.expected {
+				t.Errorf("Filter() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
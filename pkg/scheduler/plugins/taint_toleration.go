@@ -0,0 +1,40 @@
+package plugins
+
+import (
+	"context"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// TaintToleration rejects pools with NoSchedule taints the lease does not tolerate and penalizes pools with
+// untolerated PreferNoSchedule taints.
+type TaintToleration struct {
+	scorer utils.PoolScorer
+}
+
+var _ scheduler.FilterPlugin = &TaintToleration{}
+var _ scheduler.ScorePlugin = &TaintToleration{}
+
+// NewTaintToleration returns the TaintToleration plugin.
+func NewTaintToleration() scheduler.Plugin {
+	return &TaintToleration{scorer: utils.NewTaintTolerationScorer(1)}
+}
+
+func (p *TaintToleration) Name() string { return TaintTolerationName }
+
+func (p *TaintToleration) Filter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) *scheduler.Status {
+	if !utils.LeaseToleratesPoolTaints(lease, pool) {
+		return scheduler.NewStatus(utils.PoolTaintNotTolerated)
+	}
+	return nil
+}
+
+func (p *TaintToleration) Score(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pool *v1.Pool) float64 {
+	return p.scorer.Score(lease, pool)
+}
+
+func (p *TaintToleration) NormalizeScores(scores map[string]float64) {
+	p.scorer.NormalizeScores(scores)
+}
@@ -0,0 +1,65 @@
+package plugins
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestTaintTolerationFilter(t *testing.T) {
+	pool := newTestPool("pool1", "vcenter-a", 10, 10)
+	pool.Spec.Taints = []v1.Taint{
+		{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+		{Key: "slow-storage", Effect: v1.TaintEffectPreferNoSchedule},
+	}
+
+	tests := []struct {
+		name     string
+		lease    *v1.Lease
+		expected string
+	}{
+		{
+			name:     "untolerated NoSchedule taint is rejected",
+			lease:    &v1.Lease{},
+			expected: utils.PoolTaintNotTolerated,
+		},
+		{
+			name: "tolerated NoSchedule taint is accepted despite PreferNoSchedule taint",
+			lease: &v1.Lease{Spec: v1.LeaseSpec{Tolerations: []v1.Toleration{
+				{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "gpu", Effect: string(v1.TaintEffectNoSchedule)},
+			}}},
+			expected: "",
+		},
+	}
+
+	plugin := NewTaintToleration().(scheduler.FilterPlugin)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statusReason(plugin.Filter(context.TODO(), newTestState(tt.lease), tt.lease, pool))
+			if got != tt.expected {
+				t.Errorf("Filter() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTaintTolerationScore(t *testing.T) {
+	tainted := newTestPool("pool1", "vcenter-a", 10, 10)
+	tainted.Spec.Taints = []v1.Taint{{Key: "slow-storage", Effect: v1.TaintEffectPreferNoSchedule}}
+	clean := newTestPool("pool2", "vcenter-a", 10, 10)
+
+	lease := &v1.Lease{}
+	plugin := NewTaintToleration().(scheduler.ScorePlugin)
+	scores := map[string]float64{
+		tainted.Name: plugin.Score(context.TODO(), newTestState(lease), lease, tainted),
+		clean.Name:   plugin.Score(context.TODO(), newTestState(lease), lease, clean),
+	}
+	plugin.NormalizeScores(scores)
+
+	if scores[clean.Name] != utils.MaxPoolScore || scores[tainted.Name] != 0 {
+		t.Errorf("expected untainted pool to score %v and tainted pool 0, got %v", utils.MaxPoolScore, scores)
+	}
+}
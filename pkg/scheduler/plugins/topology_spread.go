@@ -0,0 +1,163 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// TopologySpread enforces the lease's topologySpreadConstraints as pools are picked one at a time. a domain is
+// eligible if a pool of the lease or a feasible pool is in it. a pool is rejected if:
+//   - it has no value for the topology key
+//   - placing it would make its domain exceed the domain with the fewest of the lease's pools by more than maxSkew.
+//     while fewer than minDomains domains are eligible, the fewest is taken to be 0.
+//   - fewer than minDomains domains are in use, only enough pools remain to reach minDomains and its domain
+//     is already in use.
+type TopologySpread struct{}
+
+var _ scheduler.PostFilterPlugin = &TopologySpread{}
+
+func (p *TopologySpread) Name() string { return TopologySpreadName }
+
+func (p *TopologySpread) PostFilter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pools, feasible []*v1.Pool) map[string]*scheduler.Status {
+	rejected := make(map[string]*scheduler.Status)
+	for _, constraint := range lease.Spec.TopologySpreadConstraints {
+		for name, status := range filterTopologySpread(constraint, state, feasible) {
+			if _, ok := rejected[name]; !ok {
+				rejected[name] = status
+			}
+		}
+	}
+	return rejected
+}
+
+// filterTopologySpread returns the feasible pools which violate constraint, keyed by pool name.
+func filterTopologySpread(constraint v1.TopologySpreadConstraint, state *scheduler.CycleState, feasible []*v1.Pool) map[string]*scheduler.Status {
+	maxSkew := int(constraint.MaxSkew)
+	if maxSkew < 1 {
+		maxSkew = 1
+	}
+	minDomains := 1
+	if constraint.MinDomains != nil && *constraint.MinDomains > 1 {
+		minDomains = int(*constraint.MinDomains)
+	}
+
+	counts := make(map[string]int)
+	for _, pool := range state.AssignedPools {
+		if domain := PoolTopologyDomain(pool, constraint.TopologyKey); domain != "" {
+			counts[domain]++
+		}
+	}
+	usedDomains := len(counts)
+
+	eligible := make(map[string]bool)
+	for domain := range counts {
+		eligible[domain] = true
+	}
+	for _, pool := range feasible {
+		if domain := PoolTopologyDomain(pool, constraint.TopologyKey); domain != "" {
+			eligible[domain] = true
+		}
+	}
+
+	minCount := -1
+	for domain := range eligible {
+		if minCount == -1 || counts[domain] < minCount {
+			minCount = counts[domain]
+		}
+	}
+	if minCount == -1 || len(eligible) < minDomains {
+		minCount = 0
+	}
+
+	needNewDomain := usedDomains < minDomains && state.RemainingPools() <= minDomains-usedDomains
+
+	rejected := make(map[string]*scheduler.Status)
+	for _, pool := range feasible {
+		domain := PoolTopologyDomain(pool, constraint.TopologyKey)
+		switch {
+		case domain == "":
+			rejected[pool.Name] = scheduler.NewStatus(fmt.Sprintf("%v %v", utils.PoolTopologyUnknown, constraint.TopologyKey))
+		case needNewDomain && counts[domain] > 0:
+			rejected[pool.Name] = scheduler.NewStatus(utils.PoolTopologyMinDomainsNotMet)
+		case counts[domain]+1-minCount > maxSkew:
+			rejected[pool.Name] = scheduler.NewStatus(utils.PoolTopologyMaxSkewExceeded)
+		}
+	}
+	return rejected
+}
+
+// TopologySpreadSatisfied returns true if the complete set of pools of a lease satisfies its
+// topologySpreadConstraints. candidates are the pools which passed filtering and decide which domains are eligible.
+func TopologySpreadSatisfied(lease *v1.Lease, pools, candidates []*v1.Pool) bool {
+	for _, constraint := range lease.Spec.TopologySpreadConstraints {
+		maxSkew := int(constraint.MaxSkew)
+		if maxSkew < 1 {
+			maxSkew = 1
+		}
+		minDomains := 1
+		if constraint.MinDomains != nil && *constraint.MinDomains > 1 {
+			minDomains = int(*constraint.MinDomains)
+		}
+
+		counts := make(map[string]int)
+		for _, pool := range pools {
+			domain := PoolTopologyDomain(pool, constraint.TopologyKey)
+			if domain == "" {
+				return false
+			}
+			counts[domain]++
+		}
+
+		if len(counts) < minDomains && len(counts) < len(pools) {
+			return false
+		}
+
+		eligible := make(map[string]bool)
+		for domain := range counts {
+			eligible[domain] = true
+		}
+		for _, pool := range candidates {
+			if domain := PoolTopologyDomain(pool, constraint.TopologyKey); domain != "" {
+				eligible[domain] = true
+			}
+		}
+
+		minCount, maxCount := -1, 0
+		for domain := range eligible {
+			if minCount == -1 || counts[domain] < minCount {
+				minCount = counts[domain]
+			}
+			if counts[domain] > maxCount {
+				maxCount = counts[domain]
+			}
+		}
+		if len(eligible) < minDomains {
+			minCount = 0
+		}
+		if maxCount-minCount > maxSkew {
+			return false
+		}
+	}
+	return true
+}
+
+// PoolTopologyDomain returns the domain of the pool for a topology key, or an empty string if the pool has none.
+func PoolTopologyDomain(pool *v1.Pool, key v1.TopologyKey) string {
+	switch key {
+	case v1.TopologyKeyServer:
+		return pool.Spec.Server
+	case v1.TopologyKeyRegion:
+		return pool.Spec.Region
+	case v1.TopologyKeyZone:
+		return pool.Spec.Zone
+	case v1.TopologyKeyIBMDatacenter:
+		return pool.Spec.IBMPoolSpec.Datacenter
+	case v1.TopologyKeyIBMPod:
+		return pool.Spec.IBMPoolSpec.Pod
+	}
+	return ""
+}
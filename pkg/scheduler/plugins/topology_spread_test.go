@@ -0,0 +1,156 @@
+package plugins
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func newZonedPool(name, server, region, zone string) *v1.Pool {
+	pool := newTestPool(name, server, 10, 10)
+	pool.Spec.Region = region
+	pool.Spec.Zone = zone
+	return pool
+}
+
+func TestTopologySpreadPostFilter(t *testing.T) {
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	tests := []struct {
+		name          string
+		requiredPools int
+		constraints   []v1.TopologySpreadConstraint
+		assignedPools []*v1.Pool
+		feasible      []*v1.Pool
+		expected      map[string]string
+	}{
+		{
+			name:          "no constraints rejects nothing",
+			requiredPools: 2,
+			assignedPools: []*v1.Pool{newZonedPool("a1", "vc1", "r1", "a")},
+			feasible:      []*v1.Pool{newZonedPool("a2", "vc1", "r1", "a")},
+			expected:      map[string]string{},
+		},
+		{
+			name:          "maxSkew rejects the crowded zone",
+			requiredPools: 4,
+			constraints:   []v1.TopologySpreadConstraint{{TopologyKey: v1.TopologyKeyZone, MaxSkew: 1}},
+			assignedPools: []*v1.Pool{newZonedPool("a1", "vc1", "r1", "a")},
+			feasible: []*v1.Pool{
+				newZonedPool("a2", "vc1", "r1", "a"),
+				newZonedPool("b1", "vc1", "r1", "b"),
+			},
+			expected: map[string]string{"a2": utils.PoolTopologyMaxSkewExceeded},
+		},
+		{
+			name:          "maxSkew of two allows a second pool in a zone",
+			requiredPools: 4,
+			constraints:   []v1.TopologySpreadConstraint{{TopologyKey: v1.TopologyKeyZone, MaxSkew: 2}},
+			assignedPools: []*v1.Pool{newZonedPool("a1", "vc1", "r1", "a")},
+			feasible: []*v1.Pool{
+				newZonedPool("a2", "vc1", "r1", "a"),
+				newZonedPool("b1", "vc1", "r1", "b"),
+			},
+			expected: map[string]string{},
+		},
+		{
+			name:          "zone which is not eligible does not count towards skew",
+			requiredPools: 4,
+			constraints:   []v1.TopologySpreadConstraint{{TopologyKey: v1.TopologyKeyZone, MaxSkew: 1}},
+			assignedPools: []*v1.Pool{newZonedPool("a1", "vc1", "r1", "a"), newZonedPool("b1", "vc1", "r1", "b")},
+			feasible:      []*v1.Pool{newZonedPool("a2", "vc1", "r1", "a")},
+			expected:      map[string]string{},
+		},
+		{
+			name:          "minDomains requires distinct vCenters",
+			requiredPools: 3,
+			constraints:   []v1.TopologySpreadConstraint{{TopologyKey: v1.TopologyKeyServer, MaxSkew: 3, MinDomains: int32Ptr(3)}},
+			assignedPools: []*v1.Pool{newZonedPool("vc1-a", "vc1", "r1", "a")},
+			feasible: []*v1.Pool{
+				newZonedPool("vc1-b", "vc1", "r1", "b"),
+				newZonedPool("vc2-a", "vc2", "r1", "a"),
+			},
+			expected: map[string]string{"vc1-b": utils.PoolTopologyMinDomainsNotMet},
+		},
+		{
+			name:          "minDomains allows reuse while enough pools remain for new domains",
+			requiredPools: 4,
+			constraints:   []v1.TopologySpreadConstraint{{TopologyKey: v1.TopologyKeyServer, MaxSkew: 3, MinDomains: int32Ptr(2)}},
+			assignedPools: []*v1.Pool{newZonedPool("vc1-a", "vc1", "r1", "a")},
+			feasible: []*v1.Pool{
+				newZonedPool("vc1-b", "vc1", "r1", "b"),
+				newZonedPool("vc2-a", "vc2", "r1", "a"),
+			},
+			expected: map[string]string{},
+		},
+		{
+			name:          "fewer eligible domains than minDomains treats the minimum as zero",
+			requiredPools: 6,
+			constraints:   []v1.TopologySpreadConstraint{{TopologyKey: v1.TopologyKeyRegion, MaxSkew: 1, MinDomains: int32Ptr(3)}},
+			assignedPools: []*v1.Pool{newZonedPool("r1-a", "vc1", "r1", "a"), newZonedPool("r2-a", "vc1", "r2", "a")},
+			feasible: []*v1.Pool{
+				newZonedPool("r1-b", "vc1", "r1", "b"),
+				newZonedPool("r2-b", "vc1", "r2", "b"),
+			},
+			expected: map[string]string{
+				"r1-b": utils.PoolTopologyMaxSkewExceeded,
+				"r2-b": utils.PoolTopologyMaxSkewExceeded,
+			},
+		},
+		{
+			name:          "pool without a value for the topology key is rejected",
+			requiredPools: 2,
+			constraints:   []v1.TopologySpreadConstraint{{TopologyKey: v1.TopologyKeyZone, MaxSkew: 1}},
+			feasible: []*v1.Pool{
+				newZonedPool("nozone", "vc1", "r1", ""),
+				newZonedPool("a1", "vc1", "r1", "a"),
+			},
+			expected: map[string]string{"nozone": utils.PoolTopologyUnknown},
+		},
+		{
+			name:          "IBM pods are spread",
+			requiredPools: 2,
+			constraints:   []v1.TopologySpreadConstraint{{TopologyKey: v1.TopologyKeyIBMPod, MaxSkew: 1}},
+			assignedPools: func() []*v1.Pool {
+				pool := newZonedPool("pod1-a", "vc1", "r1", "a")
+				pool.Spec.IBMPoolSpec.Pod = "pod1"
+				return []*v1.Pool{pool}
+			}(),
+			feasible: func() []*v1.Pool {
+				pod1 := newZonedPool("pod1-b", "vc1", "r1", "b")
+				pod1.Spec.IBMPoolSpec.Pod = "pod1"
+				pod2 := newZonedPool("pod2-a", "vc2", "r1", "a")
+				pod2.Spec.IBMPoolSpec.Pod = "pod2"
+				return []*v1.Pool{pod1, pod2}
+			}(),
+			expected: map[string]string{"pod1-b": utils.PoolTopologyMaxSkewExceeded},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{Spec: v1.LeaseSpec{Pools: tt.requiredPools, TopologySpreadConstraints: tt.constraints}}
+			state := newTestState(lease, tt.assignedPools...)
+			rejected := (&TopologySpread{}).PostFilter(context.TODO(), state, lease, tt.feasible, tt.feasible)
+
+			var names []string
+			for name := range rejected {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			if len(rejected) != len(tt.expected) {
+				t.Fatalf("rejected pools = %v, expected %v", names, tt.expected)
+			}
+			for name, reason := range tt.expected {
+				status, ok := rejected[name]
+				if !ok || !strings.HasPrefix(status.Reason, reason) {
+					t.Errorf("pool %s rejected with %v, expected %q", name, status, reason)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package plugins
+
+import (
+	"context"
+	"sort"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/scheduler"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+// VCenterCap enforces the lease's vCenters cap with smart filtering:
+// 1. If cap reached: only allow vCenters already in use
+// 2. If approaching cap with remaining pools > remaining slots: require vCenters with multiple pools
+// 3. Initial selection (no pools assigned): pre-filter to avoid low-capacity vCenters
+//
+// the excluded vCenters are recorded in the cycle state.
+type VCenterCap struct{}
+
+var _ scheduler.PostFilterPlugin = &VCenterCap{}
+
+func (p *VCenterCap) Name() string { return VCenterCapName }
+
+func (p *VCenterCap) PostFilter(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pools, feasible []*v1.Pool) map[string]*scheduler.Status {
+	if lease.Spec.VCenters <= 0 {
+		return nil
+	}
+
+	excludedVCenters := getExcludedVCenters(ctx, state, lease, pools, feasible)
+	excluded := make([]string, 0, len(excludedVCenters))
+	for server := range excludedVCenters {
+		excluded = append(excluded, server)
+	}
+	sort.Strings(excluded)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("vcenters.cap", lease.Spec.VCenters),
+		attribute.Int("vcenters.inUse", len(utils.GetVCentersInUse(state.AssignedPools))),
+		attribute.StringSlice("vcenters.excluded", excluded))
+	if len(excludedVCenters) == 0 {
+		return nil
+	}
+	log.FromContext(ctx).V(4).Info("vCenters excluded from pool selection", "vcenters", len(excludedVCenters))
+
+	rejected := make(map[string]*scheduler.Status)
+	for server := range excludedVCenters {
+		state.ExcludedVCenters[server] = true
+	}
+	for _, pool := range feasible {
+		if excludedVCenters[pool.Spec.Server] {
+			rejected[pool.Name] = scheduler.NewStatus(utils.PoolVCenterLimitReached)
+		}
+	}
+	return rejected
+}
+
+// getExcludedVCenters returns the vCenters the next pool of the lease must not be picked from. pools are all
+// candidate pools and feasible are the candidates which passed filtering.
+func getExcludedVCenters(ctx context.Context, state *scheduler.CycleState, lease *v1.Lease, pools, feasible []*v1.Pool) map[string]bool {
+	requiredPools := state.RequiredPools
+	vcentersInUse := utils.GetVCentersInUse(state.AssignedPools)
+	remainingVCenterSlots := lease.Spec.VCenters - len(vcentersInUse)
+	remainingPools := state.RemainingPools()
+
+	logger := log.FromContext(ctx)
+	logger.V(4).Info("vCenter cap", "cap", lease.Spec.VCenters, "using", len(vcentersInUse), "remainingSlots", remainingVCenterSlots, "remainingPools", remainingPools)
+
+	excludedVCenters := make(map[string]bool)
+	if len(vcentersInUse) >= lease.Spec.VCenters {
+		// Cap reached — only allow pools from vCenters already in use
+		for _, p := range pools {
+			srv := p.Spec.Server
+			if srv != "" && !vcentersInUse[srv] {
+				excludedVCenters[srv] = true
+			}
+		}
+		logger.V(2).Info("vCenter cap reached, only allowing vCenters in use")
+	} else if remainingVCenterSlots > 0 && remainingPools > remainingVCenterSlots {
+		// We need multiple pools per remaining vCenter slot
+		// Apply dynamic filtering: exclude vCenters that don't have enough pools
+		minPoolsPerVCenter := (remainingPools-1)/remainingVCenterSlots + 1
+
+		logger.V(4).Info("multiple pools needed per vCenter", "remainingPools", remainingPools, "remainingSlots", remainingVCenterSlots, "minPoolsPerVCenter", minPoolsPerVCenter)
+
+		// Count fitting pools per vCenter
+		fittingPoolsPerVCenter := make(map[string]int)
+		for _, p := range feasible {
+			if p.Spec.Server != "" && !vcentersInUse[p.Spec.Server] {
+				fittingPoolsPerVCenter[p.Spec.Server]++
+			}
+		}
+
+		// Exclude vCenters (not already in use) that don't have enough pools
+		for _, p := range pools {
+			srv := p.Spec.Server
+			if srv != "" && !vcentersInUse[srv] {
+				if fittingPoolsPerVCenter[srv] < minPoolsPerVCenter {
+					excludedVCenters[srv] = true
+				}
+			}
+		}
+
+		if len(excludedVCenters) > 0 {
+			logger.V(2).Info("excluded vCenters with too few pools", "vcenters", len(excludedVCenters), "minPoolsPerVCenter", minPoolsPerVCenter)
+		}
+	} else if lease.Spec.VCenters < requiredPools && len(state.AssignedPools) == 0 {
+		// Special case: if we need more pools than vCenters allowed (VCenters < Pools),
+		// and we haven't assigned any pools yet, we must ensure we only pick from
+		// vCenters that can participate in a valid combination to fulfill the lease.
+		//
+		// Use greedy selection: sort vCenters by pool count descending, check if
+		// the top VCenters vCenters have enough pools total. Only exclude vCenters
+		// that cannot participate in any valid combination.
+
+		// Group fitting pools by vCenter and count them
+		fittingPoolsPerVCenter := make(map[string]int)
+		for _, p := range feasible {
+			if p.Spec.Server != "" {
+				fittingPoolsPerVCenter[p.Spec.Server]++
+			}
+		}
+
+		// Build sorted list of vCenters by pool count (descending)
+		type vcenterPoolCount struct {
+			server string
+			count  int
+		}
+		vcenterCounts := make([]vcenterPoolCount, 0, len(fittingPoolsPerVCenter))
+		for server, count := range fittingPoolsPerVCenter {
+			vcenterCounts = append(vcenterCounts, vcenterPoolCount{server: server, count: count})
+		}
+		sort.Slice(vcenterCounts, func(i, j int) bool {
+			if vcenterCounts[i].count == vcenterCounts[j].count {
+				return vcenterCounts[i].server < vcenterCounts[j].server
+			}
+			return vcenterCounts[i].count > vcenterCounts[j].count
+		})
+
+		// Calculate total pools available from top VCenters vCenters
+		topVCentersPoolCount := 0
+		numVCentersToUse := lease.Spec.VCenters
+		if numVCentersToUse > len(vcenterCounts) {
+			numVCentersToUse = len(vcenterCounts)
+		}
+		for i := 0; i < numVCentersToUse; i++ {
+			topVCentersPoolCount += vcenterCounts[i].count
+		}
+
+		// If the top VCenters vCenters don't have enough pools total, we can't fulfill
+		// In this case, keep all vCenters (no exclusions) and let the normal flow handle it
+		if topVCentersPoolCount < requiredPools {
+			logger.V(4).Info("top vCenters do not have enough pools, no exclusions applied", "vcenters", numVCentersToUse, "pools", topVCentersPoolCount, "requiredPools", requiredPools)
+			return excludedVCenters
+		}
+
+		// We can potentially fulfill. Use a hybrid strategy that balances:
+		// 1. Allowing valid combinations (maintenance scenario)
+		// 2. Preventing greedy trap (low-pool vCenters exhausting cap)
+
+		// Find minimum vCenters needed from the top
+		cumulativePoolCount := 0
+		minVCentersNeeded := 0
+		for i := 0; i < len(vcenterCounts); i++ {
+			cumulativePoolCount += vcenterCounts[i].count
+			minVCentersNeeded++
+			if cumulativePoolCount >= requiredPools {
+				break
+			}
+		}
+
+		if minVCentersNeeded < lease.Spec.VCenters {
+			// We have slack (min < cap): can be selective to avoid greedy trap
+			// Keep top minVCentersNeeded, apply ceiling filter to remainder
+			ceiling := (requiredPools-1)/lease.Spec.VCenters + 1
+
+			for i := minVCentersNeeded; i < len(vcenterCounts); i++ {
+				if vcenterCounts[i].count < ceiling {
+					excludedVCenters[vcenterCounts[i].server] = true
+				}
+			}
+
+			if len(excludedVCenters) > 0 {
+				logger.V(2).Info("excluded vCenters with too few pools", "vcenters", len(excludedVCenters), "requiredPools", requiredPools, "cap", lease.Spec.VCenters, "vcentersNeeded", minVCentersNeeded)
+			}
+		} else {
+			// No slack (min >= cap): use all vCenter slots, apply combination-aware filtering
+			// This handles maintenance scenarios where we need flexibility
+			for idx, current := range vcenterCounts {
+				// For this vCenter, find the best (VCenters-1) OTHER vCenters
+				bestOthersSum := 0
+				othersCollected := 0
+				othersNeeded := lease.Spec.VCenters - 1
+
+				for i := 0; i < len(vcenterCounts) && othersCollected < othersNeeded; i++ {
+					if i != idx {
+						bestOthersSum += vcenterCounts[i].count
+						othersCollected++
+					}
+				}
+
+				// Can this vCenter + best others reach the requirement?
+				if current.count+bestOthersSum < requiredPools {
+					excludedVCenters[current.server] = true
+				}
+			}
+
+			if len(excludedVCenters) > 0 {
+				logger.V(2).Info("excluded vCenters which can not combine to the pools of the lease", "vcenters", len(excludedVCenters), "requiredPools", requiredPools, "cap", lease.Spec.VCenters)
+			}
+		}
+	}
+	return excludedVCenters
+}
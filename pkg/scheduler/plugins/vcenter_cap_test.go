@@ -0,0 +1,155 @@
+package plugins
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/utils"
+)
+
+func TestVCenterCapPostFilter(t *testing.T) {
+	tests := []struct {
+		name             string
+		requiredPools    int
+		vcenters         int
+		assignedPools    []*v1.Pool
+		pools            []*v1.Pool
+		expectedExcluded []string
+	}{
+		{
+			name:          "no cap excludes nothing",
+			requiredPools: 2,
+			vcenters:      0,
+			pools: []*v1.Pool{
+				newTestPool("pool-a", "vcenter-a", 10, 10),
+				newTestPool("pool-b", "vcenter-b", 10, 10),
+			},
+		},
+		{
+			name:          "cap reached only allows vCenters in use",
+			requiredPools: 4,
+			vcenters:      3,
+			assignedPools: []*v1.Pool{
+				newTestPool("pool-a", "vcenter-a", 10, 10),
+				newTestPool("pool-b", "vcenter-b", 10, 10),
+				newTestPool("pool-c", "vcenter-c", 10, 10),
+			},
+			pools: []*v1.Pool{
+				newTestPool("pool-a2", "vcenter-a", 10, 10),
+				newTestPool("pool-d", "vcenter-d", 10, 10),
+			},
+			expectedExcluded: []string{"vcenter-d"},
+		},
+		{
+			name:          "one slot left for two pools requires a vCenter with two pools",
+			requiredPools: 4,
+			vcenters:      3,
+			assignedPools: []*v1.Pool{
+				newTestPool("pool-a", "vcenter-a", 10, 10),
+				newTestPool("pool-b", "vcenter-b", 10, 10),
+			},
+			pools: []*v1.Pool{
+				newTestPool("pool-c1", "vcenter-c", 10, 10),
+				newTestPool("pool-d1", "vcenter-d", 10, 10),
+				newTestPool("pool-d2", "vcenter-d", 10, 10),
+			},
+			expectedExcluded: []string{"vcenter-c"},
+		},
+		{
+			name:          "pools which do not fit do not count towards a vCenter",
+			requiredPools: 4,
+			vcenters:      3,
+			assignedPools: []*v1.Pool{
+				newTestPool("pool-a", "vcenter-a", 10, 10),
+				newTestPool("pool-b", "vcenter-b", 10, 10),
+			},
+			pools: []*v1.Pool{
+				newTestPool("pool-c1", "vcenter-c", 10, 10),
+				newTestPool("pool-c2", "vcenter-c", 0, 0),
+				newTestPool("pool-d1", "vcenter-d", 10, 10),
+				newTestPool("pool-d2", "vcenter-d", 10, 10),
+			},
+			expectedExcluded: []string{"vcenter-c"},
+		},
+		{
+			name:          "no pools assigned excludes vCenters with too few pools for the cap",
+			requiredPools: 4,
+			vcenters:      3,
+			pools: []*v1.Pool{
+				newTestPool("pool-a1", "vcenter-a", 10, 10),
+				newTestPool("pool-a2", "vcenter-a", 10, 10),
+				newTestPool("pool-a3", "vcenter-a", 10, 10),
+				newTestPool("pool-a4", "vcenter-a", 10, 10),
+				newTestPool("pool-b1", "vcenter-b", 10, 10),
+				newTestPool("pool-c1", "vcenter-c", 10, 10),
+			},
+			expectedExcluded: []string{"vcenter-b", "vcenter-c"},
+		},
+		{
+			name:          "no pools assigned with every vCenter slot needed",
+			requiredPools: 4,
+			vcenters:      2,
+			pools: []*v1.Pool{
+				newTestPool("pool-a1", "vcenter-a", 10, 10),
+				newTestPool("pool-a2", "vcenter-a", 10, 10),
+				newTestPool("pool-b1", "vcenter-b", 10, 10),
+				newTestPool("pool-b2", "vcenter-b", 10, 10),
+				newTestPool("pool-c1", "vcenter-c", 10, 10),
+			},
+			expectedExcluded: []string{"vcenter-c"},
+		},
+		{
+			name:          "lease which can not be fulfilled within the cap excludes every vCenter",
+			requiredPools: 4,
+			vcenters:      2,
+			pools: []*v1.Pool{
+				newTestPool("pool-a1", "vcenter-a", 10, 10),
+				newTestPool("pool-b1", "vcenter-b", 10, 10),
+				newTestPool("pool-c1", "vcenter-c", 10, 10),
+			},
+			expectedExcluded: []string{"vcenter-a", "vcenter-b", "vcenter-c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lease := &v1.Lease{Spec: v1.LeaseSpec{Pools: tt.requiredPools, VCenters: tt.vcenters, VCpus: 8, Memory: 8}}
+			state := newTestState(lease, tt.assignedPools...)
+
+			var feasible []*v1.Pool
+			for _, pool := range tt.pools {
+				if pool.Status.VCpusAvailable >= lease.Spec.VCpus {
+					feasible = append(feasible, pool)
+				}
+			}
+
+			rejected := (&VCenterCap{}).PostFilter(context.TODO(), state, lease, tt.pools, feasible)
+
+			var excluded []string
+			for server := range state.ExcludedVCenters {
+				excluded = append(excluded, server)
+			}
+			sort.Strings(excluded)
+			if len(excluded) != len(tt.expectedExcluded) {
+				t.Fatalf("excluded vCenters = %v, expected %v", excluded, tt.expectedExcluded)
+			}
+			for i := range excluded {
+				if excluded[i] != tt.expectedExcluded[i] {
+					t.Fatalf("excluded vCenters = %v, expected %v", excluded, tt.expectedExcluded)
+				}
+			}
+
+			for _, pool := range feasible {
+				status, ok := rejected[pool.Name]
+				if state.ExcludedVCenters[pool.Spec.Server] != ok {
+					t.Errorf("pool %s rejected = %v, expected %v", pool.Name, ok, state.ExcludedVCenters[pool.Spec.Server])
+				}
+				if ok && status.Reason != utils.PoolVCenterLimitReached {
+					t.Errorf("pool %s rejected with %q, expected %q", pool.Name, status.Reason, utils.PoolVCenterLimitReached)
+				}
+			}
+		})
+	}
+}
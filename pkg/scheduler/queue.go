@@ -0,0 +1,435 @@
+package scheduler
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// DefaultInitialBackoff is the backoff of a lease after its first unsuccessful scheduling attempt.
+	DefaultInitialBackoff = 1 * time.Second
+	// DefaultMaxBackoff is the longest backoff of a lease.
+	DefaultMaxBackoff = 10 * time.Second
+	// DefaultUnschedulableTimeout is how long a lease waits for an event before it is retried anyway.
+	DefaultUnschedulableTimeout = 30 * time.Second
+
+	// backoffFlushInterval is how often leases which completed their backoff are moved to the active queue.
+	backoffFlushInterval = 1 * time.Second
+)
+
+// QueuedLease is a lease in the SchedulingQueue.
+type QueuedLease struct {
+	// Key is the namespace/name of the lease.
+	Key string
+	// Priority is the spec.priority of the lease.
+	Priority int32
+	// Tenant is the fair share tenant of the lease.
+	Tenant string
+	// Timestamp is the creation time of the lease.
+	Timestamp time.Time
+	// Generation is the metadata.generation of the lease when it was queued.
+	Generation int64
+	// Attempts is the number of times the lease was scheduled without being fulfilled.
+	Attempts int
+
+	// cycle is the scheduling cycle in which the lease was popped.
+	cycle int64
+	// backoffExpiry is when the lease may be scheduled again after an unsuccessful attempt.
+	backoffExpiry time.Time
+	// unschedulableSince is when the lease was moved to the unschedulable leases.
+	unschedulableSince time.Time
+	// index is the index of the lease in the heap holding it.
+	index int
+}
+
+// SchedulingQueue orders the leases waiting for pools. it is modeled after the kube-scheduler queue:
+//   - activeQ holds leases ready to be scheduled, by priority and then by age
+//   - backoffQ holds leases which were not fulfilled and are waiting out their backoff
+//   - unschedulable holds leases which were not fulfilled and are waiting for a pool, network or lease event
+//
+// events move the unschedulable leases back to the activeQ, or to the backoffQ if their backoff has not expired.
+// leases waiting longer than the unschedulable timeout are retried even without an event.
+type SchedulingQueue struct {
+	lock sync.Mutex
+	cond *sync.Cond
+
+	activeQ       *leaseHeap
+	backoffQ      *leaseHeap
+	unschedulable map[string]*QueuedLease
+	inFlight      map[string]*QueuedLease
+
+	// schedulingCycle is incremented each time a lease is popped. moveRequestCycle is the scheduling cycle in
+	// which the last event arrived, so a lease which was in flight during the event is not left waiting for it.
+	schedulingCycle  int64
+	moveRequestCycle int64
+
+	initialBackoff       time.Duration
+	maxBackoff           time.Duration
+	unschedulableTimeout time.Duration
+
+	// fairShare orders leases of the same priority by the usage of their tenant, when set.
+	fairShare *FairShare
+	tenant    func(*v1.Lease) string
+
+	now    func() time.Time
+	closed bool
+}
+
+// NewSchedulingQueue returns an empty queue. zero durations use the defaults.
+func NewSchedulingQueue(initialBackoff, maxBackoff, unschedulableTimeout time.Duration) *SchedulingQueue {
+	if initialBackoff <= 0 {
+		initialBackoff = DefaultInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	if unschedulableTimeout <= 0 {
+		unschedulableTimeout = DefaultUnschedulableTimeout
+	}
+	q := &SchedulingQueue{
+		activeQ: &leaseHeap{less: func(a, b *QueuedLease) bool {
+			return ScheduledBefore(a, b, nil)
+		}},
+		backoffQ: &leaseHeap{less: func(a, b *QueuedLease) bool {
+			return a.backoffExpiry.Before(b.backoffExpiry)
+		}},
+		unschedulable:        make(map[string]*QueuedLease),
+		inFlight:             make(map[string]*QueuedLease),
+		initialBackoff:       initialBackoff,
+		maxBackoff:           maxBackoff,
+		unschedulableTimeout: unschedulableTimeout,
+		now:                  time.Now,
+	}
+	q.cond = sync.NewCond(&q.lock)
+	return q
+}
+
+func olderThan(a, b *QueuedLease) bool {
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return a.Timestamp.Before(b.Timestamp)
+	}
+	return a.Key < b.Key
+}
+
+// ScheduledBefore returns true if the queue pops a before b: higher priority first, then the tenant with the
+// lowest fair share ratio, then oldest first. ratio returns the ratio of a tenant, nil without fair sharing.
+func ScheduledBefore(a, b *QueuedLease, ratio func(tenant string) float64) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if ratio != nil {
+		if ra, rb := ratio(a.Tenant), ratio(b.Tenant); ra != rb {
+			return ra < rb
+		}
+	}
+	return olderThan(a, b)
+}
+
+// ratioFunc returns the fair share ratio of each tenant, looked up once, or nil without fair sharing.
+func ratioFunc(fairShare *FairShare) func(tenant string) float64 {
+	if fairShare == nil {
+		return nil
+	}
+	ratios := make(map[string]float64)
+	return func(tenant string) float64 {
+		if r, ok := ratios[tenant]; ok {
+			return r
+		}
+		ratios[tenant] = fairShare.Ratio(tenant)
+		return ratios[tenant]
+	}
+}
+
+// LeaseOrder returns a function returning true if the queue would pop lease a before lease b, with the usage of
+// the tenants when LeaseOrder was called.
+func (q *SchedulingQueue) LeaseOrder() func(a, b *v1.Lease) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	tenant := q.tenant
+	ratio := ratioFunc(q.fairShare)
+	queued := func(lease *v1.Lease) *QueuedLease {
+		queued := &QueuedLease{Key: LeaseKey(lease), Priority: lease.Spec.Priority, Timestamp: lease.CreationTimestamp.Time}
+		if tenant != nil {
+			queued.Tenant = tenant(lease)
+		}
+		return queued
+	}
+	return func(a, b *v1.Lease) bool {
+		return ScheduledBefore(queued(a), queued(b), ratio)
+	}
+}
+
+// SetFairShare orders leases of the same priority by the usage of their tenant relative to its share, and then
+// by age. tenant returns the tenant of a lease. it must be called before leases are added.
+func (q *SchedulingQueue) SetFairShare(fairShare *FairShare, tenant func(*v1.Lease) string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.fairShare = fairShare
+	q.tenant = tenant
+}
+
+// LeaseKey returns the key of the lease in the queue.
+func LeaseKey(lease *v1.Lease) string {
+	return fmt.Sprintf("%s/%s", lease.Namespace, lease.Name)
+}
+
+// Add queues a lease which needs pools. a new lease goes to the activeQ. a lease already waiting keeps its
+// place, unless its spec changed since it was queued, in which case it is retried as soon as its backoff allows.
+// leases being scheduled are left to the outcome of their scheduling cycle.
+func (q *SchedulingQueue) Add(lease *v1.Lease) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	key := LeaseKey(lease)
+	if _, ok := q.inFlight[key]; ok {
+		return
+	}
+
+	if queued := q.activeQ.get(key); queued != nil {
+		q.update(queued, lease)
+		heap.Fix(q.activeQ, queued.index)
+		return
+	}
+	if queued := q.backoffQ.get(key); queued != nil {
+		q.update(queued, lease)
+		return
+	}
+	if queued, ok := q.unschedulable[key]; ok {
+		specChanged := queued.Generation != lease.Generation
+		q.update(queued, lease)
+		if specChanged {
+			delete(q.unschedulable, key)
+			q.requeue(queued)
+		}
+		return
+	}
+
+	queued := &QueuedLease{Key: key}
+	q.update(queued, lease)
+	heap.Push(q.activeQ, queued)
+	q.cond.Broadcast()
+}
+
+func (q *SchedulingQueue) update(queued *QueuedLease, lease *v1.Lease) {
+	queued.Priority = lease.Spec.Priority
+	queued.Timestamp = lease.CreationTimestamp.Time
+	queued.Generation = lease.Generation
+	if q.tenant != nil {
+		queued.Tenant = q.tenant(lease)
+	}
+}
+
+// requeue moves a lease to the backoffQ if its backoff has not expired, or to the activeQ.
+func (q *SchedulingQueue) requeue(queued *QueuedLease) {
+	if q.now().Before(queued.backoffExpiry) {
+		heap.Push(q.backoffQ, queued)
+		return
+	}
+	heap.Push(q.activeQ, queued)
+	q.cond.Broadcast()
+}
+
+// Delete removes a lease from the queue.
+func (q *SchedulingQueue) Delete(key string) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if queued := q.activeQ.get(key); queued != nil {
+		heap.Remove(q.activeQ, queued.index)
+	}
+	if queued := q.backoffQ.get(key); queued != nil {
+		heap.Remove(q.backoffQ, queued.index)
+	}
+	delete(q.unschedulable, key)
+	delete(q.inFlight, key)
+}
+
+// Pop returns the lease at the head of the activeQ, waiting for one if the activeQ is empty. it returns false
+// once the queue is closed.
+func (q *SchedulingQueue) Pop() (*QueuedLease, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for q.activeQ.Len() == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return nil, false
+	}
+
+	queued := q.popActive()
+	q.schedulingCycle++
+	queued.cycle = q.schedulingCycle
+	q.inFlight[queued.Key] = queued
+	return queued, true
+}
+
+// popActive removes the next lease to schedule from the activeQ. the usage of tenants changes over time, so
+// with fair sharing the activeQ is only ordered by priority and age, and the lease is picked by a scan.
+func (q *SchedulingQueue) popActive() *QueuedLease {
+	if q.fairShare == nil {
+		return heap.Pop(q.activeQ).(*QueuedLease)
+	}
+
+	ratio := ratioFunc(q.fairShare)
+	best := q.activeQ.items[0]
+	for _, queued := range q.activeQ.items[1:] {
+		if ScheduledBefore(queued, best, ratio) {
+			best = queued
+		}
+	}
+	return heap.Remove(q.activeQ, best.index).(*QueuedLease)
+}
+
+// Done removes a lease which was fulfilled, or no longer needs scheduling, from the in flight leases.
+func (q *SchedulingQueue) Done(queued *QueuedLease) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	delete(q.inFlight, queued.Key)
+}
+
+// AddUnschedulable requeues a lease which was not fulfilled by its scheduling cycle. it waits for an event, or
+// goes to the backoffQ if an event arrived while it was being scheduled.
+func (q *SchedulingQueue) AddUnschedulable(queued *QueuedLease) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if _, ok := q.inFlight[queued.Key]; !ok {
+		// deleted while being scheduled
+		return
+	}
+	delete(q.inFlight, queued.Key)
+
+	queued.Attempts++
+	queued.backoffExpiry = q.now().Add(q.backoffDuration(queued))
+	if q.moveRequestCycle >= queued.cycle {
+		heap.Push(q.backoffQ, queued)
+		return
+	}
+	queued.unschedulableSince = q.now()
+	q.unschedulable[queued.Key] = queued
+}
+
+func (q *SchedulingQueue) backoffDuration(queued *QueuedLease) time.Duration {
+	backoff := q.initialBackoff
+	for i := 1; i < queued.Attempts; i++ {
+		backoff *= 2
+		if backoff >= q.maxBackoff {
+			return q.maxBackoff
+		}
+	}
+	return backoff
+}
+
+// MoveAllToActiveQueue retries the unschedulable leases after an event which may have made them schedulable.
+func (q *SchedulingQueue) MoveAllToActiveQueue() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.moveRequestCycle = q.schedulingCycle
+	q.moveAll(func(*QueuedLease) bool { return true })
+}
+
+func (q *SchedulingQueue) moveAll(match func(*QueuedLease) bool) {
+	for key, queued := range q.unschedulable {
+		if match(queued) {
+			delete(q.unschedulable, key)
+			q.requeue(queued)
+		}
+	}
+}
+
+// flush moves the leases which completed their backoff to the activeQ, and retries the leases which waited
+// longer than the unschedulable timeout.
+func (q *SchedulingQueue) flush() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	now := q.now()
+	for q.backoffQ.Len() > 0 && !now.Before(q.backoffQ.items[0].backoffExpiry) {
+		heap.Push(q.activeQ, heap.Pop(q.backoffQ))
+		q.cond.Broadcast()
+	}
+	q.moveAll(func(queued *QueuedLease) bool {
+		return now.Sub(queued.unschedulableSince) >= q.unschedulableTimeout
+	})
+}
+
+// Run flushes the backoffQ and the unschedulable leases until ctx is done, then closes the queue.
+func (q *SchedulingQueue) Run(ctx context.Context) {
+	ticker := time.NewTicker(backoffFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			q.Close()
+			return
+		case <-ticker.C:
+			q.flush()
+		}
+	}
+}
+
+// Close wakes up Pop, which returns false from then on.
+func (q *SchedulingQueue) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// Len returns the number of leases in the activeQ, the backoffQ and waiting for an event.
+func (q *SchedulingQueue) Len() (active, backoff, unschedulable int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.activeQ.Len(), q.backoffQ.Len(), len(q.unschedulable)
+}
+
+// leaseHeap is a heap of queued leases which can look leases up by key.
+type leaseHeap struct {
+	items []*QueuedLease
+	less  func(a, b *QueuedLease) bool
+}
+
+func (h *leaseHeap) Len() int           { return len(h.items) }
+func (h *leaseHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+
+func (h *leaseHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.items[i].index = i
+	h.items[j].index = j
+}
+
+func (h *leaseHeap) Push(x any) {
+	queued := x.(*QueuedLease)
+	queued.index = len(h.items)
+	h.items = append(h.items, queued)
+}
+
+func (h *leaseHeap) Pop() any {
+	n := len(h.items)
+	queued := h.items[n-1]
+	h.items[n-1] = nil
+	h.items = h.items[:n-1]
+	queued.index = -1
+	return queued
+}
+
+func (h *leaseHeap) get(key string) *QueuedLease {
+	for _, queued := range h.items {
+		if queued.Key == key {
+			return queued
+		}
+	}
+	return nil
+}
@@ -0,0 +1,286 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+var queueEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func queueLease(name string, priority int32, age time.Duration) *v1.Lease {
+	return &v1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "vsphere-infra-helpers",
+			Generation:        1,
+			CreationTimestamp: metav1.NewTime(queueEpoch.Add(-age)),
+		},
+		Spec: v1.LeaseSpec{Priority: priority},
+	}
+}
+
+func newTestQueue(now *time.Time) *SchedulingQueue {
+	q := NewSchedulingQueue(time.Second, 4*time.Second, 30*time.Second)
+	q.now = func() time.Time { return *now }
+	return q
+}
+
+func popKeys(t *testing.T, q *SchedulingQueue) []string {
+	t.Helper()
+	var keys []string
+	for {
+		active, _, _ := q.Len()
+		if active == 0 {
+			return keys
+		}
+		queued, ok := q.Pop()
+		if !ok {
+			t.Fatalf("queue closed")
+		}
+		keys = append(keys, queued.Key)
+		q.Done(queued)
+	}
+}
+
+func TestSchedulingQueueOrder(t *testing.T) {
+	now := queueEpoch
+	q := newTestQueue(&now)
+
+	q.Add(queueLease("young", 0, time.Minute))
+	q.Add(queueLease("old", 0, time.Hour))
+	q.Add(queueLease("urgent", 10, time.Second))
+	q.Add(queueLease("low", -1, 2*time.Hour))
+
+	expected := []string{
+		"vsphere-infra-helpers/urgent",
+		"vsphere-infra-helpers/old",
+		"vsphere-infra-helpers/young",
+		"vsphere-infra-helpers/low",
+	}
+	keys := popKeys(t, q)
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, keys)
+		}
+	}
+}
+
+func TestSchedulingQueueAddUpdatesPriority(t *testing.T) {
+	now := queueEpoch
+	q := newTestQueue(&now)
+
+	q.Add(queueLease("a", 0, time.Hour))
+	q.Add(queueLease("b", 0, time.Minute))
+	q.Add(queueLease("b", 5, time.Minute))
+	q.Add(queueLease("a", 0, time.Hour))
+
+	keys := popKeys(t, q)
+	if len(keys) != 2 || keys[0] != "vsphere-infra-helpers/b" {
+		t.Fatalf("expected b first and no duplicates, got %v", keys)
+	}
+}
+
+func TestSchedulingQueueUnschedulable(t *testing.T) {
+	now := queueEpoch
+	q := newTestQueue(&now)
+	lease := queueLease("a", 0, time.Hour)
+
+	q.Add(lease)
+	queued, _ := q.Pop()
+	q.AddUnschedulable(queued)
+
+	// status updates written by the scheduling cycle do not retry the lease
+	q.Add(lease)
+	if active, backoff, unschedulable := q.Len(); active != 0 || backoff != 0 || unschedulable != 1 {
+		t.Fatalf("expected the lease to wait for an event, got active=%d backoff=%d unschedulable=%d", active, backoff, unschedulable)
+	}
+
+	// an event during the backoff moves the lease to the backoffQ
+	q.MoveAllToActiveQueue()
+	if active, backoff, _ := q.Len(); active != 0 || backoff != 1 {
+		t.Fatalf("expected the lease in the backoffQ, got active=%d backoff=%d", active, backoff)
+	}
+	now = now.Add(time.Second)
+	q.flush()
+	if active, backoff, _ := q.Len(); active != 1 || backoff != 0 {
+		t.Fatalf("expected the lease in the activeQ, got active=%d backoff=%d", active, backoff)
+	}
+
+	// the backoff doubles with each attempt
+	queued, _ = q.Pop()
+	q.AddUnschedulable(queued)
+	if expiry := queued.backoffExpiry.Sub(now); expiry != 2*time.Second {
+		t.Errorf("expected a backoff of 2s, got %v", expiry)
+	}
+
+	// a spec change retries the lease
+	now = now.Add(2 * time.Second)
+	lease.Generation++
+	q.Add(lease)
+	if active, _, unschedulable := q.Len(); active != 1 || unschedulable != 0 {
+		t.Fatalf("expected the lease in the activeQ, got active=%d unschedulable=%d", active, unschedulable)
+	}
+}
+
+func TestSchedulingQueueEventDuringCycle(t *testing.T) {
+	now := queueEpoch
+	q := newTestQueue(&now)
+
+	q.Add(queueLease("a", 0, time.Hour))
+	queued, _ := q.Pop()
+	q.MoveAllToActiveQueue()
+	q.AddUnschedulable(queued)
+
+	if _, backoff, unschedulable := q.Len(); backoff != 1 || unschedulable != 0 {
+		t.Fatalf("expected the lease in the backoffQ, got backoff=%d unschedulable=%d", backoff, unschedulable)
+	}
+}
+
+func TestSchedulingQueueUnschedulableTimeout(t *testing.T) {
+	now := queueEpoch
+	q := newTestQueue(&now)
+
+	q.Add(queueLease("a", 0, time.Hour))
+	queued, _ := q.Pop()
+	q.AddUnschedulable(queued)
+
+	now = now.Add(29 * time.Second)
+	q.flush()
+	if active, _, _ := q.Len(); active != 0 {
+		t.Fatalf("expected the lease to keep waiting")
+	}
+	now = now.Add(time.Second)
+	q.flush()
+	if active, _, _ := q.Len(); active != 1 {
+		t.Fatalf("expected the lease to be retried after the unschedulable timeout")
+	}
+}
+
+func TestSchedulingQueueDelete(t *testing.T) {
+	now := queueEpoch
+	q := newTestQueue(&now)
+
+	q.Add(queueLease("a", 0, time.Hour))
+	q.Add(queueLease("b", 0, time.Minute))
+	queued, _ := q.Pop()
+	q.Delete(queued.Key)
+	q.Delete("vsphere-infra-helpers/b")
+	q.AddUnschedulable(queued)
+
+	if active, backoff, unschedulable := q.Len(); active+backoff+unschedulable != 0 {
+		t.Fatalf("expected an empty queue, got active=%d backoff=%d unschedulable=%d", active, backoff, unschedulable)
+	}
+}
+
+func TestSchedulingQueueClose(t *testing.T) {
+	q := NewSchedulingQueue(0, 0, 0)
+	done := make(chan bool)
+	go func() {
+		_, ok := q.Pop()
+		done <- ok
+	}()
+	q.Close()
+	if ok := <-done; ok {
+		t.Fatalf("expected Pop to return false after Close")
+	}
+}
+
+func TestSchedulingQueueFairShare(t *testing.T) {
+	now := queueEpoch
+	q := newTestQueue(&now)
+	fairShare := NewFairShare(FairShareConfig{DefaultShare: 1, HalfLife: metav1.Duration{Duration: time.Hour}})
+	fairShare.now = func() time.Time { return now }
+	q.SetFairShare(fairShare, func(lease *v1.Lease) string {
+		return lease.Annotations["prow-job-type"]
+	})
+
+	jobLease := func(name, jobType string, priority int32, age time.Duration) *v1.Lease {
+		lease := queueLease(name, priority, age)
+		lease.Annotations = map[string]string{"prow-job-type": jobType}
+		return lease
+	}
+
+	capacity := Resources{VCpus: 100, Memory: 100, Networks: 100}
+	fairShare.Update(map[string]Resources{"presubmit": {VCpus: 90}, "periodic": {VCpus: 10}}, capacity)
+	now = now.Add(time.Minute)
+	fairShare.Update(map[string]Resources{"presubmit": {VCpus: 90}, "periodic": {VCpus: 10}}, capacity)
+
+	q.Add(jobLease("presubmit-old", "presubmit", 0, time.Hour))
+	q.Add(jobLease("presubmit-young", "presubmit", 0, time.Minute))
+	q.Add(jobLease("periodic", "periodic", 0, time.Second))
+	q.Add(jobLease("urgent", "presubmit", 1, 0))
+
+	expected := []string{
+		"vsphere-infra-helpers/urgent",
+		"vsphere-infra-helpers/periodic",
+		"vsphere-infra-helpers/presubmit-old",
+		"vsphere-infra-helpers/presubmit-young",
+	}
+	keys := popKeys(t, q)
+	if len(keys) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, keys)
+		}
+	}
+}
+
+func TestSchedulingQueueLeaseOrder(t *testing.T) {
+	now := queueEpoch
+	q := newTestQueue(&now)
+	jobLease := func(name, jobType string, priority int32, age time.Duration) *v1.Lease {
+		lease := queueLease(name, priority, age)
+		lease.Annotations = map[string]string{"prow-job-type": jobType}
+		return lease
+	}
+	presubmitOld := jobLease("presubmit-old", "presubmit", 0, time.Hour)
+	presubmitYoung := jobLease("presubmit-young", "presubmit", 0, time.Minute)
+	periodic := jobLease("periodic", "periodic", 0, time.Second)
+	urgent := jobLease("urgent", "presubmit", 1, 0)
+
+	before := q.LeaseOrder()
+	if !before(presubmitOld, periodic) || before(periodic, presubmitOld) {
+		t.Errorf("expected the oldest lease first without fair share")
+	}
+	if !before(urgent, presubmitOld) {
+		t.Errorf("expected the higher priority lease first")
+	}
+
+	fairShare := NewFairShare(FairShareConfig{DefaultShare: 1, HalfLife: metav1.Duration{Duration: time.Hour}})
+	fairShare.now = func() time.Time { return now }
+	q.SetFairShare(fairShare, func(lease *v1.Lease) string {
+		return lease.Annotations["prow-job-type"]
+	})
+	capacity := Resources{VCpus: 100, Memory: 100, Networks: 100}
+	fairShare.Update(map[string]Resources{"presubmit": {VCpus: 90}, "periodic": {VCpus: 10}}, capacity)
+	now = now.Add(time.Minute)
+	fairShare.Update(map[string]Resources{"presubmit": {VCpus: 90}, "periodic": {VCpus: 10}}, capacity)
+
+	tests := []struct {
+		name     string
+		a, b     *v1.Lease
+		expected bool
+	}{
+		{name: "lower fair share ratio first", a: periodic, b: presubmitOld, expected: true},
+		{name: "higher fair share ratio last", a: presubmitOld, b: periodic, expected: false},
+		{name: "oldest first within a tenant", a: presubmitOld, b: presubmitYoung, expected: true},
+		{name: "priority before fair share", a: urgent, b: periodic, expected: true},
+	}
+	before = q.LeaseOrder()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := before(tt.a, tt.b); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
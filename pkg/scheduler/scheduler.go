@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"fmt"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+// Scheduler holds a Framework for each configured lease network type.
+type Scheduler struct {
+	profiles       map[v1.NetworkType]*Framework
+	defaultProfile *Framework
+	fairShare      *FairShare
+}
+
+// New builds the frameworks of each profile in config from the plugins in registry. defaults are the plugins
+// each profile starts from before its own plugin configuration is applied. config may be nil.
+func New(config *Config, registry Registry, defaults *Plugins) (*Scheduler, error) {
+	if config == nil {
+		config = &Config{}
+	}
+
+	s := &Scheduler{
+		profiles: make(map[v1.NetworkType]*Framework),
+	}
+	for _, profile := range config.Profiles {
+		name := string(profile.NetworkType)
+		if name == "" {
+			name = DefaultProfileName
+		}
+
+		framework, err := newFramework(name, registry, mergePlugins(defaults, profile.Plugins))
+		if err != nil {
+			return nil, fmt.Errorf("error building scheduler profile %s: %w", name, err)
+		}
+
+		if profile.NetworkType == "" {
+			s.defaultProfile = framework
+			continue
+		}
+		if _, exists := s.profiles[profile.NetworkType]; exists {
+			return nil, fmt.Errorf("duplicate scheduler profile for network type %s", profile.NetworkType)
+		}
+		s.profiles[profile.NetworkType] = framework
+	}
+
+	if s.defaultProfile == nil {
+		framework, err := newFramework(DefaultProfileName, registry, mergePlugins(defaults, nil))
+		if err != nil {
+			return nil, fmt.Errorf("error building scheduler profile %s: %w", DefaultProfileName, err)
+		}
+		s.defaultProfile = framework
+	}
+
+	fairShareConfig := FairShareConfig{}
+	if config.FairShare != nil {
+		fairShareConfig = *config.FairShare
+	}
+	if err := validateFairShareConfig(&fairShareConfig); err != nil {
+		return nil, err
+	}
+	if fairShareConfig.TenantKey != TenantKeyNone {
+		s.fairShare = NewFairShare(fairShareConfig)
+	}
+	return s, nil
+}
+
+// FairShare returns the fair share tracker of the scheduler, or nil if fair sharing is disabled.
+func (s *Scheduler) FairShare() *FairShare {
+	return s.fairShare
+}
+
+// ForLease returns the framework used to schedule lease, based on its network type.
+func (s *Scheduler) ForLease(lease *v1.Lease) *Framework {
+	networkType := lease.Spec.NetworkType
+	if networkType == "" {
+		networkType = v1.NetworkTypeSingleTenant
+	}
+	if framework, ok := s.profiles[networkType]; ok {
+		return framework
+	}
+	return s.defaultProfile
+}
+
+func newFramework(name string, registry Registry, plugins *Plugins) (*Framework, error) {
+	f := &Framework{profileName: name}
+	instances := make(map[string]Plugin)
+
+	getPlugin := func(pluginName string) (Plugin, error) {
+		if plugin, ok := instances[pluginName]; ok {
+			return plugin, nil
+		}
+		factory, ok := registry[pluginName]
+		if !ok {
+			return nil, fmt.Errorf("plugin %s is not registered", pluginName)
+		}
+		plugin := factory()
+		instances[pluginName] = plugin
+		return plugin, nil
+	}
+
+	for _, config := range plugins.Filter.Enabled {
+		plugin, err := getPlugin(config.Name)
+		if err != nil {
+			return nil, err
+		}
+		filter, ok := plugin.(FilterPlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s is not a filter plugin", config.Name)
+		}
+		f.filterPlugins = append(f.filterPlugins, filter)
+	}
+
+	for _, config := range plugins.PostFilter.Enabled {
+		plugin, err := getPlugin(config.Name)
+		if err != nil {
+			return nil, err
+		}
+		postFilter, ok := plugin.(PostFilterPlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s is not a post filter plugin", config.Name)
+		}
+		f.postFilterPlugins = append(f.postFilterPlugins, postFilter)
+	}
+
+	for _, config := range plugins.Score.Enabled {
+		plugin, err := getPlugin(config.Name)
+		if err != nil {
+			return nil, err
+		}
+		score, ok := plugin.(ScorePlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s is not a score plugin", config.Name)
+		}
+		weight := config.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("plugin %s has a negative weight", config.Name)
+		}
+		f.scorePlugins = append(f.scorePlugins, weightedScorePlugin{ScorePlugin: score, weight: weight})
+	}
+
+	for _, config := range plugins.Reserve.Enabled {
+		plugin, err := getPlugin(config.Name)
+		if err != nil {
+			return nil, err
+		}
+		reserve, ok := plugin.(ReservePlugin)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s is not a reserve plugin", config.Name)
+		}
+		f.reservePlugins = append(f.reservePlugins, reserve)
+	}
+	return f, nil
+}
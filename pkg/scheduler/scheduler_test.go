@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"testing"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func testRegistry() Registry {
+	return Registry{
+		"Filter": func() Plugin { return &rejectPoolsPlugin{name: "Filter"} },
+		"Score":  func() Plugin { return &fixedScorePlugin{name: "Score"} },
+	}
+}
+
+func testDefaults() *Plugins {
+	return &Plugins{
+		Filter: PluginSet{Enabled: []PluginConfig{{Name: "Filter"}}},
+		Score:  PluginSet{Enabled: []PluginConfig{{Name: "Score"}}},
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *Config
+		expectErr bool
+	}{
+		{
+			name:   "nil config",
+			config: nil,
+		},
+		{
+			name: "profile per network type",
+			config: &Config{Profiles: []Profile{
+				{NetworkType: v1.NetworkTypeSingleTenant},
+				{NetworkType: v1.NetworkTypeMultiTenant},
+			}},
+		},
+		{
+			name: "unknown plugin",
+			config: &Config{Profiles: []Profile{
+				{Plugins: &Plugins{Filter: PluginSet{Enabled: []PluginConfig{{Name: "Missing"}}}}},
+			}},
+			expectErr: true,
+		},
+		{
+			name: "plugin enabled at an extension point it does not implement",
+			config: &Config{Profiles: []Profile{
+				{Plugins: &Plugins{Reserve: PluginSet{Enabled: []PluginConfig{{Name: "Filter"}}}}},
+			}},
+			expectErr: true,
+		},
+		{
+			name: "negative weight",
+			config: &Config{Profiles: []Profile{
+				{Plugins: &Plugins{Score: PluginSet{Enabled: []PluginConfig{{Name: "Score", Weight: -1}}}}},
+			}},
+			expectErr: true,
+		},
+		{
+			name: "duplicate network type",
+			config: &Config{Profiles: []Profile{
+				{NetworkType: v1.NetworkTypeSingleTenant},
+				{NetworkType: v1.NetworkTypeSingleTenant},
+			}},
+			expectErr: true,
+		},
+		{
+			name:      "unknown tenant key",
+			config:    &Config{FairShare: &FairShareConfig{TenantKey: "team"}},
+			expectErr: true,
+		},
+		{
+			name:      "zero share",
+			config:    &Config{FairShare: &FairShareConfig{Shares: map[string]float64{"periodic": 0}}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.config, testRegistry(), testDefaults())
+			if (err != nil) != tt.expectErr {
+				t.Errorf("New() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestForLease(t *testing.T) {
+	s, err := New(&Config{Profiles: []Profile{
+		{NetworkType: v1.NetworkTypeSingleTenant},
+		{
+			NetworkType: v1.NetworkTypeMultiTenant,
+			Plugins:     &Plugins{Filter: PluginSet{Disabled: []PluginConfig{{Name: AllPlugins}}}},
+		},
+	}}, testRegistry(), testDefaults())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		networkType v1.NetworkType
+		expected    string
+		filters     int
+	}{
+		{name: "empty network type is single-tenant", networkType: "", expected: string(v1.NetworkTypeSingleTenant), filters: 1},
+		{name: "multi-tenant profile", networkType: v1.NetworkTypeMultiTenant, expected: string(v1.NetworkTypeMultiTenant), filters: 0},
+		{name: "network type without a profile", networkType: v1.NetworkTypeDisconnected, expected: DefaultProfileName, filters: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := s.ForLease(&v1.Lease{Spec: v1.LeaseSpec{NetworkType: tt.networkType}})
+			if f.ProfileName() != tt.expected {
+				t.Errorf("ForLease() = %s, expected %s", f.ProfileName(), tt.expected)
+			}
+			if len(f.filterPlugins) != tt.filters {
+				t.Errorf("expected %d filter plugins, got %d", tt.filters, len(f.filterPlugins))
+			}
+		})
+	}
+}
+
+func TestFairShareConfig(t *testing.T) {
+	s, err := New(nil, testRegistry(), testDefaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.FairShare() != nil {
+		t.Errorf("expected fair sharing to be disabled by default")
+	}
+
+	s, err = New(&Config{FairShare: &FairShareConfig{Shares: map[string]float64{"periodic": 2}}}, testRegistry(), testDefaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.FairShare() != nil {
+		t.Errorf("expected fair sharing to be disabled without a tenant key")
+	}
+
+	s, err = New(&Config{FairShare: &FairShareConfig{TenantKey: TenantKeyJobType}}, testRegistry(), testDefaults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.FairShare() == nil {
+		t.Fatalf("expected fair sharing to be enabled")
+	}
+	config := s.FairShare().Config()
+	if config.TenantKey != TenantKeyJobType || config.DefaultShare != 1 || config.HalfLife.Duration != DefaultFairShareHalfLife {
+		t.Errorf("unexpected default fair share config: %+v", config)
+	}
+}
@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"reflect"
+
+	"go.opentelemetry.io/otel/attribute"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the attributes of the spans of API writes.
+const (
+	KindKey        = attribute.Key("k8s.kind")
+	NamespaceKey   = attribute.Key("k8s.namespace.name")
+	NameKey        = attribute.Key("k8s.object.name")
+	SubResourceKey = attribute.Key("k8s.subresource")
+)
+
+// WrapClient returns a client recording a span for each object it creates, updates, patches or deletes. reads
+// are not traced.
+func WrapClient(c client.Client) client.Client {
+	return &tracingClient{Client: c}
+}
+
+type tracingClient struct {
+	client.Client
+}
+
+// kindOf returns the kind of obj, from its type if its kind is not set, as for typed objects.
+func kindOf(obj client.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// traceWrite runs write in a span named after the verb and kind of the write, such as "Update Lease".
+func traceWrite(ctx context.Context, verb string, obj client.Object, subResource string, write func(ctx context.Context) error) error {
+	kind := kindOf(obj)
+	name := verb + " " + kind
+	attributes := []attribute.KeyValue{KindKey.String(kind), NamespaceKey.String(obj.GetNamespace()), NameKey.String(obj.GetName())}
+	if subResource != "" {
+		name += " " + subResource
+		attributes = append(attributes, SubResourceKey.String(subResource))
+	}
+	ctx, span := Start(ctx, name, attributes...)
+	defer span.End()
+	err := write(ctx)
+	RecordError(span, err)
+	return err
+}
+
+func (c *tracingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return traceWrite(ctx, "Create", obj, "", func(ctx context.Context) error {
+		return c.Client.Create(ctx, obj, opts...)
+	})
+}
+
+func (c *tracingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return traceWrite(ctx, "Update", obj, "", func(ctx context.Context) error {
+		return c.Client.Update(ctx, obj, opts...)
+	})
+}
+
+func (c *tracingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return traceWrite(ctx, "Patch", obj, "", func(ctx context.Context) error {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	})
+}
+
+func (c *tracingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return traceWrite(ctx, "Delete", obj, "", func(ctx context.Context) error {
+		return c.Client.Delete(ctx, obj, opts...)
+	})
+}
+
+func (c *tracingClient) Status() client.SubResourceWriter {
+	return &tracingSubResourceWriter{SubResourceWriter: c.Client.Status(), subResource: "status"}
+}
+
+func (c *tracingClient) SubResource(subResource string) client.SubResourceClient {
+	sub := c.Client.SubResource(subResource)
+	return &tracingSubResourceClient{
+		SubResourceClient: sub,
+		writer:            &tracingSubResourceWriter{SubResourceWriter: sub, subResource: subResource},
+	}
+}
+
+type tracingSubResourceWriter struct {
+	client.SubResourceWriter
+	subResource string
+}
+
+func (w *tracingSubResourceWriter) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return traceWrite(ctx, "Create", obj, w.subResource, func(ctx context.Context) error {
+		return w.SubResourceWriter.Create(ctx, obj, subResource, opts...)
+	})
+}
+
+func (w *tracingSubResourceWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return traceWrite(ctx, "Update", obj, w.subResource, func(ctx context.Context) error {
+		return w.SubResourceWriter.Update(ctx, obj, opts...)
+	})
+}
+
+func (w *tracingSubResourceWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return traceWrite(ctx, "Patch", obj, w.subResource, func(ctx context.Context) error {
+		return w.SubResourceWriter.Patch(ctx, obj, patch, opts...)
+	})
+}
+
+type tracingSubResourceClient struct {
+	client.SubResourceClient
+	writer *tracingSubResourceWriter
+}
+
+func (c *tracingSubResourceClient) Create(ctx context.Context, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+	return c.writer.Create(ctx, obj, subResource, opts...)
+}
+
+func (c *tracingSubResourceClient) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	return c.writer.Update(ctx, obj, opts...)
+}
+
+func (c *tracingSubResourceClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+	return c.writer.Patch(ctx, obj, patch, opts...)
+}
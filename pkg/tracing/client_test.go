@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+// recordingClient records the leases it updates.
+type recordingClient struct {
+	client.Client
+	updated []string
+}
+
+func (c *recordingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return errors.New("the update is not traced")
+	}
+	c.updated = append(c.updated, obj.GetName())
+	return nil
+}
+
+func TestWrapClient(t *testing.T) {
+	out := &bytes.Buffer{}
+	exporter := &writerExporter{out: out}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+	previous := tracerProvider
+	tracerProvider = func() trace.TracerProvider { return provider }
+	defer func() { tracerProvider = previous }()
+
+	recording := &recordingClient{}
+	c := WrapClient(recording)
+	lease := &v1.Lease{ObjectMeta: metav1.ObjectMeta{Name: "lease-1", Namespace: "ci"}}
+	if err := c.Update(context.Background(), lease); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(recording.updated) != 1 {
+		t.Fatalf("expected the lease to be updated, got %v", recording.updated)
+	}
+	if !strings.Contains(out.String(), `"name":"Update Lease"`) || !strings.Contains(out.String(), `{"key":"k8s.object.name","value":{"stringValue":"lease-1"}}`) {
+		t.Errorf("expected a span for the update, got %s", out.String())
+	}
+}
@@ -0,0 +1,317 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	// otlpTracesPath is the path of the traces of an OTLP/HTTP endpoint.
+	otlpTracesPath = "/v1/traces"
+	// otlpTimeout is the timeout of each export request.
+	otlpTimeout = 10 * time.Second
+)
+
+// the OTLP/JSON encoding of an ExportTraceServiceRequest. ids are hex encoded and 64 bit integers are strings.
+type exportRequest struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type resourceSpans struct {
+	Resource   otlpResource `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+	SchemaURL  string       `json:"schemaUrl,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type scopeSpans struct {
+	Scope     otlpScope `json:"scope"`
+	Spans     []span    `json:"spans"`
+	SchemaURL string    `json:"schemaUrl,omitempty"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Events            []event    `json:"events,omitempty"`
+	Links             []link     `json:"links,omitempty"`
+	Status            status     `json:"status"`
+}
+
+type event struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []keyValue `json:"attributes,omitempty"`
+}
+
+type link struct {
+	TraceID    string     `json:"traceId"`
+	SpanID     string     `json:"spanId"`
+	Attributes []keyValue `json:"attributes,omitempty"`
+}
+
+type status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// the OTLP status codes, which differ from the codes of the API.
+const (
+	otlpStatusOK    = 1
+	otlpStatusError = 2
+)
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue *string     `json:"stringValue,omitempty"`
+	BoolValue   *bool       `json:"boolValue,omitempty"`
+	IntValue    *string     `json:"intValue,omitempty"`
+	DoubleValue *float64    `json:"doubleValue,omitempty"`
+	ArrayValue  *arrayValue `json:"arrayValue,omitempty"`
+}
+
+type arrayValue struct {
+	Values []anyValue `json:"values"`
+}
+
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func encodeValue(value attribute.Value) anyValue {
+	switch value.Type() {
+	case attribute.BOOL:
+		v := value.AsBool()
+		return anyValue{BoolValue: &v}
+	case attribute.INT64:
+		v := strconv.FormatInt(value.AsInt64(), 10)
+		return anyValue{IntValue: &v}
+	case attribute.FLOAT64:
+		v := value.AsFloat64()
+		return anyValue{DoubleValue: &v}
+	case attribute.BOOLSLICE:
+		values := []anyValue{}
+		for _, v := range value.AsBoolSlice() {
+			values = append(values, encodeValue(attribute.BoolValue(v)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case attribute.INT64SLICE:
+		values := []anyValue{}
+		for _, v := range value.AsInt64Slice() {
+			values = append(values, encodeValue(attribute.Int64Value(v)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case attribute.FLOAT64SLICE:
+		values := []anyValue{}
+		for _, v := range value.AsFloat64Slice() {
+			values = append(values, encodeValue(attribute.Float64Value(v)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	case attribute.STRINGSLICE:
+		values := []anyValue{}
+		for _, v := range value.AsStringSlice() {
+			values = append(values, encodeValue(attribute.StringValue(v)))
+		}
+		return anyValue{ArrayValue: &arrayValue{Values: values}}
+	default:
+		v := value.Emit()
+		return anyValue{StringValue: &v}
+	}
+}
+
+func encodeAttributes(attributes []attribute.KeyValue) []keyValue {
+	var encoded []keyValue
+	for _, kv := range attributes {
+		encoded = append(encoded, keyValue{Key: string(kv.Key), Value: encodeValue(kv.Value)})
+	}
+	return encoded
+}
+
+func encodeSpan(s sdktrace.ReadOnlySpan) span {
+	encoded := span{
+		TraceID: s.SpanContext().TraceID().String(),
+		SpanID:  s.SpanContext().SpanID().String(),
+		Name:    s.Name(),
+		// the span kinds of the API have the values of OTLP.
+		Kind:              int(s.SpanKind()),
+		StartTimeUnixNano: unixNano(s.StartTime()),
+		EndTimeUnixNano:   unixNano(s.EndTime()),
+		Attributes:        encodeAttributes(s.Attributes()),
+	}
+	if s.Parent().HasSpanID() {
+		encoded.ParentSpanID = s.Parent().SpanID().String()
+	}
+	for _, e := range s.Events() {
+		encoded.Events = append(encoded.Events, event{TimeUnixNano: unixNano(e.Time), Name: e.Name, Attributes: encodeAttributes(e.Attributes)})
+	}
+	for _, l := range s.Links() {
+		encoded.Links = append(encoded.Links, link{
+			TraceID:    l.SpanContext.TraceID().String(),
+			SpanID:     l.SpanContext.SpanID().String(),
+			Attributes: encodeAttributes(l.Attributes),
+		})
+	}
+	switch s.Status().Code {
+	case codes.Ok:
+		encoded.Status.Code = otlpStatusOK
+	case codes.Error:
+		encoded.Status = status{Code: otlpStatusError, Message: s.Status().Description}
+	}
+	return encoded
+}
+
+// encodeSpans returns the spans as an export request, grouped by resource and instrumentation scope.
+func encodeSpans(spans []sdktrace.ReadOnlySpan) exportRequest {
+	type scopeKey struct {
+		resource attribute.Distinct
+		scope    instrumentation.Scope
+	}
+	request := exportRequest{ResourceSpans: []resourceSpans{}}
+	resourceIndex := map[attribute.Distinct]int{}
+	scopeIndex := map[scopeKey]int{}
+	for _, s := range spans {
+		res := s.Resource()
+		if res == nil {
+			res = resource.Empty()
+		}
+		ri, ok := resourceIndex[res.Equivalent()]
+		if !ok {
+			ri = len(request.ResourceSpans)
+			resourceIndex[res.Equivalent()] = ri
+			request.ResourceSpans = append(request.ResourceSpans, resourceSpans{
+				Resource:  otlpResource{Attributes: encodeAttributes(res.Attributes())},
+				SchemaURL: res.SchemaURL(),
+			})
+		}
+
+		key := scopeKey{resource: res.Equivalent(), scope: s.InstrumentationScope()}
+		si, ok := scopeIndex[key]
+		if !ok {
+			si = len(request.ResourceSpans[ri].ScopeSpans)
+			scopeIndex[key] = si
+			request.ResourceSpans[ri].ScopeSpans = append(request.ResourceSpans[ri].ScopeSpans, scopeSpans{
+				Scope:     otlpScope{Name: key.scope.Name, Version: key.scope.Version},
+				SchemaURL: key.scope.SchemaURL,
+			})
+		}
+		scope := &request.ResourceSpans[ri].ScopeSpans[si]
+		scope.Spans = append(scope.Spans, encodeSpan(s))
+	}
+	return request
+}
+
+// httpExporter posts the spans to an OTLP/HTTP endpoint.
+type httpExporter struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPExporter returns an exporter posting the spans as OTLP/JSON to an OTLP/HTTP endpoint, such as
+// http://otel-collector:4318. /v1/traces is used if the endpoint has no path.
+func NewHTTPExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q, expected a URL such as http://otel-collector:4318", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = otlpTracesPath
+	}
+	return &httpExporter{url: u.String(), client: &http.Client{Timeout: otlpTimeout}}, nil
+}
+
+func (e *httpExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	body, err := json.Marshal(encodeSpans(spans))
+	if err != nil {
+		return fmt.Errorf("error encoding spans: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error exporting spans to %s: %w", e.url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("error exporting spans to %s: %s", e.url, resp.Status)
+	}
+	return nil
+}
+
+func (e *httpExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// writerExporter writes each batch of spans as an OTLP/JSON export request on its own line, the format of the
+// file exporter of the OpenTelemetry collector.
+type writerExporter struct {
+	lock sync.Mutex
+	out  io.Writer
+}
+
+// NewFileExporter returns an exporter appending the spans to a file, or writing them to stdout if path is "-".
+func NewFileExporter(path string) (sdktrace.SpanExporter, error) {
+	if path == "-" {
+		return &writerExporter{out: os.Stdout}, nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening trace file: %w", err)
+	}
+	return &writerExporter{out: f}, nil
+}
+
+func (e *writerExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	line, err := json.Marshal(encodeSpans(spans))
+	if err != nil {
+		return fmt.Errorf("error encoding spans: %w", err)
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	_, err = e.out.Write(append(line, '\n'))
+	return err
+}
+
+func (e *writerExporter) Shutdown(ctx context.Context) error {
+	if f, ok := e.out.(*os.File); ok && f != os.Stdout {
+		return f.Close()
+	}
+	return nil
+}
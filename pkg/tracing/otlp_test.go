@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newTestTracer returns a tracer exporting every span as soon as it ends.
+func newTestTracer(exporter sdktrace.SpanExporter) (trace.Tracer, *sdktrace.TracerProvider) {
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return provider.Tracer(instrumentationName), provider
+}
+
+func TestWriterExporter(t *testing.T) {
+	out := &bytes.Buffer{}
+	tracer, provider := newTestTracer(&writerExporter{out: out})
+
+	ctx, parent := tracer.Start(context.Background(), "ScheduleLease", trace.WithAttributes(Lease("ci", "lease-1")...))
+	_, child := tracer.Start(ctx, "FilterPools", trace.WithAttributes(
+		attribute.Int("pools", 3),
+		attribute.StringSlice("excluded", []string{"vcenter-1"}),
+	))
+	RecordError(child, errors.New("no pools available"))
+	child.End()
+	parent.End()
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a line per exported span, got %q", out.String())
+	}
+	var childRequest, parentRequest exportRequest
+	if err := json.Unmarshal([]byte(lines[0]), &childRequest); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &parentRequest); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	childSpan := childRequest.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	parentSpan := parentRequest.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	if childSpan.TraceID != parentSpan.TraceID || childSpan.ParentSpanID != parentSpan.SpanID || parentSpan.ParentSpanID != "" {
+		t.Errorf("expected FilterPools to be a child of ScheduleLease, got %+v and %+v", childSpan, parentSpan)
+	}
+	if len(parentSpan.TraceID) != 32 || len(parentSpan.SpanID) != 16 {
+		t.Errorf("expected hex encoded ids, got %s and %s", parentSpan.TraceID, parentSpan.SpanID)
+	}
+	if childSpan.Status.Code != otlpStatusError || childSpan.Status.Message != "no pools available" {
+		t.Errorf("expected the error status, got %+v", childSpan.Status)
+	}
+	if len(childSpan.Events) != 1 || childSpan.Events[0].Name != "exception" {
+		t.Errorf("expected the error to be recorded, got %+v", childSpan.Events)
+	}
+	if name := childRequest.ResourceSpans[0].ScopeSpans[0].Scope.Name; name != instrumentationName {
+		t.Errorf("expected the scope %s, got %s", instrumentationName, name)
+	}
+
+	if !strings.Contains(lines[0], `{"key":"pools","value":{"intValue":"3"}}`) ||
+		!strings.Contains(lines[0], `{"key":"excluded","value":{"arrayValue":{"values":[{"stringValue":"vcenter-1"}]}}}`) {
+		t.Errorf("unexpected attributes %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `{"key":"lease.name","value":{"stringValue":"lease-1"}}`) {
+		t.Errorf("expected the lease attributes, got %s", lines[1])
+	}
+}
+
+func TestHTTPExporter(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+	}))
+	defer server.Close()
+
+	exporter, err := NewHTTPExporter(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	tracer, provider := newTestTracer(exporter)
+	_, span := tracer.Start(context.Background(), "ReconcileLease")
+	span.End()
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+
+	r := <-requests
+	if r.Method != http.MethodPost || r.URL.Path != otlpTracesPath || r.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected request %s %s %s", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+	}
+	request := exportRequest{}
+	if err := json.Unmarshal(<-bodies, &request); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if spans := request.ResourceSpans[0].ScopeSpans[0].Spans; len(spans) != 1 || spans[0].Name != "ReconcileLease" {
+		t.Errorf("unexpected spans %+v", spans)
+	}
+
+	for _, endpoint := range []string{"otel-collector:4318", "ftp://otel-collector", "http://"} {
+		if _, err := NewHTTPExporter(endpoint); err == nil {
+			t.Errorf("expected an error for the endpoint %s", endpoint)
+		}
+	}
+}
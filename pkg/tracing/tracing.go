@@ -0,0 +1,91 @@
+// Package tracing records OpenTelemetry traces of the reconciling and scheduling of leases, and exports them as
+// OTLP/JSON to an OTLP/HTTP endpoint or to a file.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ServiceName is the service.name of the traces.
+	ServiceName = "vsphere-capacity-manager"
+
+	instrumentationName = "github.com/openshift-splat-team/vsphere-capacity-manager"
+)
+
+// tracerProvider returns the provider of the spans, the global one set by otel.SetTracerProvider.
+var tracerProvider = otel.GetTracerProvider
+
+// the attributes of the spans.
+const (
+	LeaseNamespaceKey = attribute.Key("lease.namespace")
+	LeaseNameKey      = attribute.Key("lease.name")
+	PoolNameKey       = attribute.Key("pool.name")
+)
+
+// Lease returns the attributes identifying a lease.
+func Lease(namespace, name string) []attribute.KeyValue {
+	return []attribute.KeyValue{LeaseNamespaceKey.String(namespace), LeaseNameKey.String(name)}
+}
+
+// Start starts a span, a child of the span in ctx if there is one. the span is dropped unless a provider is
+// registered with otel.SetTracerProvider.
+func Start(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracerProvider().Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// RecordError marks the span as failed with err, if err is not nil.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// TraceID returns the ID of the trace in ctx, or an empty string if ctx is not traced.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsSampled() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// NewProvider returns a provider exporting the spans to an OTLP/HTTP endpoint and to a file, "-" for stdout.
+// nil is returned if neither is set, tracing is disabled.
+func NewProvider(endpoint, file string) (*sdktrace.TracerProvider, error) {
+	var options []sdktrace.TracerProviderOption
+	if endpoint != "" {
+		exporter, err := NewHTTPExporter(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	if file != "" {
+		exporter, err := NewFileExporter(file)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	if len(options) == 0 {
+		return nil, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("error creating trace resource: %w", err)
+	}
+	options = append(options, sdktrace.WithResource(res))
+	return sdktrace.NewTracerProvider(options...), nil
+}
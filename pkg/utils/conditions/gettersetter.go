@@ -25,6 +25,8 @@ func getWrapperObject(from interface{}) GetterSetter {
 	switch obj := from.(type) {
 	case *v1.Lease:
 		return &LeaseWrapper{obj}
+	case *v1.Network:
+		return &NetworkWrapper{obj}
 	default:
 		panic("type is not supported as conditions getter or setter")
 	}
@@ -46,6 +48,27 @@ func hasSameState(i, j *v1.Condition) bool {
 		i.Message == j.Message
 }
 
+// Get returns the condition with the given type, or nil if it is not set.
+func Get(from interface{}, t v1.ConditionType) *v1.Condition {
+	if from == nil {
+		return nil
+	}
+
+	obj := getWrapperObject(from)
+	for _, condition := range obj.GetConditions() {
+		if condition.Type == t {
+			return &condition
+		}
+	}
+	return nil
+}
+
+// IsTrue returns true if the condition with the given type is set and True.
+func IsTrue(from interface{}, t v1.ConditionType) bool {
+	condition := Get(from, t)
+	return condition != nil && condition.Status == v1.ConditionTrue
+}
+
 // Set sets the given condition.
 //
 // NOTE: If a condition already exists, the LastTransitionTime is updated only if a change is detected
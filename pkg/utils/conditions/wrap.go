@@ -15,3 +15,15 @@ func (m *LeaseWrapper) GetConditions() []v1.Condition {
 func (m *LeaseWrapper) SetConditions(conditions []v1.Condition) {
 	m.Status.Conditions = conditions
 }
+
+type NetworkWrapper struct {
+	*v1.Network
+}
+
+func (m *NetworkWrapper) GetConditions() []v1.Condition {
+	return m.Status.Conditions
+}
+
+func (m *NetworkWrapper) SetConditions(conditions []v1.Condition) {
+	m.Status.Conditions = conditions
+}
@@ -2,10 +2,9 @@ package utils
 
 import (
 	"fmt"
-	"math/rand"
-	"sort"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 
 	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
 )
@@ -17,8 +16,14 @@ const (
 	PoolInsufficientVCPU    = "Insufficient VCPU"
 	PoolInsufficientMemory  = "Insufficient memory"
 	PoolLabelMismatch       = "Pool labels do not match poolSelector"
+	PoolInvalidSelector     = "Lease poolSelector is invalid"
 	PoolTaintNotTolerated   = "Pool has taints not tolerated by lease"
 	PoolVCenterLimitReached = "Pool vCenter limit reached"
+	PoolAlreadyAssigned     = "Pool already assigned to lease"
+
+	PoolTopologyUnknown          = "Pool has no value for topology key"
+	PoolTopologyMaxSkewExceeded  = "Pool topology domain would exceed maxSkew"
+	PoolTopologyMinDomainsNotMet = "Pool topology domain already used, minDomains requires a new domain"
 )
 
 type PoolFittingInfo struct {
@@ -44,8 +49,19 @@ func tolerationMatchesTaint(toleration *v1.Toleration, taint *v1.Taint) bool {
 	return toleration.Key == taint.Key && toleration.Value == taint.Value
 }
 
-// LeaseToleratesPoolTaints checks if a lease has tolerations for all of a pool's taints.
-// Returns true if the lease can be scheduled on the pool, false otherwise.
+// leaseToleratesTaint checks if any of the lease's tolerations match the taint.
+func leaseToleratesTaint(lease *v1.Lease, taint *v1.Taint) bool {
+	for i := range lease.Spec.Tolerations {
+		if tolerationMatchesTaint(&lease.Spec.Tolerations[i], taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// LeaseToleratesPoolTaints checks if a lease has tolerations for all of a pool's NoSchedule taints.
+// Returns true if the lease can be scheduled on the pool, false otherwise. PreferNoSchedule taints
+// never prevent scheduling; they are penalized during scoring instead.
 func LeaseToleratesPoolTaints(lease *v1.Lease, pool *v1.Pool) bool {
 	// If pool has no taints, lease can always be scheduled
 	if len(pool.Spec.Taints) == 0 {
@@ -53,19 +69,14 @@ func LeaseToleratesPoolTaints(lease *v1.Lease, pool *v1.Pool) bool {
 	}
 
 	// Check each taint to see if it's tolerated
-	for _, taint := range pool.Spec.Taints {
-		tolerated := false
-
-		// Check if any of the lease's tolerations match this taint
-		for i := range lease.Spec.Tolerations {
-			if tolerationMatchesTaint(&lease.Spec.Tolerations[i], &taint) {
-				tolerated = true
-				break
-			}
+	for i := range pool.Spec.Taints {
+		taint := &pool.Spec.Taints[i]
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
 		}
 
 		// If this taint is not tolerated, the lease cannot be scheduled on this pool
-		if !tolerated {
+		if !leaseToleratesTaint(lease, taint) {
 			return false
 		}
 	}
@@ -74,23 +85,54 @@ func LeaseToleratesPoolTaints(lease *v1.Lease, pool *v1.Pool) bool {
 	return true
 }
 
-// PoolMatchesSelector checks if a pool's labels match the lease's poolSelector.
-// Returns true if all selector labels match the pool's labels.
-func PoolMatchesSelector(lease *v1.Lease, pool *v1.Pool) bool {
-	// If no selector is specified, pool matches
-	if len(lease.Spec.PoolSelector) == 0 {
-		return true
+// CountIntolerablePreferNoScheduleTaints returns the number of PreferNoSchedule taints on the pool which
+// the lease does not tolerate.
+func CountIntolerablePreferNoScheduleTaints(lease *v1.Lease, pool *v1.Pool) int {
+	count := 0
+	for i := range pool.Spec.Taints {
+		taint := &pool.Spec.Taints[i]
+		if taint.Effect != v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !leaseToleratesTaint(lease, taint) {
+			count++
+		}
 	}
+	return count
+}
 
-	// Check that all selector key-value pairs exist in the pool's labels
-	for key, value := range lease.Spec.PoolSelector {
-		poolValue, exists := pool.Labels[key]
-		if !exists || poolValue != value {
-			return false
+// LeaseHasPool returns true if the lease has an owner reference to the named pool.
+func LeaseHasPool(lease *v1.Lease, poolName string) bool {
+	for _, ownerRef := range lease.OwnerReferences {
+		if ownerRef.Kind == "Pool" && ownerRef.Name == poolName {
+			return true
 		}
 	}
+	return false
+}
 
-	return true
+// LeasePoolSelector returns the labels.Selector built from the lease's poolSelector and
+// poolSelectorExpressions. An empty selector matches every pool.
+func LeasePoolSelector(lease *v1.Lease) (labels.Selector, error) {
+	if len(lease.Spec.PoolSelector) == 0 && len(lease.Spec.PoolSelectorExpressions) == 0 {
+		return labels.Everything(), nil
+	}
+
+	return metav1.LabelSelectorAsSelector(&metav1.LabelSelector{
+		MatchLabels:      lease.Spec.PoolSelector,
+		MatchExpressions: lease.Spec.PoolSelectorExpressions,
+	})
+}
+
+// PoolMatchesSelector checks if a pool's labels match the lease's poolSelector and poolSelectorExpressions.
+// Returns true if all selector requirements match the pool's labels. An invalid selector matches no pools.
+func PoolMatchesSelector(lease *v1.Lease, pool *v1.Pool) bool {
+	selector, err := LeasePoolSelector(lease)
+	if err != nil {
+		return false
+	}
+
+	return selector.Matches(labels.Set(pool.Labels))
 }
 
 // GetVCentersInUse returns the set of distinct vCenter Server FQDNs already used
@@ -105,96 +147,8 @@ func GetVCentersInUse(assignedPools []*v1.Pool) map[string]bool {
 	return vcenters
 }
 
-// GetFittingPools returns a list of pools that have enough resources to satisfy the resource requirements and a list of
-// PoolFittingInfo specifying why pool is not a match.
-// The list is sorted by the sum of the resource usage of the pool. The pool with the least resource usage is first.
-// excludedVCenters is an optional set of vCenter Server FQDNs to exclude from consideration (used to enforce
-// the lease's VCenters cap). Pass nil or an empty map for no vcenter constraint.
-func GetFittingPools(lease *v1.Lease, pools []*v1.Pool, excludedVCenters map[string]bool) ([]*v1.Pool, []*PoolFittingInfo) {
-	var fittingPools []*v1.Pool
-	poolResults := []*PoolFittingInfo{}
-
-	for _, pool := range pools {
-		// Check if this pool is already owned by the lease
-		alreadyOwned := false
-		for _, ownerRef := range lease.OwnerReferences {
-			if ownerRef.Kind == "Pool" && ownerRef.Name == pool.Name {
-				alreadyOwned = true
-				break
-			}
-		}
-		if alreadyOwned {
-			poolResults = append(poolResults, &PoolFittingInfo{Pool: pool, MatchResults: "Pool already assigned to lease"})
-			continue
-		}
-
-		if pool.Spec.NoSchedule {
-			poolResults = append(poolResults, &PoolFittingInfo{Pool: pool, MatchResults: PoolNotSchedulable})
-			continue
-		}
-		nameMatch := len(lease.Spec.RequiredPool) > 0 && lease.Spec.RequiredPool == pool.ObjectMeta.Name
-		if !nameMatch && pool.Spec.Exclude {
-			poolResults = append(poolResults, &PoolFittingInfo{Pool: pool, MatchResults: PoolExcluded})
-			continue
-		}
-		if len(lease.Spec.RequiredPool) > 0 && !nameMatch {
-			poolResults = append(poolResults, &PoolFittingInfo{Pool: pool, MatchResults: PoolNotMatchRequired})
-			continue
-		}
-		// Check if pool labels match the lease's poolSelector
-		if !PoolMatchesSelector(lease, pool) {
-			poolResults = append(poolResults, &PoolFittingInfo{Pool: pool, MatchResults: PoolLabelMismatch})
-			continue
-		}
-		// Check if lease tolerates all pool taints
-		if !LeaseToleratesPoolTaints(lease, pool) {
-			poolResults = append(poolResults, &PoolFittingInfo{Pool: pool, MatchResults: PoolTaintNotTolerated})
-			continue
-		}
-
-		// If a vCenter cap is in effect, skip pools on excluded vCenters
-		// This check comes after fundamental pool properties and lease requirements
-		// to ensure more specific rejection reasons are reported first
-		if len(excludedVCenters) > 0 && excludedVCenters[pool.Spec.Server] {
-			poolResults = append(poolResults, &PoolFittingInfo{Pool: pool, MatchResults: PoolVCenterLimitReached})
-			continue
-		}
-		if int(pool.Status.VCpusAvailable) >= lease.Spec.VCpus &&
-			int(pool.Status.MemoryAvailable) >= lease.Spec.Memory {
-			fittingPools = append(fittingPools, pool)
-		} else {
-			var reason string
-			if pool.Status.VCpusAvailable < lease.Spec.VCpus {
-				reason = PoolInsufficientVCPU
-			} else if pool.Status.MemoryAvailable < lease.Spec.Memory {
-				reason = PoolInsufficientMemory
-			} else {
-				reason = fmt.Sprintf("[%v, %v]", PoolInsufficientVCPU, PoolInsufficientMemory)
-			}
-
-			poolResults = append(poolResults, &PoolFittingInfo{Pool: pool, MatchResults: reason})
-		}
-	}
-	sort.Slice(fittingPools, func(i, j int) bool {
-		iPool := fittingPools[i]
-		jPool := fittingPools[j]
-		cpuScoreI := float64(iPool.Status.VCpusAvailable) / float64(iPool.Spec.VCpus)
-		memoryScoreI := float64(iPool.Status.MemoryAvailable) / float64(iPool.Spec.Memory)
-		cpuScoreJ := float64(jPool.Status.VCpusAvailable) / float64(jPool.Spec.VCpus)
-		memoryScoreJ := float64(jPool.Status.MemoryAvailable) / float64(jPool.Spec.Memory)
-
-		return cpuScoreI+memoryScoreI > cpuScoreJ+memoryScoreJ
-	})
-	return fittingPools, poolResults
-}
-
-func shuffleFittingPools(pools []*v1.Pool) {
-	rand.Shuffle(len(pools), func(i, j int) {
-		pools[i], pools[j] = pools[j], pools[i]
-	})
-}
-
-func generatePoolResults(results []*PoolFittingInfo) []string {
+// GeneratePoolResults formats the reason each pool was rejected for a lease.
+func GeneratePoolResults(results []*PoolFittingInfo) []string {
 	var poolResults []string
 
 	for _, result := range results {
@@ -202,43 +156,3 @@ func generatePoolResults(results []*PoolFittingInfo) []string {
 	}
 	return poolResults
 }
-
-// GetPoolWithStrategy returns a pool that has enough resources to satisfy the lease requirements.
-// excludedVCenters is an optional set of vCenter Server FQDNs to exclude (enforces the VCenters cap).
-// Pass nil for no vcenter constraint.
-func GetPoolWithStrategy(lease *v1.Lease, pools []*v1.Pool, strategy v1.AllocationStrategy, excludedVCenters map[string]bool) (*v1.Pool, error) {
-	fittingPools, results := GetFittingPools(lease, pools, excludedVCenters)
-
-	if len(fittingPools) == 0 {
-		return nil, fmt.Errorf("no pools available. %v", generatePoolResults(results))
-	}
-	switch strategy {
-	case v1.RESOURCE_ALLOCATION_STRATEGY_RANDOM:
-		shuffleFittingPools(fittingPools)
-		fallthrough
-	case v1.RESOURCE_ALLOCATION_STRATEGY_UNDERUTILIZED:
-		fallthrough
-	default:
-		pool := fittingPools[0]
-
-		// Check if this pool is already an owner reference
-		alreadyOwner := false
-		for _, ref := range lease.OwnerReferences {
-			if ref.Kind == "Pool" && ref.Name == pool.Name {
-				alreadyOwner = true
-				break
-			}
-		}
-
-		if !alreadyOwner {
-			lease.OwnerReferences = append(lease.OwnerReferences, metav1.OwnerReference{
-				APIVersion: pool.APIVersion,
-				Kind:       pool.Kind,
-				Name:       pool.Name,
-				UID:        pool.UID,
-			})
-		}
-
-		return pool, nil
-	}
-}
@@ -0,0 +1,210 @@
+package utils
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+const (
+	// MaxPoolScore is the highest score a PoolScorer may return for a pool.
+	MaxPoolScore = float64(100)
+
+	LeastAllocatedScorerName        = "LeastAllocated"
+	PreferredPoolAffinityScorerName = "PreferredPoolAffinity"
+	PoolAntiAffinityScorerName      = "PoolAntiAffinity"
+	TaintTolerationScorerName       = "TaintToleration"
+	PoolHealthScorerName            = "PoolHealth"
+)
+
+// PoolScorer ranks the pools which passed filtering for a lease. Like kube-scheduler score plugins, a scorer
+// produces a raw score per pool which is then normalized across all candidate pools to [0, MaxPoolScore].
+type PoolScorer interface {
+	// Name returns the name of the scorer.
+	Name() string
+	// Weight returns the multiplier applied to the normalized score.
+	Weight() float64
+	// Score returns the raw score of a pool for the lease.
+	Score(lease *v1.Lease, pool *v1.Pool) float64
+	// NormalizeScores scales raw scores, keyed by pool name, to [0, MaxPoolScore].
+	NormalizeScores(scores map[string]float64)
+}
+
+// PoolScore is the weighted sum of all scorer results for a pool.
+type PoolScore struct {
+	Pool  *v1.Pool
+	Score float64
+}
+
+// normalizeToMax scales scores so the highest score becomes MaxPoolScore. when reverse is true, the highest
+// raw score becomes 0 and the lowest becomes MaxPoolScore.
+func normalizeToMax(scores map[string]float64, reverse bool) {
+	maxScore := float64(0)
+	for _, score := range scores {
+		if score > maxScore {
+			maxScore = score
+		}
+	}
+
+	for name, score := range scores {
+		normalized := MaxPoolScore
+		if maxScore > 0 {
+			normalized = score * MaxPoolScore / maxScore
+		} else if !reverse {
+			normalized = 0
+		}
+		if reverse && maxScore > 0 {
+			normalized = MaxPoolScore - normalized
+		}
+		scores[name] = normalized
+	}
+}
+
+// leastAllocatedScorer prefers pools with the largest share of free vCPUs and memory.
+type leastAllocatedScorer struct {
+	weight float64
+}
+
+func (s *leastAllocatedScorer) Name() string    { return LeastAllocatedScorerName }
+func (s *leastAllocatedScorer) Weight() float64 { return s.weight }
+
+func (s *leastAllocatedScorer) Score(lease *v1.Lease, pool *v1.Pool) float64 {
+	cpuScore := float64(0)
+	if pool.Spec.VCpus > 0 {
+		cpuScore = float64(pool.Status.VCpusAvailable) / float64(pool.Spec.VCpus)
+	}
+	memoryScore := float64(0)
+	if pool.Spec.Memory > 0 {
+		memoryScore = float64(pool.Status.MemoryAvailable) / float64(pool.Spec.Memory)
+	}
+	return (cpuScore + memoryScore) * MaxPoolScore / 2
+}
+
+// NormalizeScores leaves the free ratio as is. It is already bound by MaxPoolScore unless the pool is
+// overcommitted, which still ranks correctly.
+func (s *leastAllocatedScorer) NormalizeScores(scores map[string]float64) {}
+
+// preferredPoolAffinityScorer adds the weight of every preferredPoolAffinity term a pool matches.
+type preferredPoolAffinityScorer struct {
+	weight float64
+}
+
+func (s *preferredPoolAffinityScorer) Name() string    { return PreferredPoolAffinityScorerName }
+func (s *preferredPoolAffinityScorer) Weight() float64 { return s.weight }
+
+func (s *preferredPoolAffinityScorer) Score(lease *v1.Lease, pool *v1.Pool) float64 {
+	score := float64(0)
+	for i := range lease.Spec.PreferredPoolAffinity {
+		term := &lease.Spec.PreferredPoolAffinity[i]
+		selector, err := metav1.LabelSelectorAsSelector(&term.Preference)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(pool.Labels)) {
+			score += float64(term.Weight)
+		}
+	}
+	return score
+}
+
+func (s *preferredPoolAffinityScorer) NormalizeScores(scores map[string]float64) {
+	normalizeToMax(scores, false)
+}
+
+// poolAntiAffinityScorer penalizes pools holding leases which match a poolAntiAffinity term of the lease.
+type poolAntiAffinityScorer struct {
+	weight float64
+	leases []*v1.Lease
+}
+
+func (s *poolAntiAffinityScorer) Name() string    { return PoolAntiAffinityScorerName }
+func (s *poolAntiAffinityScorer) Weight() float64 { return s.weight }
+
+func (s *poolAntiAffinityScorer) Score(lease *v1.Lease, pool *v1.Pool) float64 {
+	penalty := float64(0)
+	for i := range lease.Spec.PoolAntiAffinity {
+		term := &lease.Spec.PoolAntiAffinity[i]
+		selector, err := metav1.LabelSelectorAsSelector(&term.LeaseSelector)
+		if err != nil {
+			continue
+		}
+		for _, other := range s.leases {
+			if other.Name == lease.Name && other.Namespace == lease.Namespace {
+				continue
+			}
+			if !LeaseHasPool(other, pool.Name) {
+				continue
+			}
+			if selector.Matches(labels.Set(other.Labels)) {
+				penalty += float64(term.Weight)
+			}
+		}
+	}
+	return penalty
+}
+
+func (s *poolAntiAffinityScorer) NormalizeScores(scores map[string]float64) {
+	normalizeToMax(scores, true)
+}
+
+// taintTolerationScorer penalizes pools with PreferNoSchedule taints which the lease does not tolerate.
+type taintTolerationScorer struct {
+	weight float64
+}
+
+func (s *taintTolerationScorer) Name() string    { return TaintTolerationScorerName }
+func (s *taintTolerationScorer) Weight() float64 { return s.weight }
+
+func (s *taintTolerationScorer) Score(lease *v1.Lease, pool *v1.Pool) float64 {
+	return float64(CountIntolerablePreferNoScheduleTaints(lease, pool))
+}
+
+func (s *taintTolerationScorer) NormalizeScores(scores map[string]float64) {
+	normalizeToMax(scores, true)
+}
+
+// poolHealthScorer penalizes pools by the share of their recent leases which reported an infrastructure failure.
+type poolHealthScorer struct {
+	weight float64
+}
+
+func (s *poolHealthScorer) Name() string    { return PoolHealthScorerName }
+func (s *poolHealthScorer) Weight() float64 { return s.weight }
+
+func (s *poolHealthScorer) Score(lease *v1.Lease, pool *v1.Pool) float64 {
+	if pool.Status.Health == nil {
+		return MaxPoolScore
+	}
+	return MaxPoolScore - float64(pool.Status.Health.InfraFailurePercent)
+}
+
+// NormalizeScores leaves the scores as is, they are already bound by MaxPoolScore. Normalizing would turn a
+// small difference in failure rate into the full score range.
+func (s *poolHealthScorer) NormalizeScores(scores map[string]float64) {}
+
+// NewLeastAllocatedScorer returns a scorer preferring pools with the most free capacity.
+func NewLeastAllocatedScorer(weight float64) PoolScorer {
+	return &leastAllocatedScorer{weight: weight}
+}
+
+// NewPreferredPoolAffinityScorer returns a scorer preferring pools matching the lease's preferredPoolAffinity.
+func NewPreferredPoolAffinityScorer(weight float64) PoolScorer {
+	return &preferredPoolAffinityScorer{weight: weight}
+}
+
+// NewPoolAntiAffinityScorer returns a scorer penalizing pools which hold any of the provided leases matching the
+// lease's poolAntiAffinity.
+func NewPoolAntiAffinityScorer(weight float64, leases []*v1.Lease) PoolScorer {
+	return &poolAntiAffinityScorer{weight: weight, leases: leases}
+}
+
+// NewTaintTolerationScorer returns a scorer penalizing pools with untolerated PreferNoSchedule taints.
+func NewTaintTolerationScorer(weight float64) PoolScorer {
+	return &taintTolerationScorer{weight: weight}
+}
+
+// NewPoolHealthScorer returns a scorer penalizing pools with a high rate of infrastructure failures.
+func NewPoolHealthScorer(weight float64) PoolScorer {
+	return &poolHealthScorer{weight: weight}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+)
+
+func scoringTestPool(name string, poolLabels map[string]string, vcpusAvailable int, taints []v1.Taint) *v1.Pool {
+	return &v1.Pool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: poolLabels,
+		},
+		Spec: v1.PoolSpec{
+			VCpus:  100,
+			Memory: 100,
+			Taints: taints,
+		},
+		Status: v1.PoolStatus{
+			VCpusAvailable:  vcpusAvailable,
+			MemoryAvailable: vcpusAvailable,
+		},
+	}
+}
+
+func TestCountIntolerablePreferNoScheduleTaints(t *testing.T) {
+	pool := scoringTestPool("pool1", nil, 50, []v1.Taint{
+		{Key: "slow-storage", Effect: v1.TaintEffectPreferNoSchedule},
+		{Key: "old-hardware", Effect: v1.TaintEffectPreferNoSchedule},
+		{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+	})
+
+	tests := []struct {
+		name     string
+		lease    *v1.Lease
+		expected int
+	}{
+		{
+			name:     "no tolerations",
+			lease:    &v1.Lease{},
+			expected: 2,
+		},
+		{
+			name: "one PreferNoSchedule taint tolerated",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					Tolerations: []v1.Toleration{
+						{Key: "slow-storage", Operator: v1.TolerationOpExists},
+					},
+				},
+			},
+			expected: 1,
+		},
+		{
+			name: "wildcard toleration tolerates all taints",
+			lease: &v1.Lease{
+				Spec: v1.LeaseSpec{
+					Tolerations: []v1.Toleration{
+						{Operator: v1.TolerationOpExists},
+					},
+				},
+			},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := CountIntolerablePreferNoScheduleTaints(tt.lease, pool)
+			if result != tt.expected {
+				t.Errorf("CountIntolerablePreferNoScheduleTaints() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLeaseToleratesPoolTaintsIgnoresPreferNoSchedule(t *testing.T) {
+	pool := scoringTestPool("pool1", nil, 50, []v1.Taint{
+		{Key: "slow-storage", Effect: v1.TaintEffectPreferNoSchedule},
+	})
+	if !LeaseToleratesPoolTaints(&v1.Lease{}, pool) {
+		t.Errorf("expected PreferNoSchedule taint to not prevent scheduling")
+	}
+}
@@ -61,6 +61,19 @@ func GetLeasePoolRefs(lease *v1.Lease) []metav1.OwnerReference {
 	return poolRefs
 }
 
+// GetLeaseAllocatedResources returns the resources a lease holds in each of its pools. leases which were never
+// fulfilled hold the resources in their spec.
+func GetLeaseAllocatedResources(lease *v1.Lease) v1.LeaseResources {
+	if lease.Status.Allocated != nil {
+		return *lease.Status.Allocated
+	}
+	return v1.LeaseResources{
+		VCpus:    lease.Spec.VCpus,
+		Memory:   lease.Spec.Memory,
+		Networks: lease.Spec.Networks,
+	}
+}
+
 // DoesLeaseHaveAllPools checks if a lease has all required pools assigned
 func DoesLeaseHaveAllPools(lease *v1.Lease) bool {
 	requiredPools := lease.Spec.Pools
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/openshift-splat-team/vsphere-capacity-manager/pkg/apis/vspherecapacitymanager.splat.io/v1"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/client/clientset/versioned"
+	"github.com/openshift-splat-team/vsphere-capacity-manager/pkg/restapi"
+)
+
+var _ = Describe("Lease API", func() {
+	const (
+		namespaceName = "default"
+		token         = "api-test-token"
+	)
+	var server *httptest.Server
+
+	request := func(method, path, body string) (int, string) {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		content, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return resp.StatusCode, string(content)
+	}
+
+	BeforeEach(func() {
+		By("starting the lease API")
+		api := &restapi.Server{
+			Client: versioned.NewForConfigOrDie(cfg),
+			Tokens: []restapi.Token{{Name: "test", Token: token, Namespaces: []string{namespaceName}}},
+		}
+		server = httptest.NewServer(api.Handler())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create, wait for and delete a lease", func() {
+		By("creating a lease")
+		code, body := request(http.MethodPost, "/api/v1/namespaces/default/leases",
+			`{"name": "api-lease-1", "vcpus": 24, "memory": 96, "boskosLeaseID": "api-boskos-1"}`)
+		Expect(code).To(Equal(http.StatusCreated), body)
+
+		lease := &v1.Lease{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespaceName, Name: "api-lease-1"}, lease)).To(Succeed())
+		Expect(lease.Labels).To(HaveKeyWithValue(v1.BoskosIdLabel, "api-boskos-1"))
+		Expect(lease.Spec.Networks).To(Equal(1))
+
+		By("refusing the env vars of a pending lease")
+		code, body = request(http.MethodGet, "/api/v1/namespaces/default/leases/api-lease-1/env", "")
+		Expect(code).To(Equal(http.StatusConflict), body)
+
+		By("waiting for the lease to be fulfilled")
+		responses := make(chan string)
+		go func() {
+			defer GinkgoRecover()
+			code, body := request(http.MethodGet, "/api/v1/namespaces/default/leases/api-lease-1?wait=30s", "")
+			Expect(code).To(Equal(http.StatusOK), body)
+			responses <- body
+		}()
+		lease.Status.Phase = v1.PHASE_FULFILLED
+		lease.Status.EnvVars = "export GOVC_URL=vcenter"
+		Expect(k8sClient.Status().Update(ctx, lease)).To(Succeed())
+
+		response := &restapi.Lease{}
+		Eventually(responses).Should(Receive(WithTransform(func(body string) error {
+			return json.Unmarshal([]byte(body), response)
+		}, Succeed())))
+		Expect(response.Phase).To(Equal(v1.PHASE_FULFILLED))
+
+		code, body = request(http.MethodGet, "/api/v1/namespaces/default/leases/api-lease-1/env", "")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("export GOVC_URL=vcenter\n"))
+
+		By("deleting the lease")
+		code, body = request(http.MethodDelete, "/api/v1/namespaces/default/leases/api-lease-1", "")
+		Expect(code).To(Equal(http.StatusNoContent), body)
+		Eventually(func() bool {
+			return apierrors.IsNotFound(k8sClient.Get(ctx, types.NamespacedName{Namespace: namespaceName, Name: "api-lease-1"}, &v1.Lease{}))
+		}).Should(BeTrue())
+	})
+
+	It("should reject leases in other namespaces", func() {
+		code, body := request(http.MethodPost, "/api/v1/namespaces/kube-system/leases", `{"vcpus": 24, "memory": 96}`)
+		Expect(code).To(Equal(http.StatusForbidden), body)
+	})
+
+	It("should list pools", func() {
+		code, body := request(http.MethodGet, "/api/v1/pools", "")
+		Expect(code).To(Equal(http.StatusOK), body)
+		pools := []restapi.Pool{}
+		Expect(json.Unmarshal([]byte(body), &pools)).To(Succeed(), fmt.Sprintf("unexpected pools %s", body))
+	})
+})
@@ -29,13 +29,13 @@ func makeProwAnnotations(jobType, jobName string) map[string]string {
 
 	switch {
 	case jobType == controller.PERIODICAL_JOB_TYPE:
-		annotations[controller.PROW_JOB_TYPE_KEY] = controller.PERIODICAL_JOB_TYPE
+		annotations[v1.PROW_JOB_TYPE_KEY] = controller.PERIODICAL_JOB_TYPE
 		annotations[controller.PROW_JOB_KEY] = jobName
 		annotations[controller.PROW_BUILD_ID_KEY] = "123456"
 		annotations[controller.PROW_GS_BUCKET_KEY] = "test-platform-results"
 		annotations[controller.PROW_JOB_URL_PREFIX_KEY] = "https://prow.ci.openshift.org/view/"
 	case jobType == controller.PRESUBMIT_JOB_TYPE:
-		annotations[controller.PROW_JOB_TYPE_KEY] = controller.PRESUBMIT_JOB_TYPE
+		annotations[v1.PROW_JOB_TYPE_KEY] = controller.PRESUBMIT_JOB_TYPE
 		annotations[controller.PROW_JOB_KEY] = jobName
 		annotations[controller.PROW_BUILD_ID_KEY] = "654321"
 		annotations[controller.PROW_GS_BUCKET_KEY] = "test-platform-results"
@@ -78,7 +78,7 @@ func (r *lease) WithShape(shape shape) *lease {
 }
 
 func (r *lease) WithBoskosID(boskosID string) *lease {
-	r.lease.Labels[controller.BoskosIdLabel] = boskosID
+	r.lease.Labels[v1.BoskosIdLabel] = boskosID
 
 	return r
 }